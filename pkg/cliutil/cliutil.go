@@ -1,12 +1,14 @@
 package cliutil
 
 import (
-	"bufio"
 	"errors"
 	"fmt"
+	"io"
 	"os"
+	"strconv"
 	"strings"
 
+	"github.com/dansimau/yas/pkg/xexec"
 	"github.com/olekukonko/tablewriter"
 	"golang.org/x/term"
 )
@@ -17,6 +19,7 @@ func PrintTable(rows [][]string) {
 	table.SetHeaderAlignment(tablewriter.ALIGN_LEFT)
 	table.SetHeader(rows[0])
 	table.SetBorder(false)
+	table.SetAutoWrapText(false)
 
 	for _, row := range rows[1:] {
 		table.Append(row)
@@ -25,16 +28,47 @@ func PrintTable(rows [][]string) {
 	table.Render()
 }
 
+// ErrNonInteractive is returned by prompts that need input from the user when
+// running in non-interactive mode.
+var ErrNonInteractive = errors.New("input required, but running in non-interactive mode")
+
+// NonInteractive returns true if prompts are disabled, e.g. for CI and
+// scripts, by setting YAS_NONINTERACTIVE=1. In non-interactive mode,
+// confirmations and other prompts with a default use the default (unless
+// AssumeYes), and prompts without one fail with ErrNonInteractive.
+func NonInteractive() bool {
+	v := os.Getenv("YAS_NONINTERACTIVE")
+	return v != "" && v != "0" && v != "false"
+}
+
+// AssumeYes returns true if confirmations are answered "yes" in
+// non-interactive mode, by setting YAS_YES=1.
+func AssumeYes() bool {
+	v := os.Getenv("YAS_YES")
+	return v != "" && v != "0" && v != "false"
+}
+
+// Quiet returns true if output other than errors is suppressed, e.g. for cron
+// jobs, by setting YAS_QUIET=1.
+func Quiet() bool {
+	v := os.Getenv("YAS_QUIET")
+	return v != "" && v != "0" && v != "false"
+}
+
 type PromptOptions struct {
 	Text      string
 	Default   string
 	Validator func(input string) error
 }
 
-func Prompt(opts PromptOptions) string {
-Prompt:
-	scanner := bufio.NewScanner(os.Stdin)
+// Prompt outputs the text and returns the user's input, or the default if the
+// input is empty. Input is requested again until it passes the validator.
+func Prompt(opts PromptOptions) (string, error) {
+	if NonInteractive() {
+		return nonInteractivePrompt(opts)
+	}
 
+Prompt:
 	if opts.Text != "" {
 		fmt.Fprint(os.Stderr, opts.Text+" ")
 	}
@@ -43,12 +77,12 @@ Prompt:
 		fmt.Fprintf(os.Stderr, "[%s] ", opts.Default)
 	}
 
-	scanner.Scan()
-	if err := scanner.Err(); err != nil {
+	line, err := readLine(os.Stdin)
+	if err != nil {
 		panic(err)
 	}
 
-	input := strings.TrimSpace(scanner.Text())
+	input := strings.TrimSpace(line)
 
 	if opts.Validator != nil {
 		if err := opts.Validator(input); err != nil {
@@ -58,10 +92,155 @@ Prompt:
 	}
 
 	if input == "" && opts.Default != "" {
-		return opts.Default
+		return opts.Default, nil
+	}
+
+	return input, nil
+}
+
+// nonInteractivePrompt returns the default of the prompt without reading any
+// input, or ErrNonInteractive if there is no valid default.
+func nonInteractivePrompt(opts PromptOptions) (string, error) {
+	if opts.Default == "" {
+		return "", fmt.Errorf("%s: %w", strings.TrimSpace(opts.Text), ErrNonInteractive)
+	}
+
+	if opts.Validator != nil {
+		if err := opts.Validator(opts.Default); err != nil {
+			return "", fmt.Errorf("%s: %w", strings.TrimSpace(opts.Text), err)
+		}
+	}
+
+	if !Quiet() {
+		fmt.Fprintf(os.Stderr, "%s %s (non-interactive)\n", opts.Text, opts.Default)
+	}
+
+	return opts.Default, nil
+}
+
+// readLine reads a line from f without buffering, so that input after the
+// line is left for subsequent prompts.
+func readLine(f *os.File) (string, error) {
+	line := []byte{}
+	b := make([]byte, 1)
+
+	for {
+		n, err := f.Read(b)
+		if n > 0 {
+			if b[0] == '\n' {
+				return string(line), nil
+			}
+
+			line = append(line, b[0])
+		}
+
+		if errors.Is(err, io.EOF) {
+			return string(line), nil
+		}
+
+		if err != nil {
+			return "", err
+		}
+	}
+}
+
+// MultiSelect outputs a numbered list of the options and prompts the user to
+// select any number of them, e.g. "1,3-4". It returns the selected options,
+// in the order they were listed.
+func MultiSelect(message string, options []string) ([]string, error) {
+	if NonInteractive() {
+		return nil, fmt.Errorf("%s %w", message, ErrNonInteractive)
+	}
+
+	for i, option := range options {
+		fmt.Fprintf(os.Stderr, "%3d) %s\n", i+1, option)
+	}
+
+	var selected []int
+
+	if _, err := Prompt(PromptOptions{
+		Text: message,
+		Validator: func(input string) (err error) {
+			selected, err = parseSelection(input, len(options))
+			return err
+		},
+	}); err != nil {
+		return nil, err
+	}
+
+	result := []string{}
+	for _, i := range selected {
+		result = append(result, options[i])
+	}
+
+	return result, nil
+}
+
+// Select outputs a numbered list of the options and prompts the user to
+// select one of them. It returns the zero-based index of the selected option.
+// If the input is empty (or in non-interactive mode), the option at
+// defaultIndex is selected.
+func Select(message string, options []string, defaultIndex int) (int, error) {
+	for i, option := range options {
+		fmt.Fprintf(os.Stderr, "%3d) %s\n", i+1, option)
+	}
+
+	input, err := Prompt(PromptOptions{
+		Text:    message,
+		Default: strconv.Itoa(defaultIndex + 1),
+		Validator: func(input string) error {
+			if input == "" {
+				return nil
+			}
+
+			if n, err := strconv.Atoi(input); err != nil || n < 1 || n > len(options) {
+				return fmt.Errorf("invalid selection: %s (enter a number between 1 and %d)", input, len(options))
+			}
+
+			return nil
+		},
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	n, _ := strconv.Atoi(input)
+
+	return n - 1, nil
+}
+
+// parseSelection parses a selection of comma or space-separated numbers and
+// ranges (e.g. "1,3-4") of options numbered from 1 to n. It returns the
+// zero-based indices of the selected options, in order.
+func parseSelection(input string, n int) ([]int, error) {
+	selected := make([]bool, n)
+
+	fields := strings.FieldsFunc(input, func(r rune) bool { return r == ',' || r == ' ' })
+	for _, field := range fields {
+		from, to, isRange := strings.Cut(field, "-")
+		if !isRange {
+			to = from
+		}
+
+		start, err1 := strconv.Atoi(from)
+		end, err2 := strconv.Atoi(to)
+		if err1 != nil || err2 != nil || start < 1 || end > n || start > end {
+			return nil, fmt.Errorf("invalid selection: %s (enter numbers between 1 and %d, e.g. 1,3-4)", field, n)
+		}
+
+		for i := start; i <= end; i++ {
+			selected[i-1] = true
+		}
+	}
+
+	indices := []int{}
+	for i, ok := range selected {
+		if ok {
+			indices = append(indices, i)
+		}
 	}
 
-	return input
+	return indices, nil
 }
 
 // // PromptWithValidation prompts the user for input and returns the result.
@@ -123,12 +302,92 @@ func confirmationValidator(input string) error {
 }
 
 // Confirm outputs the message and prompts the user for a "yes" or "no"
-// response.
+// response. In non-interactive mode, the response is the default, or "yes" if
+// AssumeYes.
 func Confirm(message string, defaultIfEmpty bool) bool {
-	input := Prompt(PromptOptions{
+	if NonInteractive() {
+		result := defaultIfEmpty || AssumeYes()
+
+		if !Quiet() {
+			if result {
+				fmt.Fprintf(os.Stderr, "%s yes (non-interactive)\n", message)
+			} else {
+				fmt.Fprintf(os.Stderr, "%s no (non-interactive, hint: use --yes to answer yes)\n", message)
+			}
+		}
+
+		return result
+	}
+
+	input, _ := Prompt(PromptOptions{
 		Text:      message,
 		Validator: confirmationValidator,
 	})
 	result, _ := parseConfirmationInput(input, defaultIfEmpty)
 	return result
 }
+
+// EditText opens the editor with the specified text and returns the edited
+// result. Like git, the editor command is run with the shell, so it can
+// include arguments, e.g. "code --wait". If editor is empty, the editor
+// configured in the environment is used. Lines beginning with "#" are stripped
+// from the result. In non-interactive mode, the text is returned unedited.
+func EditText(editor, text string) (string, error) {
+	if NonInteractive() {
+		return stripComments(text), nil
+	}
+
+	if editor == "" {
+		editor = editorFromEnv()
+	}
+
+	f, err := os.CreateTemp("", "yas-*.txt")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(f.Name())
+
+	if _, err := f.WriteString(text); err != nil {
+		f.Close()
+		return "", err
+	}
+	f.Close()
+
+	if err := xexec.Command("sh", "-c", editor+` "$@"`, editor, f.Name()).Run(); err != nil {
+		return "", fmt.Errorf("editor failed: %w", err)
+	}
+
+	b, err := os.ReadFile(f.Name())
+	if err != nil {
+		return "", err
+	}
+
+	return stripComments(string(b)), nil
+}
+
+// stripComments removes lines beginning with "#" from the text, and
+// surrounding whitespace.
+func stripComments(text string) string {
+	lines := []string{}
+	for _, line := range strings.Split(text, "\n") {
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		lines = append(lines, line)
+	}
+
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// editorFromEnv returns the editor command the user has configured, using the
+// same environment variables as git.
+func editorFromEnv() string {
+	for _, envVar := range []string{"GIT_EDITOR", "VISUAL", "EDITOR"} {
+		if v := os.Getenv(envVar); v != "" {
+			return v
+		}
+	}
+
+	return "vi"
+}
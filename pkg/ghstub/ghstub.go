@@ -0,0 +1,583 @@
+// Package ghstub is a fake of the parts of the gh CLI that yas uses, for
+// integration tests and the demo repository. Pull requests are kept in a JSON
+// file instead of on GitHub, so that their state carries over from one command
+// to the next, and merging a PR merges its head into its base on origin.
+package ghstub
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dansimau/yas/pkg/gitexec"
+	"github.com/dansimau/yas/pkg/xexec"
+)
+
+const (
+	defaultRepository = "example/repo"
+	defaultUser       = "ghstub"
+)
+
+// PullRequest is a pull request on the fake GitHub. Its JSON fields are named
+// like the fields of `gh pr view --json`.
+type PullRequest struct {
+	ID                string    `json:"id"`
+	Number            int       `json:"number"`
+	State             string    `json:"state"`
+	URL               string    `json:"url"`
+	Title             string    `json:"title"`
+	Body              string    `json:"body"`
+	Author            Author    `json:"author"`
+	IsDraft           bool      `json:"isDraft"`
+	HeadRefName       string    `json:"headRefName"`
+	BaseRefName       string    `json:"baseRefName"`
+	CreatedAt         time.Time `json:"createdAt"`
+	UpdatedAt         time.Time `json:"updatedAt"`
+	MergedAt          time.Time `json:"mergedAt"`
+	StatusCheckRollup []Check   `json:"statusCheckRollup"`
+	ReviewDecision    string    `json:"reviewDecision"`
+
+	// AutoMerge is whether auto-merge was enabled. The fake never merges PRs
+	// by itself.
+	AutoMerge bool `json:"autoMerge,omitempty"`
+}
+
+type Author struct {
+	Login string `json:"login"`
+}
+
+// Check is a check run of a PR.
+type Check struct {
+	Name       string `json:"name"`
+	Status     string `json:"status"`
+	Conclusion string `json:"conclusion"`
+}
+
+// Stub is the state of the fake GitHub.
+type Stub struct {
+	// Repository is the owner and name of the fake repository, used in PR
+	// URLs.
+	Repository string `json:"repository"`
+
+	// User is the login of the authenticated user, i.e. @me.
+	User string `json:"user"`
+
+	PullRequests []*PullRequest `json:"pullRequests"`
+
+	path string
+}
+
+// Load returns the fake GitHub whose state is kept in the file, which is
+// created when the state is saved if it doesn't exist.
+func Load(path string) (*Stub, error) {
+	stub := &Stub{
+		Repository:   defaultRepository,
+		User:         defaultUser,
+		PullRequests: []*PullRequest{},
+		path:         path,
+	}
+
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return stub, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(b, stub); err != nil {
+		return nil, fmt.Errorf("failed to load %s: %w", path, err)
+	}
+
+	return stub, nil
+}
+
+// Save writes the state to the file it was loaded from.
+func (s *Stub) Save() error {
+	b, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(s.path, append(b, '\n'), 0o644)
+}
+
+// Run runs a gh command against the fake GitHub whose state is kept in the
+// file, as if gh was run in dir, and returns its stdout.
+func Run(path, dir string, args ...string) ([]byte, error) {
+	stub, err := Load(path)
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := stub.Run(dir, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return b, stub.Save()
+}
+
+// Run runs a gh command, as if gh was run in dir, and returns its stdout. The
+// state is not saved.
+func (s *Stub) Run(dir string, args ...string) ([]byte, error) {
+	if len(args) < 2 || args[0] != "pr" {
+		return nil, fmt.Errorf("ghstub: unsupported command: gh %s", strings.Join(args, " "))
+	}
+
+	subcommand, args := args[1], args[2:]
+
+	switch subcommand {
+	case "list":
+		return s.list(args)
+	case "view":
+		return s.view(dir, args)
+	case "create":
+		return s.create(dir, args)
+	case "edit":
+		return s.edit(dir, args)
+	case "ready":
+		return s.ready(dir, args)
+	case "merge":
+		return s.merge(dir, args)
+	case "checkout":
+		return s.checkout(dir, args)
+	default:
+		return nil, fmt.Errorf("ghstub: unsupported command: gh pr %s", subcommand)
+	}
+}
+
+// Create opens a PR from head to base.
+func (s *Stub) Create(head, base, title, body string, draft bool) (*PullRequest, error) {
+	for _, pr := range s.PullRequests {
+		if pr.State == "OPEN" && pr.HeadRefName == head {
+			return nil, fmt.Errorf("a pull request for branch %q into branch %q already exists:\n%s", head, pr.BaseRefName, pr.URL)
+		}
+	}
+
+	now := time.Now().UTC()
+	number := len(s.PullRequests) + 1
+
+	pr := &PullRequest{
+		ID:                fmt.Sprintf("PR_stub_%d", number),
+		Number:            number,
+		State:             "OPEN",
+		URL:               fmt.Sprintf("https://github.com/%s/pull/%d", s.Repository, number),
+		Title:             title,
+		Body:              body,
+		Author:            Author{Login: s.User},
+		IsDraft:           draft,
+		HeadRefName:       head,
+		BaseRefName:       base,
+		CreatedAt:         now,
+		UpdatedAt:         now,
+		StatusCheckRollup: []Check{},
+	}
+
+	s.PullRequests = append(s.PullRequests, pr)
+
+	return pr, nil
+}
+
+// Find returns the PR with the number or URL, or the open (or otherwise the
+// latest) PR whose head is the branch.
+func (s *Stub) Find(ref string) (*PullRequest, error) {
+	var found *PullRequest
+
+	for _, pr := range s.PullRequests {
+		switch {
+		case strconv.Itoa(pr.Number) == ref, pr.URL == ref:
+			return pr, nil
+		case pr.HeadRefName == ref && (found == nil || found.State != "OPEN"):
+			found = pr
+		}
+	}
+
+	if found == nil {
+		return nil, fmt.Errorf("no pull requests found for branch %q", ref)
+	}
+
+	return found, nil
+}
+
+// Merge merges the PR into its base on origin, using the method (merge,
+// squash or rebase) and the subject and body of the commit, if not empty.
+// Rebase merges are made as squash merges. dir is the repository to push to
+// origin from.
+func (s *Stub) Merge(dir string, pr *PullRequest, method, subject, body string) error {
+	if pr.State != "OPEN" {
+		return fmt.Errorf("pull request #%d is %s", pr.Number, strings.ToLower(pr.State))
+	}
+
+	if pr.IsDraft {
+		return fmt.Errorf("pull request #%d is still a draft", pr.Number)
+	}
+
+	if err := git(dir, "fetch", "-q", "origin"); err != nil {
+		return err
+	}
+
+	base, head := "origin/"+pr.BaseRefName, "origin/"+pr.HeadRefName
+
+	tree, err := gitOutput(dir, "merge-tree", "--write-tree", base, head)
+	if err != nil {
+		return fmt.Errorf("pull request #%d is not mergeable: the merge commit cannot be cleanly created", pr.Number)
+	}
+
+	commitArgs := []string{"commit-tree", strings.Fields(tree)[0], "-p", base}
+	if method == "merge" {
+		commitArgs = append(commitArgs, "-p", head)
+
+		if subject == "" {
+			subject = fmt.Sprintf("Merge pull request #%d from %s", pr.Number, pr.HeadRefName)
+		}
+	} else if subject == "" {
+		subject = fmt.Sprintf("%s (#%d)", pr.Title, pr.Number)
+	}
+
+	commitArgs = append(commitArgs, "-m", subject)
+	if body != "" {
+		commitArgs = append(commitArgs, "-m", body)
+	}
+
+	commit, err := gitOutput(dir, commitArgs...)
+	if err != nil {
+		return err
+	}
+
+	if err := git(dir, "push", "-q", "origin", strings.TrimSpace(commit)+":refs/heads/"+pr.BaseRefName); err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+
+	pr.State = "MERGED"
+	pr.MergedAt = now
+	pr.UpdatedAt = now
+	pr.AutoMerge = false
+
+	return nil
+}
+
+func (s *Stub) list(args []string) ([]byte, error) {
+	_, flags, err := parseFlags(args, nil, []string{"head", "base", "state", "author", "json", "limit", "search"})
+	if err != nil {
+		return nil, err
+	}
+
+	state := strings.ToUpper(flags["state"])
+	if state == "" {
+		state = "OPEN"
+	}
+
+	author := flags["author"]
+	if author == "@me" {
+		author = s.User
+	}
+
+	// Like gh, at most 30 PRs are listed by default
+	limit := 30
+	if flags["limit"] != "" {
+		if limit, err = strconv.Atoi(flags["limit"]); err != nil || limit < 1 {
+			return nil, fmt.Errorf("ghstub: invalid limit: %s", flags["limit"])
+		}
+	}
+
+	search, err := parseSearch(flags["search"])
+	if err != nil {
+		return nil, err
+	}
+
+	result := []*PullRequest{}
+	// Newest first, like gh
+	for i := len(s.PullRequests) - 1; i >= 0 && len(result) < limit; i-- {
+		pr := s.PullRequests[i]
+
+		switch {
+		case flags["head"] != "" && pr.HeadRefName != flags["head"]:
+		case flags["base"] != "" && pr.BaseRefName != flags["base"]:
+		case author != "" && pr.Author.Login != author:
+		case state != "ALL" && pr.State != state:
+		case !search(pr):
+		default:
+			result = append(result, pr)
+		}
+	}
+
+	return json.Marshal(result)
+}
+
+// parseSearch returns a function that reports whether a PR matches the search
+// query. Only merged date qualifiers are supported, e.g. merged:>=2024-01-31;
+// other search terms are an error.
+func parseSearch(query string) (func(pr *PullRequest) bool, error) {
+	filters := []func(pr *PullRequest) bool{}
+
+	for _, term := range strings.Fields(query) {
+		value, ok := strings.CutPrefix(term, "merged:")
+		if !ok {
+			return nil, fmt.Errorf("ghstub: unsupported search: %s", term)
+		}
+
+		operator := strings.TrimRight(value, "0123456789-")
+
+		day, err := time.Parse(time.DateOnly, strings.TrimPrefix(value, operator))
+		if err != nil {
+			return nil, fmt.Errorf("ghstub: unsupported search: %s", term)
+		}
+
+		nextDay := day.AddDate(0, 0, 1)
+
+		switch operator {
+		case "":
+			filters = append(filters, func(pr *PullRequest) bool { return !pr.MergedAt.Before(day) && pr.MergedAt.Before(nextDay) })
+		case ">=":
+			filters = append(filters, func(pr *PullRequest) bool { return !pr.MergedAt.Before(day) })
+		case ">":
+			filters = append(filters, func(pr *PullRequest) bool { return !pr.MergedAt.Before(nextDay) })
+		case "<=":
+			filters = append(filters, func(pr *PullRequest) bool { return !pr.MergedAt.IsZero() && pr.MergedAt.Before(nextDay) })
+		case "<":
+			filters = append(filters, func(pr *PullRequest) bool { return !pr.MergedAt.IsZero() && pr.MergedAt.Before(day) })
+		default:
+			return nil, fmt.Errorf("ghstub: unsupported search: %s", term)
+		}
+	}
+
+	return func(pr *PullRequest) bool {
+		for _, filter := range filters {
+			if !filter(pr) {
+				return false
+			}
+		}
+
+		return true
+	}, nil
+}
+
+func (s *Stub) view(dir string, args []string) ([]byte, error) {
+	pr, _, err := s.findFromArgs(dir, args, nil, []string{"json"})
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(pr)
+}
+
+func (s *Stub) create(dir string, args []string) ([]byte, error) {
+	_, flags, err := parseFlags(args, []string{"draft"}, []string{"head", "base", "title", "body"})
+	if err != nil {
+		return nil, err
+	}
+
+	head := flags["head"]
+	if head == "" {
+		if head, err = currentBranch(dir); err != nil {
+			return nil, err
+		}
+	}
+
+	if flags["base"] == "" {
+		return nil, errors.New("ghstub: --base is required")
+	}
+
+	pr, err := s.Create(head, flags["base"], flags["title"], flags["body"], flags["draft"] != "")
+	if err != nil {
+		return nil, err
+	}
+
+	return []byte(pr.URL + "\n"), nil
+}
+
+func (s *Stub) edit(dir string, args []string) ([]byte, error) {
+	pr, flags, err := s.findOpenFromArgs(dir, args, nil, []string{"base", "title", "body"})
+	if err != nil {
+		return nil, err
+	}
+
+	if base, ok := flags["base"]; ok {
+		pr.BaseRefName = base
+	}
+
+	if title, ok := flags["title"]; ok {
+		pr.Title = title
+	}
+
+	if body, ok := flags["body"]; ok {
+		pr.Body = body
+	}
+
+	pr.UpdatedAt = time.Now().UTC()
+
+	return []byte(pr.URL + "\n"), nil
+}
+
+func (s *Stub) ready(dir string, args []string) ([]byte, error) {
+	pr, flags, err := s.findOpenFromArgs(dir, args, []string{"undo"}, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	pr.IsDraft = flags["undo"] != ""
+	pr.UpdatedAt = time.Now().UTC()
+
+	return nil, nil
+}
+
+func (s *Stub) merge(dir string, args []string) ([]byte, error) {
+	pr, flags, err := s.findOpenFromArgs(dir, args, []string{"merge", "squash", "rebase", "admin", "auto", "disable-auto", "delete-branch"}, []string{"subject", "body"})
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case flags["disable-auto"] != "":
+		pr.AutoMerge = false
+	case flags["auto"] != "":
+		pr.AutoMerge = true
+	default:
+		method := "merge"
+		for _, m := range []string{"squash", "rebase"} {
+			if flags[m] != "" {
+				method = m
+			}
+		}
+
+		if err := s.Merge(dir, pr, method, flags["subject"], flags["body"]); err != nil {
+			return nil, err
+		}
+
+		return []byte(fmt.Sprintf("✓ Merged pull request #%d (%s)\n", pr.Number, pr.Title)), nil
+	}
+
+	pr.UpdatedAt = time.Now().UTC()
+
+	return nil, nil
+}
+
+func (s *Stub) checkout(dir string, args []string) ([]byte, error) {
+	pr, _, err := s.findFromArgs(dir, args, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := git(dir, "fetch", "-q", "origin"); err != nil {
+		return nil, err
+	}
+
+	if err := git(dir, "show-ref", "-q", "--verify", "refs/heads/"+pr.HeadRefName); err != nil {
+		return nil, git(dir, "checkout", "-q", "-b", pr.HeadRefName, "--track", "origin/"+pr.HeadRefName)
+	}
+
+	if err := git(dir, "checkout", "-q", pr.HeadRefName); err != nil {
+		return nil, err
+	}
+
+	return nil, git(dir, "merge", "-q", "--ff-only", "origin/"+pr.HeadRefName)
+}
+
+// findFromArgs parses the arguments of a command that operates on a PR, and
+// returns the PR specified by the positional argument, or the PR of the
+// current branch.
+func (s *Stub) findFromArgs(dir string, args, boolFlags, valueFlags []string) (*PullRequest, map[string]string, error) {
+	positional, flags, err := parseFlags(args, boolFlags, valueFlags)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var ref string
+	switch len(positional) {
+	case 0:
+		if ref, err = currentBranch(dir); err != nil {
+			return nil, nil, err
+		}
+	case 1:
+		ref = positional[0]
+	default:
+		return nil, nil, fmt.Errorf("ghstub: too many arguments: %s", strings.Join(positional, " "))
+	}
+
+	pr, err := s.Find(ref)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return pr, flags, nil
+}
+
+// findOpenFromArgs is like findFromArgs, but returns an error if the PR is
+// not open.
+func (s *Stub) findOpenFromArgs(dir string, args, boolFlags, valueFlags []string) (*PullRequest, map[string]string, error) {
+	pr, flags, err := s.findFromArgs(dir, args, boolFlags, valueFlags)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if pr.State != "OPEN" {
+		return nil, nil, fmt.Errorf("pull request #%d is %s", pr.Number, strings.ToLower(pr.State))
+	}
+
+	return pr, flags, nil
+}
+
+// parseFlags splits the arguments into positional arguments and flags, by
+// name. Boolean flags take no value and are "true" if specified; value flags
+// are specified as --name value or --name=value. Other flags are an error, so
+// that commands the fake doesn't support don't silently succeed.
+func parseFlags(args, boolFlags, valueFlags []string) (positional []string, flags map[string]string, err error) {
+	flags = map[string]string{}
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if !strings.HasPrefix(arg, "--") {
+			positional = append(positional, arg)
+			continue
+		}
+
+		name, value, hasValue := strings.Cut(strings.TrimPrefix(arg, "--"), "=")
+
+		switch {
+		case slices.Contains(boolFlags, name) && !hasValue:
+			flags[name] = "true"
+		case slices.Contains(valueFlags, name) && hasValue:
+			flags[name] = value
+		case slices.Contains(valueFlags, name) && i+1 < len(args):
+			i++
+			flags[name] = args[i]
+		default:
+			return nil, nil, fmt.Errorf("ghstub: unsupported flag: %s", arg)
+		}
+	}
+
+	return positional, flags, nil
+}
+
+func git(dir string, args ...string) error {
+	_, err := gitOutput(dir, args...)
+	return err
+}
+
+func gitOutput(dir string, args ...string) (string, error) {
+	args = append([]string{"git", "-c", "user.name=GitHub", "-c", "user.email=noreply@github.com"}, args...)
+
+	b, err := xexec.Command(args...).WithEnvVars(gitexec.CleanedGitEnv()).WithWorkingDir(dir).WithStdout(nil).WithStderr(nil).Output()
+	if err != nil {
+		return "", fmt.Errorf("ghstub: %s failed: %w", strings.Join(args, " "), err)
+	}
+
+	return string(b), nil
+}
+
+func currentBranch(dir string) (string, error) {
+	branch, err := gitOutput(dir, "branch", "--show-current")
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(branch), nil
+}
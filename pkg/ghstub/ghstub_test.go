@@ -0,0 +1,120 @@
+package ghstub
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestRun(t *testing.T) {
+	stubFile := path.Join(t.TempDir(), "github.json")
+	dir := t.TempDir()
+
+	run := func(args ...string) []byte {
+		t.Helper()
+
+		b, err := Run(stubFile, dir, args...)
+		assert.NilError(t, err)
+
+		return b
+	}
+
+	list := func(args ...string) []PullRequest {
+		t.Helper()
+
+		prs := []PullRequest{}
+		assert.NilError(t, json.Unmarshal(run(append([]string{"pr", "list"}, args...)...), &prs))
+
+		return prs
+	}
+
+	b := run("pr", "create", "--head", "topic-a", "--base", "main", "--title", "Add a", "--body", "", "--draft")
+	assert.Equal(t, string(b), "https://github.com/example/repo/pull/1\n")
+	run("pr", "create", "--head=topic-b", "--base=topic-a", "--title=Add b")
+
+	// A branch can only have one open PR
+	_, err := Run(stubFile, dir, "pr", "create", "--head", "topic-a", "--base", "main")
+	assert.ErrorContains(t, err, "already exists")
+
+	run("pr", "ready", "1")
+	run("pr", "edit", "topic-b", "--base", "main")
+
+	prs := list("--head", "topic-b", "--state", "all", "--json", "number,baseRefName")
+	assert.Equal(t, len(prs), 1)
+	assert.Equal(t, prs[0].Number, 2)
+	assert.Equal(t, prs[0].BaseRefName, "main")
+
+	prs = list("--author", "@me")
+	assert.Equal(t, len(prs), 2)
+	assert.Equal(t, prs[0].Number, 2, "newest first")
+
+	pr := PullRequest{}
+	assert.NilError(t, json.Unmarshal(run("pr", "view", "https://github.com/example/repo/pull/1"), &pr))
+	assert.Equal(t, pr.HeadRefName, "topic-a")
+	assert.Equal(t, pr.IsDraft, false)
+
+	run("pr", "merge", "2", "--auto", "--squash")
+	assert.NilError(t, json.Unmarshal(run("pr", "view", "topic-b"), &pr))
+	assert.Equal(t, pr.AutoMerge, true)
+	assert.Equal(t, pr.State, "OPEN")
+
+	_, err = Run(stubFile, dir, "pr", "view", "topic-c")
+	assert.ErrorContains(t, err, `no pull requests found for branch "topic-c"`)
+
+	// Commands and flags the fake doesn't support are not silently ignored
+	_, err = Run(stubFile, dir, "pr", "edit", "1", "--add-label", "bug")
+	assert.ErrorContains(t, err, "unsupported flag: --add-label")
+	_, err = Run(stubFile, dir, "api", "graphql")
+	assert.ErrorContains(t, err, "unsupported command")
+}
+
+func TestListSearchAndLimit(t *testing.T) {
+	stubFile := path.Join(t.TempDir(), "github.json")
+	dir := t.TempDir()
+
+	stub, err := Load(stubFile)
+	assert.NilError(t, err)
+
+	for i, mergedAt := range []string{"2024-01-10", "2024-01-20", "2024-01-31"} {
+		pr, err := stub.Create(fmt.Sprintf("topic-%d", i), "main", "", "", false)
+		assert.NilError(t, err)
+
+		pr.State = "MERGED"
+		pr.MergedAt, err = time.Parse(time.DateOnly, mergedAt)
+		assert.NilError(t, err)
+	}
+
+	assert.NilError(t, stub.Save())
+
+	list := func(args ...string) []string {
+		t.Helper()
+
+		b, err := Run(stubFile, dir, append([]string{"pr", "list", "--state", "merged"}, args...)...)
+		assert.NilError(t, err)
+
+		prs := []PullRequest{}
+		assert.NilError(t, json.Unmarshal(b, &prs))
+
+		heads := []string{}
+		for _, pr := range prs {
+			heads = append(heads, pr.HeadRefName)
+		}
+
+		return heads
+	}
+
+	assert.DeepEqual(t, list("--search", "merged:>=2024-01-20"), []string{"topic-2", "topic-1"})
+	assert.DeepEqual(t, list("--search", "merged:>2024-01-20"), []string{"topic-2"})
+	assert.DeepEqual(t, list("--search", "merged:<2024-01-20"), []string{"topic-0"})
+	assert.DeepEqual(t, list("--search", "merged:2024-01-20"), []string{"topic-1"})
+	assert.DeepEqual(t, list("--limit", "2"), []string{"topic-2", "topic-1"})
+
+	_, err = Run(stubFile, dir, "pr", "list", "--search", "is:draft")
+	assert.ErrorContains(t, err, "unsupported search: is:draft")
+	_, err = Run(stubFile, dir, "pr", "list", "--limit", "none")
+	assert.ErrorContains(t, err, "invalid limit: none")
+}
@@ -3,11 +3,18 @@ package gitexec
 import (
 	"errors"
 	"fmt"
+	"os"
 	"os/exec"
+	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
 
+	"github.com/dansimau/yas/pkg/fsutil"
+	"github.com/dansimau/yas/pkg/log"
 	"github.com/dansimau/yas/pkg/xexec"
 	"github.com/hashicorp/go-version"
+	"gopkg.in/alessio/shellescape.v1"
 )
 
 type CloneOptions struct {
@@ -30,18 +37,51 @@ func Clone(path string, options CloneOptions) error {
 
 type Repo struct {
 	path string
+
+	// signCommits causes commits created by rebase and commit operations to
+	// be signed (with --gpg-sign).
+	signCommits bool
+
+	// editor overrides the editor that interactive commits are edited with.
+	editor string
 }
 
 func WithRepo(path string) *Repo {
 	return &Repo{path: path}
 }
 
+// WithCommitSigning enables signing of all commits created by the repo's
+// rebase and commit operations.
+func (r *Repo) WithCommitSigning(enabled bool) *Repo {
+	r.signCommits = enabled
+	return r
+}
+
+// WithEditor sets the editor command that interactive commits are edited
+// with, overriding git's editor config. An empty editor uses git's editor.
+func (r *Repo) WithEditor(editor string) *Repo {
+	r.editor = editor
+	return r
+}
+
+// signArgs returns the arguments to add to rebase/commit commands to sign
+// commits, if enabled.
+func (r *Repo) signArgs() []string {
+	if r.signCommits {
+		return []string{"--gpg-sign"}
+	}
+
+	return nil
+}
+
 func (r *Repo) run(args ...string) error {
 	_, err := r.output(args...)
 	return err
 }
 
 func (r *Repo) output(args ...string) (string, error) {
+	log.Debug("Running:", strings.Join(args, " "))
+
 	b, err := xexec.Command(args...).
 		WithEnvVars(CleanedGitEnv()).
 		WithWorkingDir(r.path).
@@ -55,7 +95,17 @@ func (r *Repo) output(args ...string) (string, error) {
 }
 
 func (r *Repo) BranchExists(ref string) (bool, error) {
-	if err := r.run("git", "show-ref", fmt.Sprintf("refs/heads/%s", ref)); err != nil {
+	return r.refExists(fmt.Sprintf("refs/heads/%s", ref))
+}
+
+// RemoteBranchExists returns true if the remote-tracking branch exists, e.g.
+// origin/feature.
+func (r *Repo) RemoteBranchExists(ref string) (bool, error) {
+	return r.refExists(fmt.Sprintf("refs/remotes/%s", ref))
+}
+
+func (r *Repo) refExists(ref string) (bool, error) {
+	if err := r.run("git", "show-ref", ref); err != nil {
 		exitErr, isExitError := err.(*exec.ExitError)
 		if !isExitError {
 			return false, err
@@ -77,6 +127,34 @@ func (r *Repo) Checkout(ref string) error {
 	return r.run("git", "-c", "core.hooksPath=/dev/null", "checkout", "-q", ref)
 }
 
+// DetachHead detaches HEAD at the current commit, so that the branch is no
+// longer checked out. The working tree is left as it is.
+func (r *Repo) DetachHead() error {
+	return r.run("git", "-c", "core.hooksPath=/dev/null", "checkout", "-q", "--detach")
+}
+
+// CreateBranch creates a new branch at startPoint and checks it out.
+func (r *Repo) CreateBranch(branchName, startPoint string) error {
+	return r.run("git", "-c", "core.hooksPath=/dev/null", "checkout", "-q", "-b", branchName, startPoint)
+}
+
+// ForceBranch moves the branch (which must not be checked out) to ref.
+func (r *Repo) ForceBranch(branchName, ref string) error {
+	return r.run("git", "branch", "-q", "-f", branchName, ref)
+}
+
+// ResetKeep resets the current branch to ref, keeping local changes. It fails
+// if local changes would be overwritten.
+func (r *Repo) ResetKeep(ref string) error {
+	return r.run("git", "reset", "-q", "--keep", ref)
+}
+
+// TrackRemoteBranch creates a local branch from the branch of the same name on
+// the remote, without checking it out.
+func (r *Repo) TrackRemoteBranch(remote, branchName string) error {
+	return r.run("git", "branch", "-q", "--track", branchName, fmt.Sprintf("%s/%s", remote, branchName))
+}
+
 func (r *Repo) DeleteBranch(branch string) error {
 	return xexec.Command("git", "branch", "-D", branch).
 		WithEnvVars(CleanedGitEnv()).
@@ -84,6 +162,29 @@ func (r *Repo) DeleteBranch(branch string) error {
 		Run()
 }
 
+func (r *Repo) Fetch(remote string) error {
+	return r.run("git", "fetch", "-q", remote)
+}
+
+// FetchBranch updates the remote-tracking branch of the branch from remote.
+func (r *Repo) FetchBranch(remote, branchName string) error {
+	return r.run("git", "fetch", "-q", remote, fmt.Sprintf("+refs/heads/%s:refs/remotes/%s/%s", branchName, remote, branchName))
+}
+
+// FastForwardBranch updates the branch (which must not be checked out) to ref.
+// It fails if this is not a fast-forward.
+func (r *Repo) FastForwardBranch(branchName, ref string) error {
+	return r.run("git", "fetch", "-q", ".", fmt.Sprintf("%s:%s", ref, branchName))
+}
+
+func (r *Repo) MergeFastForward(ref string) error {
+	return r.run("git", "merge", "-q", "--ff-only", ref)
+}
+
+// ErrDetachedHead is returned by GetCurrentBranchName when HEAD is not a
+// branch.
+var ErrDetachedHead = errors.New("currently in detached state")
+
 func (r *Repo) GetCurrentBranchName() (string, error) {
 	s, err := r.output("git", "branch", "--show-current")
 	if err != nil {
@@ -91,7 +192,7 @@ func (r *Repo) GetCurrentBranchName() (string, error) {
 	}
 
 	if s == "" {
-		return "", errors.New("currently in detached state")
+		return "", ErrDetachedHead
 	}
 
 	return s, nil
@@ -101,6 +202,116 @@ func (r *Repo) GetLocalBranchNameForCommit(ref string) (string, error) {
 	return r.output("git", "branch", "--points-at", ref, "--format=%(refname:lstrip=2)")
 }
 
+// BranchRef is the tip of a local branch.
+type BranchRef struct {
+	Hash       string
+	CommitTime time.Time
+}
+
+// BranchRefs returns the tips of all local branches, keyed by branch name,
+// using a single git command.
+func (r *Repo) BranchRefs() (map[string]BranchRef, error) {
+	return r.refs("refs/heads", 2)
+}
+
+// RemoteBranchRefs returns the tips of the remote-tracking branches of the
+// remote, keyed by branch name without the remote, e.g. "topic-a" for
+// origin/topic-a.
+func (r *Repo) RemoteBranchRefs(remote string) (map[string]BranchRef, error) {
+	refs, err := r.refs("refs/remotes/"+remote, 3)
+	if err != nil {
+		return nil, err
+	}
+
+	delete(refs, "HEAD")
+
+	return refs, nil
+}
+
+// refs returns the tips of the refs under prefix, keyed by ref name with
+// the first lstrip path components removed.
+func (r *Repo) refs(prefix string, lstrip int) (map[string]BranchRef, error) {
+	format := fmt.Sprintf("--format=%%(refname:lstrip=%d)%%00%%(objectname)%%00%%(committerdate:unix)", lstrip)
+
+	s, err := r.output("git", "for-each-ref", format, prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	refs := map[string]BranchRef{}
+	for _, line := range strings.Split(s, "\n") {
+		fields := strings.Split(line, "\x00")
+		if len(fields) != 3 {
+			continue
+		}
+
+		unix, err := strconv.ParseInt(fields[2], 10, 64)
+		if err != nil {
+			return nil, err
+		}
+
+		refs[fields[0]] = BranchRef{
+			Hash:       fields[1],
+			CommitTime: time.Unix(unix, 0),
+		}
+	}
+
+	return refs, nil
+}
+
+// AheadBehind is the number of commits that are on a ref but not on another,
+// and vice versa.
+type AheadBehind struct {
+	Ahead  int `json:"ahead"`
+	Behind int `json:"behind"`
+}
+
+// AheadBehind returns how many commits ref is ahead of and behind other.
+// Commits with equivalent patches on both sides, e.g. the old and new
+// versions of rebased commits, are not counted.
+func (r *Repo) AheadBehind(ref, other string) (AheadBehind, error) {
+	s, err := r.output("git", "rev-list", "--left-right", "--count", "--cherry-pick", ref+"..."+other)
+	if err != nil {
+		return AheadBehind{}, err
+	}
+
+	fields := strings.Fields(s)
+	if len(fields) != 2 {
+		return AheadBehind{}, fmt.Errorf("unexpected output from git rev-list: %s", s)
+	}
+
+	ahead, err := strconv.Atoi(fields[0])
+	if err != nil {
+		return AheadBehind{}, err
+	}
+
+	behind, err := strconv.Atoi(fields[1])
+	if err != nil {
+		return AheadBehind{}, err
+	}
+
+	return AheadBehind{Ahead: ahead, Behind: behind}, nil
+}
+
+// CommitTime returns the committer date of the specified commit.
+func (r *Repo) CommitTime(ref string) (time.Time, error) {
+	s, err := r.output("git", "log", "-1", "--format=%ct", ref)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	unix, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return time.Unix(unix, 0), nil
+}
+
+func (r *Repo) GetMergeBase(a, b string) (string, error) {
+	return r.output("git", "merge-base", a, b)
+}
+
 func (r *Repo) GetForkPoint(branchName string) (ref string, err error) {
 	return r.output("git", "merge-base", "--fork-point", branchName)
 }
@@ -109,6 +320,125 @@ func (r *Repo) GetShortHash(ref string) (string, error) {
 	return r.output("git", "rev-parse", "--short", ref)
 }
 
+// GetHash resolves the specified commit-ish to a full commit hash.
+func (r *Repo) GetHash(ref string) (string, error) {
+	return r.output("git", "rev-parse", "--verify", "--quiet", ref+"^{commit}")
+}
+
+// ResolveCommits resolves each of the refs to a commit hash, like GetHash, but
+// with a single git process for all of them rather than one per ref. Refs that
+// don't resolve to a commit are omitted from the result.
+func (r *Repo) ResolveCommits(refs ...string) (map[string]string, error) {
+	hashes := map[string]string{}
+	if len(refs) == 0 {
+		return hashes, nil
+	}
+
+	input := strings.Builder{}
+	for _, ref := range refs {
+		input.WriteString(ref + "^{commit}\n")
+	}
+
+	log.Debug("Running: git cat-file --batch-check for", len(refs), "refs")
+
+	b, err := xexec.Command("git", "cat-file", "--batch-check=%(objectname)").
+		WithEnvVars(CleanedGitEnv()).
+		WithWorkingDir(r.path).
+		WithStdin(strings.NewReader(input.String())).
+		WithStdout(nil).
+		Output()
+	if err != nil {
+		return nil, err
+	}
+
+	// Each line is the hash, or the input followed by "missing" or
+	// "ambiguous"
+	for i, line := range strings.Split(strings.TrimSpace(string(b)), "\n") {
+		if i < len(refs) && line != "" && !strings.Contains(line, " ") {
+			hashes[refs[i]] = line
+		}
+	}
+
+	return hashes, nil
+}
+
+// IsAncestor returns true if ancestor is an ancestor of (or the same commit
+// as) ref.
+func (r *Repo) IsAncestor(ancestor, ref string) (bool, error) {
+	if err := r.run("git", "merge-base", "--is-ancestor", ancestor, ref); err != nil {
+		// Exit code 1 means it is not an ancestor
+		if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() == 1 {
+			return false, nil
+		}
+
+		return false, err
+	}
+
+	return true, nil
+}
+
+// CountCommits returns the number of commits reachable from ref but not from
+// upstream.
+func (r *Repo) CountCommits(upstream, ref string) (int, error) {
+	s, err := r.output("git", "rev-list", "--count", fmt.Sprintf("%s..%s", upstream, ref))
+	if err != nil {
+		return 0, err
+	}
+
+	return strconv.Atoi(s)
+}
+
+// DiffStat is a summary of the changes between two commits.
+type DiffStat struct {
+	Files      int
+	Insertions int
+	Deletions  int
+}
+
+// Lines returns the total number of lines changed.
+func (s DiffStat) Lines() int {
+	return s.Insertions + s.Deletions
+}
+
+// DiffStat returns a summary of the changes on ref since its merge base with
+// base.
+func (r *Repo) DiffStat(base, ref string) (DiffStat, error) {
+	return r.PathsDiffStat(base, ref)
+}
+
+// PathsDiffStat returns a summary of the changes to the paths on ref since its
+// merge base with base. All changes are included if no paths are specified.
+func (r *Repo) PathsDiffStat(base, ref string, paths ...string) (DiffStat, error) {
+	stat := DiffStat{}
+
+	args := []string{"git", "diff", "--shortstat", fmt.Sprintf("%s...%s", base, ref), "--"}
+
+	s, err := r.output(append(args, paths...)...)
+	if err != nil || s == "" {
+		return stat, err
+	}
+
+	// e.g. " 2 files changed, 10 insertions(+), 3 deletions(-)"
+	for _, part := range strings.Split(s, ",") {
+		var n int
+		var unit string
+		if _, err := fmt.Sscanf(strings.TrimSpace(part), "%d %s", &n, &unit); err != nil {
+			return stat, fmt.Errorf("failed to parse diff stat: %s", s)
+		}
+
+		switch {
+		case strings.HasPrefix(unit, "file"):
+			stat.Files = n
+		case strings.HasPrefix(unit, "insertion"):
+			stat.Insertions = n
+		case strings.HasPrefix(unit, "deletion"):
+			stat.Deletions = n
+		}
+	}
+
+	return stat, nil
+}
+
 func (r *Repo) Push() error {
 	return xexec.Command("git", "push").
 		WithEnvVars(CleanedGitEnv()).
@@ -116,13 +446,379 @@ func (r *Repo) Push() error {
 		Run()
 }
 
+// IsDirty returns true if there are uncommitted changes to tracked files in
+// the working tree or index.
+func (r *Repo) IsDirty() (bool, error) {
+	s, err := r.output("git", "status", "--porcelain", "--untracked-files=no")
+	if err != nil {
+		return false, err
+	}
+
+	return s != "", nil
+}
+
+func (r *Repo) StashPush(message string) error {
+	return r.run("git", "stash", "push", "-q", "-m", message)
+}
+
+func (r *Repo) StashPop() error {
+	return r.run("git", "stash", "pop", "-q")
+}
+
+// StashApply applies the latest stash without removing it from the stash
+// list.
+func (r *Repo) StashApply() error {
+	return r.run("git", "stash", "apply", "-q")
+}
+
+func (r *Repo) StashDrop() error {
+	return r.run("git", "stash", "drop", "-q")
+}
+
+// ResetHard discards all changes to tracked files in the working tree and
+// index.
+func (r *Repo) ResetHard() error {
+	return r.run("git", "reset", "-q", "--hard")
+}
+
+// PushBranch pushes the specified branch to origin, setting the upstream. A
+// force-with-lease push is used since stacked branches are regularly rebased.
+func (r *Repo) PushBranch(branchName string) error {
+	return xexec.Command("git", "push", "--force-with-lease", "--set-upstream", "origin", branchName).
+		WithEnvVars(CleanedGitEnv()).
+		WithWorkingDir(r.path).
+		Run()
+}
+
 func (r *Repo) Rebase(upstream, branchName string) error {
-	return xexec.Command("git", "-c", "core.hooksPath=/dev/null", "rebase", upstream, branchName, "--update-refs").
+	args := []string{"git", "-c", "core.hooksPath=/dev/null", "rebase", upstream, branchName, "--update-refs"}
+	return xexec.Command(append(args, r.signArgs()...)...).
+		WithEnvVars(CleanedGitEnv()).
+		WithWorkingDir(r.path).
+		Run()
+}
+
+type Commit struct {
+	Hash    string
+	Message string
+}
+
+// Commits returns the commits reachable from branchName but not from upstream,
+// oldest first.
+func (r *Repo) Commits(upstream, branchName string) ([]Commit, error) {
+	s, err := r.output("git", "log", "--reverse", "--format=%H%n%B%x00", fmt.Sprintf("%s..%s", upstream, branchName))
+	if err != nil {
+		return nil, err
+	}
+
+	commits := []Commit{}
+	for _, record := range strings.Split(s, "\x00") {
+		hash, message, _ := strings.Cut(strings.TrimSpace(record), "\n")
+		if hash == "" {
+			continue
+		}
+
+		commits = append(commits, Commit{
+			Hash:    hash,
+			Message: strings.TrimSpace(message),
+		})
+	}
+
+	return commits, nil
+}
+
+// CommitMessages returns the full commit messages of commits reachable from
+// branchName but not from upstream, oldest first.
+func (r *Repo) CommitMessages(upstream, branchName string) ([]string, error) {
+	commits, err := r.Commits(upstream, branchName)
+	if err != nil {
+		return nil, err
+	}
+
+	messages := []string{}
+	for _, commit := range commits {
+		messages = append(messages, commit.Message)
+	}
+
+	return messages, nil
+}
+
+// CommitSignatureStatuses returns the signature status (as per %G? in
+// git-log(1)) of each commit reachable from branchName but not from upstream.
+func (r *Repo) CommitSignatureStatuses(upstream, branchName string) ([]string, error) {
+	s, err := r.output("git", "log", "--format=%G?", fmt.Sprintf("%s..%s", upstream, branchName))
+	if err != nil {
+		return nil, err
+	}
+
+	if s == "" {
+		return nil, nil
+	}
+
+	return strings.Split(s, "\n"), nil
+}
+
+// Commit runs `git commit` interactively with the specified args, so that the
+// user's editor and hooks are run as usual.
+func (r *Repo) Commit(args ...string) error {
+	env := CleanedGitEnv()
+	if editor := r.Editor(); editor != "" {
+		env = append(env, "GIT_EDITOR="+editor)
+	}
+
+	cmdArgs := append([]string{"git", "commit"}, r.signArgs()...)
+	return xexec.Command(append(cmdArgs, args...)...).
+		WithEnvVars(env).
+		WithWorkingDir(r.path).
+		Run()
+}
+
+// Editor returns the editor command to edit messages with: the editor set with
+// WithEditor, or else the editor git uses (GIT_EDITOR, core.editor, VISUAL or
+// EDITOR), or an empty string if it can't be determined.
+func (r *Repo) Editor() string {
+	if r.editor != "" {
+		return r.editor
+	}
+
+	// The cleaned environment doesn't include GIT_EDITOR
+	if editor := os.Getenv("GIT_EDITOR"); editor != "" {
+		return editor
+	}
+
+	editor, err := r.output("git", "var", "GIT_EDITOR")
+	if err != nil {
+		log.Debug("Unable to determine git editor:", err)
+		return ""
+	}
+
+	return editor
+}
+
+func (r *Repo) CommitWithMessageFile(path string) error {
+	args := []string{"git", "-c", "core.hooksPath=/dev/null", "commit", "-q", "-F", path}
+	return r.run(append(args, r.signArgs()...)...)
+}
+
+// ConfigValue returns the value of the specified git config key, or an empty
+// string if it is not set.
+func (r *Repo) ConfigValue(key string) (string, error) {
+	s, err := r.output("git", "config", "--get", key)
+	if err != nil {
+		// Exit code 1 means the key is not set
+		if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() == 1 {
+			return "", nil
+		}
+
+		return "", err
+	}
+
+	return s, nil
+}
+
+// MergeConflicts performs a merge of theirs into ours in memory, without
+// touching the working tree or index, and returns the paths of any files that
+// would conflict.
+func (r *Repo) MergeConflicts(ours, theirs string) ([]string, error) {
+	b, err := xexec.Command("git", "merge-tree", "--write-tree", "--name-only", "--no-messages", ours, theirs).
+		WithEnvVars(CleanedGitEnv()).
+		WithWorkingDir(r.path).
+		WithStdout(nil).
+		Output()
+	if err != nil {
+		// Exit code 1 means there are conflicts
+		if exitErr, ok := err.(*exec.ExitError); !ok || exitErr.ExitCode() != 1 {
+			return nil, err
+		}
+	}
+
+	// The first line of output is the resulting tree, followed by the paths
+	// of conflicted files
+	lines := strings.Split(strings.TrimSpace(string(b)), "\n")
+
+	return lines[1:], nil
+}
+
+func (r *Repo) MergeSquash(branchName string) error {
+	return r.run("git", "merge", "--squash", "-q", branchName)
+}
+
+// MergeNoFastForward merges the branch into the current branch, always
+// creating a merge commit with the message in the specified file.
+func (r *Repo) MergeNoFastForward(branchName, messagePath string) error {
+	args := []string{"git", "-c", "core.hooksPath=/dev/null", "merge", "-q", "--no-ff", "-F", messagePath, branchName}
+	return r.run(append(args, r.signArgs()...)...)
+}
+
+func (r *Repo) Remotes() ([]string, error) {
+	s, err := r.output("git", "remote")
+	if err != nil {
+		return nil, err
+	}
+
+	if s == "" {
+		return nil, nil
+	}
+
+	return strings.Split(s, "\n"), nil
+}
+
+func (r *Repo) WorktreePrune() error {
+	return r.run("git", "worktree", "prune")
+}
+
+// Worktree is a working tree attached to the repository.
+type Worktree struct {
+	Path string
+
+	// Branch is the branch checked out in the worktree, or empty if HEAD is
+	// detached.
+	Branch string
+
+	// Main is true for the main worktree, as opposed to a linked worktree.
+	Main bool
+}
+
+// Worktrees returns all worktrees of the repository, with the main worktree
+// first.
+func (r *Repo) Worktrees() ([]Worktree, error) {
+	s, err := r.output("git", "worktree", "list", "--porcelain")
+	if err != nil {
+		return nil, err
+	}
+
+	worktrees := []Worktree{}
+	for _, record := range strings.Split(s, "\n\n") {
+		worktree := Worktree{Main: len(worktrees) == 0}
+
+		for _, line := range strings.Split(record, "\n") {
+			key, value, _ := strings.Cut(line, " ")
+			switch key {
+			case "worktree":
+				worktree.Path = value
+			case "branch":
+				worktree.Branch = strings.TrimPrefix(value, "refs/heads/")
+			}
+		}
+
+		if worktree.Path != "" {
+			worktrees = append(worktrees, worktree)
+		}
+	}
+
+	return worktrees, nil
+}
+
+// WorktreeAdd creates a new worktree at path with a detached HEAD at the
+// current commit, and returns a Repo for it.
+func (r *Repo) WorktreeAdd(path string) (*Repo, error) {
+	if err := r.run("git", "worktree", "add", "-q", "--detach", path); err != nil {
+		return nil, err
+	}
+
+	return r.At(path), nil
+}
+
+// At returns a Repo for another worktree of the repository at path, with the
+// same options.
+func (r *Repo) At(path string) *Repo {
+	return WithRepo(path).WithCommitSigning(r.signCommits).WithEditor(r.editor)
+}
+
+// WorktreeAddBranch creates a new worktree at path with the branch checked
+// out.
+func (r *Repo) WorktreeAddBranch(path, branchName string) error {
+	return r.run("git", "worktree", "add", "-q", path, branchName)
+}
+
+func (r *Repo) WorktreeRemove(path string) error {
+	return r.run("git", "worktree", "remove", "--force", path)
+}
+
+// WorktreeRemoveUnmodified removes the worktree, failing if it has local
+// modifications or untracked files.
+func (r *Repo) WorktreeRemoveUnmodified(path string) error {
+	return r.run("git", "worktree", "remove", path)
+}
+
+// RebaseOnto transplants the commits in upstream..branchName onto newBase.
+func (r *Repo) RebaseOnto(newBase, upstream, branchName string) error {
+	args := []string{"git", "-c", "core.hooksPath=/dev/null", "rebase", "--onto", newBase, upstream, branchName}
+	return xexec.Command(append(args, r.signArgs()...)...).
 		WithEnvVars(CleanedGitEnv()).
 		WithWorkingDir(r.path).
 		Run()
 }
 
+// CommonDir returns the absolute path of the git directory shared by all
+// worktrees of the repository, i.e. the .git directory of the main worktree.
+func (r *Repo) CommonDir() (string, error) {
+	dir, err := r.output("git", "rev-parse", "--git-common-dir")
+	if err != nil {
+		return "", err
+	}
+
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(r.path, dir)
+	}
+
+	return filepath.Clean(dir), nil
+}
+
+// RebaseInProgress returns true if a rebase has stopped, e.g. due to
+// conflicts.
+func (r *Repo) RebaseInProgress() (bool, error) {
+	for _, dir := range []string{"rebase-merge", "rebase-apply"} {
+		gitPath, err := r.output("git", "rev-parse", "--git-path", dir)
+		if err != nil {
+			return false, err
+		}
+
+		if !filepath.IsAbs(gitPath) {
+			gitPath = filepath.Join(r.path, gitPath)
+		}
+
+		if fsutil.FileExists(gitPath) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// RebaseContinue continues a stopped rebase, keeping the existing commit
+// messages.
+func (r *Repo) RebaseContinue() error {
+	return xexec.Command("git", "-c", "core.hooksPath=/dev/null", "rebase", "--continue").
+		WithEnvVars(append(CleanedGitEnv(), "GIT_EDITOR=true")).
+		WithWorkingDir(r.path).
+		Run()
+}
+
+func (r *Repo) RebaseAbort() error {
+	return r.run("git", "rebase", "--abort")
+}
+
+// RebaseWithTodo runs an interactive rebase of the current branch onto
+// upstream, using the rebase todo list at todoPath instead of prompting the
+// user to edit it.
+func (r *Repo) RebaseWithTodo(upstream, todoPath string) error {
+	env := append(CleanedGitEnv(), "GIT_SEQUENCE_EDITOR=cp "+shellescape.Quote(todoPath))
+	args := []string{"git", "-c", "core.hooksPath=/dev/null", "rebase", "-q", "-i", upstream}
+
+	return xexec.Command(append(args, r.signArgs()...)...).
+		WithEnvVars(env).
+		WithWorkingDir(r.path).
+		Run()
+}
+
+// AmendCommand returns the command to amend the message of the current commit
+// with the contents of messagePath, for use in a rebase todo list.
+func (r *Repo) AmendCommand(messagePath string) string {
+	args := []string{"git", "-c", "core.hooksPath=/dev/null", "commit", "-q", "--amend", "--allow-empty", "-F", shellescape.Quote(messagePath)}
+	return strings.Join(append(args, r.signArgs()...), " ")
+}
+
 func (r *Repo) Pull() error {
 	return xexec.Command("git", "pull", "--ff", "--ff-only").
 		WithEnvVars(CleanedGitEnv()).
@@ -0,0 +1,40 @@
+package gitexec
+
+import (
+	"testing"
+
+	"github.com/dansimau/yas/pkg/testutil"
+	"gotest.tools/v3/assert"
+)
+
+func TestResolveCommits(t *testing.T) {
+	testutil.WithTempWorkingDir(t, func() {
+		testutil.ExecOrFail(t, `
+			git init -q --initial-branch=main
+			git commit -q --allow-empty -m "main-0"
+			git branch topic-a
+			git commit -q --allow-empty -m "main-1"
+			git tag v1
+		`)
+
+		repo := WithRepo(".")
+
+		main, err := repo.GetHash("main")
+		assert.NilError(t, err)
+		topicA, err := repo.GetHash("topic-a")
+		assert.NilError(t, err)
+
+		hashes, err := repo.ResolveCommits("main", "topic-a", "missing", "v1", "main~1")
+		assert.NilError(t, err)
+		assert.DeepEqual(t, hashes, map[string]string{
+			"main":    main,
+			"topic-a": topicA,
+			"v1":      main,
+			"main~1":  topicA,
+		})
+
+		hashes, err = repo.ResolveCommits()
+		assert.NilError(t, err)
+		assert.DeepEqual(t, hashes, map[string]string{})
+	})
+}
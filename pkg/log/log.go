@@ -1,12 +1,149 @@
 package log
 
 import (
+	"encoding/json"
 	"fmt"
+	"io"
 	"os"
+	"strings"
+	"sync"
+	"time"
 )
 
+type Level int
+
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+func (l Level) String() string {
+	switch l {
+	case LevelDebug:
+		return "debug"
+	case LevelInfo:
+		return "info"
+	case LevelError:
+		return "error"
+	default:
+		return "warn"
+	}
+}
+
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+type Options struct {
+	// Level is the minimum level of messages to output.
+	Level Level
+
+	// Format is the output format, either FormatText or FormatJSON.
+	Format string
+
+	// File is the path to a file to append log messages to. If empty, logs
+	// are written to stderr.
+	File string
+}
+
+var (
+	mu        sync.Mutex
+	opts      *Options
+	logWriter io.Writer = os.Stderr
+	logFile   *os.File
+)
+
+// Configure sets the logging options. It can be called multiple times; any
+// previously opened log file is closed.
+func Configure(o Options) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if logFile != nil {
+		logFile.Close()
+		logFile = nil
+	}
+
+	logWriter = os.Stderr
+
+	if o.File != "" {
+		f, err := os.OpenFile(o.File, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+		if err != nil {
+			return fmt.Errorf("failed to open log file: %w", err)
+		}
+
+		logFile = f
+		logWriter = f
+	}
+
+	opts = &o
+
+	return nil
+}
+
+func Debug(msg ...any) {
+	write(LevelDebug, msg...)
+}
+
 func Info(msg ...any) {
+	write(LevelInfo, msg...)
+}
+
+func Warn(msg ...any) {
+	write(LevelWarn, msg...)
+}
+
+func Error(msg ...any) {
+	write(LevelError, msg...)
+}
+
+func write(level Level, msg ...any) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	o := currentOptions()
+	if level < o.Level {
+		return
+	}
+
+	text := strings.TrimSuffix(fmt.Sprintln(msg...), "\n")
+
+	if o.Format == FormatJSON {
+		b, _ := json.Marshal(map[string]string{
+			"time":  time.Now().Format(time.RFC3339Nano),
+			"level": level.String(),
+			"msg":   text,
+		})
+		fmt.Fprintln(logWriter, string(b))
+		return
+	}
+
+	switch level {
+	case LevelDebug:
+		fmt.Fprintln(logWriter, "DEBUG: "+text)
+	case LevelWarn:
+		fmt.Fprintln(logWriter, "WARNING: "+text)
+	case LevelError:
+		fmt.Fprintln(logWriter, "ERROR: "+text)
+	default:
+		fmt.Fprintln(logWriter, text)
+	}
+}
+
+// currentOptions returns the configured options, or the defaults if Configure
+// has not been called. By default, only warnings are output unless
+// YAS_VERBOSE is set.
+func currentOptions() Options {
+	if opts != nil {
+		return *opts
+	}
+
 	if os.Getenv("YAS_VERBOSE") != "" {
-		fmt.Fprintln(os.Stderr, msg...)
+		return Options{Level: LevelInfo}
 	}
+
+	return Options{Level: LevelWarn}
 }
@@ -0,0 +1,37 @@
+package log_test
+
+import (
+	"encoding/json"
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/dansimau/yas/pkg/log"
+	"gotest.tools/v3/assert"
+)
+
+func TestJSONLogFile(t *testing.T) {
+	logFile := path.Join(t.TempDir(), "yas.log")
+
+	assert.NilError(t, log.Configure(log.Options{
+		Level:  log.LevelInfo,
+		Format: log.FormatJSON,
+		File:   logFile,
+	}))
+	defer log.Configure(log.Options{Level: log.LevelWarn})
+
+	log.Debug("not logged")
+	log.Info("hello", "world")
+
+	b, err := os.ReadFile(logFile)
+	assert.NilError(t, err)
+
+	lines := strings.Split(strings.TrimSpace(string(b)), "\n")
+	assert.Equal(t, len(lines), 1)
+
+	entry := map[string]string{}
+	assert.NilError(t, json.Unmarshal([]byte(lines[0]), &entry))
+	assert.Equal(t, entry["level"], "info")
+	assert.Equal(t, entry["msg"], "hello world")
+}
@@ -0,0 +1,18 @@
+package termutil
+
+import (
+	"os"
+
+	"golang.org/x/term"
+)
+
+// ColorEnabled returns true if ANSI escape sequences (colors, screen clearing)
+// should be written to f. They are disabled if NO_COLOR is set (see
+// https://no-color.org) or if f is not a terminal.
+func ColorEnabled(f *os.File) bool {
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+
+	return term.IsTerminal(int(f.Fd()))
+}
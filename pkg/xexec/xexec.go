@@ -9,6 +9,7 @@ import (
 	"os/exec"
 	"strings"
 
+	"github.com/dansimau/yas/pkg/termutil"
 	"gopkg.in/alessio/shellescape.v1"
 )
 
@@ -19,6 +20,10 @@ type Cmd struct {
 	// when verbose is true, commands will be printed to os.Stderr before they
 	// are executed.
 	verbose bool
+
+	// when quiet is true, stderr output of commands is only written to
+	// os.Stderr if the command fails.
+	quiet bool
 }
 
 // cmdConstructor is the internal constructor for a Cmd; it is shared by two
@@ -37,6 +42,13 @@ func cmdConstructor(c *Cmd) *Cmd {
 		c.verbose = true
 	}
 
+	// Similarly, scripts can silence commands that succeed. This takes
+	// precedence over XEXEC_VERBOSE.
+	if os.Getenv("XEXEC_QUIET") != "" {
+		c.quiet = true
+		c.verbose = false
+	}
+
 	return c
 }
 
@@ -64,7 +76,11 @@ func (c *Cmd) debugPrintCmd() {
 		quotedArgs = append(quotedArgs, shellescape.Quote(arg))
 	}
 
-	fmt.Fprintf(os.Stderr, "\033[1;30m+ %s\033[0m\n", strings.Join(quotedArgs, " "))
+	if termutil.ColorEnabled(os.Stderr) {
+		fmt.Fprintf(os.Stderr, "\033[1;30m+ %s\033[0m\n", strings.Join(quotedArgs, " "))
+	} else {
+		fmt.Fprintf(os.Stderr, "+ %s\n", strings.Join(quotedArgs, " "))
+	}
 }
 
 // Run is like exec.Run that always captures stderr output into the returned
@@ -77,10 +93,13 @@ func (c *Cmd) Run() error {
 	var w io.Writer
 	var stderr bytes.Buffer
 
+	// Output to stderr is held back until the command has finished
+	deferred := c.quiet && c.Stderr == os.Stderr
+
 	// If a c.Stderr has already been provided, create a multiwriter to write
 	// to both the existing c.Stderr as well as our own buffer (for storing on
 	// the error).
-	if c.Stderr != nil {
+	if c.Stderr != nil && !deferred {
 		w = io.MultiWriter(&stderr, c.Stderr)
 	} else {
 		w = &stderr
@@ -88,6 +107,10 @@ func (c *Cmd) Run() error {
 	c.Stderr = w
 
 	if err := c.Cmd.Run(); err != nil {
+		if deferred {
+			os.Stderr.Write(stderr.Bytes())
+		}
+
 		// Store stderr onto the exec error itself so users can access this
 		// if needed.
 		if ee, ok := err.(*exec.ExitError); ok {
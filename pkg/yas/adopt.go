@@ -0,0 +1,134 @@
+package yas
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/dansimau/yas/pkg/log"
+)
+
+func (yas *YAS) fetchMyOpenPullRequests() ([]pullRequestDetails, error) {
+	b, err := yas.gh("pr", "list", "--author", "@me", "--state", "open", "--json", pullRequestDetailsFields)
+	if err != nil {
+		return nil, err
+	}
+
+	prs := []pullRequestDetails{}
+	if err := json.Unmarshal(b, &prs); err != nil {
+		return nil, err
+	}
+
+	return prs, nil
+}
+
+// Adopt tracks the branches of the user's open PRs, using the base of each PR
+// as the parent of its branch, so that stacks created without yas can be
+// managed by it. Branches that don't exist locally are created from origin.
+// Branches that are already tracked are left as they are.
+func (yas *YAS) Adopt() error {
+	prs, err := yas.fetchMyOpenPullRequests()
+	if err != nil {
+		return fmt.Errorf("failed to list PRs: %w", err)
+	}
+
+	heads := map[string]bool{}
+	for _, pr := range prs {
+		heads[pr.HeadRefName] = true
+	}
+
+	if err := yas.fetch("origin"); err != nil {
+		return fmt.Errorf("failed to fetch origin: %w", err)
+	}
+
+	adopted := []string{}
+
+	// Parents must be adopted before their children, so adopt PRs whose base
+	// is resolved on each pass until no more can be adopted
+	for len(prs) > 0 {
+		remaining := []pullRequestDetails{}
+
+		for _, pr := range prs {
+			if heads[pr.BaseRefName] && !yas.isTracked(pr.BaseRefName) {
+				remaining = append(remaining, pr)
+				continue
+			}
+
+			ok, err := yas.adoptPullRequest(pr)
+			if err != nil {
+				return err
+			}
+
+			if ok {
+				adopted = append(adopted, pr.HeadRefName)
+			}
+
+			// Children of a branch that couldn't be adopted are based on trunk
+			delete(heads, pr.HeadRefName)
+		}
+
+		if len(remaining) == len(prs) {
+			// The bases form a cycle, so break it by basing the first on trunk
+			delete(heads, remaining[0].BaseRefName)
+		}
+
+		prs = remaining
+	}
+
+	if err := yas.data.Save(); err != nil {
+		return err
+	}
+
+	fmt.Printf("Adopted %d branch(es)\n", len(adopted))
+
+	for _, branchName := range adopted {
+		yas.emit(Event{Type: EventBranchCreated, Branch: branchName})
+	}
+
+	return nil
+}
+
+// adoptPullRequest tracks the head branch of the PR. It returns false if the
+// branch was skipped.
+func (yas *YAS) adoptPullRequest(pr pullRequestDetails) (bool, error) {
+	branchName := pr.HeadRefName
+
+	if yas.isTracked(branchName) {
+		fmt.Printf("Skipping '%s' (already tracked)\n", branchName)
+		return false, nil
+	}
+
+	parent := pr.BaseRefName
+	if parent != yas.cfg.TrunkBranch && !yas.isTracked(parent) {
+		log.Warn(fmt.Sprintf("Base branch '%s' of '%s' is not tracked, using '%s' as the parent", parent, branchName, yas.cfg.TrunkBranch))
+		parent = yas.cfg.TrunkBranch
+	}
+
+	exists, err := yas.git.BranchExists(branchName)
+	if err != nil {
+		return false, err
+	}
+
+	if !exists {
+		if err := yas.git.TrackRemoteBranch("origin", branchName); err != nil {
+			log.Warn(fmt.Sprintf("Skipping '%s' (not found on origin): %v", branchName, err))
+			return false, nil
+		}
+	}
+
+	branchPoint, err := yas.git.GetMergeBase(parent, branchName)
+	if err != nil {
+		return false, err
+	}
+
+	metadata := yas.data.Branches.Get(branchName)
+	metadata.Parent = parent
+	metadata.BranchPoint = branchPoint
+	metadata.GitHubPullRequest = pr.PullRequestMetadata
+	metadata.Deleted = time.Time{}
+	yas.data.Branches.Set(branchName, metadata)
+
+	fmt.Printf("Adopted '%s' on top of '%s'\n", branchName, parent)
+
+	return true, nil
+}
@@ -0,0 +1,111 @@
+package yas
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/dansimau/yas/pkg/gitexec"
+)
+
+// remoteAheadBehind returns how far each tracked branch is ahead of and behind
+// its counterpart on origin, for branches that have been pushed. The counts
+// are as up to date as the last fetch.
+func (yas *YAS) remoteAheadBehind(refs map[string]gitexec.BranchRef, cache *diffStatCache) (map[string]gitexec.AheadBehind, error) {
+	remoteRefs, err := yas.git.RemoteBranchRefs("origin")
+	if err != nil {
+		return nil, err
+	}
+
+	counts := map[string]gitexec.AheadBehind{}
+
+	for _, branch := range yas.TrackedBranches() {
+		local, localExists := refs[branch.Name]
+		remote, remoteExists := remoteRefs[branch.Name]
+
+		if !localExists || !remoteExists {
+			continue
+		}
+
+		if local.Hash == remote.Hash {
+			counts[branch.Name] = gitexec.AheadBehind{}
+			continue
+		}
+
+		if counts[branch.Name], err = cache.getAheadBehind(local.Hash, remote.Hash, yas.git.AheadBehind); err != nil {
+			return nil, err
+		}
+	}
+
+	return counts, nil
+}
+
+// branchAheadBehind returns how far the branch is ahead of and behind its
+// counterpart on origin. exists is false if the branch hasn't been pushed.
+func (yas *YAS) branchAheadBehind(branchName string) (counts gitexec.AheadBehind, exists bool, err error) {
+	remoteBranch := "origin/" + branchName
+
+	if exists, err = yas.git.RemoteBranchExists(remoteBranch); err != nil || !exists {
+		return counts, false, err
+	}
+
+	counts, err = yas.git.AheadBehind(branchName, remoteBranch)
+
+	return counts, err == nil, err
+}
+
+// warnIfBehindRemote prints a warning if origin has commits on the branch that
+// the local branch doesn't, e.g. because someone else pushed to it.
+func (yas *YAS) warnIfBehindRemote(branchName string) error {
+	counts, exists, err := yas.branchAheadBehind(branchName)
+	if err != nil || !exists || counts.Behind == 0 {
+		return err
+	}
+
+	fmt.Printf("⚠️  %s is behind origin/%s by %d commit(s), which will be overwritten (hint: someone else may have pushed to the branch)\n", branchName, branchName, counts.Behind)
+
+	return nil
+}
+
+// aheadBehindLabel formats the counts for display, e.g. "↑2 ↓1". It returns an
+// empty string if the branch is in sync with the remote.
+func aheadBehindLabel(counts gitexec.AheadBehind) string {
+	parts := []string{}
+
+	if counts.Ahead > 0 {
+		parts = append(parts, fmt.Sprintf("↑%d", counts.Ahead))
+	}
+
+	if counts.Behind > 0 {
+		parts = append(parts, fmt.Sprintf("↓%d", counts.Behind))
+	}
+
+	return strings.Join(parts, " ")
+}
+
+// needsSubmit returns true if the branch has an open PR and local commits that
+// haven't been pushed to it, e.g. after an amend. counts are the branch's
+// ahead/behind counts relative to origin.
+func needsSubmit(metadata BranchMetadata, counts gitexec.AheadBehind) bool {
+	return metadata.GitHubPullRequest.State == "OPEN" && counts.Ahead > 0
+}
+
+// needsSubmitLabel formats the number of unpushed commits of a branch that
+// needs submitting for display, e.g. "needs submit (2 unpushed commits)". It
+// returns an empty string if the branch doesn't need submitting.
+func needsSubmitLabel(metadata BranchMetadata, counts gitexec.AheadBehind) string {
+	if !needsSubmit(metadata, counts) {
+		return ""
+	}
+
+	return fmt.Sprintf("needs submit (%s)", unpushedCommits(counts.Ahead))
+}
+
+// unpushedCommits formats a number of unpushed commits, e.g. "1 unpushed
+// commit" or "2 unpushed commits".
+func unpushedCommits(n int) string {
+	if n == 1 {
+		return "1 unpushed commit"
+	}
+
+	return fmt.Sprintf("%d unpushed commits", n)
+}
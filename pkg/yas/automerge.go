@@ -0,0 +1,65 @@
+package yas
+
+import (
+	"fmt"
+)
+
+type AutoMergeOptions struct {
+	// Branch is the branch whose PR to change (default: the current branch).
+	Branch string
+
+	// Enable enables auto-merge if true, and disables it otherwise.
+	Enable bool
+}
+
+// SetAutoMerge enables or disables GitHub auto-merge on the PR of the branch,
+// and records it so that submit can re-enable auto-merge after pushing.
+func (yas *YAS) SetAutoMerge(opts AutoMergeOptions) error {
+	branchName, err := yas.trackedBranchOrCurrent(opts.Branch)
+	if err != nil {
+		return err
+	}
+
+	if err := yas.RefreshRemoteStatus(branchName); err != nil {
+		return err
+	}
+
+	metadata := yas.data.Branches.Get(branchName)
+	if metadata.GitHubPullRequest.State != "OPEN" {
+		return fmt.Errorf("branch '%s' has no open PR (hint: run `yas submit`)", branchName)
+	}
+
+	if opts.Enable {
+		if err := yas.enableAutoMerge(metadata); err != nil {
+			return err
+		}
+
+		fmt.Printf("Enabled auto-merge for the PR of '%s'\n", branchName)
+	} else {
+		if err := yas.ghRun("pr", "merge", metadata.GitHubPullRequest.ref(branchName), "--disable-auto"); err != nil {
+			return fmt.Errorf("failed to disable auto-merge for the PR of '%s': %w", branchName, err)
+		}
+
+		fmt.Printf("Disabled auto-merge for the PR of '%s'\n", branchName)
+	}
+
+	metadata.AutoMerge = opts.Enable
+	yas.data.Branches.Set(branchName, metadata)
+
+	return yas.data.Save()
+}
+
+// enableAutoMerge enables GitHub auto-merge on the open PR of the branch,
+// using the configured merge strategy.
+func (yas *YAS) enableAutoMerge(metadata BranchMetadata) error {
+	strategy, err := yas.mergeStrategy("")
+	if err != nil {
+		return err
+	}
+
+	if err := yas.ghRun("pr", "merge", metadata.GitHubPullRequest.ref(metadata.Name), "--auto", "--"+strategy); err != nil {
+		return fmt.Errorf("failed to enable auto-merge for the PR of '%s': %w", metadata.Name, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,430 @@
+package yas
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/dansimau/yas/pkg/gitexec"
+)
+
+// CreateBranch creates a new branch at the specified commit-ish (default:
+// current branch) and checks it out. The nearest tracked ancestor of the
+// commit (or trunk) is recorded as the parent.
+func (yas *YAS) CreateBranch(branchName, from string) error {
+	exists, err := yas.git.BranchExists(branchName)
+	if err != nil {
+		return err
+	}
+
+	if exists {
+		return fmt.Errorf("branch '%s' already exists", branchName)
+	}
+
+	if from == "" {
+		currentBranch, err := yas.git.GetCurrentBranchName()
+		if err != nil {
+			return err
+		}
+
+		from = currentBranch
+	}
+
+	branchPoint, err := yas.git.GetHash(from)
+	if err != nil || branchPoint == "" {
+		return fmt.Errorf("'%s' is not a valid commit", from)
+	}
+
+	parent, err := yas.nearestTrackedAncestor(from, branchPoint)
+	if err != nil {
+		return err
+	}
+
+	previousBranch, _ := yas.git.GetCurrentBranchName()
+
+	if err := yas.git.CreateBranch(branchName, branchPoint); err != nil {
+		return err
+	}
+
+	yas.data.PreviousBranch = previousBranch
+
+	yas.data.Branches.Set(branchName, BranchMetadata{
+		Name:        branchName,
+		Parent:      parent,
+		BranchPoint: branchPoint,
+	})
+
+	if err := yas.data.Save(); err != nil {
+		return err
+	}
+
+	fmt.Printf("Created branch '%s' on top of '%s'\n", branchName, parent)
+
+	yas.emit(Event{Type: EventBranchCreated, Branch: branchName})
+
+	return nil
+}
+
+// nearestTrackedAncestor returns the tracked branch (or trunk) that is the
+// closest ancestor of commit. If ref is itself the name of a tracked branch or
+// trunk, it is returned directly.
+func (yas *YAS) nearestTrackedAncestor(ref, commit string) (string, error) {
+	candidates := append([]string{yas.cfg.TrunkBranch}, yas.TrackedBranches().BranchNames()...)
+
+	for _, candidate := range candidates {
+		if candidate == ref {
+			return candidate, nil
+		}
+	}
+
+	nearest := ""
+	nearestDistance := -1
+
+	for _, candidate := range candidates {
+		isAncestor, err := yas.git.IsAncestor(candidate, commit)
+		if err != nil {
+			return "", err
+		}
+
+		if !isAncestor {
+			continue
+		}
+
+		distance, err := yas.git.CountCommits(candidate, commit)
+		if err != nil {
+			return "", err
+		}
+
+		if nearestDistance == -1 || distance < nearestDistance {
+			nearest = candidate
+			nearestDistance = distance
+		}
+	}
+
+	if nearest == "" {
+		return "", errors.New("commit is not based on trunk or any tracked branch")
+	}
+
+	return nearest, nil
+}
+
+// AutoParent can be passed as the parent to `yas add` to infer the parent
+// with InferParent.
+const AutoParent = "@auto"
+
+// InferParent returns the tracked branch (or trunk) that is the nearest
+// ancestor of the branch (default: current branch), i.e. the one whose
+// merge-base with the branch has the fewest commits between it and the branch.
+// Unlike nearestTrackedAncestor, candidates don't need to be ancestors, so
+// that a parent that has moved on since the branch was created is still
+// found. Ties are broken in favour of actual ancestors, then branches further
+// up the stack.
+func (yas *YAS) InferParent(branchName string) (string, error) {
+	if branchName == "" {
+		currentBranch, err := yas.git.GetCurrentBranchName()
+		if err != nil {
+			return "", err
+		}
+
+		branchName = currentBranch
+	}
+
+	if branchName == yas.cfg.TrunkBranch {
+		return "", errors.New("trunk branch has no parent")
+	}
+
+	excluded := append(yas.descendants(branchName), branchName)
+	candidates := append([]string{yas.cfg.TrunkBranch}, yas.TrackedBranches().SortedByName().BranchNames()...)
+
+	nearest := ""
+	nearestDistance := 0
+	nearestIsAncestor := false
+
+	for _, candidate := range candidates {
+		if slices.Contains(excluded, candidate) {
+			continue
+		}
+
+		mergeBase, err := yas.git.GetMergeBase(candidate, branchName)
+		if err != nil {
+			// No common history
+			continue
+		}
+
+		distance, err := yas.git.CountCommits(mergeBase, branchName)
+		if err != nil {
+			return "", err
+		}
+
+		tip, err := yas.git.GetHash(candidate)
+		if err != nil {
+			return "", err
+		}
+
+		isAncestor := tip == mergeBase
+
+		better := nearest == "" ||
+			distance < nearestDistance ||
+			distance == nearestDistance && isAncestor && !nearestIsAncestor ||
+			distance == nearestDistance && isAncestor == nearestIsAncestor && yas.depth(candidate) > yas.depth(nearest)
+
+		if !better {
+			continue
+		}
+
+		nearest = candidate
+		nearestDistance = distance
+		nearestIsAncestor = isAncestor
+	}
+
+	if nearest == "" {
+		return "", fmt.Errorf("branch '%s' has no common history with trunk or any tracked branch", branchName)
+	}
+
+	return nearest, nil
+}
+
+// Switch checks out the specified branch. If the branch name is "-", the
+// branch that was checked out before the last switch is checked out.
+func (yas *YAS) Switch(branchName string) error {
+	if branchName == "-" {
+		if yas.data.PreviousBranch == "" {
+			return errors.New("no previous branch to switch to")
+		}
+
+		branchName = yas.data.PreviousBranch
+	}
+
+	currentBranch, err := yas.git.GetCurrentBranchName()
+	if err != nil {
+		return err
+	}
+
+	if currentBranch == branchName {
+		fmt.Printf("Already on '%s'\n", branchName)
+		return nil
+	}
+
+	worktrees, err := yas.BranchWorktrees()
+	if err != nil {
+		return err
+	}
+
+	// The branch can't be checked out here too, so point to the worktree
+	// instead of failing with git's error
+	if worktree, ok := worktrees[branchName]; ok {
+		return fmt.Errorf("branch '%s' is checked out in another worktree: %s", branchName, worktree.Path)
+	}
+
+	if err := yas.git.Checkout(branchName); err != nil {
+		return err
+	}
+
+	yas.data.PreviousBranch = currentBranch
+
+	if err := yas.data.Save(); err != nil {
+		return err
+	}
+
+	fmt.Printf("Switched to branch '%s'\n", branchName)
+
+	return nil
+}
+
+// InsertBranch creates a new branch at the current branch and checks it out,
+// like CreateBranch, and inserts it below the current branch's children: they
+// are reparented onto the new branch and restacked.
+func (yas *YAS) InsertBranch(branchName string) error {
+	currentBranch, err := yas.git.GetCurrentBranchName()
+	if err != nil {
+		return err
+	}
+
+	if currentBranch == yas.cfg.TrunkBranch {
+		return errors.New("cannot insert a branch below the children of the trunk branch")
+	}
+
+	if !yas.isTracked(currentBranch) {
+		return fmt.Errorf("branch '%s' is not tracked (hint: run `yas add`)", currentBranch)
+	}
+
+	// Check before creating the branch, so it isn't left half-inserted
+	if err := yas.checkClean(yas.descendants(currentBranch), true); err != nil {
+		return err
+	}
+
+	if err := yas.CreateBranch(branchName, currentBranch); err != nil {
+		return err
+	}
+
+	if len(yas.data.Branches.ToSlice().NotDeleted().WithParent(currentBranch)) == 1 {
+		return nil
+	}
+
+	return yas.Move(MoveOptions{
+		Branch: branchName,
+		After:  currentBranch,
+	})
+}
+
+// CreateBranchWithChanges creates the branch like CreateBranch, and moves any
+// uncommitted changes onto it. If the changes conflict with the new branch,
+// the branch is not created and the changes are left where they were.
+func (yas *YAS) CreateBranchWithChanges(branchName, from string) error {
+	previousBranch, err := yas.git.GetCurrentBranchName()
+	if err != nil {
+		return err
+	}
+
+	stashed, err := yas.stash()
+	if err != nil {
+		return err
+	}
+
+	if !stashed {
+		return yas.CreateBranch(branchName, from)
+	}
+
+	if err := yas.CreateBranch(branchName, from); err != nil {
+		return yas.restoreChanges(err)
+	}
+
+	if err := yas.applyChanges(yas.git); err != nil {
+		if err := yas.git.Checkout(previousBranch); err != nil {
+			return err
+		}
+
+		if err := yas.git.DeleteBranch(branchName); err != nil {
+			return err
+		}
+
+		yas.data.Branches.Remove(branchName)
+		yas.data.PreviousBranch = ""
+
+		if err := yas.data.Save(); err != nil {
+			return err
+		}
+
+		return yas.restoreChanges(fmt.Errorf("local changes conflict with '%s', so the branch was not created", branchName))
+	}
+
+	fmt.Printf("Moved local changes to '%s'\n", branchName)
+
+	return nil
+}
+
+// SwitchWithChanges switches to the branch like Switch, and moves any
+// uncommitted changes onto it. If the branch is checked out in a linked
+// worktree, the changes are moved to that worktree instead. If the changes
+// conflict with the branch, they are left where they were.
+func (yas *YAS) SwitchWithChanges(branchName string) error {
+	if branchName == "-" && yas.data.PreviousBranch != "" {
+		branchName = yas.data.PreviousBranch
+	}
+
+	worktrees, err := yas.BranchWorktrees()
+	if err != nil {
+		return err
+	}
+
+	if worktree, ok := worktrees[branchName]; ok && !worktree.Main {
+		return yas.moveChangesToWorktree(branchName, worktree.Path)
+	}
+
+	currentBranch, err := yas.git.GetCurrentBranchName()
+	if err != nil {
+		return err
+	}
+
+	previousBranch := yas.data.PreviousBranch
+
+	stashed, err := yas.stash()
+	if err != nil {
+		return err
+	}
+
+	if !stashed {
+		return yas.Switch(branchName)
+	}
+
+	if err := yas.Switch(branchName); err != nil {
+		return yas.restoreChanges(err)
+	}
+
+	if err := yas.applyChanges(yas.git); err != nil {
+		if err := yas.git.Checkout(currentBranch); err != nil {
+			return err
+		}
+
+		yas.data.PreviousBranch = previousBranch
+
+		if err := yas.data.Save(); err != nil {
+			return err
+		}
+
+		return yas.restoreChanges(fmt.Errorf("local changes conflict with '%s', so the branch was not switched", branchName))
+	}
+
+	fmt.Printf("Moved local changes to '%s'\n", branchName)
+
+	return nil
+}
+
+// moveChangesToWorktree moves uncommitted changes to the linked worktree that
+// the branch is checked out in.
+func (yas *YAS) moveChangesToWorktree(branchName, worktreePath string) error {
+	worktree := gitexec.WithRepo(worktreePath)
+
+	dirty, err := worktree.IsDirty()
+	if err != nil {
+		return err
+	}
+
+	if dirty {
+		return fmt.Errorf("'%s' is checked out in worktree %s, which has uncommitted changes", branchName, worktreePath)
+	}
+
+	stashed, err := yas.stash()
+	if err != nil {
+		return err
+	}
+
+	if !stashed {
+		return fmt.Errorf("'%s' is checked out in worktree %s", branchName, worktreePath)
+	}
+
+	if err := yas.applyChanges(worktree); err != nil {
+		return yas.restoreChanges(fmt.Errorf("local changes conflict with '%s'", branchName))
+	}
+
+	fmt.Printf("Moved local changes to '%s' in worktree %s\n", branchName, worktreePath)
+
+	return nil
+}
+
+// applyChanges applies the changes stashed by stash to the worktree of repo.
+// If they don't apply cleanly, the worktree is reset and the stash is kept so
+// the changes can be restored with restoreChanges.
+func (yas *YAS) applyChanges(repo *gitexec.Repo) error {
+	if err := repo.StashApply(); err != nil {
+		if resetErr := repo.ResetHard(); resetErr != nil {
+			return fmt.Errorf("failed to reset after conflicting changes (hint: your changes are in `git stash list`): %w", resetErr)
+		}
+
+		return err
+	}
+
+	return repo.StashDrop()
+}
+
+// restoreChanges restores the changes stashed by stash to the current
+// worktree, after the operation failed with err.
+func (yas *YAS) restoreChanges(err error) error {
+	if popErr := yas.git.StashPop(); popErr != nil {
+		return fmt.Errorf("%w; failed to restore stashed changes (hint: run `git stash pop`): %v", err, popErr)
+	}
+
+	fmt.Println("Restored stashed changes")
+
+	return err
+}
@@ -0,0 +1,100 @@
+package yas
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// StateBundle is a portable copy of the state and repository config, for
+// moving work between clones of a repository.
+type StateBundle struct {
+	SchemaVersion int                       `json:"schemaVersion"`
+	Config        Config                    `json:"config"`
+	Branches      map[string]BranchMetadata `json:"branches"`
+	Stacks        map[string]StackMetadata  `json:"stacks,omitempty"`
+}
+
+// ExportState writes the state and repository config to w as a JSON bundle.
+func (yas *YAS) ExportState(w io.Writer) error {
+	cfg, err := ReadRepoConfig(yas.cfg.RepoDirectory)
+	if err != nil {
+		return err
+	}
+
+	bundle := StateBundle{
+		SchemaVersion: currentSchemaVersion,
+		Config:        *cfg,
+		Branches:      map[string]BranchMetadata{},
+		Stacks:        yas.data.Stacks,
+	}
+
+	for _, branch := range yas.data.Branches.ToSlice() {
+		bundle.Branches[branch.Name] = branch
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(bundle)
+}
+
+type ImportOptions struct {
+	// Merge merges the bundle into the local state, rather than replacing
+	// it. When a branch exists in both, the most recently updated metadata
+	// is kept. The local config is not changed.
+	Merge bool
+}
+
+// ImportState reads a bundle created by ExportState and imports it into the
+// local state.
+func (yas *YAS) ImportState(r io.Reader, opts ImportOptions) error {
+	bundle := StateBundle{}
+	if err := json.NewDecoder(r).Decode(&bundle); err != nil {
+		return fmt.Errorf("failed to read bundle: %w", err)
+	}
+
+	if bundle.SchemaVersion > currentSchemaVersion {
+		return fmt.Errorf("bundle schema version %d is newer than supported version %d (hint: upgrade yas)", bundle.SchemaVersion, currentSchemaVersion)
+	}
+
+	if !opts.Merge {
+		bundle.Config.RepoDirectory = yas.cfg.RepoDirectory
+		if _, err := yas.UpdateConfig(bundle.Config); err != nil {
+			return err
+		}
+
+		yas.data.Branches = &branchMap{data: map[string]BranchMetadata{}}
+		yas.data.Stacks = map[string]StackMetadata{}
+	}
+
+	imported, kept := 0, 0
+
+	for name, branch := range bundle.Branches {
+		if yas.data.Branches.Exists(name) && !branch.Updated.After(yas.data.Branches.Get(name).Updated) {
+			kept++
+			continue
+		}
+
+		branch.Name = name
+		yas.data.Branches.restore(name, branch)
+		imported++
+	}
+
+	for root, stack := range bundle.Stacks {
+		if _, exists := yas.data.Stacks[root]; !exists {
+			yas.data.Stacks[root] = stack
+		}
+	}
+
+	if err := yas.data.Save(); err != nil {
+		return err
+	}
+
+	fmt.Printf("Imported %d branch(es)\n", imported)
+	if kept > 0 {
+		fmt.Printf("Kept %d local branch(es) that were updated more recently\n", kept)
+	}
+
+	return nil
+}
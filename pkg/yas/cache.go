@@ -0,0 +1,184 @@
+package yas
+
+import (
+	"encoding/json"
+	"os"
+
+	"github.com/dansimau/yas/pkg/gitexec"
+	"github.com/dansimau/yas/pkg/log"
+)
+
+const diffStatCacheFile = "yas-cache.json"
+
+// diffStatCache caches the diff stats of branches, keyed by their base and
+// tip commits, so that branches that haven't changed aren't diffed again. It
+// also caches the ahead/behind counts of branches relative to their remote
+// counterparts, keyed by the local and remote tips, and whether commits are
+// ancestors of others. The cache is only an optimisation, so failures to read
+// or write it are ignored.
+type diffStatCache struct {
+	filePath        string
+	dirty           bool
+	used            map[string]bool
+	usedAheadBehind map[string]bool
+	usedAncestors   map[string]bool
+
+	Stats       map[string]gitexec.DiffStat    `json:"diffStats"`
+	AheadBehind map[string]gitexec.AheadBehind `json:"aheadBehind,omitempty"`
+	Ancestors   map[string]bool                `json:"ancestors,omitempty"`
+}
+
+func loadDiffStatCache(filePath string) *diffStatCache {
+	cache := &diffStatCache{
+		filePath:        filePath,
+		used:            map[string]bool{},
+		usedAheadBehind: map[string]bool{},
+		usedAncestors:   map[string]bool{},
+		Stats:           map[string]gitexec.DiffStat{},
+		AheadBehind:     map[string]gitexec.AheadBehind{},
+		Ancestors:       map[string]bool{},
+	}
+
+	b, err := os.ReadFile(filePath)
+	if err != nil {
+		return cache
+	}
+
+	if err := json.Unmarshal(b, cache); err != nil {
+		log.Debug("Ignoring invalid cache file", filePath+":", err)
+		cache.Stats = map[string]gitexec.DiffStat{}
+		cache.AheadBehind = map[string]gitexec.AheadBehind{}
+		cache.Ancestors = map[string]bool{}
+	}
+
+	if cache.AheadBehind == nil {
+		cache.AheadBehind = map[string]gitexec.AheadBehind{}
+	}
+
+	if cache.Ancestors == nil {
+		cache.Ancestors = map[string]bool{}
+	}
+
+	return cache
+}
+
+// get returns the cached diff stat between base and tip, or computes it with
+// diffStat if it isn't cached.
+func (c *diffStatCache) get(base, tip string, diffStat func(base, tip string) (gitexec.DiffStat, error)) (gitexec.DiffStat, error) {
+	key := base + "..." + tip
+	c.used[key] = true
+
+	if stat, ok := c.Stats[key]; ok {
+		return stat, nil
+	}
+
+	stat, err := diffStat(base, tip)
+	if err != nil {
+		return stat, err
+	}
+
+	c.Stats[key] = stat
+	c.dirty = true
+
+	return stat, nil
+}
+
+// getAheadBehind returns the cached ahead/behind counts of local relative to
+// remote, or computes them with aheadBehind if they aren't cached.
+func (c *diffStatCache) getAheadBehind(local, remote string, aheadBehind func(local, remote string) (gitexec.AheadBehind, error)) (gitexec.AheadBehind, error) {
+	key := local + "..." + remote
+	c.usedAheadBehind[key] = true
+
+	if counts, ok := c.AheadBehind[key]; ok {
+		return counts, nil
+	}
+
+	counts, err := aheadBehind(local, remote)
+	if err != nil {
+		return counts, err
+	}
+
+	c.AheadBehind[key] = counts
+	c.dirty = true
+
+	return counts, nil
+}
+
+// getIsAncestor returns whether the ancestor commit is an ancestor of ref (a
+// commit hash), computing it with isAncestor if it isn't cached.
+func (c *diffStatCache) getIsAncestor(ancestor, ref string, isAncestor func(ancestor, ref string) (bool, error)) (bool, error) {
+	key := ancestor + ".." + ref
+	c.usedAncestors[key] = true
+
+	if result, ok := c.Ancestors[key]; ok {
+		return result, nil
+	}
+
+	result, err := isAncestor(ancestor, ref)
+	if err != nil {
+		return result, err
+	}
+
+	c.Ancestors[key] = result
+	c.dirty = true
+
+	return result, nil
+}
+
+// setIsAncestor records whether the ancestor commit is an ancestor of ref (a
+// commit hash), when it is already known.
+func (c *diffStatCache) setIsAncestor(ancestor, ref string, result bool) {
+	key := ancestor + ".." + ref
+	c.usedAncestors[key] = true
+	c.Ancestors[key] = result
+	c.dirty = true
+}
+
+// save writes the cache, if it has changed. Entries that weren't used are
+// dropped so that the cache doesn't grow indefinitely. Each kind of entry is
+// only pruned if that kind was used, since not every command uses all of
+// them.
+func (c *diffStatCache) save() {
+	if pruneUnused(c.Stats, c.used) {
+		c.dirty = true
+	}
+
+	if pruneUnused(c.AheadBehind, c.usedAheadBehind) {
+		c.dirty = true
+	}
+
+	if pruneUnused(c.Ancestors, c.usedAncestors) {
+		c.dirty = true
+	}
+
+	if !c.dirty {
+		return
+	}
+
+	b, err := json.Marshal(c)
+	if err != nil {
+		return
+	}
+
+	if err := os.WriteFile(c.filePath, b, 0o644); err != nil {
+		log.Debug("Failed to write cache file", c.filePath+":", err)
+	}
+}
+
+// pruneUnused deletes the entries that weren't used, unless none were used.
+// It returns true if any entries were deleted.
+func pruneUnused[V any](entries map[string]V, used map[string]bool) bool {
+	if len(used) == 0 {
+		return false
+	}
+
+	pruned := false
+	for key := range entries {
+		if !used[key] {
+			delete(entries, key)
+			pruned = true
+		}
+	}
+
+	return pruned
+}
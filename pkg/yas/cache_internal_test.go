@@ -0,0 +1,39 @@
+package yas
+
+import (
+	"path"
+	"testing"
+
+	"github.com/dansimau/yas/pkg/gitexec"
+	"gotest.tools/v3/assert"
+)
+
+func TestDiffStatCache(t *testing.T) {
+	filePath := path.Join(t.TempDir(), "yas-cache.json")
+
+	calls := 0
+	diffStat := func(base, tip string) (gitexec.DiffStat, error) {
+		calls++
+		return gitexec.DiffStat{Files: 1, Insertions: len(tip)}, nil
+	}
+
+	cache := loadDiffStatCache(filePath)
+	_, err := cache.get("a", "b", diffStat)
+	assert.NilError(t, err)
+	_, err = cache.get("a", "cc", diffStat)
+	assert.NilError(t, err)
+	cache.save()
+	assert.Equal(t, calls, 2)
+
+	// Cached entries are not recomputed
+	cache = loadDiffStatCache(filePath)
+	stat, err := cache.get("a", "cc", diffStat)
+	assert.NilError(t, err)
+	assert.Equal(t, stat, gitexec.DiffStat{Files: 1, Insertions: 2})
+	assert.Equal(t, calls, 2)
+	cache.save()
+
+	// Unused entries are dropped
+	cache = loadDiffStatCache(filePath)
+	assert.Equal(t, len(cache.Stats), 1)
+}
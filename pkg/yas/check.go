@@ -0,0 +1,116 @@
+package yas
+
+import (
+	"fmt"
+)
+
+// Rules checked by Check.
+const (
+	RuleMissingBranch = "missing-branch"
+	RuleParentChain   = "parent-chain"
+	RuleCycle         = "cycle"
+	RuleBranchPoint   = "branch-point"
+	RulePRBase        = "pr-base"
+)
+
+// Violation is a stack invariant that doesn't hold for a branch.
+type Violation struct {
+	Branch  string `json:"branch"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// Check verifies the invariants of the tracked stacks: every branch exists
+// and has a chain of parents to trunk without cycles, branch points are
+// ancestors of branch tips, and the bases of open PRs match the recorded
+// parents. Unlike Doctor, it only uses local state, so that it is suitable for
+// pre-push hooks and CI.
+func (yas *YAS) Check() ([]Violation, error) {
+	refs, err := yas.git.BranchRefs()
+	if err != nil {
+		return nil, err
+	}
+
+	violations := []Violation{}
+	violate := func(branchName, rule, format string, args ...any) {
+		violations = append(violations, Violation{Branch: branchName, Rule: rule, Message: fmt.Sprintf(format, args...)})
+	}
+
+	for _, branch := range yas.data.Branches.ToSlice().NotDeleted().WithParents().SortedByName() {
+		if _, exists := refs[branch.Name]; !exists {
+			violate(branch.Name, RuleMissingBranch, "branch does not exist (hint: run `yas clean`)")
+			continue
+		}
+
+		ancestor, err := yas.brokenParentChain(branch.Name)
+		if err != nil {
+			return nil, err
+		}
+
+		switch {
+		case ancestor == branch.Name:
+			violate(branch.Name, RuleCycle, "branch is its own ancestor (hint: run `yas move`)")
+		case ancestor != "":
+			violate(branch.Name, RuleParentChain, "ancestor '%s' is not tracked and not trunk (hint: run `yas add --branch=%s`)", ancestor, ancestor)
+		}
+
+		if branch.BranchPoint != "" {
+			isAncestor, err := yas.git.IsAncestor(branch.BranchPoint, branch.Name)
+			if err != nil || !isAncestor {
+				violate(branch.Name, RuleBranchPoint, "branch point %s is not an ancestor of the branch (hint: run `yas restack`)", shortHash(branch.BranchPoint))
+			}
+		}
+
+		pr := branch.GitHubPullRequest
+		if base := yas.pullRequestBase(branch); pr.State == "OPEN" && pr.BaseRefName != "" && pr.BaseRefName != base {
+			violate(branch.Name, RulePRBase, "PR base is '%s' but should be '%s' (hint: run `yas submit`)", pr.BaseRefName, base)
+		}
+	}
+
+	return violations, nil
+}
+
+// brokenParentChain follows the parents of the branch towards trunk. It
+// returns the first ancestor that is neither trunk, tracked nor a remote
+// parent, or the branch itself if the chain loops back to it. It returns an
+// empty string if the chain reaches trunk or another cycle that doesn't
+// include the branch, which is reported for the branches in that cycle.
+func (yas *YAS) brokenParentChain(branchName string) (string, error) {
+	seen := map[string]bool{}
+
+	for name := branchName; ; {
+		seen[name] = true
+
+		parent := yas.data.Branches.Get(name).Parent
+		if parent == yas.cfg.TrunkBranch {
+			return "", nil
+		}
+
+		if parent == branchName {
+			return branchName, nil
+		}
+
+		if seen[parent] {
+			return "", nil
+		}
+
+		if !yas.isTracked(parent) {
+			remote, err := yas.remoteParent(parent)
+			if err != nil || remote != "" {
+				return "", err
+			}
+
+			return parent, nil
+		}
+
+		name = parent
+	}
+}
+
+func shortHash(hash string) string {
+	if len(hash) > 7 {
+		return hash[:7]
+	}
+
+	return hash
+}
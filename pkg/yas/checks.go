@@ -0,0 +1,128 @@
+package yas
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+const (
+	checkPending ChecksRollup = "pending"
+	checkSuccess ChecksRollup = "success"
+	checkFailure ChecksRollup = "failure"
+)
+
+// statusCheck is an entry in the statusCheckRollup of a PR. It is either a
+// check run (with status and conclusion) or a commit status (with state).
+type statusCheck struct {
+	Name       string `json:"name"`
+	Context    string `json:"context"`
+	Status     string `json:"status"`
+	Conclusion string `json:"conclusion"`
+	State      string `json:"state"`
+}
+
+func (c statusCheck) displayName() string {
+	if c.Name != "" {
+		return c.Name
+	}
+
+	return c.Context
+}
+
+// result returns whether the check is pending, succeeded or failed.
+func (c statusCheck) result() ChecksRollup {
+	if c.State != "" {
+		switch c.State {
+		case "SUCCESS":
+			return checkSuccess
+		case "PENDING", "EXPECTED":
+			return checkPending
+		default:
+			return checkFailure
+		}
+	}
+
+	if c.Status != "COMPLETED" {
+		return checkPending
+	}
+
+	switch c.Conclusion {
+	case "SUCCESS", "NEUTRAL", "SKIPPED":
+		return checkSuccess
+	default:
+		return checkFailure
+	}
+}
+
+func (yas *YAS) fetchStatusChecks(branchName string) ([]statusCheck, error) {
+	b, err := yas.gh("pr", "view", branchName, "--json", "statusCheckRollup")
+	if err != nil {
+		return nil, err
+	}
+
+	data := struct {
+		StatusCheckRollup []statusCheck `json:"statusCheckRollup"`
+	}{}
+
+	if err := json.Unmarshal(b, &data); err != nil {
+		return nil, err
+	}
+
+	return data.StatusCheckRollup, nil
+}
+
+// waitForChecks polls the checks of the branch's PR until they have all
+// passed. It returns an error if any check fails or the timeout is reached.
+// Right after a push, CI may not have registered any checks yet, so a PR with
+// no checks is waited on too.
+func (yas *YAS) waitForChecks(branchName string, timeout, interval time.Duration) error {
+	if interval == 0 {
+		interval = 30 * time.Second
+	}
+
+	start := time.Now()
+
+	for {
+		checks, err := yas.fetchStatusChecks(branchName)
+		if err != nil {
+			return fmt.Errorf("failed to fetch checks: %w", err)
+		}
+
+		pending := 0
+		for _, check := range checks {
+			switch check.result() {
+			case checkFailure:
+				return fmt.Errorf("check '%s' failed", check.displayName())
+			case checkPending:
+				pending++
+			}
+		}
+
+		if len(checks) > 0 && pending == 0 {
+			fmt.Printf("✅ All %d check(s) passed\n", len(checks))
+			return nil
+		}
+
+		elapsed := time.Since(start)
+
+		if len(checks) == 0 {
+			if timeout > 0 && elapsed >= timeout {
+				return fmt.Errorf("timed out after %s waiting for checks to start", timeout)
+			}
+
+			fmt.Printf("⏳ Waiting for checks to start (%s elapsed)\n", elapsed.Round(time.Second))
+			time.Sleep(interval)
+
+			continue
+		}
+
+		if timeout > 0 && elapsed >= timeout {
+			return fmt.Errorf("timed out after %s waiting for %d check(s)", timeout, pending)
+		}
+
+		fmt.Printf("⏳ Waiting for %d of %d check(s) (%s elapsed)\n", pending, len(checks), elapsed.Round(time.Second))
+
+		time.Sleep(interval)
+	}
+}
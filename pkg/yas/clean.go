@@ -0,0 +1,146 @@
+package yas
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// StaleBranches returns metadata entries for branches that were deleted more
+// than the specified duration ago. If configured, branches whose PRs are still
+// open are excluded.
+//
+// Branches that were deleted outside of yas are marked as deleted, but this
+// is only saved by a subsequent call to PruneBranchMetadata.
+func (yas *YAS) StaleBranches(olderThan time.Duration) (Branches, error) {
+	if err := yas.markDeletedBranches(); err != nil {
+		return nil, err
+	}
+
+	cutoff := time.Now().Add(-olderThan)
+
+	return yas.data.Branches.ToSlice().filter(func(b BranchMetadata) bool {
+		if yas.cfg.DeletedBranchRetainOpenPRs && b.GitHubPullRequest.State == "OPEN" {
+			return false
+		}
+
+		return !b.Deleted.IsZero() && !b.Deleted.After(cutoff)
+	}), nil
+}
+
+// markDeletedBranches sets the deleted timestamp on any tracked branch that no
+// longer exists in the repository (e.g. it was deleted outside of yas).
+func (yas *YAS) markDeletedBranches() error {
+	for _, branch := range yas.data.Branches.ToSlice().NotDeleted() {
+		exists, err := yas.git.BranchExists(branch.Name)
+		if err != nil {
+			return err
+		}
+
+		if exists {
+			continue
+		}
+
+		branch.Deleted = time.Now()
+		yas.data.Branches.Set(branch.Name, branch)
+	}
+
+	return nil
+}
+
+// PruneBranchMetadata permanently removes metadata for the specified branches.
+func (yas *YAS) PruneBranchMetadata(names ...string) error {
+	for _, name := range names {
+		yas.data.Branches.Remove(name)
+	}
+
+	return yas.data.Save()
+}
+
+// PruneWorktrees removes administrative data for worktrees whose directories
+// no longer exist.
+func (yas *YAS) PruneWorktrees() error {
+	return yas.git.WorktreePrune()
+}
+
+// OrphanedWorktreeDirs returns the directories in the configured worktree
+// directory that are not worktrees known to git (e.g. left behind after a
+// worktree was pruned). Directories containing worktrees, such as the parent
+// directories of branches with slashes in their names, are not included.
+func (yas *YAS) OrphanedWorktreeDirs() ([]string, error) {
+	worktrees, err := yas.git.Worktrees()
+	if err != nil {
+		return nil, err
+	}
+
+	worktreePaths := []string{}
+	for _, worktree := range worktrees {
+		worktreePaths = append(worktreePaths, resolvePath(worktree.Path))
+	}
+
+	return orphanedDirs(resolvePath(yas.cfg.worktreeDir()), worktreePaths)
+}
+
+// RemoveOrphanedWorktreeDirs deletes the specified directories, as returned
+// by OrphanedWorktreeDirs.
+func (yas *YAS) RemoveOrphanedWorktreeDirs(dirs ...string) error {
+	for _, dir := range dirs {
+		if err := os.RemoveAll(dir); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func orphanedDirs(dir string, worktreePaths []string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	orphaned := []string{}
+
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+
+		entryPath := filepath.Join(dir, entry.Name())
+
+		switch {
+		case slices.Contains(worktreePaths, entryPath):
+			continue
+		case slices.ContainsFunc(worktreePaths, func(p string) bool { return strings.HasPrefix(p, entryPath+"/") }):
+			children, err := orphanedDirs(entryPath, worktreePaths)
+			if err != nil {
+				return nil, err
+			}
+
+			orphaned = append(orphaned, children...)
+		default:
+			orphaned = append(orphaned, entryPath)
+		}
+	}
+
+	return orphaned, nil
+}
+
+// resolvePath returns the path with symlinks resolved, so that it can be
+// compared with paths reported by git, or the path unchanged if it can't be
+// resolved.
+func resolvePath(p string) string {
+	resolved, err := filepath.EvalSymlinks(p)
+	if err != nil {
+		return p
+	}
+
+	return resolved
+}
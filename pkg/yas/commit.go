@@ -0,0 +1,122 @@
+package yas
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"text/template"
+)
+
+// defaultTicketPattern matches ticket IDs in branch names, e.g. PROJ-123.
+const defaultTicketPattern = `[A-Z][A-Z0-9]*-[0-9]+`
+
+// commitTemplateData is the data available to the commit template and ticket
+// URL templates.
+type commitTemplateData struct {
+	Branch string
+
+	// Ticket is the ticket ID in the branch name, e.g. PROJ-123.
+	Ticket string
+}
+
+// ticket returns the ticket ID in the branch name, or an empty string if there
+// isn't one.
+func (yas *YAS) ticket(branchName string) (string, error) {
+	pattern := yas.cfg.TicketPattern
+	if pattern == "" {
+		pattern = defaultTicketPattern
+	}
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return "", fmt.Errorf("invalid ticketPattern: %w", err)
+	}
+
+	match := re.FindStringSubmatch(branchName)
+	switch {
+	case match == nil:
+		return "", nil
+	case len(match) > 1:
+		return match[1], nil
+	}
+
+	return match[0], nil
+}
+
+// renderTicketTemplate renders the named template config value for the
+// branch. It returns an empty string if the template is empty or the branch
+// name has no ticket ID.
+func (yas *YAS) renderTicketTemplate(name, text, branchName string) (string, error) {
+	if text == "" {
+		return "", nil
+	}
+
+	ticket, err := yas.ticket(branchName)
+	if err != nil || ticket == "" {
+		return "", err
+	}
+
+	tmpl, err := template.New(name).Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("invalid %s template: %w", name, err)
+	}
+
+	buf := &strings.Builder{}
+	if err := tmpl.Execute(buf, commitTemplateData{Branch: branchName, Ticket: ticket}); err != nil {
+		return "", fmt.Errorf("invalid %s template: %w", name, err)
+	}
+
+	return buf.String(), nil
+}
+
+// Commit runs `git commit` with the additional args. If a commit template is
+// configured and the current branch name contains a ticket ID, the editor is
+// prefilled with the rendered template, e.g. "[PROJ-123] ".
+func (yas *YAS) Commit(args []string) error {
+	branchName, err := yas.git.GetCurrentBranchName()
+	if err != nil {
+		return err
+	}
+
+	message, err := yas.renderTicketTemplate("commitTemplate", yas.cfg.CommitTemplate, branchName)
+	if err != nil {
+		return err
+	}
+
+	if message == "" {
+		return yas.git.Commit(args...)
+	}
+
+	f, err := os.CreateTemp("", "yas-commit-template-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	if _, err := f.WriteString(message); err != nil {
+		f.Close()
+		return err
+	}
+
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	return yas.git.Commit(append([]string{"--template", f.Name()}, args...)...)
+}
+
+// appendTicketLink appends a link to the branch's ticket to the PR body, if a
+// ticket URL is configured and the branch name contains a ticket ID.
+func (yas *YAS) appendTicketLink(branchName, body string) (string, error) {
+	link, err := yas.renderTicketTemplate("ticketURL", yas.cfg.TicketURL, branchName)
+	if err != nil || link == "" {
+		return body, err
+	}
+
+	if body == "" {
+		return link, nil
+	}
+
+	return body + "\n\n" + link, nil
+}
@@ -2,42 +2,272 @@ package yas
 
 import (
 	"errors"
+	"fmt"
 	"os"
 	"path"
+	"strings"
+	"time"
 
 	"github.com/dansimau/yas/pkg/fsutil"
 	"gopkg.in/yaml.v2"
 )
 
-const configFilename = ".git/yas.yaml"
+const configFilename = "yas.yaml"
+
+const defaultDeletedBranchRetentionDays = 7
+
+const (
+	// PRBodyCommits generates PR bodies from the bodies of all commits on the
+	// branch. This is the default.
+	PRBodyCommits = "commits"
+
+	// PRBodyFirstCommit generates PR bodies from the body of the first commit
+	// on the branch only.
+	PRBodyFirstCommit = "first-commit"
+
+	// PRBodyEmpty creates PRs with an empty body.
+	PRBodyEmpty = "empty"
+)
+
+const (
+	// MergeStrategySquash squashes the commits of the branch into a single
+	// commit on trunk. This is the default.
+	MergeStrategySquash = "squash"
+
+	// MergeStrategyMerge creates a merge commit on trunk.
+	MergeStrategyMerge = "merge"
+
+	// MergeStrategyRebase rebases the commits of the branch onto trunk.
+	MergeStrategyRebase = "rebase"
+)
 
 type Config struct {
-	RepoDirectory string `yaml:"-"`
-	TrunkBranch   string `yaml:"trunkBranch"`
+	RepoDirectory string `yaml:"-" json:"-"`
+	TrunkBranch   string `yaml:"trunkBranch,omitempty"`
+
+	// PRBody controls how PR bodies are generated from commit messages when
+	// a PR is created.
+	PRBody string `yaml:"prBody,omitempty"`
+
+	// SignCommits signs all commits created by yas (e.g. during restack).
+	SignCommits bool `yaml:"signCommits,omitempty"`
+
+	// Autostash stashes local modifications before restacking and restores
+	// them afterwards.
+	Autostash bool `yaml:"autostash,omitempty"`
+
+	Hooks Hooks `yaml:"hooks,omitempty"`
+
+	// BranchPrefix is the prefix of branch names created by the user, e.g.
+	// "dan/". If empty, it is derived from the git user email.
+	BranchPrefix string `yaml:"branchPrefix,omitempty"`
+
+	// DefaultDraft creates new PRs as drafts. If not set, PRs are created
+	// as drafts.
+	DefaultDraft *bool `yaml:"defaultDraft,omitempty"`
+
+	// MergeSubject is a text/template for the subject of squash-merge
+	// commits, e.g. "{{.Title}} (#{{.Number}})". See mergeSubjectData for the
+	// available fields.
+	MergeSubject string `yaml:"mergeSubject,omitempty"`
+
+	// MergeStrategy is how branches are merged into trunk by default: squash
+	// (the default), merge or rebase.
+	MergeStrategy string `yaml:"mergeStrategy,omitempty"`
+
+	// Editor is the command used to edit messages, e.g. "code --wait". It
+	// is run with the shell, like git runs editors. If empty, the same editor
+	// as git is used (GIT_EDITOR, core.editor, VISUAL or EDITOR).
+	Editor string `yaml:"editor,omitempty"`
+
+	// CommitTemplate is a text/template used to prefill the commit message
+	// editor in `yas commit` when the branch name contains a ticket ID, e.g.
+	// "[{{.Ticket}}] ". See commitTemplateData for the available fields.
+	CommitTemplate string `yaml:"commitTemplate,omitempty"`
+
+	// TicketPattern is the regular expression that matches ticket IDs in
+	// branch names. If it has a capture group, the first group is the
+	// ticket ID. Default: [A-Z][A-Z0-9]*-[0-9]+, e.g. PROJ-123.
+	TicketPattern string `yaml:"ticketPattern,omitempty"`
+
+	// TicketURL is a text/template for a link to the ticket, e.g.
+	// "https://example.atlassian.net/browse/{{.Ticket}}". If set, the link is
+	// appended to the bodies of new PRs for branches with a ticket ID.
+	TicketURL string `yaml:"ticketURL,omitempty"`
+
+	// IgnoreBranchPatterns are glob patterns (as used by path.Match) of
+	// untracked branches to hide, e.g. machine-generated branches such as
+	// "dependabot/*". Like .gitignore, a pattern also matches branches under
+	// a matching leading directory, so "renovate" ignores "renovate/foo/bar".
+	IgnoreBranchPatterns []string `yaml:"ignoreBranchPatterns,omitempty"`
+
+	// GitHubAttempts is how many times gh commands are run before giving up,
+	// if they fail with rate-limit or network errors. Default: 3.
+	GitHubAttempts int `yaml:"githubAttempts,omitempty"`
+
+	// DeletedBranchRetentionDays is how many days the metadata of deleted
+	// branches is kept before it is pruned by `yas clean` or `yas sync`.
+	// Default: 7.
+	DeletedBranchRetentionDays *int `yaml:"deletedBranchRetentionDays,omitempty"`
+
+	// DeletedBranchRetainOpenPRs keeps the metadata of deleted branches whose
+	// PRs are still open, regardless of how long ago they were deleted.
+	DeletedBranchRetainOpenPRs bool `yaml:"deletedBranchRetainOpenPRs,omitempty"`
+
+	// WorktreeDir is the directory that `yas worktree add` creates worktrees
+	// in, one per branch. Relative paths are relative to the repository.
+	// Default: a "<repo>-worktrees" directory next to the repository.
+	WorktreeDir string `yaml:"worktreeDir,omitempty"`
+
+	// BaseBranchOverrides maps parent branches to the branches that PRs are
+	// opened against instead, e.g. {main: develop} to open PRs for branches
+	// on main against develop. Stack parents are unchanged.
+	BaseBranchOverrides map[string]string `yaml:"baseBranchOverrides,omitempty"`
+
+	// Limits are the PR size and stack depth limits checked on submit.
+	Limits Limits `yaml:"limits,omitempty"`
+}
+
+// githubAttempts returns how many times gh commands are attempted.
+func (c Config) githubAttempts() int {
+	if c.GitHubAttempts <= 0 {
+		return defaultGitHubAttempts
+	}
+
+	return c.GitHubAttempts
+}
+
+// DeletedBranchRetention returns how long the metadata of deleted branches is
+// kept before it is pruned.
+func (c Config) DeletedBranchRetention() time.Duration {
+	days := defaultDeletedBranchRetentionDays
+	if c.DeletedBranchRetentionDays != nil {
+		days = *c.DeletedBranchRetentionDays
+	}
+
+	return time.Duration(days) * 24 * time.Hour
+}
+
+// baseBranch returns the branch that PRs for branches on the parent branch are
+// opened against: the parent, or its override in BaseBranchOverrides.
+func (c Config) baseBranch(parent string) string {
+	if base := c.BaseBranchOverrides[parent]; base != "" {
+		return base
+	}
+
+	return parent
+}
+
+// worktreeDir returns the directory that worktrees are created in.
+func (c Config) worktreeDir() string {
+	if c.WorktreeDir == "" {
+		return path.Join(path.Dir(c.RepoDirectory), path.Base(c.RepoDirectory)+"-worktrees")
+	}
+
+	if path.IsAbs(c.WorktreeDir) {
+		return c.WorktreeDir
+	}
+
+	return path.Join(c.RepoDirectory, c.WorktreeDir)
+}
+
+// CreateDraftPRs returns true if new PRs should be created as drafts.
+func (c Config) CreateDraftPRs() bool {
+	return c.DefaultDraft == nil || *c.DefaultDraft
 }
 
 func IsConfigured(repoDirectory string) bool {
-	return fsutil.FileExists(path.Join(repoDirectory, configFilename))
+	return fsutil.FileExists(gitFile(repoDirectory, configFilename))
+}
+
+// GlobalConfigPath returns the path of the user's global config file. Values
+// in the global config are defaults for all repositories, and are overridden
+// by the repository config.
+func GlobalConfigPath() (string, error) {
+	configDir := os.Getenv("XDG_CONFIG_HOME")
+	if configDir == "" {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+
+		configDir = path.Join(homeDir, ".config")
+	}
+
+	return path.Join(configDir, "yas", "config.yaml"), nil
 }
 
+// ReadConfig reads the effective config for the repository, i.e. the global
+// config overridden by the repository config.
 func ReadConfig(repoDirectory string) (*Config, error) {
 	if !IsConfigured(repoDirectory) {
 		return nil, errors.New("repository not configured (hint: run `yas init`)")
 	}
 
-	yamlBytes, err := os.ReadFile(path.Join(repoDirectory, configFilename))
+	config, err := ReadGlobalConfig()
 	if err != nil {
 		return nil, err
 	}
 
-	config := Config{}
-	if err := yaml.Unmarshal(yamlBytes, &config); err != nil {
+	if err := readConfigFile(gitFile(repoDirectory, configFilename), config); err != nil {
 		return nil, err
 	}
 
 	config.RepoDirectory = repoDirectory
 
-	return &config, nil
+	return config, nil
+}
+
+// ReadRepoConfig reads only the repository config, without global defaults.
+func ReadRepoConfig(repoDirectory string) (*Config, error) {
+	if !IsConfigured(repoDirectory) {
+		return nil, errors.New("repository not configured (hint: run `yas init`)")
+	}
+
+	config := &Config{}
+	if err := readConfigFile(gitFile(repoDirectory, configFilename), config); err != nil {
+		return nil, err
+	}
+
+	config.RepoDirectory = repoDirectory
+
+	return config, nil
+}
+
+// ReadGlobalConfig reads the global config. If there is no global config file,
+// an empty config is returned.
+func ReadGlobalConfig() (*Config, error) {
+	configFilePath, err := GlobalConfigPath()
+	if err != nil {
+		return nil, err
+	}
+
+	config := &Config{}
+
+	if !fsutil.FileExists(configFilePath) {
+		return config, nil
+	}
+
+	if err := readConfigFile(configFilePath, config); err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
+
+// readConfigFile unmarshals the config file into config. Values not set in
+// the file are left unchanged.
+func readConfigFile(configFilePath string, config *Config) error {
+	yamlBytes, err := os.ReadFile(configFilePath)
+	if err != nil {
+		return err
+	}
+
+	if err := yaml.Unmarshal(yamlBytes, config); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", configFilePath, err)
+	}
+
+	return nil
 }
 
 // WriteConfig writes config to config file underneath the repo directory
@@ -48,10 +278,118 @@ func WriteConfig(cfg Config) (string, error) {
 		return "", err
 	}
 
-	configFilePath := path.Join(cfg.RepoDirectory, configFilename)
+	configFilePath := gitFile(cfg.RepoDirectory, configFilename)
 	if err := os.WriteFile(configFilePath, yamlBytes, 0o644); err != nil {
 		return "", err
 	}
 
 	return configFilePath, nil
 }
+
+// WriteGlobalConfig writes the global config file. It returns the path to the
+// file it wrote to.
+func WriteGlobalConfig(cfg Config) (string, error) {
+	yamlBytes, err := yaml.Marshal(cfg)
+	if err != nil {
+		return "", err
+	}
+
+	configFilePath, err := GlobalConfigPath()
+	if err != nil {
+		return "", err
+	}
+
+	if err := os.MkdirAll(path.Dir(configFilePath), 0o755); err != nil {
+		return "", err
+	}
+
+	if err := os.WriteFile(configFilePath, yamlBytes, 0o644); err != nil {
+		return "", err
+	}
+
+	return configFilePath, nil
+}
+
+// ConfigValues returns the config as a flat map of keys (as they appear in the
+// config file, with nested keys joined by ".") to values.
+func ConfigValues(cfg Config) (map[string]string, error) {
+	yamlBytes, err := yaml.Marshal(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	data := yaml.MapSlice{}
+	if err := yaml.Unmarshal(yamlBytes, &data); err != nil {
+		return nil, err
+	}
+
+	values := map[string]string{}
+	flattenConfigValues(values, "", data)
+
+	return values, nil
+}
+
+func flattenConfigValues(values map[string]string, prefix string, data yaml.MapSlice) {
+	for _, item := range data {
+		key := prefix + fmt.Sprint(item.Key)
+
+		if nested, ok := item.Value.(yaml.MapSlice); ok {
+			flattenConfigValues(values, key+".", nested)
+			continue
+		}
+
+		values[key] = fmt.Sprint(item.Value)
+	}
+}
+
+// UnsetConfigValue returns a copy of the config with the value of the key (as
+// returned by ConfigValues) removed, so that the default applies.
+func UnsetConfigValue(cfg Config, key string) (Config, error) {
+	yamlBytes, err := yaml.Marshal(cfg)
+	if err != nil {
+		return cfg, err
+	}
+
+	data := yaml.MapSlice{}
+	if err := yaml.Unmarshal(yamlBytes, &data); err != nil {
+		return cfg, err
+	}
+
+	data, removed := removeConfigValue(data, key)
+	if !removed {
+		return cfg, fmt.Errorf("config key '%s' is not set", key)
+	}
+
+	if yamlBytes, err = yaml.Marshal(data); err != nil {
+		return cfg, err
+	}
+
+	unset := Config{RepoDirectory: cfg.RepoDirectory}
+	if err := yaml.Unmarshal(yamlBytes, &unset); err != nil {
+		return cfg, err
+	}
+
+	return unset, nil
+}
+
+func removeConfigValue(data yaml.MapSlice, key string) (yaml.MapSlice, bool) {
+	for i, item := range data {
+		itemKey := fmt.Sprint(item.Key)
+
+		if itemKey == key {
+			return append(data[:i], data[i+1:]...), true
+		}
+
+		nested, ok := item.Value.(yaml.MapSlice)
+		if !ok || !strings.HasPrefix(key, itemKey+".") {
+			continue
+		}
+
+		if nested, removed := removeConfigValue(nested, strings.TrimPrefix(key, itemKey+".")); removed {
+			data[i].Value = nested
+			return data, true
+		}
+	}
+
+	return data, false
+}
@@ -0,0 +1,164 @@
+package yas
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/dansimau/yas/pkg/log"
+)
+
+const (
+	daemonPIDFile = "yas-daemon.pid"
+	daemonLogFile = "yas-daemon.log"
+)
+
+// Daemon manages the background process that periodically refreshes PR
+// metadata for a repository. The PID file is locked while the daemon is
+// running, which prevents more than one daemon running per repository.
+type Daemon struct {
+	repoDirectory string
+}
+
+func NewDaemon(repoDirectory string) *Daemon {
+	return &Daemon{repoDirectory: repoDirectory}
+}
+
+func (d *Daemon) pidFilePath() string {
+	return gitFile(d.repoDirectory, daemonPIDFile)
+}
+
+// LogFilePath returns the path of the file that daemon output is written to.
+func (d *Daemon) LogFilePath() string {
+	return gitFile(d.repoDirectory, daemonLogFile)
+}
+
+// Status returns the PID of the daemon and whether it is running.
+func (d *Daemon) Status() (pid int, running bool, err error) {
+	f, err := os.Open(d.pidFilePath())
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, false, nil
+	}
+
+	if err != nil {
+		return 0, false, err
+	}
+	defer f.Close()
+
+	// If the lock can be acquired, the daemon that wrote the PID file is no
+	// longer running
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_SH|syscall.LOCK_NB); err == nil {
+		return 0, false, nil
+	}
+
+	b, err := os.ReadFile(d.pidFilePath())
+	if err != nil {
+		return 0, false, err
+	}
+
+	pid, err = strconv.Atoi(strings.TrimSpace(string(b)))
+	if err != nil {
+		return 0, false, fmt.Errorf("invalid PID file %s: %w", d.pidFilePath(), err)
+	}
+
+	return pid, true, nil
+}
+
+// Start runs the specified command (which should run the daemon, see Run) as
+// a detached background process and returns its PID.
+func (d *Daemon) Start(command []string) (pid int, err error) {
+	if pid, running, err := d.Status(); err != nil {
+		return 0, err
+	} else if running {
+		return 0, fmt.Errorf("daemon already running (pid %d)", pid)
+	}
+
+	logFile, err := os.OpenFile(d.LogFilePath(), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return 0, err
+	}
+	defer logFile.Close()
+
+	c := exec.Command(command[0], command[1:]...)
+	c.Dir = d.repoDirectory
+	c.Stdout = logFile
+	c.Stderr = logFile
+	c.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+
+	if err := c.Start(); err != nil {
+		return 0, fmt.Errorf("failed to start daemon: %w", err)
+	}
+
+	pid = c.Process.Pid
+
+	return pid, c.Process.Release()
+}
+
+// Stop signals the daemon to exit.
+func (d *Daemon) Stop() error {
+	pid, running, err := d.Status()
+	if err != nil {
+		return err
+	}
+
+	if !running {
+		return errors.New("daemon is not running")
+	}
+
+	return syscall.Kill(pid, syscall.SIGTERM)
+}
+
+// Run refreshes PR metadata for all tracked branches every interval until
+// the process is interrupted or terminated.
+func (d *Daemon) Run(interval time.Duration) error {
+	f, err := os.OpenFile(d.pidFilePath(), os.O_CREATE|os.O_RDWR, 0o644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+		return errors.New("daemon already running")
+	}
+
+	if err := f.Truncate(0); err != nil {
+		return err
+	}
+
+	if _, err := f.WriteString(strconv.Itoa(os.Getpid()) + "\n"); err != nil {
+		return err
+	}
+
+	// Remove while the lock is still held, so another daemon can't have
+	// started in the meantime
+	defer os.Remove(d.pidFilePath())
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	log.Info("Daemon started, refreshing every", interval)
+
+	for {
+		if err := d.refresh(); err != nil {
+			log.Warn("failed to refresh PR status:", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			log.Info("Daemon stopped")
+			return nil
+		case <-time.After(interval):
+		}
+	}
+}
+
+func (d *Daemon) refresh() error {
+	return RefreshAllRemoteStatus(d.repoDirectory, "daemon refresh")
+}
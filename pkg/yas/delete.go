@@ -0,0 +1,95 @@
+package yas
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"slices"
+)
+
+// DeleteResult is the outcome of deleting a single branch.
+type DeleteResult struct {
+	Branch string
+
+	// Worktree is the path of the linked worktree that was removed along
+	// with the branch, if any.
+	Worktree string
+
+	Err error
+}
+
+// BranchesMatching returns the names of tracked branches that match the glob
+// pattern (as used by path.Match), e.g. "dan/spike-*".
+func (yas *YAS) BranchesMatching(pattern string) ([]string, error) {
+	names := []string{}
+
+	for _, branch := range yas.TrackedBranches() {
+		matches, err := path.Match(pattern, branch.Name)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern: %w", err)
+		}
+
+		if matches {
+			names = append(names, branch.Name)
+		}
+	}
+
+	slices.Sort(names)
+
+	return names, nil
+}
+
+// DeleteBranches deletes each of the branches, continuing if a branch fails
+// to delete. Children are deleted before their parents. Branches that are
+// checked out in a linked worktree have the worktree removed first, unless it
+// has local modifications. Branches with children that aren't deleted are
+// not deleted, since the children would be left without a parent.
+func (yas *YAS) DeleteBranches(names []string) ([]DeleteResult, error) {
+	worktrees, err := yas.BranchWorktrees()
+	if err != nil {
+		return nil, err
+	}
+
+	names = slices.Clone(names)
+	slices.SortStableFunc(names, func(a, b string) int {
+		return yas.stackDepth(b) - yas.stackDepth(a)
+	})
+
+	results := []DeleteResult{}
+	for _, name := range names {
+		result := DeleteResult{Branch: name}
+
+		if worktree, ok := worktrees[name]; ok && !worktree.Main {
+			result.Worktree = worktree.Path
+		}
+
+		result.Err = yas.deleteBranch(name, result.Worktree)
+		results = append(results, result)
+
+		if result.Err != nil {
+			recordStep(name, "failed", result.Err)
+		} else {
+			recordStep(name, "deleted", nil)
+		}
+	}
+
+	return results, nil
+}
+
+func (yas *YAS) deleteBranch(name, worktree string) error {
+	if name == yas.cfg.TrunkBranch {
+		return errors.New("cannot delete trunk branch")
+	}
+
+	if children := yas.data.Branches.ToSlice().NotDeleted().WithParent(name).SortedByName(); len(children) > 0 {
+		return fmt.Errorf("branch has children (hint: move '%s' onto another branch first)", children[0].Name)
+	}
+
+	if worktree != "" {
+		if err := yas.git.WorktreeRemoveUnmodified(worktree); err != nil {
+			return fmt.Errorf("failed to remove worktree %s: %w", worktree, err)
+		}
+	}
+
+	return yas.DeleteBranch(name)
+}
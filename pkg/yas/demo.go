@@ -0,0 +1,170 @@
+package yas
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+
+	"github.com/dansimau/yas/pkg/ghstub"
+	"github.com/dansimau/yas/pkg/gitexec"
+	"github.com/dansimau/yas/pkg/xexec"
+)
+
+// demoBranches are the stacked branches of the demo repository, parents
+// first. Each branch adds a file to the branch below it.
+var demoBranches = []struct {
+	name, parent, file, message string
+}{
+	{"demo/topic-a", "main", "a.txt", "Add a"},
+	{"demo/topic-b", "demo/topic-a", "b.txt", "Add b"},
+	{"demo/topic-c", "demo/topic-b", "c.txt", "Add c"},
+}
+
+// CreateDemoRepository creates a playground repository in dir, for trying out
+// yas without touching real repositories or PRs. It contains a trunk and a
+// stack of three branches with PRs, and trunk has moved on since the stack was
+// created, so that it needs restacking. The origin remote is a local bare
+// repository in dir, so nothing is ever pushed to GitHub, and the PRs are on a
+// fake GitHub whose state is kept in a file in dir, which yas uses when
+// YAS_GH_STUB is set to its path. It returns the path of the repository and
+// of the fake GitHub's state file.
+func CreateDemoRepository(dir string) (repoDir, gitHubStubFile string, err error) {
+	dir, err = filepath.Abs(dir)
+	if err != nil {
+		return "", "", err
+	}
+
+	originDir := path.Join(dir, "origin.git")
+	repoDir = path.Join(dir, "repo")
+	gitHubStubFile = path.Join(dir, "github.json")
+
+	git := func(dir string, args ...string) error {
+		args = append([]string{"git", "-c", "user.name=yas demo", "-c", "user.email=demo@example.com"}, args...)
+
+		if err := xexec.Command(args...).WithEnvVars(gitexec.CleanedGitEnv()).WithWorkingDir(dir).WithStdout(nil).WithStderr(nil).Run(); err != nil {
+			return fmt.Errorf("failed to create demo repository: %w", err)
+		}
+
+		return nil
+	}
+
+	commit := func(file, content, message string) error {
+		if err := os.WriteFile(path.Join(repoDir, file), []byte(content), 0o644); err != nil {
+			return err
+		}
+
+		if err := git(repoDir, "add", file); err != nil {
+			return err
+		}
+
+		return git(repoDir, "commit", "-q", "-m", message)
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", "", err
+	}
+
+	if err := git(dir, "init", "-q", "--bare", "--initial-branch=main", originDir); err != nil {
+		return "", "", err
+	}
+
+	if err := git(dir, "clone", "-q", originDir, repoDir); err != nil {
+		return "", "", err
+	}
+
+	if err := commit("README.md", "# yas demo\n", "Initial commit"); err != nil {
+		return "", "", err
+	}
+
+	if err := git(repoDir, "push", "-q", "origin", "main"); err != nil {
+		return "", "", err
+	}
+
+	for _, branch := range demoBranches {
+		if err := git(repoDir, "checkout", "-q", "-b", branch.name); err != nil {
+			return "", "", err
+		}
+
+		if err := commit(branch.file, branch.name+"\n", branch.message); err != nil {
+			return "", "", err
+		}
+
+		if err := git(repoDir, "push", "-q", "-u", "origin", branch.name); err != nil {
+			return "", "", err
+		}
+	}
+
+	// Someone else merged to trunk since the stack was created
+	if err := git(repoDir, "checkout", "-q", "main"); err != nil {
+		return "", "", err
+	}
+
+	if err := commit("README.md", "# yas demo\n\nTry `yas list` and `yas restack`.\n", "Update README"); err != nil {
+		return "", "", err
+	}
+
+	if err := git(repoDir, "push", "-q", "origin", "main"); err != nil {
+		return "", "", err
+	}
+
+	if err := git(repoDir, "checkout", "-q", demoBranches[len(demoBranches)-1].name); err != nil {
+		return "", "", err
+	}
+
+	cfg := Config{RepoDirectory: repoDir, TrunkBranch: "main"}
+	if _, err := WriteConfig(cfg); err != nil {
+		return "", "", err
+	}
+
+	yas, err := New(cfg)
+	if err != nil {
+		return "", "", err
+	}
+
+	stub, err := ghstub.Load(gitHubStubFile)
+	if err != nil {
+		return "", "", err
+	}
+
+	stub.Repository = "example/yas-demo"
+	stub.User = "yas-demo"
+
+	for i, branch := range demoBranches {
+		branchPoint, err := yas.git.GetMergeBase(branch.parent, branch.name)
+		if err != nil {
+			return "", "", err
+		}
+
+		pr, err := stub.Create(branch.name, branch.parent, branch.message, "", i == len(demoBranches)-1)
+		if err != nil {
+			return "", "", err
+		}
+
+		yas.data.Branches.Set(branch.name, BranchMetadata{
+			Name:        branch.name,
+			Parent:      branch.parent,
+			BranchPoint: branchPoint,
+			GitHubPullRequest: PullRequestMetadata{
+				ID:          pr.ID,
+				Number:      pr.Number,
+				State:       pr.State,
+				URL:         pr.URL,
+				Author:      PullRequestAuthor{Login: pr.Author.Login},
+				IsDraft:     pr.IsDraft,
+				UpdatedAt:   pr.UpdatedAt,
+				BaseRefName: pr.BaseRefName,
+			},
+		})
+	}
+
+	if err := stub.Save(); err != nil {
+		return "", "", err
+	}
+
+	if err := yas.data.Save(); err != nil {
+		return "", "", err
+	}
+
+	return repoDir, gitHubStubFile, nil
+}
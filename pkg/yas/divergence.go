@@ -0,0 +1,102 @@
+package yas
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/dansimau/yas/pkg/gitexec"
+)
+
+// ForeignCommit is a commit on a branch that also belongs to another tracked
+// branch outside of the branch's stack, e.g. as a result of a botched rebase.
+type ForeignCommit struct {
+	gitexec.Commit
+
+	// Owner is the branch the commit also belongs to.
+	Owner string
+}
+
+// foreignCommits returns the commits on the branch (i.e. not on its parent)
+// that are also reachable from tracked branches that are not ancestors or
+// descendants of the branch. If the branch is wholly contained in another
+// branch, the commits are considered foreign to the other branch instead.
+func (yas *YAS) foreignCommits(branchName string) ([]ForeignCommit, error) {
+	metadata := yas.data.Branches.Get(branchName)
+	if metadata.Parent == "" {
+		return nil, nil
+	}
+
+	tip, err := yas.git.GetHash(branchName)
+	if err != nil {
+		return nil, err
+	}
+
+	lineage := yas.stack(branchName)
+	seen := map[string]bool{}
+	result := []ForeignCommit{}
+
+	for _, other := range yas.TrackedBranches().SortedByName() {
+		if slices.Contains(lineage, other.Name) {
+			continue
+		}
+
+		mergeBase, err := yas.git.GetMergeBase(branchName, other.Name)
+		if err != nil {
+			// No common history
+			continue
+		}
+
+		if mergeBase == tip {
+			continue
+		}
+
+		commits, err := yas.git.Commits(metadata.Parent, mergeBase)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, commit := range commits {
+			if seen[commit.Hash] {
+				continue
+			}
+
+			seen[commit.Hash] = true
+			result = append(result, ForeignCommit{Commit: commit, Owner: other.Name})
+		}
+	}
+
+	return result, nil
+}
+
+// checkDivergence returns an error if any of the branches contain foreign
+// commits.
+func (yas *YAS) checkDivergence(branchNames ...string) error {
+	for _, branchName := range branchNames {
+		commits, err := yas.foreignCommits(branchName)
+		if err != nil {
+			return err
+		}
+
+		if len(commits) > 0 {
+			return fmt.Errorf("branch '%s' contains %d commit(s) from other branches (%s) (hint: rebase to remove them or use --allow-divergence)", branchName, len(commits), foreignOwners(commits))
+		}
+	}
+
+	return nil
+}
+
+// foreignOwners returns the sorted, comma-separated list of branches that the
+// commits belong to.
+func foreignOwners(commits []ForeignCommit) string {
+	owners := []string{}
+	for _, commit := range commits {
+		if !slices.Contains(owners, commit.Owner) {
+			owners = append(owners, commit.Owner)
+		}
+	}
+
+	slices.Sort(owners)
+
+	return strings.Join(owners, ", ")
+}
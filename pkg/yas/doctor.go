@@ -0,0 +1,125 @@
+package yas
+
+import (
+	"fmt"
+)
+
+// Doctor runs checks against the repository and yas state and returns a list
+// of any problems found.
+func (yas *YAS) Doctor() (problems []string, err error) {
+	checks := []func() ([]string, error){
+		yas.checkCommitSignatures,
+		yas.checkForeignCommits,
+		yas.checkBranchPoints,
+	}
+
+	for _, check := range checks {
+		result, err := check()
+		if err != nil {
+			return nil, err
+		}
+
+		problems = append(problems, result...)
+	}
+
+	return problems, nil
+}
+
+// checkCommitSignatures reports branches containing unsigned commits when the
+// repository requires commits to be signed.
+func (yas *YAS) checkCommitSignatures() ([]string, error) {
+	gpgSign, err := yas.git.ConfigValue("commit.gpgsign")
+	if err != nil {
+		return nil, err
+	}
+
+	if !yas.cfg.SignCommits && gpgSign != "true" {
+		return nil, nil
+	}
+
+	branches := yas.data.Branches.ToSlice().NotDeleted().WithParents().SortedByName()
+
+	problems := []string{}
+	for _, branch := range branches {
+		statuses, err := yas.git.CommitSignatureStatuses(branch.Parent, branch.Name)
+		if err != nil {
+			return nil, err
+		}
+
+		unsigned := 0
+		for _, status := range statuses {
+			if status == "N" {
+				unsigned++
+			}
+		}
+
+		if unsigned > 0 {
+			problems = append(problems, fmt.Sprintf("branch '%s' has %d unsigned commit(s) (hint: set signCommits and run `yas restack`)", branch.Name, unsigned))
+		}
+	}
+
+	return problems, nil
+}
+
+// checkForeignCommits reports branches containing commits that belong to other
+// branches outside of their stack.
+func (yas *YAS) checkForeignCommits() ([]string, error) {
+	problems := []string{}
+
+	for _, branch := range yas.data.Branches.ToSlice().NotDeleted().WithParents().SortedByName() {
+		commits, err := yas.foreignCommits(branch.Name)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(commits) > 0 {
+			problems = append(problems, fmt.Sprintf("branch '%s' contains %d commit(s) from other branches (%s)", branch.Name, len(commits), foreignOwners(commits)))
+		}
+	}
+
+	return problems, nil
+}
+
+// checkBranchPoints reports branches that don't have a branch point recorded,
+// e.g. because they were tracked before branch points were recorded.
+func (yas *YAS) checkBranchPoints() ([]string, error) {
+	problems := []string{}
+
+	for _, branch := range yas.data.Branches.ToSlice().NotDeleted().WithParents().SortedByName() {
+		if branch.BranchPoint == "" {
+			problems = append(problems, fmt.Sprintf("branch '%s' has no branch point recorded (hint: run `yas doctor --fix`)", branch.Name))
+		}
+	}
+
+	return problems, nil
+}
+
+// DoctorFix fixes the problems found by Doctor that can be fixed
+// automatically, and returns a description of each fix.
+func (yas *YAS) DoctorFix() (fixes []string, err error) {
+	branchNames := yas.data.Branches.ToSlice().NotDeleted().WithParents().SortedByName().BranchNames()
+
+	backfilled, err := yas.backfillBranchPoints(branchNames)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, name := range backfilled {
+		fixes = append(fixes, fmt.Sprintf("recorded branch point of '%s' (%s)", name, shortHash(yas.data.Branches.Get(name).BranchPoint)))
+	}
+
+	repaired, err := yas.repairStaleBranchPointsOf(branchNames)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(repaired) == 0 {
+		return fixes, nil
+	}
+
+	for _, name := range repaired {
+		fixes = append(fixes, fmt.Sprintf("updated stale branch point of '%s' (%s)", name, shortHash(yas.data.Branches.Get(name).BranchPoint)))
+	}
+
+	return fixes, yas.data.Save()
+}
@@ -0,0 +1,113 @@
+package yas
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path"
+	"time"
+
+	"github.com/dansimau/yas/pkg/log"
+	"github.com/dansimau/yas/pkg/xexec"
+)
+
+// pluginsDir contains executables that are run for every event emitted by
+// yas. Each plugin receives the event as JSON on stdin.
+const pluginsDir = "yas-plugins"
+
+type EventType string
+
+const (
+	EventBranchCreated EventType = "BranchCreated"
+	EventBranchDeleted EventType = "BranchDeleted"
+	EventRestacked     EventType = "Restacked"
+	EventPRSubmitted   EventType = "PRSubmitted"
+	EventPRMerged      EventType = "PRMerged"
+)
+
+// Event describes an operation that yas has completed.
+type Event struct {
+	Type   EventType `json:"type"`
+	Time   time.Time `json:"time"`
+	Branch string    `json:"branch"`
+	Parent string    `json:"parent,omitempty"`
+	PRURL  string    `json:"prURL,omitempty"`
+
+	// Branches lists all branches affected by the operation, e.g. every
+	// branch that was restacked.
+	Branches []string `json:"branches,omitempty"`
+}
+
+// plugins returns the paths to the executables in the plugins directory, in
+// lexical order.
+func (yas *YAS) plugins() []string {
+	dir := gitFile(yas.cfg.RepoDirectory, pluginsDir)
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+
+	plugins := []string{}
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil || !info.Mode().IsRegular() || info.Mode().Perm()&0o111 == 0 {
+			continue
+		}
+
+		plugins = append(plugins, path.Join(dir, entry.Name()))
+	}
+
+	return plugins
+}
+
+// emit sends the event to all plugins, and records it in the command's result,
+// if it is being recorded. Like post hooks, the operation has already
+// completed, so plugin failures are only reported.
+func (yas *YAS) emit(event Event) {
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+
+	if event.Branch != "" {
+		metadata := yas.data.Branches.Get(event.Branch)
+
+		if event.Parent == "" {
+			event.Parent = metadata.Parent
+		}
+
+		if event.PRURL == "" {
+			event.PRURL = metadata.GitHubPullRequest.URL
+		}
+	}
+
+	recordEvent(event)
+
+	if os.Getenv("YAS_NO_HOOKS") != "" {
+		return
+	}
+
+	plugins := yas.plugins()
+	if len(plugins) == 0 {
+		return
+	}
+
+	b, err := json.Marshal(event)
+	if err != nil {
+		log.Warn("failed to encode event:", err)
+		return
+	}
+
+	for _, plugin := range plugins {
+		log.Debug("Sending", event.Type, "event to plugin", plugin)
+
+		err := xexec.Command(plugin).
+			WithStdin(bytes.NewReader(b)).
+			WithEnvVars(append(os.Environ(), "YAS_EVENT="+string(event.Type))).
+			WithWorkingDir(yas.cfg.RepoDirectory).
+			Run()
+		if err != nil {
+			log.Warn("plugin", path.Base(plugin), "failed:", err)
+		}
+	}
+}
@@ -0,0 +1,194 @@
+package yas
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/dansimau/yas/pkg/ghstub"
+	"github.com/dansimau/yas/pkg/log"
+	"github.com/dansimau/yas/pkg/xexec"
+)
+
+// defaultGitHubAttempts is how many times a gh command is run before giving
+// up, if it fails with a rate-limit or network error.
+const defaultGitHubAttempts = 3
+
+const defaultGHRetryDelay = time.Second
+
+// ghRetryDelay is the delay before the first retry of a gh command. It
+// doubles with each subsequent retry.
+var ghRetryDelay = defaultGHRetryDelay
+
+// GitHubErrorKind classifies why a gh command failed.
+type GitHubErrorKind string
+
+const (
+	GitHubErrorAuth      GitHubErrorKind = "authentication"
+	GitHubErrorRateLimit GitHubErrorKind = "rate limit"
+	GitHubErrorNetwork   GitHubErrorKind = "network"
+	GitHubErrorOther     GitHubErrorKind = "other"
+)
+
+// ghErrorPatterns are substrings of (lowercased) gh stderr that identify the
+// kind of error.
+var ghErrorPatterns = []struct {
+	kind     GitHubErrorKind
+	patterns []string
+}{
+	{GitHubErrorAuth, []string{"gh auth login", "authentication", "bad credentials", "http 401", "not logged in"}},
+	{GitHubErrorRateLimit, []string{"rate limit", "http 429"}},
+	{GitHubErrorNetwork, []string{"dial tcp", "connection refused", "connection reset", "i/o timeout", "no such host", "tls handshake", "http 502", "http 503", "http 504", "unexpected eof"}},
+}
+
+// GitHubError is a failed gh command.
+type GitHubError struct {
+	Kind GitHubErrorKind
+
+	// Message is the first line of gh's stderr.
+	Message string
+
+	Err error
+}
+
+func (e *GitHubError) Error() string {
+	msg := e.Message
+	if msg == "" {
+		msg = e.Err.Error()
+	}
+
+	switch e.Kind {
+	case GitHubErrorAuth:
+		return fmt.Sprintf("GitHub authentication failed: %s (hint: run `gh auth login`)", msg)
+	case GitHubErrorRateLimit:
+		return fmt.Sprintf("GitHub rate limit exceeded: %s (hint: wait a few minutes and try again)", msg)
+	case GitHubErrorNetwork:
+		return fmt.Sprintf("network error talking to GitHub: %s", msg)
+	default:
+		return fmt.Sprintf("gh failed: %s", msg)
+	}
+}
+
+func (e *GitHubError) Unwrap() error {
+	return e.Err
+}
+
+// retryable returns true if the command may succeed if run again.
+func (e *GitHubError) retryable() bool {
+	return e.Kind == GitHubErrorRateLimit || e.Kind == GitHubErrorNetwork
+}
+
+// classifyGitHubError returns a GitHubError for an error from running gh,
+// based on its stderr.
+func classifyGitHubError(err error) *GitHubError {
+	stderr := ""
+
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		stderr = strings.TrimSpace(string(exitErr.Stderr))
+	}
+
+	ghErr := &GitHubError{Kind: GitHubErrorOther, Message: stderr, Err: err}
+	if line, _, found := strings.Cut(stderr, "\n"); found {
+		ghErr.Message = line
+	}
+
+	lower := strings.ToLower(stderr)
+	for _, p := range ghErrorPatterns {
+		for _, pattern := range p.patterns {
+			if strings.Contains(lower, pattern) {
+				ghErr.Kind = p.kind
+				return ghErr
+			}
+		}
+	}
+
+	return ghErr
+}
+
+// gitHubStubEnvVar is the environment variable that, when set to the path of
+// a file, makes yas use a fake GitHub that keeps PRs in the file instead of
+// running gh (see package ghstub), e.g. for tests and the demo repository.
+const gitHubStubEnvVar = "YAS_GH_STUB"
+
+// gitHubProvider runs gh commands, against GitHub or a fake of it.
+type gitHubProvider interface {
+	// output runs a read-only gh command in dir and returns its stdout.
+	output(dir string, args ...string) ([]byte, error)
+
+	// run runs a gh command in dir that changes something on GitHub, e.g.
+	// creates a PR, printing its output.
+	run(dir string, args ...string) error
+}
+
+// newGitHubProvider returns the fake GitHub if YAS_GH_STUB is set, or gh
+// otherwise.
+func newGitHubProvider() gitHubProvider {
+	if path := os.Getenv(gitHubStubEnvVar); path != "" {
+		return gitHubStub(path)
+	}
+
+	return ghCLI{}
+}
+
+// ghCLI runs commands with the gh CLI.
+type ghCLI struct{}
+
+func (ghCLI) output(dir string, args ...string) ([]byte, error) {
+	return xexec.Command(append([]string{"gh"}, args...)...).WithWorkingDir(dir).WithStdout(nil).WithStderr(nil).Output()
+}
+
+func (ghCLI) run(dir string, args ...string) error {
+	return xexec.Command(append([]string{"gh"}, args...)...).WithWorkingDir(dir).Run()
+}
+
+// gitHubStub runs commands against the fake GitHub whose state is kept in the
+// file.
+type gitHubStub string
+
+func (s gitHubStub) output(dir string, args ...string) ([]byte, error) {
+	return ghstub.Run(string(s), dir, args...)
+}
+
+func (s gitHubStub) run(dir string, args ...string) error {
+	b, err := ghstub.Run(string(s), dir, args...)
+	os.Stdout.Write(b)
+
+	return err
+}
+
+// gh runs a read-only gh command and returns its stdout. Commands that fail
+// with rate-limit or network errors are retried with exponential backoff, up
+// to the configured number of attempts.
+func (yas *YAS) gh(args ...string) ([]byte, error) {
+	return runGH(yas.github, yas.cfg.RepoDirectory, yas.cfg.githubAttempts(), args...)
+}
+
+// ghRun runs a gh command that changes something on GitHub.
+func (yas *YAS) ghRun(args ...string) error {
+	return yas.github.run(yas.cfg.RepoDirectory, args...)
+}
+
+func runGH(github gitHubProvider, dir string, attempts int, args ...string) ([]byte, error) {
+	delay := ghRetryDelay
+
+	for attempt := 1; ; attempt++ {
+		b, err := github.output(dir, args...)
+		if err == nil {
+			return b, nil
+		}
+
+		ghErr := classifyGitHubError(err)
+		if !ghErr.retryable() || attempt >= attempts {
+			return nil, ghErr
+		}
+
+		log.Info(fmt.Sprintf("gh %s failed (attempt %d of %d), retrying in %s: %s", args[0], attempt, attempts, delay, ghErr))
+
+		time.Sleep(delay)
+		delay *= 2
+	}
+}
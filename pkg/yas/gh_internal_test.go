@@ -0,0 +1,75 @@
+package yas
+
+import (
+	"errors"
+	"os"
+	"path"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+// withFakeGH puts a fake gh that runs script on PATH.
+func withFakeGH(t *testing.T, script string) string {
+	binDir := t.TempDir()
+	assert.NilError(t, os.WriteFile(path.Join(binDir, "gh"), []byte("#!/bin/sh\n"+script+"\n"), 0o755))
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	return binDir
+}
+
+func TestRunGHRetriesRateLimit(t *testing.T) {
+	ghRetryDelay = 0
+	t.Cleanup(func() { ghRetryDelay = defaultGHRetryDelay })
+
+	// Fails with a rate-limit error on the first call, then succeeds
+	dir := withFakeGH(t, `
+		if [ ! -f "$0.called" ]; then
+			touch "$0.called"
+			echo "API rate limit exceeded for user ID 1." >&2
+			exit 1
+		fi
+		echo '[]'
+	`)
+
+	b, err := runGH(ghCLI{}, dir, 3, "pr", "list")
+	assert.NilError(t, err)
+	assert.Equal(t, string(b), "[]\n")
+}
+
+func TestRunGHGivesUpAfterAttempts(t *testing.T) {
+	ghRetryDelay = 0
+	t.Cleanup(func() { ghRetryDelay = defaultGHRetryDelay })
+
+	dir := withFakeGH(t, `
+		echo x >> "$0.calls"
+		echo "Post \"https://api.github.com/graphql\": dial tcp: lookup api.github.com: no such host" >&2
+		exit 1
+	`)
+
+	_, err := runGH(ghCLI{}, dir, 2, "pr", "list")
+	assert.ErrorContains(t, err, "network error talking to GitHub")
+
+	calls, err := os.ReadFile(path.Join(dir, "gh.calls"))
+	assert.NilError(t, err)
+	assert.Equal(t, string(calls), "x\nx\n")
+}
+
+func TestRunGHDoesNotRetryAuth(t *testing.T) {
+	dir := withFakeGH(t, `
+		echo x >> "$0.calls"
+		echo "To get started with GitHub CLI, please run:  gh auth login" >&2
+		exit 4
+	`)
+
+	_, err := runGH(ghCLI{}, dir, 3, "pr", "list")
+
+	ghErr := &GitHubError{}
+	assert.Assert(t, errors.As(err, &ghErr))
+	assert.Equal(t, ghErr.Kind, GitHubErrorAuth)
+	assert.ErrorContains(t, err, "hint: run `gh auth login`")
+
+	calls, err := os.ReadFile(path.Join(dir, "gh.calls"))
+	assert.NilError(t, err)
+	assert.Equal(t, string(calls), "x\n")
+}
@@ -0,0 +1,30 @@
+package yas
+
+import (
+	"path"
+	"sync"
+
+	"github.com/dansimau/yas/pkg/gitexec"
+)
+
+// gitDirs caches the common git directory of each repository directory.
+var gitDirs sync.Map
+
+// gitFile returns the path of the yas file with the name in the git directory
+// of the repository. It is the git directory shared by all worktrees, so that
+// yas has the same config and state in every worktree of the repository.
+func gitFile(repoDirectory, name string) string {
+	if dir, ok := gitDirs.Load(repoDirectory); ok {
+		return path.Join(dir.(string), name)
+	}
+
+	dir, err := gitexec.WithRepo(repoDirectory).CommonDir()
+	if err != nil {
+		// Not a repository (yet)
+		return path.Join(repoDirectory, ".git", name)
+	}
+
+	gitDirs.Store(repoDirectory, dir)
+
+	return path.Join(dir, name)
+}
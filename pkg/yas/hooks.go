@@ -0,0 +1,68 @@
+package yas
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/dansimau/yas/pkg/log"
+	"github.com/dansimau/yas/pkg/xexec"
+)
+
+// Hooks are shell commands run before and after yas operations. If a pre hook
+// exits non-zero, the operation is aborted.
+type Hooks struct {
+	PreSubmit   string `yaml:"preSubmit,omitempty"`
+	PostSubmit  string `yaml:"postSubmit,omitempty"`
+	PreRestack  string `yaml:"preRestack,omitempty"`
+	PostRestack string `yaml:"postRestack,omitempty"`
+	PreMerge    string `yaml:"preMerge,omitempty"`
+	PostMerge   string `yaml:"postMerge,omitempty"`
+}
+
+// runPreHook runs the specified hook command, returning an error if the hook
+// fails.
+func (yas *YAS) runPreHook(hookName, command, branchName string) error {
+	if err := yas.runHook(hookName, command, branchName); err != nil {
+		return fmt.Errorf("%s hook failed: %w", hookName, err)
+	}
+
+	return nil
+}
+
+// runPostHook runs the specified hook command. The operation has already
+// completed, so failures are only reported.
+func (yas *YAS) runPostHook(hookName, command, branchName string) {
+	if err := yas.runHook(hookName, command, branchName); err != nil {
+		log.Warn(hookName, "hook failed:", err)
+	}
+}
+
+// runHook runs a hook command with the shell. Details about the branch being
+// operated on are passed to the command in environment variables.
+func (yas *YAS) runHook(hookName, command, branchName string) error {
+	if command == "" || os.Getenv("YAS_NO_HOOKS") != "" {
+		return nil
+	}
+
+	metadata := yas.data.Branches.Get(branchName)
+
+	// The tip of a combined stack is submitted as the stack's PR
+	prURL := metadata.GitHubPullRequest.URL
+	if stack := yas.data.Stacks[yas.stackRoot(branchName)]; stack.Combined && stack.CombinedHead == branchName {
+		prURL = stack.CombinedPullRequest.URL
+	}
+
+	env := append(os.Environ(),
+		"YAS_HOOK="+hookName,
+		"YAS_BRANCH="+branchName,
+		"YAS_PARENT="+metadata.Parent,
+		"YAS_PR_URL="+prURL,
+	)
+
+	fmt.Printf("Running %s hook...\n", hookName)
+
+	return xexec.Command("sh", "-c", command).
+		WithEnvVars(env).
+		WithWorkingDir(yas.cfg.RepoDirectory).
+		Run()
+}
@@ -0,0 +1,86 @@
+package yas
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/dansimau/yas/pkg/gitexec"
+)
+
+// Limits are checked when submitting, to encourage small PRs. A value of
+// zero means no limit.
+type Limits struct {
+	// MaxPRLines is the maximum number of changed lines in a PR.
+	MaxPRLines int `yaml:"maxPRLines,omitempty"`
+
+	// MaxPRFiles is the maximum number of changed files in a PR.
+	MaxPRFiles int `yaml:"maxPRFiles,omitempty"`
+
+	// MaxStackDepth is the maximum number of branches in a stack, from trunk
+	// to the tip.
+	MaxStackDepth int `yaml:"maxStackDepth,omitempty"`
+}
+
+// branchDiffStat returns the changes on the branch since its branch point.
+func (yas *YAS) branchDiffStat(branchName string) (gitexec.DiffStat, error) {
+	metadata := yas.data.Branches.Get(branchName)
+
+	base := metadata.BranchPoint
+	if base == "" {
+		base = metadata.Parent
+	}
+
+	return yas.git.DiffStat(base, branchName)
+}
+
+// stackDepth returns the number of branches from trunk to the specified
+// branch, inclusive.
+func (yas *YAS) stackDepth(branchName string) int {
+	depth := 0
+	for _, name := range yas.stack(branchName) {
+		depth++
+		if name == branchName {
+			break
+		}
+	}
+
+	return depth
+}
+
+// limitViolations returns a description of each configured limit exceeded by
+// a PR with the specified changes and stack depth.
+func (yas *YAS) limitViolations(stat gitexec.DiffStat, depth int) []string {
+	limits := yas.cfg.Limits
+	violations := []string{}
+
+	if limits.MaxPRLines > 0 && stat.Lines() > limits.MaxPRLines {
+		violations = append(violations, fmt.Sprintf("%d lines changed (max %d)", stat.Lines(), limits.MaxPRLines))
+	}
+
+	if limits.MaxPRFiles > 0 && stat.Files > limits.MaxPRFiles {
+		violations = append(violations, fmt.Sprintf("%d files changed (max %d)", stat.Files, limits.MaxPRFiles))
+	}
+
+	if limits.MaxStackDepth > 0 && depth > limits.MaxStackDepth {
+		violations = append(violations, fmt.Sprintf("stack depth %d (max %d)", depth, limits.MaxStackDepth))
+	}
+
+	return violations
+}
+
+// checkLimits prints a warning if the PR for the branch exceeds the configured
+// limits, or returns an error if strict is true.
+func (yas *YAS) checkLimits(branchName string, stat gitexec.DiffStat, depth int, strict bool) error {
+	violations := yas.limitViolations(stat, depth)
+	if len(violations) == 0 {
+		return nil
+	}
+
+	if strict {
+		return fmt.Errorf("branch '%s' exceeds limits: %s (hint: split the branch into smaller PRs)", branchName, strings.Join(violations, ", "))
+	}
+
+	fmt.Printf("⚠️  %s exceeds limits: %s\n", branchName, strings.Join(violations, ", "))
+
+	return nil
+}
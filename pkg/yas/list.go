@@ -0,0 +1,335 @@
+package yas
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/dansimau/yas/pkg/gitexec"
+	"github.com/dansimau/yas/pkg/termutil"
+	"github.com/heimdalr/dag"
+	"github.com/xlab/treeprint"
+)
+
+type ListOptions struct {
+	// All includes untracked branches.
+	All bool
+
+	// Mine only shows branches with the user's branch prefix.
+	Mine bool
+
+	// Author only shows branches with PRs created by the specified GitHub
+	// user.
+	Author string
+
+	// OlderThan only shows branches whose last commit is older than the
+	// specified duration.
+	OlderThan time.Duration
+
+	// Stack only shows the stack containing the specified branch, or the
+	// stack with the specified name.
+	Stack string
+
+	// Verbose shows the number of lines changed on each branch since its
+	// branch point, and the path of its worktree, if it is checked out in a
+	// linked worktree.
+	Verbose bool
+
+	// Porcelain prints one line per branch in a stable format for scripts,
+	// instead of the tree. See listPorcelain.
+	Porcelain bool
+}
+
+func (opts ListOptions) filtered() bool {
+	return opts.Mine || opts.Author != "" || opts.OlderThan > 0 || opts.Stack != ""
+}
+
+func (yas *YAS) toTree(graph *dag.DAG, rootNode string, visible map[string]bool, details map[string]string) (treeprint.Tree, error) {
+	tree := treeprint.NewWithRoot(rootNode)
+
+	if err := addNodesFromGraph(tree, graph, rootNode, visible, details, yas.stackHeaders()); err != nil {
+		return nil, err
+	}
+
+	return tree, nil
+}
+
+func (yas *YAS) List(opts ListOptions) error {
+	opts.Stack = yas.resolveStack(opts.Stack)
+
+	graph, err := yas.graph()
+	if err != nil {
+		return fmt.Errorf("failed to get graph: %w", err)
+	}
+
+	// Look up all branch tips at once, rather than running git commands for
+	// each branch
+	refs, err := yas.git.BranchRefs()
+	if err != nil {
+		return err
+	}
+
+	var visible map[string]bool
+	if opts.filtered() {
+		if visible, err = yas.visibleBranches(opts, refs); err != nil {
+			return err
+		}
+	}
+
+	cache := loadDiffStatCache(gitFile(yas.cfg.RepoDirectory, diffStatCacheFile))
+	defer cache.save()
+
+	if _, err := yas.repairStaleBranchPoints(yas.TrackedBranches().BranchNames(), refs, cache); err != nil {
+		return err
+	}
+
+	if opts.Porcelain {
+		return yas.listPorcelain(opts, visible, refs, cache)
+	}
+
+	details := map[string]string{}
+	if opts.Verbose {
+		if details, err = yas.branchDetails(refs, cache); err != nil {
+			return err
+		}
+	}
+
+	aheadBehind, err := yas.remoteAheadBehind(refs, cache)
+	if err != nil {
+		return err
+	}
+
+	for name, counts := range aheadBehind {
+		if label := aheadBehindLabel(counts); label != "" {
+			details[name] = strings.TrimSpace(label + " " + details[name])
+		}
+	}
+
+	for _, branch := range yas.TrackedBranches() {
+		if label := needsSubmitLabel(branch, aheadBehind[branch.Name]); label != "" {
+			details[branch.Name] = strings.TrimSpace(details[branch.Name] + " " + label)
+		}
+	}
+
+	tree, err := yas.toTree(graph, yas.cfg.TrunkBranch, visible, details)
+	if err != nil {
+		return err
+	}
+
+	fmt.Print(tree.String())
+
+	if !opts.All {
+		return nil
+	}
+
+	untrackedBranches, err := yas.UntrackedBranches()
+	if err != nil {
+		return err
+	}
+
+	untracked := []string{}
+	for _, name := range untrackedBranches {
+		if name == yas.cfg.TrunkBranch {
+			continue
+		}
+
+		matches, err := yas.matchesListFilters(BranchMetadata{Name: name}, opts, refs)
+		if err != nil {
+			return err
+		}
+
+		if matches {
+			untracked = append(untracked, name)
+		}
+	}
+
+	if len(untracked) == 0 {
+		return nil
+	}
+
+	slices.Sort(untracked)
+
+	fmt.Println("\nUntracked branches:")
+	for _, name := range untracked {
+		fmt.Printf("  %s\n", name)
+	}
+
+	return nil
+}
+
+// branchDetails returns the details shown for each tracked branch in verbose
+// mode: the changes since its branch point, its activity and its linked
+// worktree, if any.
+func (yas *YAS) branchDetails(refs map[string]gitexec.BranchRef, cache *diffStatCache) (map[string]string, error) {
+	worktrees, err := yas.BranchWorktrees()
+	if err != nil {
+		return nil, err
+	}
+
+	details := map[string]string{}
+
+	for _, branch := range yas.TrackedBranches() {
+		ref, exists := refs[branch.Name]
+		if !exists {
+			// The branch was deleted outside of yas
+			continue
+		}
+
+		base := branch.BranchPoint
+		if base == "" {
+			base = refs[branch.Parent].Hash
+		}
+
+		stat, err := cache.get(base, ref.Hash, yas.git.DiffStat)
+		if err != nil {
+			return nil, err
+		}
+
+		detail := fmt.Sprintf("(+%d -%d, %d files) %s", stat.Insertions, stat.Deletions, stat.Files, branchActivity(branch, ref.CommitTime))
+
+		if worktree, ok := worktrees[branch.Name]; ok && !worktree.Main {
+			detail += fmt.Sprintf(" [worktree: %s]", worktree.Path)
+		}
+
+		details[branch.Name] = detail
+	}
+
+	return details, nil
+}
+
+// Branches with no activity for these durations are shown in yellow and red
+// respectively in verbose mode.
+const (
+	inactiveAge = 7 * 24 * time.Hour
+	staleAge    = 30 * 24 * time.Hour
+)
+
+// branchActivity returns a description of when the branch was created and
+// last had activity, i.e. its last commit and last PR update. It is colored
+// by how long ago the last activity was.
+func branchActivity(branch BranchMetadata, lastCommit time.Time) string {
+	parts := []string{}
+	if !branch.Created.IsZero() {
+		parts = append(parts, "created "+ago(branch.Created))
+	}
+
+	parts = append(parts, "last commit "+ago(lastCommit))
+
+	lastActivity := lastCommit
+	if prUpdated := branch.GitHubPullRequest.UpdatedAt; !prUpdated.IsZero() {
+		parts = append(parts, "PR updated "+ago(prUpdated))
+
+		if prUpdated.After(lastActivity) {
+			lastActivity = prUpdated
+		}
+	}
+
+	activity := strings.Join(parts, ", ")
+
+	if !termutil.ColorEnabled(os.Stdout) {
+		return activity
+	}
+
+	switch inactive := time.Since(lastActivity); {
+	case inactive > staleAge:
+		return "\033[31m" + activity + "\033[0m"
+	case inactive > inactiveAge:
+		return "\033[33m" + activity + "\033[0m"
+	default:
+		return activity
+	}
+}
+
+// ago formats the time since t in the largest whole unit, e.g. "3d ago".
+func ago(t time.Time) string {
+	d := time.Since(t)
+
+	switch {
+	case d >= 24*time.Hour:
+		return fmt.Sprintf("%dd ago", int(d.Hours()/24))
+	case d >= time.Hour:
+		return fmt.Sprintf("%dh ago", int(d.Hours()))
+	case d >= time.Minute:
+		return fmt.Sprintf("%dm ago", int(d.Minutes()))
+	default:
+		return "just now"
+	}
+}
+
+// visibleBranches returns the tracked branches that match the list filters,
+// along with their ancestors so they can be shown in the tree.
+func (yas *YAS) visibleBranches(opts ListOptions, refs map[string]gitexec.BranchRef) (map[string]bool, error) {
+	visible := map[string]bool{}
+
+	for _, branch := range yas.TrackedBranches() {
+		matches, err := yas.matchesListFilters(branch, opts, refs)
+		if err != nil {
+			return nil, err
+		}
+
+		if !matches {
+			continue
+		}
+
+		for _, name := range yas.stack(branch.Name) {
+			visible[name] = true
+			if name == branch.Name {
+				break
+			}
+		}
+	}
+
+	return visible, nil
+}
+
+func (yas *YAS) matchesListFilters(branch BranchMetadata, opts ListOptions, refs map[string]gitexec.BranchRef) (bool, error) {
+	if opts.Mine {
+		prefix, err := yas.branchPrefix()
+		if err != nil {
+			return false, err
+		}
+
+		if !strings.HasPrefix(branch.Name, prefix) {
+			return false, nil
+		}
+	}
+
+	if opts.Author != "" && branch.GitHubPullRequest.Author.Login != opts.Author {
+		return false, nil
+	}
+
+	if opts.Stack != "" && !slices.Contains(yas.stack(opts.Stack), branch.Name) {
+		return false, nil
+	}
+
+	if opts.OlderThan > 0 {
+		ref, exists := refs[branch.Name]
+		if !exists || time.Since(ref.CommitTime) < opts.OlderThan {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// branchPrefix returns the configured branch prefix, or a prefix derived from
+// the git user email (e.g. "dan/" for dan@example.com).
+func (yas *YAS) branchPrefix() (string, error) {
+	if yas.cfg.BranchPrefix != "" {
+		return yas.cfg.BranchPrefix, nil
+	}
+
+	email, err := yas.git.ConfigValue("user.email")
+	if err != nil {
+		return "", err
+	}
+
+	user, _, _ := strings.Cut(email, "@")
+	if user == "" {
+		return "", fmt.Errorf("unable to determine branch prefix (hint: set branchPrefix in config)")
+	}
+
+	return user + "/", nil
+}
@@ -0,0 +1,121 @@
+package yas
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"syscall"
+	"time"
+
+	"github.com/dansimau/yas/pkg/log"
+)
+
+const lockFile = "yas.lock"
+
+// lockMaxAge is the age after which a lock is considered stale even if its
+// PID is running, since the PID may have been reused.
+const lockMaxAge = 24 * time.Hour
+
+// ErrLocked is returned when the repository is locked by another yas
+// operation.
+var ErrLocked = errors.New("another yas operation is running")
+
+// Lock is an advisory lock that prevents concurrent yas operations from
+// modifying the same repository.
+type Lock struct {
+	filePath string
+}
+
+type lockInfo struct {
+	PID       int       `json:"pid"`
+	Operation string    `json:"operation"`
+	Acquired  time.Time `json:"acquired"`
+}
+
+// stale returns true if the process holding the lock is no longer running or
+// the lock is too old.
+func (l lockInfo) stale() bool {
+	if time.Since(l.Acquired) > lockMaxAge {
+		return true
+	}
+
+	return errors.Is(syscall.Kill(l.PID, 0), syscall.ESRCH)
+}
+
+// LockRepository acquires the lock for the repository. Stale locks left by
+// processes that are no longer running are removed.
+func LockRepository(repoDirectory, operation string) (*Lock, error) {
+	l := &Lock{filePath: gitFile(repoDirectory, lockFile)}
+
+	b, err := json.Marshal(lockInfo{
+		PID:       os.Getpid(),
+		Operation: operation,
+		Acquired:  time.Now(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for attempt := 0; attempt < 2; attempt++ {
+		f, err := os.OpenFile(l.filePath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+		if err == nil {
+			defer f.Close()
+
+			if _, err := f.Write(b); err != nil {
+				os.Remove(l.filePath)
+				return nil, err
+			}
+
+			return l, nil
+		}
+
+		if !errors.Is(err, os.ErrExist) {
+			return nil, err
+		}
+
+		holder, err := l.holder()
+		if err != nil {
+			return nil, err
+		}
+
+		if !holder.stale() {
+			return nil, fmt.Errorf("%w (%s, pid %d, started %s) (hint: if not, remove %s)", ErrLocked, holder.Operation, holder.PID, holder.Acquired.Format(time.DateTime), l.filePath)
+		}
+
+		log.Info("Removing stale lock held by pid", holder.PID)
+
+		if err := os.Remove(l.filePath); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+	}
+
+	return nil, ErrLocked
+}
+
+// holder returns details of the process that holds the lock. An unreadable
+// lock file is treated as stale.
+func (l *Lock) holder() (lockInfo, error) {
+	b, err := os.ReadFile(l.filePath)
+	if errors.Is(err, os.ErrNotExist) {
+		// Released in the meantime
+		return lockInfo{}, nil
+	}
+
+	if err != nil {
+		return lockInfo{}, err
+	}
+
+	info := lockInfo{}
+	if err := json.Unmarshal(b, &info); err != nil {
+		log.Debug("Ignoring invalid lock file", l.filePath+":", err)
+		return lockInfo{}, nil
+	}
+
+	return info, nil
+}
+
+// Release releases the lock.
+func (l *Lock) Release() error {
+	return os.Remove(l.filePath)
+}
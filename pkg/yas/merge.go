@@ -0,0 +1,550 @@
+package yas
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/dansimau/yas/pkg/cliutil"
+	"github.com/dansimau/yas/pkg/log"
+)
+
+type MergeOptions struct {
+	// Strategy is how the branch is merged: squash, merge or rebase. If
+	// empty, the configured merge strategy is used.
+	Strategy string
+
+	// Local performs the merge locally and pushes trunk, rather than
+	// merging the PR on GitHub.
+	Local bool
+
+	// Wait waits for the PR's checks to pass before merging. The repository
+	// is locked only once the checks have passed.
+	Wait bool
+
+	// Timeout is the maximum time to wait for checks to pass.
+	Timeout time.Duration
+
+	// PollInterval is the time between checking the status of checks.
+	PollInterval time.Duration
+
+	// Admin merges the PR with `gh pr merge --admin`, bypassing branch
+	// protection rules such as required checks and reviews.
+	Admin bool
+}
+
+// Merge merges the current branch into trunk using the merge strategy, then
+// reparents any children of the branch onto trunk and deletes the merged
+// branch.
+func (yas *YAS) Merge(opts MergeOptions) error {
+	strategy, err := yas.mergeStrategy(opts.Strategy)
+	if err != nil {
+		return err
+	}
+
+	branchName, err := yas.git.GetCurrentBranchName()
+	if err != nil {
+		return err
+	}
+
+	if branchName == yas.cfg.TrunkBranch {
+		return errors.New("cannot merge trunk branch")
+	}
+
+	if err := yas.checkNotWIP(branchName, "merged", ""); err != nil {
+		return err
+	}
+
+	metadata := yas.data.Branches.Get(branchName)
+	if metadata.Parent != yas.cfg.TrunkBranch {
+		return fmt.Errorf("branch '%s' must be on top of %s to merge (parent is '%s')", branchName, yas.cfg.TrunkBranch, metadata.Parent)
+	}
+
+	messages, err := yas.git.CommitMessages(yas.cfg.TrunkBranch, branchName)
+	if err != nil {
+		return err
+	}
+
+	if len(messages) == 0 {
+		return fmt.Errorf("branch '%s' has no commits to merge", branchName)
+	}
+
+	if err := yas.runPreHook("preMerge", yas.cfg.Hooks.PreMerge, branchName); err != nil {
+		return err
+	}
+
+	message, err := yas.mergeMessage(branchName, strategy, messages)
+	if err != nil {
+		return err
+	}
+
+	if opts.Wait {
+		if err := yas.waitForChecks(branchName, opts.Timeout, opts.PollInterval); err != nil {
+			return err
+		}
+
+		lock, err := LockRepository(yas.cfg.RepoDirectory, "merge")
+		if err != nil {
+			return err
+		}
+		defer lock.Release()
+
+		// Other yas operations may have changed the state while waiting
+		if yas.data, err = loadData(gitFile(yas.cfg.RepoDirectory, yasStateFile)); err != nil {
+			return err
+		}
+	}
+
+	// The rebase strategy rewrites the branch's commits, so children are
+	// rebased from the tip of the branch before it was merged
+	mergedTip, err := yas.git.GetHash(branchName)
+	if err != nil {
+		return err
+	}
+
+	worktreePath, err := yas.leaveLinkedWorktree(branchName)
+	if err != nil {
+		return err
+	}
+
+	if opts.Local {
+		err = yas.mergeLocal(branchName, strategy, message)
+	} else {
+		if opts.Admin {
+			fmt.Printf("⚠️  Admin merge of '%s': bypassing branch protection rules\n", branchName)
+			log.Warn(fmt.Sprintf("Admin merge of '%s', bypassing branch protection rules", branchName))
+		}
+
+		err = yas.mergePullRequest(branchName, strategy, message, opts.Admin)
+	}
+
+	if err != nil {
+		if worktreePath != "" {
+			if err := yas.git.At(worktreePath).Checkout(branchName); err != nil {
+				log.Warn("failed to check out", branchName, "in", worktreePath+":", err)
+			}
+		}
+
+		return err
+	}
+
+	fmt.Printf("Merged '%s' into %s\n", branchName, yas.cfg.TrunkBranch)
+
+	if err := yas.cleanupMergedBranch(branchName, mergedTip, worktreePath); err != nil {
+		return err
+	}
+
+	yas.runPostHook("postMerge", yas.cfg.Hooks.PostMerge, branchName)
+	yas.emit(Event{Type: EventPRMerged, Branch: branchName})
+
+	return nil
+}
+
+// MergeStack merges each branch in the stack containing the specified branch
+// (default: the current branch), or the stack with the specified name, into
+// trunk in turn, starting from the bottom of the stack.
+func (yas *YAS) MergeStack(branchName string, opts MergeOptions) error {
+	branchName = yas.resolveStack(branchName)
+
+	if branchName == "" {
+		currentBranch, err := yas.git.GetCurrentBranchName()
+		if err != nil {
+			return err
+		}
+
+		branchName = currentBranch
+	}
+
+	if !yas.isTracked(branchName) {
+		return fmt.Errorf("branch '%s' is not tracked (hint: run `yas add`)", branchName)
+	}
+
+	if err := yas.checkNotWIP(branchName, "merged", ""); err != nil {
+		return err
+	}
+
+	for _, name := range yas.stack(branchName) {
+		if err := yas.git.Checkout(name); err != nil {
+			return err
+		}
+
+		if err := yas.Merge(opts); err != nil {
+			return fmt.Errorf("failed to merge '%s': %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+// mergeStrategy returns the merge strategy to use: the specified strategy, or
+// the configured one, or squash.
+func (yas *YAS) mergeStrategy(strategy string) (string, error) {
+	if strategy == "" {
+		strategy = yas.cfg.MergeStrategy
+	}
+
+	switch strategy {
+	case "":
+		return MergeStrategySquash, nil
+	case MergeStrategySquash, MergeStrategyMerge, MergeStrategyRebase:
+		return strategy, nil
+	}
+
+	return "", fmt.Errorf("invalid merge strategy '%s' (must be one of: squash, merge, rebase)", strategy)
+}
+
+// mergeMessage returns the message of the commit that the merge creates on
+// trunk, after the user has edited it. The rebase strategy keeps the
+// branch's commits as they are, so it has no message.
+func (yas *YAS) mergeMessage(branchName, strategy string, messages []string) (string, error) {
+	var message string
+	var err error
+
+	switch strategy {
+	case MergeStrategyRebase:
+		return "", nil
+	case MergeStrategyMerge:
+		message = fmt.Sprintf("Merge branch '%s'", branchName)
+		if yas.cfg.MergeSubject != "" {
+			subject, _, _ := strings.Cut(messages[0], "\n")
+			message, err = yas.applyMergeSubject(branchName, subject)
+		}
+	default:
+		message, err = yas.applyMergeSubject(branchName, squashMessage(messages))
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	message, err = cliutil.EditText(yas.git.Editor(), message)
+	if err != nil {
+		return "", err
+	}
+
+	if message == "" {
+		return "", errors.New("aborting merge due to empty commit message")
+	}
+
+	return message, nil
+}
+
+func (yas *YAS) mergeLocal(branchName, strategy, message string) error {
+	if strategy == MergeStrategyRebase {
+		if err := yas.git.Rebase(yas.cfg.TrunkBranch, branchName); err != nil {
+			return fmt.Errorf("failed to rebase '%s' onto %s: %w", branchName, yas.cfg.TrunkBranch, err)
+		}
+	}
+
+	if err := yas.git.Checkout(yas.cfg.TrunkBranch); err != nil {
+		return err
+	}
+
+	if strategy == MergeStrategyRebase {
+		if err := yas.git.MergeFastForward(branchName); err != nil {
+			return err
+		}
+	} else if err := yas.commitMerge(branchName, strategy, message); err != nil {
+		return err
+	}
+
+	remotes, err := yas.git.Remotes()
+	if err != nil {
+		return err
+	}
+
+	// Nothing to push to
+	if len(remotes) == 0 {
+		return nil
+	}
+
+	if err := yas.git.Push(); err != nil {
+		return fmt.Errorf("failed to push %s: %w", yas.cfg.TrunkBranch, err)
+	}
+
+	return nil
+}
+
+// commitMerge merges the branch into the current branch with the message,
+// either as a squashed commit or a merge commit.
+func (yas *YAS) commitMerge(branchName, strategy, message string) error {
+	f, err := os.CreateTemp("", "yas-merge-*.txt")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	if _, err := f.WriteString(message); err != nil {
+		f.Close()
+		return err
+	}
+	f.Close()
+
+	if strategy == MergeStrategyMerge {
+		return yas.git.MergeNoFastForward(branchName, f.Name())
+	}
+
+	if err := yas.git.MergeSquash(branchName); err != nil {
+		return err
+	}
+
+	return yas.git.CommitWithMessageFile(f.Name())
+}
+
+func (yas *YAS) mergePullRequest(branchName, strategy, message string, admin bool) error {
+	pr := yas.data.Branches.Get(branchName).GitHubPullRequest
+	args := []string{"pr", "merge", pr.ref(branchName), "--" + strategy}
+
+	if admin {
+		args = append(args, "--admin")
+	}
+
+	if message != "" {
+		subject, body, _ := strings.Cut(message, "\n")
+		args = append(args, "--subject", subject, "--body", strings.TrimSpace(body))
+	}
+
+	if err := yas.ghRun(args...); err != nil {
+		return err
+	}
+
+	if err := yas.git.Checkout(yas.cfg.TrunkBranch); err != nil {
+		return err
+	}
+
+	return yas.git.Pull()
+}
+
+// leaveLinkedWorktree makes yas operate on the main worktree if the branch is
+// checked out in a linked worktree, so that trunk can be checked out there.
+// HEAD is detached in the linked worktree, so that the branch can be merged
+// and deleted. It returns the path of the linked worktree, or empty if the
+// branch isn't checked out in one.
+func (yas *YAS) leaveLinkedWorktree(branchName string) (string, error) {
+	worktrees, err := yas.git.Worktrees()
+	if err != nil {
+		return "", err
+	}
+
+	mainPath, linkedPath := "", ""
+	for _, worktree := range worktrees {
+		switch {
+		case worktree.Main:
+			mainPath = worktree.Path
+		case worktree.Branch == branchName:
+			linkedPath = worktree.Path
+		}
+	}
+
+	if mainPath == "" || linkedPath == "" {
+		return "", nil
+	}
+
+	if err := yas.git.At(linkedPath).DetachHead(); err != nil {
+		return "", err
+	}
+
+	yas.cfg.RepoDirectory = mainPath
+	yas.git = yas.git.At(mainPath)
+
+	return linkedPath, nil
+}
+
+// cleanupMergedBranch rebases the children of the merged branch onto trunk,
+// reparents them and deletes the merged branch, along with the linked
+// worktree it was checked out in, if any. mergedTip is the tip of the branch
+// before it was merged, i.e. the commit the children are based on.
+func (yas *YAS) cleanupMergedBranch(branchName, mergedTip, worktreePath string) error {
+	trunkTip, err := yas.git.GetHash(yas.cfg.TrunkBranch)
+	if err != nil {
+		return err
+	}
+
+	for _, child := range yas.data.Branches.ToSlice().NotDeleted().WithParent(branchName).SortedByName() {
+		childTip, err := yas.git.GetHash(child.Name)
+		if err != nil {
+			return err
+		}
+
+		if err := yas.git.RebaseOnto(yas.cfg.TrunkBranch, mergedTip, child.Name); err != nil {
+			return fmt.Errorf("failed to rebase '%s' onto %s: %w", child.Name, yas.cfg.TrunkBranch, err)
+		}
+
+		child.Parent = yas.cfg.TrunkBranch
+		child.BranchPoint = trunkTip
+
+		// Save the new parent straight away, so the metadata matches the
+		// rebased branch even if retargeting the PR fails
+		yas.data.Branches.Set(child.Name, child)
+		if err := yas.data.Save(); err != nil {
+			return err
+		}
+
+		// Retarget the PR before the merged branch is deleted, so GitHub
+		// doesn't close it
+		if err := yas.retargetPullRequest(&child); err != nil {
+			return err
+		}
+
+		yas.data.Branches.Set(child.Name, child)
+
+		fmt.Printf("Set '%s' as parent of '%s'\n", yas.cfg.TrunkBranch, child.Name)
+
+		if err := yas.restackChildren(child.Name, childTip); err != nil {
+			return err
+		}
+	}
+
+	if err := yas.data.Save(); err != nil {
+		return err
+	}
+
+	if err := yas.git.Checkout(yas.cfg.TrunkBranch); err != nil {
+		return err
+	}
+
+	if err := yas.deleteBranch(branchName, worktreePath); err != nil {
+		return err
+	}
+
+	if worktreePath != "" {
+		fmt.Printf("Removed worktree %s\n", worktreePath)
+	}
+
+	return nil
+}
+
+// ReparentChildren sets the parent of each child of the merged branch to the
+// merged branch's parent and retargets their PRs, so that the merged branch
+// can be deleted. The children are not rebased.
+func (yas *YAS) ReparentChildren(branchName string) error {
+	parent := yas.data.Branches.Get(branchName).Parent
+	if parent == "" {
+		parent = yas.cfg.TrunkBranch
+	}
+
+	for _, child := range yas.data.Branches.ToSlice().NotDeleted().WithParent(branchName).SortedByName() {
+		child.Parent = parent
+
+		yas.data.Branches.Set(child.Name, child)
+		if err := yas.data.Save(); err != nil {
+			return err
+		}
+
+		if err := yas.retargetPullRequest(&child); err != nil {
+			return err
+		}
+
+		yas.data.Branches.Set(child.Name, child)
+
+		fmt.Printf("Set '%s' as parent of '%s'\n", parent, child.Name)
+	}
+
+	return yas.data.Save()
+}
+
+// retargetPullRequest changes the base of the branch's open PR to its parent,
+// unless the PR base is overridden.
+func (yas *YAS) retargetPullRequest(metadata *BranchMetadata) error {
+	if metadata.GitHubPullRequest.State != "OPEN" || metadata.PRBase != "" {
+		return nil
+	}
+
+	base := yas.pullRequestBase(*metadata)
+	if metadata.GitHubPullRequest.BaseRefName == base {
+		return nil
+	}
+
+	if err := yas.ghRun("pr", "edit", metadata.GitHubPullRequest.ref(metadata.Name), "--base", base); err != nil {
+		return fmt.Errorf("failed to retarget PR of '%s' onto %s: %w", metadata.Name, base, err)
+	}
+
+	metadata.GitHubPullRequest.BaseRefName = base
+
+	fmt.Printf("Retargeted PR of '%s' onto %s\n", metadata.Name, base)
+
+	return nil
+}
+
+// mergeSubjectData is the data available to the merge subject template.
+type mergeSubjectData struct {
+	// Title is the default subject, i.e. the subject of the first commit on
+	// the branch.
+	Title string
+
+	Branch string
+
+	// Number is the number of the branch's PR, or empty if it has no PR.
+	Number string
+
+	// Ticket is the ticket ID in the branch name, e.g. PROJ-123, or empty if
+	// there isn't one.
+	Ticket string
+
+	// StackSize is the number of branches in the stack, including the branch
+	// being merged.
+	StackSize int
+}
+
+// applyMergeSubject replaces the subject of the message with the configured
+// merge subject template, if any.
+func (yas *YAS) applyMergeSubject(branchName, message string) (string, error) {
+	if yas.cfg.MergeSubject == "" {
+		return message, nil
+	}
+
+	tmpl, err := template.New("mergeSubject").Parse(yas.cfg.MergeSubject)
+	if err != nil {
+		return "", fmt.Errorf("invalid mergeSubject template: %w", err)
+	}
+
+	subject, body, _ := strings.Cut(message, "\n")
+
+	ticket, err := yas.ticket(branchName)
+	if err != nil {
+		return "", err
+	}
+
+	data := mergeSubjectData{
+		Title:     subject,
+		Branch:    branchName,
+		Ticket:    ticket,
+		StackSize: len(yas.stack(branchName)),
+	}
+
+	if number := yas.data.Branches.Get(branchName).GitHubPullRequest.Number; number != 0 {
+		data.Number = strconv.Itoa(number)
+	}
+
+	buf := &strings.Builder{}
+	if err := tmpl.Execute(buf, data); err != nil {
+		return "", fmt.Errorf("invalid mergeSubject template: %w", err)
+	}
+
+	if body == "" {
+		return buf.String(), nil
+	}
+
+	return buf.String() + "\n" + body, nil
+}
+
+// squashMessage generates a default commit message for squashing the
+// specified commit messages into a single commit.
+func squashMessage(messages []string) string {
+	if len(messages) == 1 {
+		return messages[0]
+	}
+
+	subject, _, _ := strings.Cut(messages[0], "\n")
+
+	lines := []string{subject, ""}
+	for _, message := range messages {
+		lines = append(lines, "* "+strings.ReplaceAll(message, "\n", "\n  "))
+	}
+
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,80 @@
+package yas
+
+import (
+	"fmt"
+	"slices"
+)
+
+// isMerged returns true if the branch's PR was merged, according to the
+// cached PR state, although the branch hasn't been deleted yet.
+func isMerged(metadata BranchMetadata) bool {
+	return metadata.GitHubPullRequest.State == "MERGED"
+}
+
+// unmergedAncestor returns the closest ancestor of the branch whose PR hasn't
+// been merged, or trunk.
+func (yas *YAS) unmergedAncestor(branchName string) string {
+	seen := map[string]bool{}
+
+	parent := yas.data.Branches.Get(branchName).Parent
+	for parent != "" && parent != yas.cfg.TrunkBranch && !seen[parent] {
+		metadata := yas.data.Branches.Get(parent)
+		if !isMerged(metadata) {
+			return parent
+		}
+
+		seen[parent] = true
+		parent = metadata.Parent
+	}
+
+	if parent == "" {
+		return yas.cfg.TrunkBranch
+	}
+
+	return parent
+}
+
+// reparentOffMerged moves the branches in the queue whose parent's PR was
+// merged onto their closest unmerged ancestor (usually trunk), so that they
+// are rebased onto it instead of onto the merged branch. It returns the queue
+// without the merged branches, which are left as they are until they are
+// deleted.
+func (yas *YAS) reparentOffMerged(queue []string) ([]string, error) {
+	changed := false
+
+	for _, branchName := range queue {
+		metadata := yas.data.Branches.Get(branchName)
+		if isMerged(metadata) || metadata.Parent == "" || !isMerged(yas.data.Branches.Get(metadata.Parent)) {
+			continue
+		}
+
+		mergedParent := metadata.Parent
+		metadata.Parent = yas.unmergedAncestor(branchName)
+
+		// Save the new parent before retargeting the PR, so that it isn't
+		// lost if retargeting fails
+		yas.data.Branches.Set(branchName, metadata)
+		if err := yas.data.Save(); err != nil {
+			return nil, err
+		}
+
+		if err := yas.retargetPullRequest(&metadata); err != nil {
+			return nil, err
+		}
+
+		yas.data.Branches.Set(branchName, metadata)
+		changed = true
+
+		fmt.Printf("Set '%s' as parent of '%s' (%s was merged)\n", metadata.Parent, branchName, mergedParent)
+	}
+
+	if changed {
+		if err := yas.data.Save(); err != nil {
+			return nil, err
+		}
+	}
+
+	return slices.DeleteFunc(slices.Clone(queue), func(name string) bool {
+		return isMerged(yas.data.Branches.Get(name))
+	}), nil
+}
@@ -0,0 +1,316 @@
+package yas
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+)
+
+type MoveOptions struct {
+	// Branch is the branch to move (default: current branch).
+	Branch string
+
+	// Onto is the new parent of the branch.
+	Onto string
+
+	// After splices the branch in between this branch and its children, who
+	// become children of the moved branch.
+	After string
+
+	// Before splices the branch in between this branch and its parent, i.e.
+	// this branch becomes a child of the moved branch.
+	Before string
+
+	// WithoutDescendants moves only the branch itself. Its children are
+	// reattached to its old parent.
+	WithoutDescendants bool
+
+	// Autostash stashes local modifications before moving and restores
+	// them afterwards.
+	Autostash bool
+
+	// ForceDirty moves the branch even if there are uncommitted changes that
+	// the rebases may fail on or disrupt.
+	ForceDirty bool
+}
+
+// Move rebases a branch onto a new parent and records the new parent. By
+// default, the descendants of the branch are moved with it. With After or
+// Before, the branch is moved on its own and spliced into another position in
+// the stack.
+func (yas *YAS) Move(opts MoveOptions) (err error) {
+	currentBranchName, err := yas.git.GetCurrentBranchName()
+	if err != nil {
+		return err
+	}
+
+	branchName := opts.Branch
+	if branchName == "" {
+		branchName = currentBranchName
+	}
+
+	if !yas.data.Branches.Exists(branchName) {
+		return fmt.Errorf("branch '%s' is not tracked (hint: run `yas add`)", branchName)
+	}
+
+	affected := yas.moveAffected(branchName, opts)
+	autostash := opts.Autostash || yas.cfg.Autostash
+
+	if !opts.ForceDirty {
+		if err := yas.checkClean(affected, !autostash); err != nil {
+			return err
+		}
+	}
+
+	if autostash {
+		var stashed bool
+		if stashed, err = yas.stash(); err != nil {
+			return err
+		}
+
+		if stashed {
+			defer yas.unstash(&err)
+		}
+	}
+
+	yas.progress = newProgress(len(affected), 0)
+	defer func() { yas.progress = nil }()
+
+	if opts.Onto == "" {
+		if err := yas.splice(branchName, opts.After, opts.Before); err != nil {
+			return err
+		}
+
+		// Rebasing checks out each branch, so switch back to where we started
+		if err := yas.git.Checkout(currentBranchName); err != nil {
+			return err
+		}
+
+		yas.progress.summary()
+
+		if opts.After != "" {
+			fmt.Printf("Moved '%s' after '%s'\n", branchName, opts.After)
+		} else {
+			fmt.Printf("Moved '%s' before '%s'\n", branchName, opts.Before)
+		}
+
+		return nil
+	}
+
+	if opts.Onto != yas.cfg.TrunkBranch && !yas.data.Branches.Exists(opts.Onto) {
+		return fmt.Errorf("branch '%s' is not tracked", opts.Onto)
+	}
+
+	if opts.Onto == branchName || slices.Contains(yas.descendants(branchName), opts.Onto) {
+		return fmt.Errorf("cannot move '%s' onto itself or one of its descendants", branchName)
+	}
+
+	metadata := yas.data.Branches.Get(branchName)
+	oldParent := metadata.Parent
+
+	upstream, err := yas.restackUpstream(metadata, "", nil)
+	if err != nil {
+		return err
+	}
+
+	oldTip, err := yas.git.GetHash(branchName)
+	if err != nil {
+		return err
+	}
+
+	newParentTip, err := yas.git.GetHash(opts.Onto)
+	if err != nil {
+		return err
+	}
+
+	if err := yas.rebaseOnto(opts.Onto, upstream, branchName); err != nil {
+		return fmt.Errorf("failed to rebase '%s' onto '%s': %w", branchName, opts.Onto, err)
+	}
+
+	metadata.Parent = opts.Onto
+	metadata.BranchPoint = newParentTip
+	yas.data.Branches.Set(branchName, metadata)
+
+	if err := yas.data.Save(); err != nil {
+		return err
+	}
+
+	if opts.WithoutDescendants {
+		err = yas.reattachChildren(branchName, oldTip, oldParent)
+	} else {
+		err = yas.restackChildren(branchName, oldTip)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	// Rebasing checks out each branch, so switch back to where we started
+	if err := yas.git.Checkout(currentBranchName); err != nil {
+		return err
+	}
+
+	yas.progress.summary()
+
+	fmt.Printf("Moved '%s' onto '%s'\n", branchName, opts.Onto)
+
+	return nil
+}
+
+// moveAffected returns the branches that may be rebased by the move.
+func (yas *YAS) moveAffected(branchName string, opts MoveOptions) []string {
+	candidates := append([]string{branchName}, yas.descendants(branchName)...)
+
+	switch {
+	case opts.After != "":
+		candidates = append(candidates, yas.descendants(opts.After)...)
+	case opts.Before != "":
+		candidates = append(candidates, opts.Before)
+		candidates = append(candidates, yas.descendants(opts.Before)...)
+	}
+
+	affected := []string{}
+	for _, name := range candidates {
+		if !slices.Contains(affected, name) {
+			affected = append(affected, name)
+		}
+	}
+
+	return affected
+}
+
+// reattachChildren rebases the children of branchName, which were based on
+// oldTip, onto newParent and records newParent as their parent.
+func (yas *YAS) reattachChildren(branchName, oldTip, newParent string) error {
+	newParentTip, err := yas.git.GetHash(newParent)
+	if err != nil {
+		return err
+	}
+
+	for _, child := range yas.data.Branches.ToSlice().NotDeleted().WithParent(branchName).SortedByName() {
+		childTip, err := yas.git.GetHash(child.Name)
+		if err != nil {
+			return err
+		}
+
+		if err := yas.rebaseOnto(newParent, oldTip, child.Name); err != nil {
+			return fmt.Errorf("failed to rebase '%s' onto '%s': %w", child.Name, newParent, err)
+		}
+
+		child.Parent = newParent
+		child.BranchPoint = newParentTip
+		yas.data.Branches.Set(child.Name, child)
+
+		if err := yas.restackChildren(child.Name, childTip); err != nil {
+			return err
+		}
+	}
+
+	return yas.data.Save()
+}
+
+// splice moves branchName on its own to sit after or before the target
+// branch. Its children are first reattached to its old parent. Moving after
+// the target makes the target's children children of branchName; moving
+// before the target puts branchName between the target and its parent. Only
+// the branches whose parents change, and their descendants, are rebased.
+func (yas *YAS) splice(branchName, after, before string) error {
+	target := after
+	if target == "" {
+		target = before
+	}
+
+	if target == "" {
+		return errors.New("no branch to move onto, after or before")
+	}
+
+	if target == branchName {
+		return fmt.Errorf("cannot move '%s' before or after itself", branchName)
+	}
+
+	if target != yas.cfg.TrunkBranch && !yas.data.Branches.Exists(target) {
+		return fmt.Errorf("branch '%s' is not tracked", target)
+	}
+
+	if before == yas.cfg.TrunkBranch {
+		return errors.New("cannot move a branch before the trunk branch")
+	}
+
+	metadata := yas.data.Branches.Get(branchName)
+
+	upstream, err := yas.restackUpstream(metadata, "", nil)
+	if err != nil {
+		return err
+	}
+
+	oldTip, err := yas.git.GetHash(branchName)
+	if err != nil {
+		return err
+	}
+
+	// Detach the branch from its children first, so that the target can be
+	// one of them
+	if err := yas.reattachChildren(branchName, oldTip, metadata.Parent); err != nil {
+		return err
+	}
+
+	parent := target
+	children := []string{}
+
+	if before != "" {
+		parent = yas.data.Branches.Get(target).Parent
+		children = append(children, target)
+	} else {
+		for _, child := range yas.data.Branches.ToSlice().NotDeleted().WithParent(target).SortedByName() {
+			if child.Name != branchName {
+				children = append(children, child.Name)
+			}
+		}
+	}
+
+	parentTip, err := yas.git.GetHash(parent)
+	if err != nil {
+		return err
+	}
+
+	if err := yas.rebaseOnto(parent, upstream, branchName); err != nil {
+		return fmt.Errorf("failed to rebase '%s' onto '%s': %w", branchName, parent, err)
+	}
+
+	metadata.Parent = parent
+	metadata.BranchPoint = parentTip
+	yas.data.Branches.Set(branchName, metadata)
+
+	newTip, err := yas.git.GetHash(branchName)
+	if err != nil {
+		return err
+	}
+
+	for _, childName := range children {
+		child := yas.data.Branches.Get(childName)
+
+		childUpstream, err := yas.restackUpstream(child, parentTip, nil)
+		if err != nil {
+			return err
+		}
+
+		childTip, err := yas.git.GetHash(childName)
+		if err != nil {
+			return err
+		}
+
+		if err := yas.rebaseOnto(branchName, childUpstream, childName); err != nil {
+			return fmt.Errorf("failed to rebase '%s' onto '%s': %w", childName, branchName, err)
+		}
+
+		child.Parent = branchName
+		child.BranchPoint = newTip
+		yas.data.Branches.Set(childName, child)
+
+		if err := yas.restackChildren(childName, childTip); err != nil {
+			return err
+		}
+	}
+
+	return yas.data.Save()
+}
@@ -0,0 +1,100 @@
+package yas
+
+import (
+	"slices"
+
+	"github.com/dansimau/yas/pkg/gitexec"
+)
+
+// PathOwner is a tracked branch that modifies a path.
+type PathOwner struct {
+	Branch string
+
+	// Stat is the changes to the path on the branch since its branch point.
+	Stat gitexec.DiffStat
+}
+
+// Owns returns the tracked branches that modify the path (a file, or any file
+// in a directory), by diffing each branch against its branch point. If stack
+// is not empty, only the branches in the stack with that name, or containing
+// that branch, are included. Branches are returned in stack order, i.e.
+// parents before their children.
+func (yas *YAS) Owns(path, stack string) ([]PathOwner, error) {
+	branchNames, err := yas.trackedBranchesInStackOrder(stack)
+	if err != nil {
+		return nil, err
+	}
+
+	refs, err := yas.git.BranchRefs()
+	if err != nil {
+		return nil, err
+	}
+
+	// Ensure the diffs don't include commits from the parent, if the branch
+	// was rebased outside of yas
+	if _, err := yas.repairStaleBranchPointsOf(branchNames); err != nil {
+		return nil, err
+	}
+
+	owners := []PathOwner{}
+
+	for _, name := range branchNames {
+		if _, exists := refs[name]; !exists {
+			// The branch was deleted outside of yas
+			continue
+		}
+
+		metadata := yas.data.Branches.Get(name)
+
+		base := metadata.BranchPoint
+		if base == "" {
+			base = metadata.Parent
+		}
+
+		stat, err := yas.git.PathsDiffStat(base, name, path)
+		if err != nil {
+			return nil, err
+		}
+
+		if stat.Files > 0 {
+			owners = append(owners, PathOwner{Branch: name, Stat: stat})
+		}
+	}
+
+	return owners, nil
+}
+
+// trackedBranchesInStackOrder returns the tracked branches stack by stack,
+// with stacks sorted by the name of their root and parents before their
+// children. If stack is not empty, only the branches in the stack with that
+// name, or containing that branch, are returned.
+func (yas *YAS) trackedBranchesInStackOrder(stack string) ([]string, error) {
+	if stack != "" {
+		branchName, err := yas.trackedBranchOrCurrent(yas.resolveStack(stack))
+		if err != nil {
+			return nil, err
+		}
+
+		return yas.stack(yas.stackRoot(branchName)), nil
+	}
+
+	roots := []string{}
+	for _, branch := range yas.TrackedBranches().SortedByName() {
+		if root := yas.stackRoot(branch.Name); !slices.Contains(roots, root) {
+			roots = append(roots, root)
+		}
+	}
+
+	slices.Sort(roots)
+
+	branchNames := []string{}
+	for _, root := range roots {
+		for _, name := range yas.stack(root) {
+			if !slices.Contains(branchNames, name) {
+				branchNames = append(branchNames, name)
+			}
+		}
+	}
+
+	return branchNames, nil
+}
@@ -0,0 +1,161 @@
+package yas
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/dansimau/yas/pkg/gitexec"
+)
+
+// Flags of a branch in the porcelain list format.
+const (
+	porcelainFlagCurrent      = "current"
+	porcelainFlagDraft        = "draft"
+	porcelainFlagMerged       = "merged"
+	porcelainFlagClosed       = "closed"
+	porcelainFlagAutoMerge    = "auto-merge"
+	porcelainFlagExternal     = "external"
+	porcelainFlagNeedsRestack = "needs-restack"
+	porcelainFlagNeedsSubmit  = "needs-submit"
+	porcelainFlagUntracked    = "untracked"
+	porcelainFlagWIP          = "wip"
+)
+
+// listPorcelain prints the branches in the porcelain format of `yas list
+// --porcelain`, which is meant for scripts and is kept backwards compatible
+// across versions of yas. Each branch is printed on its own line, stack by
+// stack with parents before their children, as tab-separated fields:
+//
+//	name<TAB>parent<TAB>pr_url<TAB>flags
+//
+// Empty fields are printed as "-", e.g. the PR URL of a branch without a PR.
+// Flags is a comma-separated list of: current, draft, merged, closed,
+// auto-merge, external, wip, needs-restack, needs-submit and untracked (with
+// --all).
+// New flags may be added, so scripts should ignore flags they don't know. The
+// output is never colored.
+func (yas *YAS) listPorcelain(opts ListOptions, visible map[string]bool, refs map[string]gitexec.BranchRef, cache *diffStatCache) error {
+	currentBranch, err := yas.git.GetCurrentBranchName()
+	if err != nil {
+		return err
+	}
+
+	aheadBehind, err := yas.remoteAheadBehind(refs, cache)
+	if err != nil {
+		return err
+	}
+
+	branchNames, err := yas.trackedBranchesInStackOrder("")
+	if err != nil {
+		return err
+	}
+
+	for _, name := range branchNames {
+		if visible != nil && !visible[name] {
+			continue
+		}
+
+		metadata := yas.data.Branches.Get(name)
+
+		flags := []string{}
+		if name == currentBranch {
+			flags = append(flags, porcelainFlagCurrent)
+		}
+
+		switch pr := metadata.GitHubPullRequest; pr.State {
+		case "OPEN":
+			if pr.IsDraft {
+				flags = append(flags, porcelainFlagDraft)
+			}
+
+			if metadata.AutoMerge {
+				flags = append(flags, porcelainFlagAutoMerge)
+			}
+		case "MERGED":
+			flags = append(flags, porcelainFlagMerged)
+		case "CLOSED":
+			flags = append(flags, porcelainFlagClosed)
+		}
+
+		if metadata.External {
+			flags = append(flags, porcelainFlagExternal)
+		}
+
+		if metadata.WIP {
+			flags = append(flags, porcelainFlagWIP)
+		}
+
+		if _, exists := refs[name]; exists {
+			parentTip := refs[metadata.Parent].Hash
+			if parentTip == "" {
+				// Not a local branch, e.g. a remote parent
+				if parentTip, err = yas.git.GetHash(metadata.Parent); err != nil {
+					return err
+				}
+			}
+
+			needsRestack, err := yas.needsRestack(metadata, parentTip)
+			if err != nil {
+				return err
+			}
+
+			if needsRestack {
+				flags = append(flags, porcelainFlagNeedsRestack)
+			}
+		}
+
+		if needsSubmit(metadata, aheadBehind[name]) {
+			flags = append(flags, porcelainFlagNeedsSubmit)
+		}
+
+		printPorcelainLine(name, metadata.Parent, metadata.GitHubPullRequest.URL, flags)
+	}
+
+	if !opts.All {
+		return nil
+	}
+
+	untrackedBranches, err := yas.UntrackedBranches()
+	if err != nil {
+		return err
+	}
+
+	slices.Sort(untrackedBranches)
+
+	for _, name := range untrackedBranches {
+		if name == yas.cfg.TrunkBranch {
+			continue
+		}
+
+		matches, err := yas.matchesListFilters(BranchMetadata{Name: name}, opts, refs)
+		if err != nil {
+			return err
+		}
+
+		if !matches {
+			continue
+		}
+
+		flags := []string{porcelainFlagUntracked}
+		if name == currentBranch {
+			flags = append([]string{porcelainFlagCurrent}, flags...)
+		}
+
+		printPorcelainLine(name, "", "", flags)
+	}
+
+	return nil
+}
+
+// printPorcelainLine prints a branch in the porcelain list format.
+func printPorcelainLine(name, parent, prURL string, flags []string) {
+	fields := []string{name, parent, prURL, strings.Join(flags, ",")}
+	for i, field := range fields {
+		if field == "" {
+			fields[i] = "-"
+		}
+	}
+
+	fmt.Println(strings.Join(fields, "\t"))
+}
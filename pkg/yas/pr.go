@@ -0,0 +1,217 @@
+package yas
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/dansimau/yas/pkg/log"
+)
+
+// pullRequestDetailsFields are the gh JSON fields of pullRequestDetails.
+const pullRequestDetailsFields = pullRequestFields + ",headRefName"
+
+// pullRequestDetails is the subset of `gh pr view` output needed to track a
+// pull request locally.
+type pullRequestDetails struct {
+	PullRequestMetadata
+
+	HeadRefName string `json:"headRefName"`
+}
+
+func (yas *YAS) fetchPullRequest(ref string) (*pullRequestDetails, error) {
+	b, err := yas.gh("pr", "view", ref, "--json", pullRequestDetailsFields)
+	if err != nil {
+		return nil, err
+	}
+
+	pr := &pullRequestDetails{}
+	if err := json.Unmarshal(b, pr); err != nil {
+		return nil, err
+	}
+
+	return pr, nil
+}
+
+// CheckoutPullRequest checks out the pull request (specified by number, URL or
+// branch) as a local branch and tracks it. The parent is inferred from the
+// base of the PR: if the base is trunk or a tracked branch it is used as the
+// parent, otherwise trunk is used.
+func (yas *YAS) CheckoutPullRequest(ref string) error {
+	pr, err := yas.fetchPullRequest(ref)
+	if err != nil {
+		return fmt.Errorf("failed to fetch PR %s: %w", ref, err)
+	}
+
+	parent := pr.BaseRefName
+	if parent != yas.cfg.TrunkBranch && !yas.data.Branches.Exists(parent) {
+		log.Warn(fmt.Sprintf("Base branch '%s' is not tracked, using '%s' as the parent (hint: check out the PR for '%s' first)", parent, yas.cfg.TrunkBranch, parent))
+		parent = yas.cfg.TrunkBranch
+	}
+
+	previousBranch, _ := yas.git.GetCurrentBranchName()
+
+	if err := yas.ghRun("pr", "checkout", ref); err != nil {
+		return fmt.Errorf("failed to check out PR %s: %w", ref, err)
+	}
+
+	branchPoint, err := yas.git.GetMergeBase(parent, pr.HeadRefName)
+	if err != nil {
+		return err
+	}
+
+	metadata := yas.data.Branches.Get(pr.HeadRefName)
+	metadata.Name = pr.HeadRefName
+	metadata.Parent = parent
+	metadata.BranchPoint = branchPoint
+	metadata.GitHubPullRequest = pr.PullRequestMetadata
+	metadata.Deleted = time.Time{}
+
+	yas.data.PreviousBranch = previousBranch
+	yas.data.Branches.Set(pr.HeadRefName, metadata)
+
+	if err := yas.data.Save(); err != nil {
+		return err
+	}
+
+	fmt.Printf("Checked out '%s' on top of '%s'\n", pr.HeadRefName, parent)
+
+	yas.emit(Event{Type: EventBranchCreated, Branch: pr.HeadRefName})
+
+	return nil
+}
+
+// CreateBranchFromPullRequest checks out the head branch of someone else's
+// PR, tracking it as an external branch, and creates a new branch on top of
+// it. Restack keeps the external branch up to date with the PR.
+func (yas *YAS) CreateBranchFromPullRequest(branchName, ref string) error {
+	exists, err := yas.git.BranchExists(branchName)
+	if err != nil {
+		return err
+	}
+
+	if exists {
+		return fmt.Errorf("branch '%s' already exists", branchName)
+	}
+
+	pr, err := yas.fetchPullRequest(ref)
+	if err != nil {
+		return fmt.Errorf("failed to fetch PR %s: %w", ref, err)
+	}
+
+	if err := yas.CheckoutPullRequest(ref); err != nil {
+		return err
+	}
+
+	metadata := yas.data.Branches.Get(pr.HeadRefName)
+	metadata.External = true
+	yas.data.Branches.Set(pr.HeadRefName, metadata)
+
+	if err := yas.data.Save(); err != nil {
+		return err
+	}
+
+	previousBranch := yas.data.PreviousBranch
+
+	if err := yas.CreateBranch(branchName, pr.HeadRefName); err != nil {
+		return err
+	}
+
+	// Switching back should return to where we were before the PR was
+	// checked out
+	yas.data.PreviousBranch = previousBranch
+
+	return yas.data.Save()
+}
+
+type ReadyOptions struct {
+	// Branch is the branch whose PR is marked as ready (default: current
+	// branch).
+	Branch string
+
+	// Stack marks the PRs of every branch in the stack as ready.
+	Stack bool
+}
+
+// MarkPullRequestsReady marks draft PRs as ready for review.
+func (yas *YAS) MarkPullRequestsReady(opts ReadyOptions) error {
+	branchName := opts.Branch
+	if branchName == "" {
+		currentBranch, err := yas.git.GetCurrentBranchName()
+		if err != nil {
+			return err
+		}
+
+		branchName = currentBranch
+	}
+
+	if !yas.data.Branches.Exists(branchName) {
+		return fmt.Errorf("branch '%s' is not tracked (hint: run `yas add`)", branchName)
+	}
+
+	branchNames := []string{branchName}
+	if opts.Stack {
+		branchNames = yas.stack(branchName)
+	}
+
+	if err := yas.RefreshRemoteStatus(branchNames...); err != nil {
+		return err
+	}
+
+	for _, name := range branchNames {
+		metadata := yas.data.Branches.Get(name)
+
+		if metadata.GitHubPullRequest.State != "OPEN" {
+			if opts.Stack {
+				fmt.Printf("Skipping '%s' (no open PR)\n", name)
+				continue
+			}
+
+			return fmt.Errorf("branch '%s' has no open PR (hint: run `yas submit`)", name)
+		}
+
+		if !metadata.GitHubPullRequest.IsDraft {
+			fmt.Printf("PR for '%s' is already ready for review\n", name)
+			continue
+		}
+
+		if err := yas.setPullRequestDraft(name, false); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// setPullRequestDraft converts the open PR of the branch to a draft, or marks
+// it as ready for review, and records the new state.
+func (yas *YAS) setPullRequestDraft(branchName string, draft bool) error {
+	if err := yas.ghSetDraft(branchName, draft); err != nil {
+		return err
+	}
+
+	metadata := yas.data.Branches.Get(branchName)
+	metadata.GitHubPullRequest.IsDraft = draft
+	yas.data.Branches.Set(branchName, metadata)
+
+	return yas.data.Save()
+}
+
+// ghSetDraft converts the open PR with the specified head branch to a draft,
+// or marks it as ready for review.
+func (yas *YAS) ghSetDraft(head string, draft bool) error {
+	args := []string{"pr", "ready", head}
+	if draft {
+		args = append(args, "--undo")
+	}
+
+	if err := yas.ghRun(args...); err != nil {
+		if draft {
+			return fmt.Errorf("failed to convert PR for '%s' to draft: %w", head, err)
+		}
+
+		return fmt.Errorf("failed to mark PR for '%s' as ready: %w", head, err)
+	}
+
+	return nil
+}
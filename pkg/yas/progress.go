@@ -0,0 +1,98 @@
+package yas
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/dansimau/yas/pkg/cliutil"
+)
+
+// progress reports the steps of an operation across several branches, e.g.
+// "[3/10] Rebasing topic-c onto topic-b…", and prints a summary of the
+// outcome of each step when the operation finishes. All methods are no-ops on
+// a nil progress, so that operations can report progress unconditionally.
+type progress struct {
+	total   int
+	current int
+
+	start     time.Time
+	stepStart time.Time
+
+	// rows are the branch, outcome and duration of each finished step.
+	rows [][]string
+}
+
+// newProgress returns a progress for an operation with the specified number
+// of steps, of which done have already been completed (e.g. by a previous
+// invocation, when resuming).
+func newProgress(total, done int) *progress {
+	now := time.Now()
+
+	return &progress{
+		total:     total,
+		current:   done,
+		start:     now,
+		stepStart: now,
+	}
+}
+
+// step prints the start of the next step.
+func (p *progress) step(format string, args ...any) {
+	if p == nil {
+		return
+	}
+
+	p.current++
+
+	// The number of steps is an estimate for some operations
+	if p.current > p.total {
+		p.total = p.current
+	}
+
+	p.stepStart = time.Now()
+
+	fmt.Printf("[%d/%d] %s\n", p.current, p.total, fmt.Sprintf(format, args...))
+}
+
+// done records the outcome of the current step for the summary, and for the
+// command's result, if it is being recorded.
+func (p *progress) done(branchName, outcome string, err error) {
+	recordStep(branchName, outcome, err)
+
+	if p == nil {
+		return
+	}
+
+	p.rows = append(p.rows, []string{branchName, outcome, formatElapsed(time.Since(p.stepStart))})
+}
+
+// summary prints the outcome of each step and the total elapsed time.
+func (p *progress) summary() {
+	if p == nil || len(p.rows) == 0 {
+		return
+	}
+
+	fmt.Println()
+	cliutil.PrintTable(append([][]string{{"BRANCH", "RESULT", "TIME"}}, p.rows...))
+	fmt.Printf("\nDone in %s\n", formatElapsed(time.Since(p.start)))
+}
+
+// formatElapsed formats a duration to a tenth of a second, e.g. "1.2s".
+func formatElapsed(d time.Duration) string {
+	return fmt.Sprintf("%.1fs", d.Seconds())
+}
+
+// rebaseOnto transplants the commits in upstream..branchName onto newBase,
+// reporting it as a step of the current operation's progress.
+func (yas *YAS) rebaseOnto(newBase, upstream, branchName string) error {
+	yas.progress.step("Rebasing %s onto %s…", branchName, newBase)
+
+	if err := yas.git.RebaseOnto(newBase, upstream, branchName); err != nil {
+		yas.progress.done(branchName, "failed", err)
+		return err
+	}
+
+	yas.progress.done(branchName, "rebased", nil)
+
+	return nil
+}
@@ -0,0 +1,70 @@
+package yas
+
+import (
+	"errors"
+
+	"github.com/dansimau/yas/pkg/gitexec"
+)
+
+// PromptInfo is a summary of the current stack for use in shell prompts.
+type PromptInfo struct {
+	Branch string `json:"branch"`
+
+	// StackDepth is the position of the branch in its stack, where the
+	// bottom branch is 1.
+	StackDepth int `json:"stackDepth"`
+
+	// NeedsRestack is the number of branches in the stack that aren't on top
+	// of their parent.
+	NeedsRestack int `json:"needsRestack"`
+}
+
+// PromptInfo returns a summary of the current stack, or nil if the current
+// branch is not tracked. It only uses local state, i.e. it doesn't fetch or
+// call GitHub, so that it is fast enough to run on every prompt.
+func (yas *YAS) PromptInfo() (*PromptInfo, error) {
+	branchName, err := yas.git.GetCurrentBranchName()
+	if errors.Is(err, gitexec.ErrDetachedHead) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if branchName == yas.cfg.TrunkBranch || !yas.isTracked(branchName) {
+		return nil, nil
+	}
+
+	refs, err := yas.git.BranchRefs()
+	if err != nil {
+		return nil, err
+	}
+
+	info := &PromptInfo{
+		Branch:     branchName,
+		StackDepth: yas.stackDepth(branchName),
+	}
+
+	for _, name := range yas.stack(branchName) {
+		metadata := yas.data.Branches.Get(name)
+
+		parentTip := refs[metadata.Parent].Hash
+		if parentTip == "" {
+			// Not a local branch, e.g. a remote parent
+			if parentTip, err = yas.git.GetHash(metadata.Parent); err != nil {
+				return nil, err
+			}
+		}
+
+		needsRestack, err := yas.needsRestack(metadata, parentTip)
+		if err != nil {
+			return nil, err
+		}
+
+		if needsRestack {
+			info.NeedsRestack++
+		}
+	}
+
+	return info, nil
+}
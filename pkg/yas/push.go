@@ -0,0 +1,192 @@
+package yas
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/dansimau/yas/pkg/cliutil"
+	"github.com/dansimau/yas/pkg/gitexec"
+)
+
+// pushBranch force-pushes the branch to origin, and records the pushed commit
+// so that commits pushed to the branch by someone else can be detected the
+// next time. If origin has such commits, the user chooses whether to rebase
+// them onto the local branch first, overwrite them, or abort.
+func (yas *YAS) pushBranch(branchName string) error {
+	if err := yas.checkRemoteDivergence(branchName); err != nil {
+		return err
+	}
+
+	if err := yas.git.PushBranch(branchName); err != nil {
+		return fmt.Errorf("failed to push: %w", err)
+	}
+
+	hash, err := yas.git.GetHash(branchName)
+	if err != nil {
+		return err
+	}
+
+	metadata := yas.data.Branches.Get(branchName)
+	if metadata.Name != branchName {
+		// Not tracked, e.g. the tip of a combined stack that was deleted
+		return nil
+	}
+
+	metadata.Pushed = hash
+	yas.data.Branches.Set(branchName, metadata)
+
+	return yas.data.Save()
+}
+
+// Choices when origin has commits on a branch that yas didn't push.
+const (
+	divergedRebase = iota
+	divergedForce
+	divergedAbort
+)
+
+// checkRemoteDivergence fetches the branch from origin and checks for commits
+// on it that yas didn't push, i.e. that aren't reachable from the commit yas
+// last pushed. Commits that are only missing locally because the branch was
+// rebased or amended since then are not a divergence. If the branch was last
+// pushed outside of yas, only a warning is printed.
+func (yas *YAS) checkRemoteDivergence(branchName string) error {
+	remoteBranch := "origin/" + branchName
+
+	exists, err := yas.git.RemoteBranchExists(remoteBranch)
+	if err != nil || !exists {
+		return err
+	}
+
+	if err := yas.git.FetchBranch("origin", branchName); err != nil {
+		return fmt.Errorf("failed to fetch %s: %w", remoteBranch, err)
+	}
+
+	pushed := yas.data.Branches.Get(branchName).Pushed
+	if pushed == "" {
+		return yas.warnIfBehindRemote(branchName)
+	}
+
+	remoteTip, err := yas.git.GetHash(remoteBranch)
+	if err != nil {
+		return err
+	}
+
+	if remoteTip == pushed {
+		return nil
+	}
+
+	upToDate, err := yas.git.IsAncestor(remoteTip, branchName)
+	if err != nil || upToDate {
+		return err
+	}
+
+	// If the commit yas pushed was overwritten, e.g. by a force-push, compare
+	// from where the histories diverged instead
+	base := pushed
+	if onRemote, err := yas.git.IsAncestor(pushed, remoteTip); err != nil {
+		return err
+	} else if !onRemote {
+		if base, err = yas.git.GetMergeBase(branchName, remoteBranch); err != nil {
+			return err
+		}
+	}
+
+	commits, err := yas.git.Commits(base, remoteTip)
+	if err != nil {
+		return err
+	}
+
+	stat, err := yas.git.DiffStat(base, remoteTip)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("⚠️  %s has %d commit(s) that yas didn't push, which will be overwritten (hint: someone else may have pushed to the branch):\n", remoteBranch, len(commits))
+	for _, commit := range commits {
+		subject, _, _ := strings.Cut(commit.Message, "\n")
+		fmt.Printf("    %s %s\n", shortHash(commit.Hash), subject)
+	}
+	fmt.Printf("    (+%d -%d, %d files)\n", stat.Insertions, stat.Deletions, stat.Files)
+
+	choice, err := cliutil.Select("What do you want to do?", []string{
+		divergedRebase: fmt.Sprintf("Rebase the commits from %s onto %s, then push", remoteBranch, branchName),
+		divergedForce:  fmt.Sprintf("Push anyway, overwriting the commits on %s", remoteBranch),
+		divergedAbort:  "Abort",
+	}, divergedAbort)
+	if err != nil {
+		return err
+	}
+
+	switch choice {
+	case divergedRebase:
+		return yas.rebaseRemoteCommits(branchName, base, remoteBranch)
+	case divergedForce:
+		return nil
+	default:
+		return fmt.Errorf("%s has commits that yas didn't push (hint: pull them into %s, or submit again and choose to overwrite them)", remoteBranch, branchName)
+	}
+}
+
+// rebaseRemoteCommits transplants the commits in base..remoteBranch onto the
+// branch. If there are conflicts, the rebase is aborted and the branch is
+// left unchanged.
+func (yas *YAS) rebaseRemoteCommits(branchName, base, remoteBranch string) error {
+	dirty, err := yas.git.IsDirty()
+	if err != nil {
+		return err
+	}
+
+	if dirty {
+		return errors.New("there are uncommitted changes (hint: commit or stash them)")
+	}
+
+	previous, err := yas.git.GetCurrentBranchName()
+	if errors.Is(err, gitexec.ErrDetachedHead) {
+		previous, err = yas.git.GetHash("HEAD")
+	}
+	if err != nil {
+		return err
+	}
+
+	rebaseErr := yas.git.RebaseOnto(branchName, base, remoteBranch)
+	if rebaseErr != nil {
+		if err := yas.git.RebaseAbort(); err != nil {
+			return err
+		}
+	}
+
+	// The rebase leaves HEAD detached at the rebased commits
+	rebased, err := yas.git.GetHash("HEAD")
+	if err != nil {
+		return err
+	}
+
+	if err := yas.git.Checkout(branchName); err != nil {
+		return err
+	}
+
+	if rebaseErr == nil {
+		rebaseErr = yas.git.MergeFastForward(rebased)
+	}
+
+	if previous != branchName {
+		if err := yas.git.Checkout(previous); err != nil {
+			return err
+		}
+	}
+
+	if rebaseErr != nil {
+		return fmt.Errorf("failed to rebase the commits from %s onto %s (hint: run `git cherry-pick %s..%s` on %s to resolve the conflicts, then submit again): %w",
+			remoteBranch, branchName, shortHash(base), remoteBranch, branchName, rebaseErr)
+	}
+
+	fmt.Printf("Rebased the commits from %s onto %s\n", remoteBranch, branchName)
+
+	if len(yas.data.Branches.ToSlice().NotDeleted().WithParent(branchName)) > 0 {
+		fmt.Printf("(hint: run `yas restack` to update the branches stacked on %s)\n", branchName)
+	}
+
+	return nil
+}
@@ -0,0 +1,157 @@
+package yas
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+
+	"github.com/dansimau/yas/pkg/fsutil"
+	"github.com/dansimau/yas/pkg/log"
+)
+
+// Recover rebuilds the state file from git and GitHub, for when it has been
+// lost or corrupted. The existing state file, if any, is backed up first.
+func Recover(repoDirectory string) error {
+	cfg, err := ReadConfig(repoDirectory)
+	if err != nil {
+		return fmt.Errorf("failed to read config: %w", err)
+	}
+
+	statePath := gitFile(repoDirectory, yasStateFile)
+	if fsutil.FileExists(statePath) {
+		backupPath := statePath + ".bak"
+		if err := os.Rename(statePath, backupPath); err != nil {
+			return fmt.Errorf("failed to back up state file: %w", err)
+		}
+
+		fmt.Printf("Backed up existing state file to: %s\n", backupPath)
+	}
+
+	yas, err := New(*cfg)
+	if err != nil {
+		return err
+	}
+
+	return yas.recover()
+}
+
+// fetchBranchPullRequest returns the most recent PR for the branch, or nil if
+// there is none.
+func (yas *YAS) fetchBranchPullRequest(branchName string) (*pullRequestDetails, error) {
+	b, err := yas.gh("pr", "list", "--head", branchName, "--state", "all", "--json", pullRequestDetailsFields)
+	if err != nil {
+		return nil, err
+	}
+
+	prs := []pullRequestDetails{}
+	if err := json.Unmarshal(b, &prs); err != nil {
+		return nil, err
+	}
+
+	if len(prs) == 0 {
+		return nil, nil
+	}
+
+	return &prs[0], nil
+}
+
+// recover tracks every local branch with commits that aren't on trunk. The
+// parent of a branch is the base of its open PR, if it has one, otherwise its
+// nearest ancestor branch.
+func (yas *YAS) recover() error {
+	localBranches, err := yas.UntrackedBranches()
+	if err != nil {
+		return err
+	}
+
+	branchNames := []string{}
+	for _, name := range localBranches {
+		if name == yas.cfg.TrunkBranch {
+			continue
+		}
+
+		// Branches without their own commits were merged or never started
+		if distance, err := yas.git.CountCommits(yas.cfg.TrunkBranch, name); err != nil || distance == 0 {
+			continue
+		}
+
+		branchNames = append(branchNames, name)
+	}
+
+	slices.Sort(branchNames)
+
+	for _, name := range branchNames {
+		metadata := BranchMetadata{Name: name}
+
+		pr, err := yas.fetchBranchPullRequest(name)
+		if err != nil {
+			log.Warn(fmt.Sprintf("failed to fetch PR for '%s': %v", name, err))
+		}
+
+		if pr != nil {
+			metadata.GitHubPullRequest = pr.PullRequestMetadata
+		}
+
+		if pr != nil && pr.State == "OPEN" && (pr.BaseRefName == yas.cfg.TrunkBranch || slices.Contains(branchNames, pr.BaseRefName)) {
+			metadata.Parent = pr.BaseRefName
+		} else if metadata.Parent, err = yas.nearestAncestorBranch(name, branchNames); err != nil {
+			return err
+		}
+
+		if metadata.BranchPoint, err = yas.git.GetMergeBase(metadata.Parent, name); err != nil {
+			return err
+		}
+
+		yas.data.Branches.Set(name, metadata)
+
+		fmt.Printf("Recovered '%s' on top of '%s'\n", name, metadata.Parent)
+	}
+
+	if err := yas.data.Save(); err != nil {
+		return err
+	}
+
+	fmt.Printf("Recovered %d branch(es)\n", len(branchNames))
+
+	return nil
+}
+
+// nearestAncestorBranch returns the candidate branch (or trunk) that is the
+// closest ancestor of the branch. Trunk is returned if none of the candidates
+// are ancestors.
+func (yas *YAS) nearestAncestorBranch(branchName string, candidates []string) (string, error) {
+	nearest := yas.cfg.TrunkBranch
+	nearestDistance, err := yas.git.CountCommits(yas.cfg.TrunkBranch, branchName)
+	if err != nil {
+		return "", err
+	}
+
+	for _, candidate := range candidates {
+		if candidate == branchName {
+			continue
+		}
+
+		isAncestor, err := yas.git.IsAncestor(candidate, branchName)
+		if err != nil {
+			return "", err
+		}
+
+		if !isAncestor {
+			continue
+		}
+
+		distance, err := yas.git.CountCommits(candidate, branchName)
+		if err != nil {
+			return "", err
+		}
+
+		// Branches at the same commit would be each other's parent
+		if distance > 0 && distance < nearestDistance {
+			nearest = candidate
+			nearestDistance = distance
+		}
+	}
+
+	return nearest, nil
+}
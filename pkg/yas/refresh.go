@@ -0,0 +1,164 @@
+package yas
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/dansimau/yas/pkg/log"
+)
+
+type RefreshOptions struct {
+	// Stack refreshes every branch in the current stack.
+	Stack bool
+
+	// All refreshes every tracked branch.
+	All bool
+}
+
+// Refresh fetches the PR status of branches from GitHub and fetches origin,
+// then prints what changed for each branch, including whether origin has
+// commits that the local branch doesn't.
+func (yas *YAS) Refresh(opts RefreshOptions) error {
+	branchNames := yas.TrackedBranches().SortedByName().BranchNames()
+
+	if !opts.All {
+		currentBranch, err := yas.git.GetCurrentBranchName()
+		if err != nil {
+			return err
+		}
+
+		if !yas.isTracked(currentBranch) {
+			return fmt.Errorf("branch '%s' is not tracked (hint: use --all to refresh all branches)", currentBranch)
+		}
+
+		branchNames = []string{currentBranch}
+		if opts.Stack {
+			branchNames = yas.stack(currentBranch)
+		}
+	}
+
+	before := map[string]PullRequestMetadata{}
+	for _, name := range branchNames {
+		before[name] = yas.data.Branches.Get(name).GitHubPullRequest
+	}
+
+	// Report the branches that were refreshed even if others failed, and
+	// return the failures at the end
+	var failed RefreshErrors
+	if err := yas.RefreshRemoteStatus(branchNames...); err != nil && !errors.As(err, &failed) {
+		return err
+	}
+
+	if err := yas.fetchOrigin(); err != nil {
+		return err
+	}
+
+	for _, name := range branchNames {
+		if failed[name] != nil {
+			fmt.Printf("%s: failed to fetch PR status\n", name)
+			continue
+		}
+
+		changes := pullRequestChanges(before[name], yas.data.Branches.Get(name).GitHubPullRequest)
+
+		counts, _, err := yas.branchAheadBehind(name)
+		if err != nil {
+			return err
+		}
+
+		if counts.Behind > 0 {
+			changes = append(changes, fmt.Sprintf("⚠️  %d commit(s) on origin not on local branch", counts.Behind))
+		}
+
+		if label := needsSubmitLabel(yas.data.Branches.Get(name), counts); label != "" {
+			changes = append(changes, label)
+		}
+
+		if len(changes) == 0 {
+			changes = []string{"no changes"}
+		}
+
+		fmt.Printf("%s: %s\n", name, strings.Join(changes, ", "))
+	}
+
+	if len(failed) > 0 {
+		return failed
+	}
+
+	return nil
+}
+
+// fetchOrigin fetches origin, if the repository has it, so that the
+// ahead/behind counts of branches are up to date.
+func (yas *YAS) fetchOrigin() error {
+	remotes, err := yas.git.Remotes()
+	if err != nil {
+		return err
+	}
+
+	if !slices.Contains(remotes, "origin") {
+		return nil
+	}
+
+	if err := yas.fetch("origin"); err != nil {
+		return fmt.Errorf("failed to fetch origin: %w", err)
+	}
+
+	return nil
+}
+
+// pullRequestChanges returns a description of each change between two
+// versions of a PR's metadata.
+func pullRequestChanges(before, after PullRequestMetadata) []string {
+	changes := []string{}
+
+	if before.State != after.State {
+		changes = append(changes, fmt.Sprintf("%s → %s", valueOrNone(before.State), valueOrNone(after.State)))
+	}
+
+	if before.BaseRefName != after.BaseRefName && before.BaseRefName != "" && after.BaseRefName != "" {
+		changes = append(changes, fmt.Sprintf("base %s → %s", before.BaseRefName, after.BaseRefName))
+	}
+
+	if before.IsDraft && !after.IsDraft && after.State == "OPEN" {
+		changes = append(changes, "ready for review")
+	}
+
+	return changes
+}
+
+func valueOrNone(s string) string {
+	if s == "" {
+		return "no PR"
+	}
+
+	return s
+}
+
+// RefreshAllRemoteStatus refreshes the PR status of all tracked branches while
+// holding the repository lock, for refreshes in the background (by the daemon
+// and `list --watch`). If another yas operation holds the lock, the refresh is
+// skipped.
+func RefreshAllRemoteStatus(repoDirectory, operation string) error {
+	lock, err := LockRepository(repoDirectory, operation)
+	if errors.Is(err, ErrLocked) {
+		log.Debug("Skipping refresh:", err)
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+	defer lock.Release()
+
+	// Reload from disk each time to pick up changes made by other yas
+	// invocations
+	yas, err := NewFromRepository(repoDirectory)
+	if err != nil {
+		return err
+	}
+
+	return yas.RefreshRemoteStatus(yas.TrackedBranches().BranchNames()...)
+}
@@ -0,0 +1,137 @@
+package yas
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/dansimau/yas/pkg/log"
+)
+
+type ReportOptions struct {
+	// Since is the first day of the period to report on.
+	Since time.Time
+
+	// Until is the last day of the period to report on.
+	Until time.Time
+}
+
+type reportPullRequest struct {
+	Number      int
+	Title       string
+	URL         string
+	HeadRefName string
+	CreatedAt   time.Time
+	MergedAt    time.Time
+}
+
+// Report generates a markdown summary of work on yas-managed branches in the
+// period, e.g. for a standup: the branches created, the PRs created and
+// merged, and the current open stacks. Branch history comes from the state
+// file; PR history comes from GitHub.
+func (yas *YAS) Report(opts ReportOptions) (string, error) {
+	created, err := yas.fetchReportPullRequests("all", "created", opts.Since, opts.Until)
+	if err != nil {
+		return "", err
+	}
+
+	merged, err := yas.fetchReportPullRequests("merged", "merged", opts.Since, opts.Until)
+	if err != nil {
+		return "", err
+	}
+
+	sb := &strings.Builder{}
+
+	fmt.Fprintf(sb, "# Report: %s to %s\n", opts.Since.Format(time.DateOnly), opts.Until.Format(time.DateOnly))
+
+	end := opts.Until.AddDate(0, 0, 1)
+
+	branches := Branches{}
+	for _, branch := range yas.data.Branches.ToSlice().SortedByName() {
+		if !branch.Created.Before(opts.Since) && branch.Created.Before(end) {
+			branches = append(branches, branch)
+		}
+	}
+
+	fmt.Fprintf(sb, "\n## Branches created (%d)\n\n", len(branches))
+	for _, branch := range branches {
+		fmt.Fprintf(sb, "- `%s` (%s)\n", branch.Name, branch.Created.Format(time.DateOnly))
+	}
+
+	fmt.Fprintf(sb, "\n## PRs submitted (%d)\n\n", len(created))
+	for _, pr := range created {
+		fmt.Fprintf(sb, "- %s (`%s`)\n", pr.link(), pr.HeadRefName)
+	}
+
+	fmt.Fprintf(sb, "\n## PRs merged (%d)\n\n", len(merged))
+	for _, pr := range merged {
+		fmt.Fprintf(sb, "- %s (`%s`), merged %s\n", pr.link(), pr.HeadRefName, pr.MergedAt.Format(time.DateOnly))
+	}
+
+	roots := yas.data.Branches.ToSlice().NotDeleted().WithParent(yas.cfg.TrunkBranch).SortedByName()
+
+	fmt.Fprintf(sb, "\n## Open stacks (%d)\n\n", len(roots))
+	for _, root := range roots {
+		yas.writeReportStack(sb, root, 0)
+	}
+
+	return sb.String(), nil
+}
+
+// writeReportStack writes the branch and its descendants as a nested list.
+func (yas *YAS) writeReportStack(sb *strings.Builder, branch BranchMetadata, indent int) {
+	fmt.Fprintf(sb, "%s- `%s`", strings.Repeat("  ", indent), branch.Name)
+
+	if pr := branch.GitHubPullRequest; pr.URL != "" {
+		fmt.Fprintf(sb, " ([#%d](%s))", pr.Number, pr.URL)
+	}
+
+	sb.WriteString("\n")
+
+	for _, child := range yas.data.Branches.ToSlice().NotDeleted().WithParent(branch.Name).SortedByName() {
+		yas.writeReportStack(sb, child, indent+1)
+	}
+}
+
+func (pr reportPullRequest) link() string {
+	return fmt.Sprintf("[#%d %s](%s)", pr.Number, pr.Title, pr.URL)
+}
+
+// fetchReportPullRequests returns the PRs in the state for yas-managed
+// branches whose date field (created or merged) is in the period, oldest
+// first.
+func (yas *YAS) fetchReportPullRequests(state, field string, since, until time.Time) ([]reportPullRequest, error) {
+	search := fmt.Sprintf("%s:%s..%s", field, since.Format(time.DateOnly), until.Format(time.DateOnly))
+
+	log.Info("Fetching PRs", search)
+
+	b, err := yas.gh("pr", "list", "--state", state, "--author", "@me", "--limit", "500", "--search", search, "--json", "number,title,url,headRefName,createdAt,mergedAt")
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch PRs: %w", err)
+	}
+
+	data := []reportPullRequest{}
+	if err := json.Unmarshal(b, &data); err != nil {
+		return nil, err
+	}
+
+	prs := []reportPullRequest{}
+	for _, pr := range data {
+		// Only include PRs for branches managed by yas
+		if yas.data.Branches.Exists(pr.HeadRefName) {
+			prs = append(prs, pr)
+		}
+	}
+
+	slices.SortStableFunc(prs, func(a, b reportPullRequest) int {
+		if field == "merged" {
+			return a.MergedAt.Compare(b.MergedAt)
+		}
+
+		return a.CreatedAt.Compare(b.CreatedAt)
+	})
+
+	return prs, nil
+}
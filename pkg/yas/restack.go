@@ -0,0 +1,976 @@
+package yas
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/dansimau/yas/pkg/cliutil"
+	"github.com/dansimau/yas/pkg/gitexec"
+	"github.com/dansimau/yas/pkg/log"
+)
+
+type RestackOptions struct {
+	// All restacks every tracked branch, not just the current stack.
+	All bool
+
+	// Branch restacks only the specified branch and its descendants. If the
+	// current branch is not among them, the current checkout is left
+	// untouched.
+	Branch string
+
+	// Stack restacks the stack containing the specified branch, or the stack
+	// with the specified name, rather than the current stack. If the current
+	// branch is not in the stack, the current checkout is left untouched.
+	Stack string
+
+	// Autostash stashes local modifications before restacking and restores
+	// them afterwards.
+	Autostash bool
+
+	// ForceDirty restacks even if there are uncommitted changes that the
+	// rebases may fail on or disrupt.
+	ForceDirty bool
+
+	// ContinueOnError aborts the rebase of a branch that conflicts and skips
+	// its descendants, restacking the remaining branches (e.g. other stacks)
+	// before stopping at the first conflict to be resolved.
+	ContinueOnError bool
+}
+
+// restackState is the progress of a restack that stopped due to conflicts,
+// so that it can be resumed with `yas continue`.
+type restackState struct {
+	// OriginalBranch is the branch that was checked out when the restack
+	// started.
+	OriginalBranch string `json:"originalBranch"`
+
+	// Queue is the branches remaining to be restacked. The first branch is
+	// the one whose rebase stopped.
+	Queue []string `json:"queue"`
+
+	// OldTips are the tips of branches before they were rebased, so that
+	// children can be transplanted from the old parent commits.
+	OldTips map[string]string `json:"oldTips"`
+
+	Restacked []string `json:"restacked,omitempty"`
+	Skipped   []string `json:"skipped,omitempty"`
+
+	// ContinueOnError is true if conflicting branches are put aside until
+	// the rest of the queue has been restacked (see
+	// RestackOptions.ContinueOnError). Conflicted is the branches that were
+	// put aside.
+	ContinueOnError bool     `json:"continueOnError,omitempty"`
+	Conflicted      []string `json:"conflicted,omitempty"`
+
+	// Stashed is true if local changes were stashed before restacking.
+	Stashed bool `json:"stashed,omitempty"`
+}
+
+func (yas *YAS) Restack(opts RestackOptions) (err error) {
+	if yas.data.Restack != nil {
+		return errors.New("a restack is already in progress (hint: run `yas continue`)")
+	}
+
+	currentBranchName, err := yas.git.GetCurrentBranchName()
+	if err != nil {
+		return err
+	}
+
+	if currentBranchName != yas.cfg.TrunkBranch && !yas.data.Branches.Exists(currentBranchName) {
+		return fmt.Errorf("branch '%s' is not tracked (hint: run `yas add`)", currentBranchName)
+	}
+
+	// Like rebasing the current branch onto trunk with --update-refs, this
+	// restacks its ancestors as well as its descendants
+	queue := yas.stack(currentBranchName)
+	if opts.All {
+		queue = yas.restackQueue(yas.cfg.TrunkBranch)
+	} else if opts.Branch != "" {
+		if !yas.data.Branches.Exists(opts.Branch) {
+			return fmt.Errorf("branch '%s' is not tracked (hint: run `yas add`)", opts.Branch)
+		}
+
+		queue = append([]string{opts.Branch}, yas.descendants(opts.Branch)...)
+	} else if opts.Stack != "" {
+		opts.Stack = yas.resolveStack(opts.Stack)
+
+		if !yas.data.Branches.Exists(opts.Stack) {
+			return fmt.Errorf("branch '%s' is not tracked (hint: run `yas add`)", opts.Stack)
+		}
+
+		queue = yas.stack(opts.Stack)
+	}
+
+	// Branches outside the current stack are restacked in a temporary
+	// worktree, leaving the current checkout untouched
+	inWorktree := (opts.Branch != "" || opts.Stack != "") && !slices.Contains(queue, currentBranchName)
+	autostash := opts.Autostash || yas.cfg.Autostash
+
+	if !opts.ForceDirty {
+		if err := yas.checkClean(queue, !inWorktree && !autostash); err != nil {
+			return err
+		}
+	}
+
+	if err := yas.repairRewrittenTrunkBranchPoints(queue); err != nil {
+		return err
+	}
+
+	backfilled, err := yas.backfillBranchPoints(queue)
+	if err != nil {
+		return err
+	}
+
+	repaired, err := yas.repairStaleBranchPointsOf(queue)
+	if err != nil {
+		return err
+	}
+
+	if len(repaired) > 0 {
+		if err := yas.data.Save(); err != nil {
+			return err
+		}
+	}
+
+	if len(backfilled) > 0 {
+		fmt.Printf("⚠️  Branch point not set for %s, recorded the merge-base with the parent instead\n", strings.Join(backfilled, ", "))
+	}
+
+	// Branches whose PRs were merged are left alone, and their children are
+	// moved off them
+	if queue, err = yas.reparentOffMerged(queue); err != nil {
+		return err
+	}
+
+	if err := yas.runPreHook("preRestack", yas.cfg.Hooks.PreRestack, currentBranchName); err != nil {
+		return err
+	}
+
+	if inWorktree {
+		if err := yas.restackInWorktree(queue); err != nil {
+			return err
+		}
+
+		yas.runPostHook("postRestack", yas.cfg.Hooks.PostRestack, currentBranchName)
+		yas.emit(Event{Type: EventRestacked, Branch: currentBranchName, Branches: queue})
+
+		return nil
+	}
+
+	state := &restackState{
+		OriginalBranch:  currentBranchName,
+		Queue:           queue,
+		OldTips:         map[string]string{},
+		ContinueOnError: opts.ContinueOnError,
+	}
+
+	if autostash {
+		if state.Stashed, err = yas.stash(); err != nil {
+			return err
+		}
+	}
+
+	return yas.runRestack(state)
+}
+
+type ContinueOptions struct {
+	// AbortCurrent aborts the rebase of the conflicted branch and skips it
+	// and its descendants, instead of continuing the rebase.
+	AbortCurrent bool
+
+	// Autostash stashes local modifications before restacking the remaining
+	// branches and restores them afterwards.
+	Autostash bool
+
+	// ForceDirty continues even if there are uncommitted changes that the
+	// remaining rebases may fail on or disrupt.
+	ForceDirty bool
+}
+
+// Continue resumes a restack that stopped due to conflicts.
+func (yas *YAS) Continue(opts ContinueOptions) error {
+	state := yas.data.Restack
+	if state == nil || len(state.Queue) == 0 {
+		return errors.New("no restack in progress")
+	}
+
+	branchName := state.Queue[0]
+
+	inProgress, err := yas.git.RebaseInProgress()
+	if err != nil {
+		return err
+	}
+
+	if opts.AbortCurrent {
+		if inProgress {
+			if err := yas.git.RebaseAbort(); err != nil {
+				return fmt.Errorf("failed to abort rebase of '%s': %w", branchName, err)
+			}
+		}
+
+		state.Skipped = append(state.Skipped, branchName)
+	} else {
+		// The rebase may have already been continued with git
+		if inProgress {
+			if err := yas.git.RebaseContinue(); err != nil {
+				return fmt.Errorf("failed to continue rebase of '%s' (hint: resolve conflicts and `git add` the files): %w", branchName, err)
+			}
+		}
+
+		if err := yas.updateBranchPoint(branchName); err != nil {
+			return err
+		}
+
+		state.Restacked = append(state.Restacked, branchName)
+	}
+
+	state.Queue = state.Queue[1:]
+
+	if len(state.Queue) > 0 {
+		autostash := (opts.Autostash || yas.cfg.Autostash) && !state.Stashed
+
+		if !opts.ForceDirty {
+			if err := yas.checkClean(state.Queue, !autostash); err != nil {
+				return err
+			}
+		}
+
+		if autostash {
+			if state.Stashed, err = yas.stash(); err != nil {
+				return err
+			}
+		}
+	}
+
+	return yas.runRestack(state)
+}
+
+// runRestack restacks the branches in the queue. If a rebase stops due to
+// conflicts, the state is saved so the restack can be resumed with
+// Continue.
+func (yas *YAS) runRestack(state *restackState) (err error) {
+	done := len(state.Restacked) + len(state.Skipped)
+	yas.progress = newProgress(done+len(state.Queue), done)
+	defer func() { yas.progress = nil }()
+
+	refs, err := yas.newRestackRefs(state.Queue)
+	if err != nil {
+		return yas.endRestack(state, err)
+	}
+	defer refs.cache.save()
+
+	for len(state.Queue) > 0 {
+		branchName := state.Queue[0]
+
+		if parent := yas.data.Branches.Get(branchName).Parent; slices.Contains(state.Skipped, parent) || slices.Contains(state.Conflicted, parent) {
+			yas.progress.step("Skipping %s (%s was skipped)", branchName, parent)
+			yas.progress.done(branchName, "skipped", nil)
+
+			state.Skipped = append(state.Skipped, branchName)
+			state.Queue = state.Queue[1:]
+			continue
+		}
+
+		if err := yas.restackBranch(branchName, state.OldTips, refs); err != nil {
+			if inProgress, _ := yas.git.RebaseInProgress(); inProgress {
+				if state.ContinueOnError {
+					if err := yas.git.RebaseAbort(); err != nil {
+						return yas.endRestack(state, fmt.Errorf("failed to abort rebase of '%s': %w", branchName, err))
+					}
+
+					state.Conflicted = append(state.Conflicted, branchName)
+					state.Queue = state.Queue[1:]
+
+					continue
+				}
+
+				yas.data.Restack = state
+				if err := yas.data.Save(); err != nil {
+					return err
+				}
+
+				return fmt.Errorf("%w (hint: resolve conflicts and run `yas continue`, or `yas continue --abort-current` to skip '%s' and its descendants)", err, branchName)
+			}
+
+			return yas.endRestack(state, err)
+		}
+
+		state.Restacked = append(state.Restacked, branchName)
+		state.Queue = state.Queue[1:]
+	}
+
+	if len(state.Conflicted) > 0 {
+		yas.requeueConflicted(state)
+		return yas.runRestack(state)
+	}
+
+	// Rebasing checks out each branch, so switch back to where we started
+	if err := yas.git.Checkout(state.OriginalBranch); err != nil {
+		return yas.endRestack(state, err)
+	}
+
+	if err := yas.endRestack(state, nil); err != nil {
+		return err
+	}
+
+	yas.progress.summary()
+
+	if len(state.Skipped) > 0 {
+		fmt.Printf("Skipped: %s\n", strings.Join(state.Skipped, ", "))
+	}
+
+	yas.runPostHook("postRestack", yas.cfg.Hooks.PostRestack, state.OriginalBranch)
+	yas.emit(Event{Type: EventRestacked, Branch: state.OriginalBranch, Branches: state.Restacked})
+
+	return nil
+}
+
+// requeueConflicted reports the stacks with branches that were put aside due
+// to conflicts, and queues those branches and their skipped descendants to be
+// restacked again, so that the restack stops at the first conflict to be
+// resolved with Continue.
+func (yas *YAS) requeueConflicted(state *restackState) {
+	if len(state.Conflicted) == 0 {
+		return
+	}
+
+	yas.progress.summary()
+
+	fmt.Printf("\n⚠️  %d stack(s) have conflicts and need to be restacked manually:\n", len(state.Conflicted))
+	for _, branchName := range state.Conflicted {
+		fmt.Printf("    %s (conflict in %s)\n", yas.stackRoot(branchName), branchName)
+	}
+
+	fmt.Println()
+
+	for _, branchName := range state.Conflicted {
+		state.Queue = append(state.Queue, branchName)
+
+		for _, descendant := range yas.descendants(branchName) {
+			if i := slices.Index(state.Skipped, descendant); i >= 0 {
+				state.Skipped = slices.Delete(state.Skipped, i, i+1)
+				state.Queue = append(state.Queue, descendant)
+			}
+		}
+	}
+
+	state.Conflicted = nil
+	state.ContinueOnError = false
+}
+
+// endRestack clears the saved restack state and restores stashed changes.
+func (yas *YAS) endRestack(state *restackState, err error) error {
+	yas.data.Restack = nil
+	if saveErr := yas.data.Save(); saveErr != nil && err == nil {
+		err = saveErr
+	}
+
+	if state.Stashed {
+		yas.unstash(&err)
+	}
+
+	return err
+}
+
+// restackInWorktree restacks the branches in a temporary worktree, so that
+// the current checkout is not modified.
+func (yas *YAS) restackInWorktree(queue []string) error {
+	worktreePath, err := os.MkdirTemp("", "yas-restack-")
+	if err != nil {
+		return err
+	}
+
+	// git worktree add requires that the path does not exist
+	if err := os.Remove(worktreePath); err != nil {
+		return err
+	}
+
+	worktree, err := yas.git.WorktreeAdd(worktreePath)
+	if err != nil {
+		return fmt.Errorf("failed to create worktree: %w", err)
+	}
+
+	mainRepo := yas.git
+	yas.git = worktree
+
+	defer func() {
+		yas.git = mainRepo
+
+		if err := mainRepo.WorktreeRemove(worktreePath); err != nil {
+			log.Warn("failed to remove worktree", worktreePath+":", err)
+		}
+	}()
+
+	yas.progress = newProgress(len(queue), 0)
+	defer func() { yas.progress = nil }()
+
+	oldTips := map[string]string{}
+
+	refs, err := yas.newRestackRefs(queue)
+	if err != nil {
+		return err
+	}
+	defer refs.cache.save()
+
+	for _, branchName := range queue {
+		if err := yas.restackBranch(branchName, oldTips, refs); err != nil {
+			return err
+		}
+	}
+
+	yas.progress.summary()
+
+	return nil
+}
+
+// restackRefs answers the questions restackBranch asks git about each
+// branch, with fewer git processes: the tips of the branches to restack and
+// their parents are resolved with a single process up front, and ancestry is
+// looked up in the cache, which already has most answers from checking for
+// stale branch points.
+type restackRefs struct {
+	git   *gitexec.Repo
+	tips  map[string]string
+	cache *diffStatCache
+}
+
+// newRestackRefs returns the restackRefs for restacking the branches in the
+// queue.
+func (yas *YAS) newRestackRefs(queue []string) (*restackRefs, error) {
+	names := slices.Clone(queue)
+	for _, branchName := range queue {
+		if parent := yas.data.Branches.Get(branchName).Parent; parent != "" {
+			names = append(names, parent)
+		}
+	}
+
+	tips, err := yas.git.ResolveCommits(names...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &restackRefs{
+		git:   yas.git,
+		tips:  tips,
+		cache: loadDiffStatCache(gitFile(yas.cfg.RepoDirectory, diffStatCacheFile)),
+	}, nil
+}
+
+// tip returns the commit hash of the ref.
+func (r *restackRefs) tip(ref string) (string, error) {
+	if hash, ok := r.tips[ref]; ok {
+		return hash, nil
+	}
+
+	return r.git.GetHash(ref)
+}
+
+// changed forgets the tip of the ref, which is about to change, e.g. because
+// it is being rebased.
+func (r *restackRefs) changed(ref string) {
+	delete(r.tips, ref)
+}
+
+// rebased records the new tip of the branch after it was rebased onto the
+// commit, for its children and for the next command's stale branch point
+// checks.
+func (r *restackRefs) rebased(branchName, onto string) error {
+	hash, err := r.git.GetHash(branchName)
+	if err != nil {
+		return err
+	}
+
+	r.tips[branchName] = hash
+	r.cache.setIsAncestor(onto, hash, true)
+
+	return nil
+}
+
+// isAncestor returns true if the commit is an ancestor of ref.
+func (r *restackRefs) isAncestor(commit, ref string) (bool, error) {
+	hash, err := r.tip(ref)
+	if err != nil {
+		return false, err
+	}
+
+	return r.cache.getIsAncestor(commit, hash, r.git.IsAncestor)
+}
+
+// restackBranch rebases the branch onto the current tip of its parent and
+// updates the branch point.
+func (yas *YAS) restackBranch(branchName string, oldTips map[string]string, refs *restackRefs) error {
+	metadata := yas.data.Branches.Get(branchName)
+	if metadata.Parent == "" {
+		return nil
+	}
+
+	// When resuming, the tip before the restack started has already been
+	// recorded
+	if _, ok := oldTips[branchName]; !ok {
+		oldTip, err := refs.tip(branchName)
+		if err != nil {
+			return err
+		}
+
+		oldTips[branchName] = oldTip
+	}
+
+	if metadata.External {
+		refs.changed(branchName)
+
+		yas.progress.step("Updating %s from its upstream…", branchName)
+
+		if err := yas.updateExternalBranch(metadata); err != nil {
+			yas.progress.done(branchName, "failed", err)
+			return err
+		}
+
+		yas.progress.done(branchName, "updated", nil)
+
+		return nil
+	}
+
+	// Remote parents are rebased onto the latest fetched tip
+	remote, err := yas.remoteParent(metadata.Parent)
+	if err != nil {
+		return err
+	}
+
+	if remote != "" {
+		if err := yas.fetch(remote); err != nil {
+			return fmt.Errorf("failed to fetch '%s': %w", metadata.Parent, err)
+		}
+
+		refs.changed(metadata.Parent)
+	}
+
+	upstream, err := yas.restackUpstream(metadata, oldTips[metadata.Parent], refs)
+	if err != nil {
+		return err
+	}
+
+	parentTip, err := refs.tip(metadata.Parent)
+	if err != nil {
+		return err
+	}
+
+	if upstream == parentTip {
+		log.Debug("Skipping rebase of", branchName, "(branch point matches parent)")
+
+		yas.progress.step("%s is up to date with %s", branchName, metadata.Parent)
+		yas.progress.done(branchName, "up to date", nil)
+	} else {
+		refs.changed(branchName)
+
+		if err := yas.rebaseOnto(metadata.Parent, upstream, branchName); err != nil {
+			return fmt.Errorf("failed to rebase '%s' onto '%s': %w", branchName, metadata.Parent, err)
+		}
+
+		if err := refs.rebased(branchName, parentTip); err != nil {
+			return err
+		}
+	}
+
+	metadata.BranchPoint = parentTip
+	yas.data.Branches.Set(branchName, metadata)
+
+	return yas.data.Save()
+}
+
+// updateExternalBranch fetches the upstream of an external branch and resets
+// the branch to it, since the owner of the branch may have rebased it.
+func (yas *YAS) updateExternalBranch(metadata BranchMetadata) error {
+	remote, err := yas.git.ConfigValue(fmt.Sprintf("branch.%s.remote", metadata.Name))
+	if err != nil {
+		return err
+	}
+
+	if remote == "" {
+		remote = "origin"
+	}
+
+	if err := yas.fetch(remote); err != nil {
+		return fmt.Errorf("failed to fetch '%s': %w", metadata.Name, err)
+	}
+
+	currentBranch, err := yas.git.GetCurrentBranchName()
+	if err != nil {
+		return err
+	}
+
+	upstream := metadata.Name + "@{upstream}"
+	if currentBranch == metadata.Name {
+		err = yas.git.ResetKeep(upstream)
+	} else {
+		err = yas.git.ForceBranch(metadata.Name, upstream)
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to update '%s' from its upstream: %w", metadata.Name, err)
+	}
+
+	if metadata.BranchPoint, err = yas.git.GetMergeBase(metadata.Parent, metadata.Name); err != nil {
+		return err
+	}
+
+	yas.data.Branches.Set(metadata.Name, metadata)
+
+	return yas.data.Save()
+}
+
+// updateBranchPoint records the current tip of the branch's parent as its
+// branch point, after the branch has been rebased onto it.
+func (yas *YAS) updateBranchPoint(branchName string) error {
+	metadata := yas.data.Branches.Get(branchName)
+
+	parentTip, err := yas.git.GetHash(metadata.Parent)
+	if err != nil {
+		return err
+	}
+
+	metadata.BranchPoint = parentTip
+	yas.data.Branches.Set(branchName, metadata)
+
+	return yas.data.Save()
+}
+
+// restackUpstream returns the commit after which the branch's own commits
+// start, i.e. the upstream to use to rebase the branch onto its parent. The
+// recorded branch point is preferred; if it is not usable, the previous tip of
+// the parent (if it was rebased as part of this restack) or the merge-base with
+// the parent is used. If refs is not nil, it is used to check ancestry.
+func (yas *YAS) restackUpstream(metadata BranchMetadata, oldParentTip string, refs *restackRefs) (string, error) {
+	checkAncestor := yas.git.IsAncestor
+	if refs != nil {
+		checkAncestor = refs.isAncestor
+	}
+
+	for _, candidate := range []string{metadata.BranchPoint, oldParentTip} {
+		if candidate == "" {
+			continue
+		}
+
+		isAncestor, err := checkAncestor(candidate, metadata.Name)
+		if err != nil {
+			// Commit might not exist anymore
+			log.Debug("Ignoring branch point", candidate, "for", metadata.Name+":", err)
+			continue
+		}
+
+		if isAncestor {
+			return candidate, nil
+		}
+	}
+
+	return yas.git.GetMergeBase(metadata.Parent, metadata.Name)
+}
+
+// repairRewrittenTrunkBranchPoints detects branches based on trunk whose branch
+// points are no longer reachable from trunk, which happens when trunk history
+// is rewritten (e.g. force-pushed). After confirming with the user, it
+// recomputes their branch points using the merge-base with the new trunk.
+func (yas *YAS) repairRewrittenTrunkBranchPoints(branchNames []string) error {
+	unreachable := Branches{}
+
+	for _, branchName := range branchNames {
+		metadata := yas.data.Branches.Get(branchName)
+		if metadata.Parent != yas.cfg.TrunkBranch || metadata.BranchPoint == "" {
+			continue
+		}
+
+		reachable, err := yas.git.IsAncestor(metadata.BranchPoint, yas.cfg.TrunkBranch)
+		if err != nil {
+			log.Debug("Branch point", metadata.BranchPoint, "of", branchName, "is invalid:", err)
+		}
+
+		if err != nil || !reachable {
+			unreachable = append(unreachable, metadata)
+		}
+	}
+
+	if len(unreachable) == 0 {
+		return nil
+	}
+
+	fmt.Printf("⚠️  %s history appears to have been rewritten. Branch points are no longer reachable for:\n", yas.cfg.TrunkBranch)
+	for _, branch := range unreachable {
+		fmt.Printf("    %s\n", branch.Name)
+	}
+
+	if !cliutil.Confirm(fmt.Sprintf("Recompute branch points using the merge-base with %s? [y/N]", yas.cfg.TrunkBranch), false) {
+		return errors.New("aborted")
+	}
+
+	for _, branch := range unreachable {
+		mergeBase, err := yas.git.GetMergeBase(yas.cfg.TrunkBranch, branch.Name)
+		if err != nil {
+			return fmt.Errorf("failed to recompute branch point of '%s': %w", branch.Name, err)
+		}
+
+		branch.BranchPoint = mergeBase
+		yas.data.Branches.Set(branch.Name, branch)
+	}
+
+	return yas.data.Save()
+}
+
+// backfillBranchPoints records the merge-base with the parent as the branch
+// point of branches that don't have one, e.g. branches tracked before branch
+// points were recorded. It returns the names of the branches that were
+// updated.
+func (yas *YAS) backfillBranchPoints(branchNames []string) ([]string, error) {
+	backfilled := []string{}
+
+	for _, branchName := range branchNames {
+		metadata := yas.data.Branches.Get(branchName)
+		if metadata.Parent == "" || metadata.BranchPoint != "" {
+			continue
+		}
+
+		mergeBase, err := yas.git.GetMergeBase(metadata.Parent, branchName)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compute branch point of '%s': %w", branchName, err)
+		}
+
+		metadata.BranchPoint = mergeBase
+		yas.data.Branches.Set(branchName, metadata)
+		backfilled = append(backfilled, branchName)
+	}
+
+	if len(backfilled) == 0 {
+		return nil, nil
+	}
+
+	return backfilled, yas.data.Save()
+}
+
+// repairStaleBranchPointsOf repairs the stale branch points of the branches
+// (see repairStaleBranchPoints).
+func (yas *YAS) repairStaleBranchPointsOf(branchNames []string) ([]string, error) {
+	refs, err := yas.git.BranchRefs()
+	if err != nil {
+		return nil, err
+	}
+
+	cache := loadDiffStatCache(gitFile(yas.cfg.RepoDirectory, diffStatCacheFile))
+	defer cache.save()
+
+	return yas.repairStaleBranchPoints(branchNames, refs, cache)
+}
+
+// repairStaleBranchPoints recomputes the branch points of branches that no
+// longer contain their recorded branch point, e.g. because they were rebased
+// outside of yas, using the merge-base with the parent. Otherwise the branch
+// point would give misleading restack status. It returns the names of the
+// branches that were updated.
+//
+// The repair is only made in memory, since read-only commands such as list
+// don't hold the repository lock. Commands that do save it.
+func (yas *YAS) repairStaleBranchPoints(branchNames []string, refs map[string]gitexec.BranchRef, cache *diffStatCache) ([]string, error) {
+	repaired := []string{}
+
+	for _, branchName := range branchNames {
+		metadata := yas.data.Branches.Get(branchName)
+		if metadata.Parent == "" || metadata.BranchPoint == "" {
+			continue
+		}
+
+		ref, exists := refs[branchName]
+		if !exists {
+			// The branch was deleted outside of yas
+			continue
+		}
+
+		isAncestor, err := cache.getIsAncestor(metadata.BranchPoint, ref.Hash, yas.git.IsAncestor)
+		if err != nil {
+			// The commit might not exist anymore
+			log.Debug("Branch point", metadata.BranchPoint, "of", branchName, "is invalid:", err)
+		}
+
+		if isAncestor {
+			continue
+		}
+
+		mergeBase, err := yas.git.GetMergeBase(metadata.Parent, branchName)
+		if err != nil {
+			log.Debug("Not repairing branch point of", branchName+":", err)
+			continue
+		}
+
+		log.Info("Branch point of", branchName, "is no longer in the branch, using the merge-base with", metadata.Parent, "instead:", mergeBase)
+
+		metadata.BranchPoint = mergeBase
+		yas.data.Branches.Set(branchName, metadata)
+		repaired = append(repaired, branchName)
+	}
+
+	return repaired, nil
+}
+
+// RestackConflict describes a branch that would conflict when restacked onto
+// its parent.
+type RestackConflict struct {
+	Branch string
+	Parent string
+	Files  []string
+}
+
+// RestackConflicts previews restacking the current branch and its
+// descendants and returns the branches that would conflict. The working tree
+// is not modified.
+func (yas *YAS) RestackConflicts() ([]RestackConflict, error) {
+	currentBranchName, err := yas.git.GetCurrentBranchName()
+	if err != nil {
+		return nil, err
+	}
+
+	conflicts := []RestackConflict{}
+
+	for _, branchName := range yas.restackQueue(currentBranchName) {
+		metadata := yas.data.Branches.Get(branchName)
+		if metadata.Parent == "" {
+			continue
+		}
+
+		files, err := yas.git.MergeConflicts(metadata.Parent, branchName)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check '%s' for conflicts: %w", branchName, err)
+		}
+
+		if len(files) > 0 {
+			conflicts = append(conflicts, RestackConflict{
+				Branch: branchName,
+				Parent: metadata.Parent,
+				Files:  files,
+			})
+		}
+	}
+
+	return conflicts, nil
+}
+
+// restackQueue returns the branches that are restacked when restacking from the
+// specified branch, i.e. the branch itself and all of its descendants.
+func (yas *YAS) restackQueue(branchName string) []string {
+	queue := []string{}
+	if branchName != yas.cfg.TrunkBranch {
+		queue = append(queue, branchName)
+	}
+
+	return append(queue, yas.descendants(branchName)...)
+}
+
+// restackChildren rebases the children of branchName, which were based on
+// oldTip, onto the current tip of branchName. Descendants are restacked
+// recursively.
+func (yas *YAS) restackChildren(branchName, oldTip string) error {
+	newTip, err := yas.git.GetHash(branchName)
+	if err != nil {
+		return err
+	}
+
+	for _, child := range yas.data.Branches.ToSlice().NotDeleted().WithParent(branchName).SortedByName() {
+		childTip, err := yas.git.GetHash(child.Name)
+		if err != nil {
+			return err
+		}
+
+		if err := yas.rebaseOnto(branchName, oldTip, child.Name); err != nil {
+			return fmt.Errorf("failed to rebase '%s' onto '%s': %w", child.Name, branchName, err)
+		}
+
+		child.BranchPoint = newTip
+		yas.data.Branches.Set(child.Name, child)
+
+		if err := yas.restackChildren(child.Name, childTip); err != nil {
+			return err
+		}
+	}
+
+	return yas.data.Save()
+}
+
+// checkClean returns an error if there are uncommitted changes that rebasing
+// the branches would fail on or disrupt: in the current worktree if inPlace is
+// true, and in any linked worktree that one of the branches is checked out in.
+func (yas *YAS) checkClean(branchNames []string, inPlace bool) error {
+	if inPlace {
+		dirty, err := yas.git.IsDirty()
+		if err != nil {
+			return err
+		}
+
+		if dirty {
+			return errors.New("there are uncommitted changes (hint: commit or stash them, or use --autostash or --force-dirty)")
+		}
+	}
+
+	currentBranchName, err := yas.git.GetCurrentBranchName()
+	if err != nil {
+		return err
+	}
+
+	worktrees, err := yas.BranchWorktrees()
+	if err != nil {
+		return err
+	}
+
+	for _, branchName := range branchNames {
+		worktree, ok := worktrees[branchName]
+		if !ok || branchName == currentBranchName {
+			continue
+		}
+
+		dirty, err := gitexec.WithRepo(worktree.Path).IsDirty()
+		if err != nil {
+			return err
+		}
+
+		if dirty {
+			return fmt.Errorf("'%s' is checked out in worktree %s, which has uncommitted changes (hint: commit or stash them there, or use --force-dirty)", branchName, worktree.Path)
+		}
+	}
+
+	return nil
+}
+
+// stash stashes local modifications, if there are any. It returns true if
+// changes were stashed.
+func (yas *YAS) stash() (bool, error) {
+	dirty, err := yas.git.IsDirty()
+	if err != nil {
+		return false, err
+	}
+
+	if !dirty {
+		return false, nil
+	}
+
+	if err := yas.git.StashPush("yas autostash"); err != nil {
+		return false, fmt.Errorf("failed to stash changes: %w", err)
+	}
+
+	fmt.Println("Stashed local changes")
+
+	return true, nil
+}
+
+// unstash restores changes stashed by stash. It is intended to be deferred;
+// if the operation failed (*err is not nil), the stash is left in place since
+// the repository may be mid-rebase.
+func (yas *YAS) unstash(err *error) {
+	if *err != nil {
+		fmt.Println("Local changes were stashed (hint: run `git stash pop` when done)")
+		return
+	}
+
+	if popErr := yas.git.StashPop(); popErr != nil {
+		*err = fmt.Errorf("failed to restore stashed changes (hint: run `git stash pop`): %w", popErr)
+		return
+	}
+
+	fmt.Println("Restored stashed changes")
+}
@@ -0,0 +1,65 @@
+package yas
+
+// Result is a machine-readable description of what a command did, e.g. for
+// wrapper tooling and bots.
+type Result struct {
+	Command string `json:"command"`
+	Success bool   `json:"success"`
+	Error   string `json:"error,omitempty"`
+
+	// Steps are the outcomes for each branch of operations across several
+	// branches, e.g. whether each branch was rebased, pushed or skipped.
+	Steps []Step `json:"steps"`
+
+	// Events are the operations that completed, as sent to plugins.
+	Events []Event `json:"events"`
+}
+
+// Step is the outcome of an operation on a single branch.
+type Step struct {
+	Branch string `json:"branch"`
+	Result string `json:"result"`
+	Error  string `json:"error,omitempty"`
+}
+
+// recording is the result that steps and events are recorded to, if any.
+var recording *Result
+
+// RecordResult starts recording the steps and events of all operations to a
+// new result for the command, until StopRecording is called.
+func RecordResult(command string) *Result {
+	recording = &Result{
+		Command: command,
+		Steps:   []Step{},
+		Events:  []Event{},
+	}
+
+	return recording
+}
+
+// StopRecording stops recording to the result returned by RecordResult.
+func StopRecording() {
+	recording = nil
+}
+
+// recordStep records the outcome of an operation on the branch, if a result
+// is being recorded.
+func recordStep(branchName, outcome string, err error) {
+	if recording == nil {
+		return
+	}
+
+	step := Step{Branch: branchName, Result: outcome}
+	if err != nil {
+		step.Error = err.Error()
+	}
+
+	recording.Steps = append(recording.Steps, step)
+}
+
+// recordEvent records the event, if a result is being recorded.
+func recordEvent(event Event) {
+	if recording != nil {
+		recording.Events = append(recording.Events, event)
+	}
+}
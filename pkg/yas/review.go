@@ -0,0 +1,210 @@
+package yas
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// reviewQuery fetches the latest reviews and the review threads of a PR by its
+// node ID.
+const reviewQuery = `query($id: ID!) {
+  node(id: $id) {
+    ... on PullRequest {
+      latestReviews(first: 100) {
+        nodes { state body author { login } }
+      }
+      reviewThreads(first: 100) {
+        nodes {
+          isResolved
+          isOutdated
+          path
+          line
+          originalLine
+          comments(first: 1) {
+            nodes { body author { login } }
+          }
+        }
+      }
+    }
+  }
+}`
+
+// ReviewComment is a review requesting changes, or the first comment of an
+// unresolved review thread.
+type ReviewComment struct {
+	Author string
+
+	// Path and Line are the location of a review thread comment. They are
+	// empty for reviews.
+	Path string
+	Line int
+
+	// Outdated is true if the thread is on code that has since changed.
+	Outdated bool
+
+	// Body is the first line of the comment.
+	Body string
+}
+
+// PullRequestReview is the review feedback on the PR of a branch that still
+// needs attention.
+type PullRequestReview struct {
+	Branch string
+
+	// URL is the URL of the PR, or empty if the branch has no open PR.
+	URL string
+
+	ReviewDecision string
+
+	// ChangesRequested are the reviews of reviewers whose latest review
+	// requests changes.
+	ChangesRequested []ReviewComment
+
+	// Threads are the unresolved review threads.
+	Threads []ReviewComment
+}
+
+type ReviewOptions struct {
+	// Branch is the branch whose PR to summarize (default: current branch).
+	Branch string
+
+	// Stack summarizes the PRs of every branch in the stack containing the
+	// branch, or the stack with the name.
+	Stack bool
+}
+
+// Reviews fetches the requested changes and unresolved review threads of the
+// PRs of the branch or stack.
+func (yas *YAS) Reviews(opts ReviewOptions) ([]PullRequestReview, error) {
+	branchName := opts.Branch
+	if opts.Stack {
+		branchName = yas.resolveStack(branchName)
+	}
+
+	branchName, err := yas.trackedBranchOrCurrent(branchName)
+	if err != nil {
+		return nil, err
+	}
+
+	branchNames := []string{branchName}
+	if opts.Stack {
+		branchNames = yas.stack(branchName)
+	}
+
+	if err := yas.RefreshRemoteStatus(branchNames...); err != nil {
+		return nil, err
+	}
+
+	reviews := []PullRequestReview{}
+	for _, name := range branchNames {
+		pr := yas.data.Branches.Get(name).GitHubPullRequest
+
+		review := PullRequestReview{Branch: name}
+		if pr.State == "OPEN" {
+			review, err = yas.fetchPullRequestReview(name, pr)
+			if err != nil {
+				return nil, fmt.Errorf("failed to fetch reviews of '%s': %w", name, err)
+			}
+		}
+
+		reviews = append(reviews, review)
+	}
+
+	return reviews, nil
+}
+
+func (yas *YAS) fetchPullRequestReview(branchName string, pr PullRequestMetadata) (PullRequestReview, error) {
+	review := PullRequestReview{
+		Branch:         branchName,
+		URL:            pr.URL,
+		ReviewDecision: pr.ReviewDecision,
+	}
+
+	b, err := yas.gh("api", "graphql", "-f", "query="+reviewQuery, "-F", "id="+pr.ID)
+	if err != nil {
+		return review, err
+	}
+
+	type author struct {
+		Login string `json:"login"`
+	}
+
+	data := struct {
+		Data struct {
+			Node struct {
+				LatestReviews struct {
+					Nodes []struct {
+						State  string `json:"state"`
+						Body   string `json:"body"`
+						Author author `json:"author"`
+					} `json:"nodes"`
+				} `json:"latestReviews"`
+				ReviewThreads struct {
+					Nodes []struct {
+						IsResolved   bool   `json:"isResolved"`
+						IsOutdated   bool   `json:"isOutdated"`
+						Path         string `json:"path"`
+						Line         int    `json:"line"`
+						OriginalLine int    `json:"originalLine"`
+						Comments     struct {
+							Nodes []struct {
+								Body   string `json:"body"`
+								Author author `json:"author"`
+							} `json:"nodes"`
+						} `json:"comments"`
+					} `json:"nodes"`
+				} `json:"reviewThreads"`
+			} `json:"node"`
+		} `json:"data"`
+	}{}
+
+	if err := json.Unmarshal(b, &data); err != nil {
+		return review, err
+	}
+
+	for _, r := range data.Data.Node.LatestReviews.Nodes {
+		if r.State != "CHANGES_REQUESTED" {
+			continue
+		}
+
+		review.ChangesRequested = append(review.ChangesRequested, ReviewComment{
+			Author: r.Author.Login,
+			Body:   firstLine(r.Body),
+		})
+	}
+
+	for _, thread := range data.Data.Node.ReviewThreads.Nodes {
+		if thread.IsResolved || len(thread.Comments.Nodes) == 0 {
+			continue
+		}
+
+		line := thread.Line
+		if line == 0 {
+			// Outdated threads no longer have a line in the current diff
+			line = thread.OriginalLine
+		}
+
+		comment := thread.Comments.Nodes[0]
+		review.Threads = append(review.Threads, ReviewComment{
+			Author:   comment.Author.Login,
+			Path:     thread.Path,
+			Line:     line,
+			Outdated: thread.IsOutdated,
+			Body:     firstLine(comment.Body),
+		})
+	}
+
+	return review, nil
+}
+
+// firstLine returns the first non-empty line of the text.
+func firstLine(text string) string {
+	for _, line := range strings.Split(text, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			return line
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,184 @@
+package yas
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/dansimau/yas/pkg/cliutil"
+	"github.com/dansimau/yas/pkg/gitexec"
+)
+
+// rewordSeparator marks the start of each commit message in the reword editor.
+const rewordSeparator = ">>> commit "
+
+type RewordOptions struct {
+	// UpdatePRTitle updates the title of the branch's PR if the subject of
+	// the first commit changed.
+	UpdatePRTitle bool
+}
+
+// Reword opens the editor with the messages of the commits on the current
+// branch, rewrites the commits with the edited messages and then restacks any
+// descendants.
+func (yas *YAS) Reword(opts RewordOptions) error {
+	branchName, err := yas.git.GetCurrentBranchName()
+	if err != nil {
+		return err
+	}
+
+	if branchName == yas.cfg.TrunkBranch {
+		return errors.New("cannot reword commits on trunk branch")
+	}
+
+	metadata := yas.data.Branches.Get(branchName)
+
+	parent := metadata.Parent
+	if parent == "" {
+		parent = yas.cfg.TrunkBranch
+	}
+
+	// Use the merge-base so the branch is not moved onto a newer parent
+	base, err := yas.git.GetMergeBase(parent, branchName)
+	if err != nil {
+		return err
+	}
+
+	commits, err := yas.git.Commits(base, branchName)
+	if err != nil {
+		return err
+	}
+
+	if len(commits) == 0 {
+		return fmt.Errorf("branch '%s' has no commits to reword", branchName)
+	}
+
+	if cliutil.NonInteractive() {
+		return fmt.Errorf("reword needs an editor: %w", cliutil.ErrNonInteractive)
+	}
+
+	edited, err := cliutil.EditText(yas.git.Editor(), rewordTemplate(commits))
+	if err != nil {
+		return err
+	}
+
+	messages, err := parseRewordMessages(edited, len(commits))
+	if err != nil {
+		return err
+	}
+
+	tempDir, err := os.MkdirTemp("", "yas-reword-*")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tempDir)
+
+	changed := false
+	todo := []string{}
+
+	for i, commit := range commits {
+		todo = append(todo, "pick "+commit.Hash)
+
+		if messages[i] == commit.Message {
+			continue
+		}
+
+		messagePath := path.Join(tempDir, fmt.Sprintf("message-%d", i))
+		if err := os.WriteFile(messagePath, []byte(messages[i]), 0o644); err != nil {
+			return err
+		}
+
+		todo = append(todo, "exec "+yas.git.AmendCommand(messagePath))
+		changed = true
+	}
+
+	if !changed {
+		fmt.Println("No commit messages changed")
+		return nil
+	}
+
+	todoPath := path.Join(tempDir, "todo")
+	if err := os.WriteFile(todoPath, []byte(strings.Join(todo, "\n")+"\n"), 0o644); err != nil {
+		return err
+	}
+
+	oldTip, err := yas.git.GetHash(branchName)
+	if err != nil {
+		return err
+	}
+
+	if err := yas.git.RebaseWithTodo(base, todoPath); err != nil {
+		return fmt.Errorf("failed to reword commits: %w", err)
+	}
+
+	if err := yas.restackChildren(branchName, oldTip); err != nil {
+		return err
+	}
+
+	if err := yas.git.Checkout(branchName); err != nil {
+		return err
+	}
+
+	fmt.Printf("Reworded commits on '%s'\n", branchName)
+
+	oldSubject, _, _ := strings.Cut(commits[0].Message, "\n")
+	newSubject, _, _ := strings.Cut(messages[0], "\n")
+
+	if opts.UpdatePRTitle && oldSubject != newSubject && metadata.GitHubPullRequest.State == "OPEN" {
+		if err := yas.ghRun("pr", "edit", metadata.GitHubPullRequest.ref(branchName), "--title", newSubject); err != nil {
+			return fmt.Errorf("failed to update PR title: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// rewordTemplate generates the text to show in the editor for rewording the
+// specified commits.
+func rewordTemplate(commits []gitexec.Commit) string {
+	lines := []string{
+		"# Edit the commit messages below. Lines starting with '#' are ignored.",
+		"# Do not remove or reorder the '" + strings.TrimSpace(rewordSeparator) + "' lines.",
+		"",
+	}
+
+	for _, commit := range commits {
+		lines = append(lines, rewordSeparator+commit.Hash[:7], commit.Message, "")
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// parseRewordMessages parses the edited reword template back into a list of
+// commit messages.
+func parseRewordMessages(text string, expectedCount int) ([]string, error) {
+	messages := []string{}
+
+	for _, line := range strings.Split(text, "\n") {
+		if strings.HasPrefix(line, rewordSeparator) {
+			messages = append(messages, "")
+			continue
+		}
+
+		if len(messages) == 0 {
+			continue
+		}
+
+		messages[len(messages)-1] += line + "\n"
+	}
+
+	if len(messages) != expectedCount {
+		return nil, fmt.Errorf("expected %d commit messages but found %d", expectedCount, len(messages))
+	}
+
+	for i := range messages {
+		messages[i] = strings.TrimSpace(messages[i])
+		if messages[i] == "" {
+			return nil, errors.New("aborting due to empty commit message")
+		}
+	}
+
+	return messages, nil
+}
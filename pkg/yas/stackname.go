@@ -0,0 +1,118 @@
+package yas
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SetStackName names the stack containing the branch (default: the current
+// branch), so that it can be referred to by name instead of by one of its
+// branches. An empty name removes the stack's name.
+func (yas *YAS) SetStackName(branchName, name string) error {
+	branchName, err := yas.trackedBranchOrCurrent(branchName)
+	if err != nil {
+		return err
+	}
+
+	if strings.ContainsAny(name, " \t\n") {
+		return fmt.Errorf("invalid stack name '%s': must not contain whitespace", name)
+	}
+
+	root := yas.stackRoot(branchName)
+
+	if name != "" {
+		if other := yas.namedStackRoot(name); other != "" && other != root {
+			return fmt.Errorf("stack name '%s' is already used by the stack rooted at '%s'", name, other)
+		}
+
+		if yas.isTracked(name) {
+			return fmt.Errorf("stack name '%s' is the name of a tracked branch", name)
+		}
+	}
+
+	stack := yas.data.Stacks[root]
+	stack.Name = name
+	yas.data.Stacks[root] = stack
+
+	if err := yas.data.Save(); err != nil {
+		return err
+	}
+
+	if name == "" {
+		fmt.Printf("Removed the name of the stack rooted at '%s'\n", root)
+	} else {
+		fmt.Printf("Named the stack rooted at '%s': %s\n", root, name)
+	}
+
+	return nil
+}
+
+// StackName returns the name of the stack containing the branch (default: the
+// current branch), or an empty string if it has no name.
+func (yas *YAS) StackName(branchName string) (string, error) {
+	branchName, err := yas.trackedBranchOrCurrent(branchName)
+	if err != nil {
+		return "", err
+	}
+
+	return yas.data.Stacks[yas.stackRoot(branchName)].Name, nil
+}
+
+// trackedBranchOrCurrent returns the branch, or the current branch if it is
+// empty, and returns an error if it is not tracked.
+func (yas *YAS) trackedBranchOrCurrent(branchName string) (string, error) {
+	if branchName == "" {
+		currentBranch, err := yas.git.GetCurrentBranchName()
+		if err != nil {
+			return "", err
+		}
+
+		branchName = currentBranch
+	}
+
+	if !yas.isTracked(branchName) {
+		return "", fmt.Errorf("branch '%s' is not tracked (hint: run `yas add`)", branchName)
+	}
+
+	return branchName, nil
+}
+
+// namedStackRoot returns the root branch of the stack with the name, or an
+// empty string if there is none.
+func (yas *YAS) namedStackRoot(name string) string {
+	for root, stack := range yas.data.Stacks {
+		if stack.Name == name && yas.isTracked(root) {
+			return root
+		}
+	}
+
+	return ""
+}
+
+// resolveStack returns the root branch of the stack if ref is the name of a
+// stack, or ref itself otherwise (i.e. a branch in the stack).
+func (yas *YAS) resolveStack(ref string) string {
+	if ref == "" || yas.isTracked(ref) {
+		return ref
+	}
+
+	if root := yas.namedStackRoot(ref); root != "" {
+		return root
+	}
+
+	return ref
+}
+
+// stackHeaders returns the names of named stacks, by the name of their root
+// branch, to show above the root branch in the list.
+func (yas *YAS) stackHeaders() map[string]string {
+	headers := map[string]string{}
+
+	for root, stack := range yas.data.Stacks {
+		if stack.Name != "" && yas.isTracked(root) {
+			headers[root] = "📚 " + stack.Name
+		}
+	}
+
+	return headers
+}
@@ -0,0 +1,97 @@
+package yas
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/dansimau/yas/pkg/log"
+)
+
+type Stats struct {
+	OpenStacks         int
+	AverageStackDepth  float64
+	MergedPRs          int
+	AverageTimeToMerge time.Duration
+}
+
+type mergedPullRequest struct {
+	HeadRefName string
+	CreatedAt   time.Time
+	MergedAt    time.Time
+}
+
+// Stats summarises the current stacks and the PRs for yas-managed branches
+// that were merged within the specified period.
+func (yas *YAS) Stats(period time.Duration) (*Stats, error) {
+	stats := &Stats{}
+
+	branches := yas.data.Branches.ToSlice().NotDeleted()
+	roots := branches.WithParent(yas.cfg.TrunkBranch)
+
+	totalDepth := 0
+	for _, root := range roots {
+		stackDepth := 0
+		for _, name := range yas.stack(root.Name) {
+			stackDepth = max(stackDepth, yas.depth(name))
+		}
+
+		totalDepth += stackDepth
+	}
+
+	stats.OpenStacks = len(roots)
+	if len(roots) > 0 {
+		stats.AverageStackDepth = float64(totalDepth) / float64(len(roots))
+	}
+
+	mergedPullRequests, err := yas.fetchMergedPullRequests(time.Now().Add(-period))
+	if err != nil {
+		return nil, err
+	}
+
+	var totalTimeToMerge time.Duration
+	for _, pr := range mergedPullRequests {
+		// Only count PRs for branches managed by yas
+		if !yas.data.Branches.Exists(pr.HeadRefName) {
+			continue
+		}
+
+		stats.MergedPRs++
+		totalTimeToMerge += pr.MergedAt.Sub(pr.CreatedAt)
+	}
+
+	if stats.MergedPRs > 0 {
+		stats.AverageTimeToMerge = totalTimeToMerge / time.Duration(stats.MergedPRs)
+	}
+
+	return stats, nil
+}
+
+// depth returns the number of branches between the specified branch and trunk,
+// including the branch itself.
+func (yas *YAS) depth(branchName string) int {
+	depth := 0
+	seen := map[string]bool{}
+
+	for name := branchName; name != "" && name != yas.cfg.TrunkBranch && !seen[name]; name = yas.data.Branches.Get(name).Parent {
+		seen[name] = true
+		depth++
+	}
+
+	return depth
+}
+
+func (yas *YAS) fetchMergedPullRequests(since time.Time) ([]mergedPullRequest, error) {
+	log.Info("Fetching merged PRs since", since.Format(time.DateOnly))
+
+	b, err := yas.gh("pr", "list", "--state", "merged", "--limit", "500", "--search", "merged:>="+since.Format(time.DateOnly), "--json", "headRefName,createdAt,mergedAt")
+	if err != nil {
+		return nil, err
+	}
+
+	data := []mergedPullRequest{}
+	if err := json.Unmarshal(b, &data); err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
@@ -0,0 +1,115 @@
+package yas
+
+import (
+	"fmt"
+)
+
+// Status prints a summary of the current branch: its parent, whether it needs
+// restacking, its PR and the worktree it is checked out in.
+func (yas *YAS) Status() error {
+	branchName, err := yas.git.GetCurrentBranchName()
+	if err != nil {
+		return err
+	}
+
+	if branchName == "HEAD" {
+		fmt.Println("HEAD is detached")
+		return nil
+	}
+
+	fmt.Printf("On branch %s\n", branchName)
+
+	metadata := yas.data.Branches.Get(branchName)
+
+	switch {
+	case branchName == yas.cfg.TrunkBranch:
+		fmt.Println("Trunk branch")
+	case !yas.isTracked(branchName):
+		fmt.Println("Not tracked (hint: run `yas add`)")
+	default:
+		fmt.Printf("Parent: %s\n", metadata.Parent)
+
+		if _, err := yas.repairStaleBranchPointsOf([]string{branchName}); err != nil {
+			return err
+		}
+
+		metadata = yas.data.Branches.Get(branchName)
+
+		parentTip, err := yas.git.GetHash(metadata.Parent)
+		if err != nil {
+			return err
+		}
+
+		needsRestack, err := yas.needsRestack(metadata, parentTip)
+		if err != nil {
+			return err
+		}
+
+		if needsRestack {
+			fmt.Println("Needs restack (hint: run `yas restack`)")
+		}
+
+		counts, pushed, err := yas.branchAheadBehind(branchName)
+		if err != nil {
+			return err
+		}
+
+		if pushed {
+			label := aheadBehindLabel(counts)
+			if label == "" {
+				label = "up to date"
+			}
+
+			fmt.Printf("Remote: origin/%s (%s)\n", branchName, label)
+
+			if counts.Behind > 0 {
+				fmt.Println("⚠️  Behind remote (hint: someone else may have pushed to the branch)")
+			}
+		}
+
+		if needsSubmit(metadata, counts) {
+			fmt.Printf("Needs submit: %s (hint: run `yas submit`)\n", unpushedCommits(counts.Ahead))
+		}
+
+		if metadata.GitHubPullRequest.URL != "" {
+			fmt.Printf("PR: %s (%s)\n", metadata.GitHubPullRequest.URL, metadata.GitHubPullRequest.State)
+		}
+	}
+
+	worktrees, err := yas.BranchWorktrees()
+	if err != nil {
+		return err
+	}
+
+	if worktree, ok := worktrees[branchName]; ok {
+		fmt.Printf("Worktree: %s\n", worktree.Path)
+	}
+
+	return nil
+}
+
+// needsRestack returns true if the branch is not on top of parentTip, the
+// current tip of its parent. For remote parents, the tip of the
+// remote-tracking branch is used, so it is only as up to date as the last
+// fetch. Branches whose PRs were merged never need restacking, and their
+// children always do, to move them off the merged branch.
+func (yas *YAS) needsRestack(metadata BranchMetadata, parentTip string) (bool, error) {
+	if isMerged(metadata) {
+		return false, nil
+	}
+
+	if isMerged(yas.data.Branches.Get(metadata.Parent)) {
+		return true, nil
+	}
+
+	if metadata.BranchPoint == parentTip {
+		return false, nil
+	}
+
+	onTop, err := yas.git.IsAncestor(parentTip, metadata.Name)
+	if err != nil {
+		return false, err
+	}
+
+	return !onTop, nil
+}
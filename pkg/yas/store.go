@@ -2,37 +2,115 @@ package yas
 
 import (
 	"encoding/json"
+	"fmt"
 	"os"
+	"path"
+	"strconv"
 	"sync"
+	"time"
 
 	"github.com/dansimau/yas/pkg/fsutil"
+	"github.com/dansimau/yas/pkg/log"
 )
 
+// currentSchemaVersion is the version of the state file format written by
+// this version of yas.
+const currentSchemaVersion = 2
+
+// migrations upgrade the raw state data from each schema version to the next:
+// migrations[i] migrates from version i to i+1.
+var migrations = []func(data map[string]any) error{
+	// 0: legacy state files without a schema version. The format is
+	// otherwise unchanged.
+	func(data map[string]any) error { return nil },
+
+	// 1: PR numbers were parsed from PR URLs instead of being stored.
+	func(data map[string]any) error {
+		branches, _ := data["branches"].(map[string]any)
+		for _, branch := range branches {
+			if branch, ok := branch.(map[string]any); ok {
+				migratePullRequestNumber(branch["GitHubPullRequest"])
+			}
+		}
+
+		stacks, _ := data["stacks"].(map[string]any)
+		for _, stack := range stacks {
+			if stack, ok := stack.(map[string]any); ok {
+				migratePullRequestNumber(stack["CombinedPullRequest"])
+			}
+		}
+
+		return nil
+	},
+}
+
+// migratePullRequestNumber sets the number of the raw PR metadata from its
+// URL, e.g. https://github.com/owner/repo/pull/123, if it doesn't have one.
+func migratePullRequestNumber(pr any) {
+	metadata, ok := pr.(map[string]any)
+	if !ok || metadata["Number"] != nil {
+		return
+	}
+
+	url, _ := metadata["URL"].(string)
+	if number, err := strconv.Atoi(path.Base(url)); err == nil && number > 0 {
+		metadata["Number"] = number
+	}
+}
+
 type yasData struct {
-	Branches *branchMap `json:"branches"`
+	SchemaVersion int `json:"schemaVersion"`
+
+	Branches *branchMap               `json:"branches"`
+	Stacks   map[string]StackMetadata `json:"stacks,omitempty"`
+
+	// PreviousBranch is the branch that was checked out before yas last
+	// switched branches.
+	PreviousBranch string `json:"previousBranch,omitempty"`
+
+	// Restack is the progress of a restack that stopped due to conflicts.
+	Restack *restackState `json:"restack,omitempty"`
 }
 type yasDatabase struct {
 	*yasData
 
 	filePath string
+
+	// saveMu serializes saves, which happen concurrently when refreshing PR
+	// status
+	saveMu sync.Mutex
 }
 
+// Save writes the state file. The file is replaced atomically so that it's
+// never left partially written.
 func (d *yasDatabase) Save() error {
+	d.saveMu.Lock()
+	defer d.saveMu.Unlock()
+
 	b, err := json.MarshalIndent(d.yasData, "", "  ")
 	if err != nil {
 		return err
 	}
 
-	return os.WriteFile(d.filePath, b, 0o644)
+	log.Debug("Writing state file", d.filePath)
+
+	tmpPath := d.filePath + ".tmp"
+	if err := os.WriteFile(tmpPath, b, 0o644); err != nil {
+		return err
+	}
+
+	return os.Rename(tmpPath, d.filePath)
 }
 
 func loadData(filePath string) (*yasDatabase, error) {
 	db := &yasDatabase{
 		filePath: filePath,
 		yasData: &yasData{
+			SchemaVersion: currentSchemaVersion,
 			Branches: &branchMap{
 				data: map[string]BranchMetadata{},
 			},
+			Stacks: map[string]StackMetadata{},
 		},
 	}
 
@@ -45,13 +123,73 @@ func loadData(filePath string) (*yasDatabase, error) {
 		return nil, err
 	}
 
+	b, migrated, err := migrateData(filePath, b)
+	if err != nil {
+		return nil, err
+	}
+
 	if err := json.Unmarshal(b, &db.yasData); err != nil {
 		return nil, err
 	}
 
+	if db.Stacks == nil {
+		db.Stacks = map[string]StackMetadata{}
+	}
+
+	if migrated {
+		if err := db.Save(); err != nil {
+			return nil, err
+		}
+	}
+
 	return db, nil
 }
 
+// migrateData upgrades the state file contents to the current schema
+// version. The original file is backed up before it is migrated. It returns
+// the migrated contents and whether any migrations were applied.
+func migrateData(filePath string, b []byte) ([]byte, bool, error) {
+	data := map[string]any{}
+	if err := json.Unmarshal(b, &data); err != nil {
+		return nil, false, err
+	}
+
+	version := 0
+	if v, ok := data["schemaVersion"].(float64); ok {
+		version = int(v)
+	}
+
+	if version > currentSchemaVersion {
+		return nil, false, fmt.Errorf("state file %s has schema version %d but this version of yas only supports up to version %d (hint: upgrade yas)", filePath, version, currentSchemaVersion)
+	}
+
+	if version == currentSchemaVersion {
+		return b, false, nil
+	}
+
+	backupPath := fmt.Sprintf("%s.v%d.bak", filePath, version)
+	if err := os.WriteFile(backupPath, b, 0o644); err != nil {
+		return nil, false, fmt.Errorf("failed to back up state file: %w", err)
+	}
+
+	for ; version < currentSchemaVersion; version++ {
+		log.Info("Migrating state file from schema version", version, "to", version+1)
+
+		if err := migrations[version](data); err != nil {
+			return nil, false, fmt.Errorf("failed to migrate state file from schema version %d: %w", version, err)
+		}
+	}
+
+	data["schemaVersion"] = currentSchemaVersion
+
+	b, err := json.Marshal(data)
+	if err != nil {
+		return nil, false, err
+	}
+
+	return b, true, nil
+}
+
 type branchMap struct {
 	sync.RWMutex
 	data map[string]BranchMetadata
@@ -86,6 +224,21 @@ func (m *branchMap) Set(name string, data BranchMetadata) {
 	m.Lock()
 	defer m.Unlock()
 
+	// Record when we first started tracking the branch
+	if data.Created.IsZero() {
+		data.Created = time.Now()
+	}
+
+	data.Updated = time.Now()
+
+	m.data[name] = data
+}
+
+// restore sets the branch metadata as-is, without updating timestamps.
+func (m *branchMap) restore(name string, data BranchMetadata) {
+	m.Lock()
+	defer m.Unlock()
+
 	m.data[name] = data
 }
 
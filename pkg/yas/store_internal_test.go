@@ -0,0 +1,53 @@
+package yas
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"testing"
+
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/assert/cmp"
+)
+
+func TestLoadDataMigratesLegacyStateFile(t *testing.T) {
+	filePath := path.Join(t.TempDir(), ".yasstate")
+	legacy := `{"branches":{"topic-a":{"Parent":"main"}}}`
+	assert.NilError(t, os.WriteFile(filePath, []byte(legacy), 0o644))
+
+	db, err := loadData(filePath)
+	assert.NilError(t, err)
+	assert.Equal(t, db.SchemaVersion, currentSchemaVersion)
+	assert.Equal(t, db.Branches.Get("topic-a").Parent, "main")
+
+	backup, err := os.ReadFile(filePath + ".v0.bak")
+	assert.NilError(t, err)
+	assert.Equal(t, string(backup), legacy)
+
+	b, err := os.ReadFile(filePath)
+	assert.NilError(t, err)
+	assert.Assert(t, cmp.Contains(string(b), fmt.Sprintf(`"schemaVersion": %d`, currentSchemaVersion)))
+}
+
+func TestLoadDataMigratesPullRequestNumbers(t *testing.T) {
+	filePath := path.Join(t.TempDir(), ".yasstate")
+	state := `{"schemaVersion":1,"branches":{` +
+		`"topic-a":{"Parent":"main","GitHubPullRequest":{"ID":"PR_1","URL":"https://github.example.com/ghe/test/test/pull/12"}},` +
+		`"topic-b":{"Parent":"topic-a"}` +
+		`},"stacks":{"topic-a":{"CombinedPullRequest":{"ID":"PR_2","URL":"https://github.com/test/test/pull/34"}}}}`
+	assert.NilError(t, os.WriteFile(filePath, []byte(state), 0o644))
+
+	db, err := loadData(filePath)
+	assert.NilError(t, err)
+	assert.Equal(t, db.Branches.Get("topic-a").GitHubPullRequest.Number, 12)
+	assert.Equal(t, db.Branches.Get("topic-b").GitHubPullRequest.Number, 0)
+	assert.Equal(t, db.Stacks["topic-a"].CombinedPullRequest.Number, 34)
+}
+
+func TestLoadDataRefusesNewerSchemaVersion(t *testing.T) {
+	filePath := path.Join(t.TempDir(), ".yasstate")
+	assert.NilError(t, os.WriteFile(filePath, []byte(`{"schemaVersion":999,"branches":{}}`), 0o644))
+
+	_, err := loadData(filePath)
+	assert.ErrorContains(t, err, "schema version 999")
+}
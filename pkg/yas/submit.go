@@ -0,0 +1,545 @@
+package yas
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+type SubmitOptions struct {
+	// Stack submits every branch in the current stack, rather than just the
+	// current branch.
+	Stack bool
+
+	// Combined submits the whole stack as a single PR from the stack tip to
+	// trunk. Once a stack has been submitted as combined, it is always
+	// submitted that way.
+	Combined bool
+
+	// AllowDivergence submits branches even if they contain commits that
+	// belong to other branches.
+	AllowDivergence bool
+
+	// Strict fails instead of warning when a PR exceeds the configured
+	// limits.
+	Strict bool
+
+	// Range submits only a contiguous range of branches in the stack,
+	// specified as "<base>..<top>" (both inclusive), where base is an
+	// ancestor of top.
+	Range string
+
+	// Branch selects the branch (or with Stack, the stack containing the
+	// branch, or the stack with the name) to submit. Defaults to the current
+	// branch.
+	Branch string
+
+	// Draft, if set, creates new PRs as drafts (true) or ready for review
+	// (false), overriding the defaultDraft config, and converts existing open
+	// PRs to match.
+	Draft *bool
+
+	// Force submits the branches even if their stack is marked as WIP.
+	Force bool
+}
+
+// createDraft returns true if new PRs should be created as drafts.
+func (opts SubmitOptions) createDraft(cfg Config) bool {
+	if opts.Draft != nil {
+		return *opts.Draft
+	}
+
+	return cfg.CreateDraftPRs()
+}
+
+// draftChange returns true if the draft state of an existing PR should be
+// changed.
+func (opts SubmitOptions) draftChange(pr PullRequestMetadata) bool {
+	return opts.Draft != nil && *opts.Draft != pr.IsDraft
+}
+
+// SubmitResult is the outcome of submitting a single branch.
+type SubmitResult struct {
+	Branch  string
+	Err     error
+	Skipped bool
+}
+
+func (yas *YAS) Submit(opts SubmitOptions) ([]SubmitResult, error) {
+	currentBranch, err := yas.git.GetCurrentBranchName()
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.Stack {
+		opts.Branch = yas.resolveStack(opts.Branch)
+	}
+
+	if opts.Branch != "" {
+		if !yas.isTracked(opts.Branch) {
+			return nil, fmt.Errorf("branch '%s' is not tracked (hint: run `yas add`)", opts.Branch)
+		}
+
+		currentBranch = opts.Branch
+	}
+
+	if currentBranch == "HEAD" {
+		return nil, errors.New("cannot submit in detached HEAD state")
+	}
+
+	if !opts.Force {
+		if err := yas.checkNotWIP(currentBranch, "submitted", "--force"); err != nil {
+			return nil, err
+		}
+	}
+
+	if opts.Range != "" {
+		if opts.Combined || yas.data.Stacks[yas.stackRoot(currentBranch)].Combined {
+			return nil, errors.New("a range can't be submitted as a combined PR")
+		}
+
+		branchNames, err := yas.submitRange(currentBranch, opts.Range)
+		if err != nil {
+			return nil, err
+		}
+
+		return yas.submitBranches(branchNames, opts), nil
+	}
+
+	if opts.Combined || yas.data.Stacks[yas.stackRoot(currentBranch)].Combined {
+		tip, err := yas.submitCombined(currentBranch, opts)
+		return []SubmitResult{{
+			Branch: tip,
+			Err:    err,
+		}}, nil
+	}
+
+	if !opts.Stack {
+		err := yas.submitBranch(currentBranch, opts)
+		if err != nil {
+			recordStep(currentBranch, "failed", err)
+		} else {
+			recordStep(currentBranch, "pushed", nil)
+		}
+
+		return []SubmitResult{{
+			Branch: currentBranch,
+			Err:    err,
+		}}, nil
+	}
+
+	return yas.submitBranches(yas.stack(currentBranch), opts), nil
+}
+
+// submitBranches submits each of the branches, which must be ordered parents
+// first. Branches whose parent failed to submit are skipped.
+func (yas *YAS) submitBranches(branchNames []string, opts SubmitOptions) []SubmitResult {
+	results := []SubmitResult{}
+	failed := map[string]bool{}
+
+	// Someone else's PR
+	branchNames = slices.DeleteFunc(slices.Clone(branchNames), func(name string) bool {
+		return yas.data.Branches.Get(name).External
+	})
+
+	yas.progress = newProgress(len(branchNames), 0)
+	defer func() { yas.progress = nil }()
+
+	for _, branchName := range branchNames {
+		metadata := yas.data.Branches.Get(branchName)
+
+		// The PR base of this branch is broken if the parent failed, so skip
+		// it. Other branches in the stack can proceed.
+		if failed[metadata.Parent] {
+			yas.progress.step("Skipping %s (%s failed)", branchName, metadata.Parent)
+			yas.progress.done(branchName, "skipped", nil)
+
+			failed[branchName] = true
+			results = append(results, SubmitResult{Branch: branchName, Skipped: true})
+			continue
+		}
+
+		yas.progress.step("Submitting %s…", branchName)
+
+		err := yas.submitBranch(branchName, opts)
+		if err != nil {
+			yas.progress.done(branchName, "failed", err)
+			failed[branchName] = true
+		} else {
+			yas.progress.done(branchName, "pushed", nil)
+		}
+
+		results = append(results, SubmitResult{Branch: branchName, Err: err})
+	}
+
+	yas.progress.summary()
+
+	return results
+}
+
+// submitRange returns the branches in the range "<base>..<top>" of the stack
+// containing the specified branch, parents first.
+func (yas *YAS) submitRange(branchName, branchRange string) ([]string, error) {
+	base, top, ok := strings.Cut(branchRange, "..")
+	if !ok || base == "" || top == "" {
+		return nil, fmt.Errorf("invalid range '%s' (expected <base>..<top>)", branchRange)
+	}
+
+	stack := yas.stack(branchName)
+	for _, name := range []string{base, top} {
+		if !slices.Contains(stack, name) {
+			return nil, fmt.Errorf("branch '%s' is not in the current stack", name)
+		}
+	}
+
+	branchNames := []string{}
+	seen := map[string]bool{}
+
+	for name := top; !seen[name]; name = yas.data.Branches.Get(name).Parent {
+		seen[name] = true
+		branchNames = append([]string{name}, branchNames...)
+
+		if name == base {
+			return branchNames, nil
+		}
+
+		if name == yas.cfg.TrunkBranch || !yas.isTracked(name) {
+			break
+		}
+	}
+
+	return nil, fmt.Errorf("invalid range '%s': '%s' is not an ancestor of '%s'", branchRange, base, top)
+}
+
+// submitBranch pushes the branch and then creates a PR for it, or updates the
+// base of the existing PR.
+func (yas *YAS) submitBranch(branchName string, opts SubmitOptions) error {
+	if yas.data.Branches.Get(branchName).External {
+		return fmt.Errorf("branch '%s' belongs to someone else's PR and can't be submitted", branchName)
+	}
+
+	if !opts.AllowDivergence {
+		if err := yas.checkDivergence(branchName); err != nil {
+			return err
+		}
+	}
+
+	stat, err := yas.branchDiffStat(branchName)
+	if err != nil {
+		return err
+	}
+
+	if err := yas.checkLimits(branchName, stat, yas.stackDepth(branchName), opts.Strict); err != nil {
+		return err
+	}
+
+	if err := yas.refreshRemoteStatus(branchName); err != nil {
+		return fmt.Errorf("failed to fetch PR status: %w", err)
+	}
+
+	if err := yas.runPreHook("preSubmit", yas.cfg.Hooks.PreSubmit, branchName); err != nil {
+		return err
+	}
+
+	if err := yas.pushBranch(branchName); err != nil {
+		return err
+	}
+
+	if err := yas.createOrUpdatePullRequest(branchName, opts); err != nil {
+		return err
+	}
+
+	if yas.cfg.Hooks.PostSubmit != "" || len(yas.plugins()) > 0 || recording != nil {
+		// Refresh so that the PR URL of a newly created PR is available to
+		// the hook, plugins and the recorded result
+		if err := yas.refreshRemoteStatus(branchName); err != nil {
+			return fmt.Errorf("failed to fetch PR status: %w", err)
+		}
+
+		yas.runPostHook("postSubmit", yas.cfg.Hooks.PostSubmit, branchName)
+		yas.emit(Event{Type: EventPRSubmitted, Branch: branchName})
+	}
+
+	return nil
+}
+
+// createOrUpdatePullRequest creates a PR for the branch, or updates the base
+// (and if requested, the draft state) of the existing PR.
+func (yas *YAS) createOrUpdatePullRequest(branchName string, opts SubmitOptions) error {
+	metadata := yas.data.Branches.Get(branchName)
+	base := yas.pullRequestBase(metadata)
+
+	if metadata.GitHubPullRequest.State == "OPEN" {
+		if err := yas.ghRun("pr", "edit", metadata.GitHubPullRequest.ref(branchName), "--base", base); err != nil {
+			return fmt.Errorf("failed to update PR: %w", err)
+		}
+
+		if opts.draftChange(metadata.GitHubPullRequest) {
+			if err := yas.setPullRequestDraft(branchName, *opts.Draft); err != nil {
+				return err
+			}
+		}
+
+		// GitHub disables auto-merge when the PR is force-pushed
+		if metadata.AutoMerge {
+			return yas.enableAutoMerge(metadata)
+		}
+
+		return nil
+	}
+
+	parent := metadata.Parent
+	if parent == "" {
+		parent = yas.cfg.TrunkBranch
+	}
+
+	messages, err := yas.git.CommitMessages(parent, branchName)
+	if err != nil {
+		return err
+	}
+
+	if len(messages) == 0 {
+		return fmt.Errorf("branch has no commits on top of %s", parent)
+	}
+
+	title, body := pullRequestTitleAndBody(messages, yas.cfg.PRBody)
+
+	body, err = yas.appendTicketLink(branchName, body)
+	if err != nil {
+		return err
+	}
+
+	prCreateArgs := []string{
+		"--head", branchName,
+		"--base", base,
+		"--title", title,
+		"--body", body,
+	}
+
+	if opts.createDraft(yas.cfg) {
+		prCreateArgs = append(prCreateArgs, "--draft")
+	}
+
+	if err := yas.ghRun(append([]string{"pr", "create"}, prCreateArgs...)...); err != nil {
+		return fmt.Errorf("failed to create PR: %w", err)
+	}
+
+	return nil
+}
+
+// submitCombined submits the stack containing the specified branch as a single
+// PR, with the stack tip as the head and trunk as the base. It returns the name
+// of the stack tip.
+func (yas *YAS) submitCombined(branchName string, opts SubmitOptions) (tip string, err error) {
+	branchNames := yas.stack(branchName)
+	root := branchNames[0]
+
+	if !opts.AllowDivergence {
+		if err := yas.checkDivergence(branchNames...); err != nil {
+			return "", err
+		}
+	}
+
+	tips := []string{}
+	for _, name := range branchNames {
+		if len(yas.data.Branches.ToSlice().NotDeleted().WithParent(name)) == 0 {
+			tips = append(tips, name)
+		}
+	}
+
+	if len(tips) != 1 {
+		return "", fmt.Errorf("combined submit requires a linear stack (found multiple tips: %s)", strings.Join(tips, ", "))
+	}
+
+	tip = tips[0]
+
+	stackMetadata := yas.data.Stacks[root]
+	if stackMetadata.CombinedHead != "" && stackMetadata.CombinedHead != tip && stackMetadata.CombinedPullRequest.State == "OPEN" {
+		return tip, fmt.Errorf("stack tip changed from '%s' to '%s' (hint: close %s and submit again)", stackMetadata.CombinedHead, tip, stackMetadata.CombinedPullRequest.URL)
+	}
+
+	stat, err := yas.git.DiffStat(yas.cfg.TrunkBranch, tip)
+	if err != nil {
+		return tip, err
+	}
+
+	if err := yas.checkLimits(tip, stat, len(branchNames), opts.Strict); err != nil {
+		return tip, err
+	}
+
+	if err := yas.runPreHook("preSubmit", yas.cfg.Hooks.PreSubmit, tip); err != nil {
+		return tip, err
+	}
+
+	if err := yas.pushBranch(tip); err != nil {
+		return tip, err
+	}
+
+	title, section, err := yas.combinedTitleAndBody(branchNames)
+	if err != nil {
+		return tip, err
+	}
+
+	pullRequest, err := yas.fetchGitHubPullRequestStatus(tip)
+	if err != nil {
+		return tip, fmt.Errorf("failed to fetch PR status: %w", err)
+	}
+
+	if pullRequest != nil && pullRequest.State == "OPEN" {
+		existingBody, err := yas.fetchPullRequestBody(pullRequest.ref(tip))
+		if err != nil {
+			return tip, fmt.Errorf("failed to fetch PR body: %w", err)
+		}
+
+		body := withStackSection(existingBody, section, root)
+
+		if err := yas.ghRun("pr", "edit", pullRequest.ref(tip), "--base", yas.cfg.baseBranch(yas.cfg.TrunkBranch), "--body", body); err != nil {
+			return tip, fmt.Errorf("failed to update PR: %w", err)
+		}
+
+		if opts.draftChange(*pullRequest) {
+			if err := yas.ghSetDraft(tip, *opts.Draft); err != nil {
+				return tip, err
+			}
+
+			pullRequest.IsDraft = *opts.Draft
+		}
+	} else {
+		prCreateArgs := []string{"--head", tip, "--base", yas.cfg.baseBranch(yas.cfg.TrunkBranch), "--title", title, "--body", withStackSection("", section, root)}
+		if opts.createDraft(yas.cfg) {
+			prCreateArgs = append(prCreateArgs, "--draft")
+		}
+
+		if err := yas.ghRun(append([]string{"pr", "create"}, prCreateArgs...)...); err != nil {
+			return tip, fmt.Errorf("failed to create PR: %w", err)
+		}
+
+		if pullRequest, err = yas.fetchGitHubPullRequestStatus(tip); err != nil {
+			return tip, fmt.Errorf("failed to fetch PR status: %w", err)
+		}
+	}
+
+	stackMetadata.Combined = true
+	stackMetadata.CombinedHead = tip
+	if pullRequest != nil {
+		stackMetadata.CombinedPullRequest = *pullRequest
+	}
+
+	yas.data.Stacks[root] = stackMetadata
+
+	if err := yas.data.Save(); err != nil {
+		return tip, err
+	}
+
+	yas.runPostHook("postSubmit", yas.cfg.Hooks.PostSubmit, tip)
+	yas.emit(Event{Type: EventPRSubmitted, Branch: tip, PRURL: stackMetadata.CombinedPullRequest.URL, Branches: branchNames})
+
+	return tip, nil
+}
+
+// Markers around the section of a combined PR's body that yas generates, so
+// that it can be regenerated without touching anything the user wrote.
+const (
+	stackSectionStart = "<!-- yas-stack-start -->"
+	stackSectionEnd   = "<!-- yas-stack-end -->"
+)
+
+// withStackSection returns the PR body with the text between the stack
+// section markers replaced by section. If the body has no markers, the
+// section is appended, unless the body was generated by yas before markers
+// were added (i.e. it starts with the section of the stack root), in which
+// case it is replaced.
+func withStackSection(body, section, root string) string {
+	marked := stackSectionStart + "\n" + section + "\n" + stackSectionEnd
+
+	start := strings.Index(body, stackSectionStart)
+	end := strings.Index(body, stackSectionEnd)
+
+	switch {
+	case start >= 0 && end > start:
+		return body[:start] + marked + body[end+len(stackSectionEnd):]
+	case strings.TrimSpace(body) == "" || strings.HasPrefix(body, "### "+root+"\n"):
+		return marked
+	default:
+		return strings.TrimRight(body, "\n") + "\n\n" + marked
+	}
+}
+
+// fetchPullRequestBody returns the current body of the PR.
+func (yas *YAS) fetchPullRequestBody(ref string) (string, error) {
+	b, err := yas.gh("pr", "view", ref, "--json", "body")
+	if err != nil {
+		return "", err
+	}
+
+	data := struct {
+		Body string `json:"body"`
+	}{}
+
+	if err := json.Unmarshal(b, &data); err != nil {
+		return "", err
+	}
+
+	return data.Body, nil
+}
+
+// combinedTitleAndBody generates the title and body for a combined PR. The
+// body contains a section for each branch listing its commits.
+func (yas *YAS) combinedTitleAndBody(branchNames []string) (title, body string, err error) {
+	sections := []string{}
+
+	for _, name := range branchNames {
+		parent := yas.data.Branches.Get(name).Parent
+		if parent == "" {
+			parent = yas.cfg.TrunkBranch
+		}
+
+		messages, err := yas.git.CommitMessages(parent, name)
+		if err != nil {
+			return "", "", err
+		}
+
+		if title == "" && len(messages) > 0 {
+			title, _, _ = strings.Cut(messages[0], "\n")
+		}
+
+		lines := []string{"### " + name, ""}
+		for _, message := range messages {
+			subject, _, _ := strings.Cut(message, "\n")
+			lines = append(lines, "- "+subject)
+		}
+
+		sections = append(sections, strings.Join(lines, "\n"))
+	}
+
+	if title == "" {
+		return "", "", errors.New("stack has no commits to submit")
+	}
+
+	return title, strings.Join(sections, "\n\n"), nil
+}
+
+// pullRequestTitleAndBody generates a PR title from the subject of the first
+// commit, and the body from the commit bodies according to the PR body mode.
+func pullRequestTitleAndBody(messages []string, mode string) (title, body string) {
+	title, firstBody, _ := strings.Cut(messages[0], "\n")
+
+	switch mode {
+	case PRBodyEmpty:
+		return title, ""
+	case PRBodyFirstCommit:
+		return title, strings.TrimSpace(firstBody)
+	}
+
+	bodies := []string{}
+	for _, message := range messages {
+		_, messageBody, _ := strings.Cut(message, "\n")
+		if messageBody = strings.TrimSpace(messageBody); messageBody != "" {
+			bodies = append(bodies, messageBody)
+		}
+	}
+
+	return title, strings.Join(bodies, "\n\n")
+}
@@ -0,0 +1,72 @@
+package yas
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestPullRequestTitleAndBody(t *testing.T) {
+	messages := []string{
+		"Add foo\n\nFoo is needed for bar.",
+		"Fix typo",
+		"Add baz\n\nBaz completes the set.",
+	}
+
+	for _, test := range []struct {
+		mode          string
+		expectedTitle string
+		expectedBody  string
+	}{
+		{
+			mode:          "",
+			expectedTitle: "Add foo",
+			expectedBody:  "Foo is needed for bar.\n\nBaz completes the set.",
+		},
+		{
+			mode:          PRBodyFirstCommit,
+			expectedTitle: "Add foo",
+			expectedBody:  "Foo is needed for bar.",
+		},
+		{
+			mode:          PRBodyEmpty,
+			expectedTitle: "Add foo",
+			expectedBody:  "",
+		},
+	} {
+		title, body := pullRequestTitleAndBody(messages, test.mode)
+		assert.Equal(t, title, test.expectedTitle)
+		assert.Equal(t, body, test.expectedBody)
+	}
+}
+
+func TestWithStackSection(t *testing.T) {
+	const section = "### topic-a\n\n- Add a"
+	const marked = "<!-- yas-stack-start -->\n" + section + "\n<!-- yas-stack-end -->"
+
+	for _, test := range []struct {
+		body     string
+		expected string
+	}{
+		{
+			body:     "",
+			expected: marked,
+		},
+		{
+			// Only the text between the markers is replaced
+			body:     "Intro\n\n<!-- yas-stack-start -->\n### topic-a\n\n- Old\n<!-- yas-stack-end -->\n\nNotes",
+			expected: "Intro\n\n" + marked + "\n\nNotes",
+		},
+		{
+			// Body generated before markers were added
+			body:     "### topic-a\n\n- Old",
+			expected: marked,
+		},
+		{
+			body:     "Written by hand\n",
+			expected: "Written by hand\n\n" + marked,
+		},
+	} {
+		assert.Equal(t, withStackSection(test.body, section, "topic-a"), test.expected)
+	}
+}
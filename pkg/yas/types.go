@@ -1,7 +1,11 @@
 package yas
 
 import (
+	"encoding/json"
 	"slices"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/dansimau/yas/pkg/sliceutil"
 )
@@ -10,11 +14,137 @@ type BranchMetadata struct {
 	Name              string
 	GitHubPullRequest PullRequestMetadata
 	Parent            string `json:",omitempty"`
+
+	// PRBase overrides the base branch of the branch's PR on GitHub. When
+	// empty, the parent is used as the PR base.
+	PRBase string `json:",omitempty"`
+
+	// BranchPoint is the commit on the parent that the branch was created
+	// from.
+	BranchPoint string `json:",omitempty"`
+
+	// External indicates the branch belongs to someone else's PR. It is
+	// never rebased or pushed; restack updates it from its upstream instead.
+	External bool `json:",omitempty"`
+
+	// AutoMerge indicates GitHub auto-merge was enabled for the branch's PR
+	// with yas, so it is re-enabled when the branch is submitted again.
+	AutoMerge bool `json:",omitempty"`
+
+	// Pushed is the commit of the branch that yas last pushed to origin. It is
+	// used to detect commits pushed to the branch by someone else.
+	Pushed string `json:",omitempty"`
+
+	// WIP indicates the branch's stack is a work in progress, so it is not
+	// submitted (without forcing) or merged.
+	WIP bool `json:",omitempty"`
+
+	Created time.Time
+	Deleted time.Time
+
+	// Updated is when the metadata was last changed.
+	Updated time.Time
+}
+
+// PullRequestBase returns the branch that the PR for this branch should target.
+func (b BranchMetadata) PullRequestBase(trunkBranch string) string {
+	if b.PRBase != "" {
+		return b.PRBase
+	}
+
+	if b.Parent != "" {
+		return b.Parent
+	}
+
+	return trunkBranch
 }
 
+// pullRequestFields are the gh JSON fields of PullRequestMetadata.
+const pullRequestFields = "id,number,state,url,author,isDraft,updatedAt,baseRefName,statusCheckRollup,reviewDecision"
+
 type PullRequestMetadata struct {
-	ID    string
-	State string
+	ID          string
+	Number      int `json:",omitempty"`
+	State       string
+	URL         string            `json:",omitempty"`
+	Author      PullRequestAuthor `json:",omitempty"`
+	IsDraft     bool              `json:",omitempty"`
+	UpdatedAt   time.Time
+	BaseRefName string `json:",omitempty"`
+
+	// StatusCheckRollup is the overall result of the PR's checks.
+	StatusCheckRollup ChecksRollup `json:",omitempty"`
+
+	// ReviewDecision is APPROVED, CHANGES_REQUESTED or REVIEW_REQUIRED, or
+	// empty if reviews aren't required.
+	ReviewDecision string `json:",omitempty"`
+}
+
+// ref returns the argument that identifies the PR in gh commands: its number,
+// or the name of its head branch if the number isn't known.
+func (pr PullRequestMetadata) ref(branchName string) string {
+	if pr.Number == 0 {
+		return branchName
+	}
+
+	return strconv.Itoa(pr.Number)
+}
+
+// ChecksRollup is the overall result of a PR's checks: pending, success or
+// failure, or empty if the PR has no checks. When unmarshaled from the list of
+// checks returned by gh, it is summarized into a single result.
+type ChecksRollup string
+
+func (r *ChecksRollup) UnmarshalJSON(b []byte) error {
+	var result string
+	if err := json.Unmarshal(b, &result); err == nil {
+		*r = ChecksRollup(result)
+		return nil
+	}
+
+	checks := []statusCheck{}
+	if err := json.Unmarshal(b, &checks); err != nil {
+		return err
+	}
+
+	*r = ""
+	for _, check := range checks {
+		switch check.result() {
+		case checkFailure:
+			*r = checkFailure
+			return nil
+		case checkPending:
+			*r = checkPending
+		case checkSuccess:
+			if *r == "" {
+				*r = checkSuccess
+			}
+		}
+	}
+
+	return nil
+}
+
+type PullRequestAuthor struct {
+	Login string `json:",omitempty"`
+}
+
+// StackMetadata holds data about a whole stack. Stacks are identified by the
+// name of their root branch (the bottom branch, whose parent is trunk).
+type StackMetadata struct {
+	// Name is a human-friendly name for the stack, which can be used instead
+	// of a branch name to refer to the stack.
+	Name string `json:",omitempty"`
+
+	// Combined indicates the stack is submitted as a single PR containing
+	// every branch, instead of one PR per branch.
+	Combined bool `json:",omitempty"`
+
+	// CombinedHead is the branch (stack tip) that the combined PR was
+	// created from.
+	CombinedHead string `json:",omitempty"`
+
+	CombinedPullRequest PullRequestMetadata
 }
 
 type Branches []BranchMetadata
@@ -59,6 +189,28 @@ func (b *Branches) Set(data BranchMetadata) {
 	*b = n
 }
 
+func (b Branches) NotDeleted() Branches {
+	return b.filter(func(b BranchMetadata) bool {
+		return b.Deleted.IsZero()
+	})
+}
+
+// SortedByName returns a copy of the branches sorted by name.
+func (b Branches) SortedByName() Branches {
+	sorted := slices.Clone(b)
+	slices.SortFunc(sorted, func(a, b BranchMetadata) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	return sorted
+}
+
+func (b Branches) WithParent(name string) Branches {
+	return b.filter(func(b BranchMetadata) bool {
+		return b.Parent == name
+	})
+}
+
 func (b Branches) WithParents() Branches {
 	return b.filter(func(b BranchMetadata) bool {
 		return b.Parent != ""
@@ -1,22 +1,126 @@
 package yas
 
 import (
+	"fmt"
+	"os"
+	"slices"
+
+	"github.com/dansimau/yas/pkg/termutil"
 	"github.com/heimdalr/dag"
 	"github.com/xlab/treeprint"
 )
 
-func addNodesFromGraph(treeNode treeprint.Tree, graph *dag.DAG, vertexID string) error {
+// addNodesFromGraph adds the children of vertexID in the graph to the tree,
+// recursively. If visible is not nil, only branches in visible are added.
+// Details of a branch, if any, are appended to its label, and its header, if
+// any, is added as a node above it.
+func addNodesFromGraph(treeNode treeprint.Tree, graph *dag.DAG, vertexID string, visible map[string]bool, details, headers map[string]string) error {
 	children, err := graph.GetChildren(vertexID)
 	if err != nil {
 		return err
 	}
 
+	childIDs := []string{}
 	for child := range children {
-		childTree := treeNode.AddBranch(child)
-		if err := addNodesFromGraph(childTree, graph, child); err != nil {
+		childIDs = append(childIDs, child)
+	}
+
+	slices.Sort(childIDs)
+
+	for _, child := range childIDs {
+		if visible != nil && !visible[child] {
+			continue
+		}
+
+		var label string
+		switch v := children[child].(type) {
+		case remoteBranch:
+			label = string(v) + " [remote]"
+		case BranchMetadata:
+			label = branchLabel(v)
+		}
+
+		if details[child] != "" {
+			label += " " + details[child]
+		}
+
+		parentTree := treeNode
+		if headers[child] != "" {
+			parentTree = treeNode.AddBranch(headers[child])
+		}
+
+		childTree := parentTree.AddBranch(label)
+		if err := addNodesFromGraph(childTree, graph, child, visible, details, headers); err != nil {
 			return err
 		}
 	}
 
 	return nil
 }
+
+// remoteBranch is a remote-tracking branch in the graph that is the parent of
+// a tracked branch, but doesn't exist locally.
+type remoteBranch string
+
+// branchLabel returns the text to display for a branch in the list tree.
+func branchLabel(branch BranchMetadata) string {
+	label := branch.Name
+
+	if branch.PRBase != "" && branch.PRBase != branch.Parent {
+		label = fmt.Sprintf("%s (PR base: %s)", label, branch.PRBase)
+	}
+
+	if branch.GitHubPullRequest.State == "OPEN" && branch.GitHubPullRequest.IsDraft {
+		label += " [draft]"
+	}
+
+	if branch.GitHubPullRequest.State == "OPEN" {
+		label += pullRequestIndicators(branch.GitHubPullRequest)
+	}
+
+	if branch.GitHubPullRequest.State == "OPEN" && branch.AutoMerge {
+		label += " [auto-merge]"
+	}
+
+	if branch.External {
+		label += " [external]"
+	}
+
+	if branch.WIP {
+		label += " [wip]"
+	}
+
+	if isMerged(branch) {
+		label += " (merged)"
+
+		if termutil.ColorEnabled(os.Stdout) {
+			label = "\033[90m" + label + "\033[0m"
+		}
+	}
+
+	return label
+}
+
+// pullRequestIndicators returns compact indicators of the PR's check status
+// and review decision, e.g. " ✓ 👍".
+func pullRequestIndicators(pr PullRequestMetadata) string {
+	indicators := ""
+
+	switch pr.StatusCheckRollup {
+	case checkSuccess:
+		indicators += " ✓"
+	case checkFailure:
+		indicators += " ✗"
+	case checkPending:
+		indicators += " ●"
+	}
+
+	switch pr.ReviewDecision {
+	case "APPROVED":
+		indicators += " 👍"
+	case "CHANGES_REQUESTED":
+		indicators += " ✋"
+	}
+
+	return indicators
+}
@@ -0,0 +1,60 @@
+package yas
+
+import (
+	"fmt"
+)
+
+// SetStackWIP marks every branch in the stack containing the branch (default:
+// the current branch), or the stack with the name, as a work in progress, or
+// unmarks them if wip is false. Branches in a WIP stack can't be merged, and
+// are only submitted when forced, to prevent opening PRs too early.
+func (yas *YAS) SetStackWIP(branchName string, wip bool) error {
+	branchName, err := yas.trackedBranchOrCurrent(yas.resolveStack(branchName))
+	if err != nil {
+		return err
+	}
+
+	branchNames := yas.stack(branchName)
+	for _, name := range branchNames {
+		metadata := yas.data.Branches.Get(name)
+		metadata.WIP = wip
+		yas.data.Branches.Set(name, metadata)
+	}
+
+	if err := yas.data.Save(); err != nil {
+		return err
+	}
+
+	if wip {
+		fmt.Printf("Marked the stack rooted at '%s' as WIP\n", branchNames[0])
+	} else {
+		fmt.Printf("Unmarked the stack rooted at '%s' as WIP\n", branchNames[0])
+	}
+
+	return nil
+}
+
+// checkNotWIP returns an error if any branch in the stack containing the
+// branch is marked as WIP, including branches added to the stack after it was
+// marked. The error says the branch can't be done (e.g. "submitted"), with
+// any alternative as a hint.
+func (yas *YAS) checkNotWIP(branchName, done, alternative string) error {
+	if branchName == yas.cfg.TrunkBranch {
+		return nil
+	}
+
+	for _, name := range yas.stack(branchName) {
+		if !yas.data.Branches.Get(name).WIP {
+			continue
+		}
+
+		hint := "run `yas stack wip --off` first"
+		if alternative != "" {
+			hint += ", or use " + alternative
+		}
+
+		return fmt.Errorf("the stack containing '%s' is marked as WIP and can't be %s (hint: %s)", branchName, done, hint)
+	}
+
+	return nil
+}
@@ -0,0 +1,105 @@
+package yas
+
+import (
+	"fmt"
+	"path"
+
+	"github.com/dansimau/yas/pkg/gitexec"
+)
+
+// BranchWorktrees returns the worktree that each branch is checked out in,
+// keyed by branch name. Branches that aren't checked out are not included.
+func (yas *YAS) BranchWorktrees() (map[string]gitexec.Worktree, error) {
+	worktrees, err := yas.git.Worktrees()
+	if err != nil {
+		return nil, err
+	}
+
+	result := map[string]gitexec.Worktree{}
+	for _, worktree := range worktrees {
+		if worktree.Branch != "" {
+			result[worktree.Branch] = worktree
+		}
+	}
+
+	return result, nil
+}
+
+// AddWorktree creates a linked worktree with the tracked branch checked out,
+// at worktreePath, or if it is empty, in the configured worktree directory. If
+// the branch only exists on origin, a local branch is created from it first.
+// It returns the path of the worktree.
+func (yas *YAS) AddWorktree(branchName, worktreePath string) (string, error) {
+	if branchName != yas.cfg.TrunkBranch && !yas.isTracked(branchName) {
+		return "", fmt.Errorf("branch '%s' is not tracked (hint: run `yas add`)", branchName)
+	}
+
+	worktrees, err := yas.BranchWorktrees()
+	if err != nil {
+		return "", err
+	}
+
+	if worktree, ok := worktrees[branchName]; ok {
+		return "", fmt.Errorf("branch '%s' is already checked out in %s", branchName, worktree.Path)
+	}
+
+	exists, err := yas.git.BranchExists(branchName)
+	if err != nil {
+		return "", err
+	}
+
+	if !exists {
+		remoteExists, err := yas.git.RemoteBranchExists("origin/" + branchName)
+		if err != nil {
+			return "", err
+		}
+
+		if !remoteExists {
+			return "", fmt.Errorf("branch '%s' doesn't exist locally or on origin", branchName)
+		}
+
+		if err := yas.git.TrackRemoteBranch("origin", branchName); err != nil {
+			return "", fmt.Errorf("failed to create '%s' from origin: %w", branchName, err)
+		}
+	}
+
+	if worktreePath == "" {
+		worktreePath = path.Join(yas.cfg.worktreeDir(), branchName)
+	}
+
+	if err := yas.git.WorktreeAddBranch(worktreePath, branchName); err != nil {
+		return "", fmt.Errorf("failed to create worktree: %w", err)
+	}
+
+	return worktreePath, nil
+}
+
+// RemoveWorktree removes the linked worktree that the branch is checked out
+// in. Unless force is true, it fails if the worktree has local modifications
+// or untracked files. It returns the path of the removed worktree.
+func (yas *YAS) RemoveWorktree(branchName string, force bool) (string, error) {
+	worktrees, err := yas.BranchWorktrees()
+	if err != nil {
+		return "", err
+	}
+
+	worktree, ok := worktrees[branchName]
+	if !ok {
+		return "", fmt.Errorf("branch '%s' is not checked out in a worktree", branchName)
+	}
+
+	if worktree.Main {
+		return "", fmt.Errorf("branch '%s' is checked out in the main worktree, which can't be removed", branchName)
+	}
+
+	remove := yas.git.WorktreeRemoveUnmodified
+	if force {
+		remove = yas.git.WorktreeRemove
+	}
+
+	if err := remove(worktree.Path); err != nil {
+		return "", fmt.Errorf("failed to remove worktree %s (hint: use --force to discard local changes): %w", worktree.Path, err)
+	}
+
+	return worktree.Path, nil
+}
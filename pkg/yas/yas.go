@@ -5,28 +5,38 @@ import (
 	"errors"
 	"fmt"
 	"path"
+	"slices"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/dansimau/yas/pkg/gitexec"
 	"github.com/dansimau/yas/pkg/log"
-	"github.com/dansimau/yas/pkg/xexec"
 	"github.com/go-git/go-git/v5"
 	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/hashicorp/go-version"
 	"github.com/heimdalr/dag"
 	"github.com/sourcegraph/conc/pool"
-	"github.com/xlab/treeprint"
 )
 
 var minimumRequiredGitVersion = version.Must(version.NewVersion("2.38"))
 
-const yasStateFile = ".git/.yasstate"
+const yasStateFile = ".yasstate"
 
 type YAS struct {
 	cfg  Config
 	data *yasDatabase
 	git  *gitexec.Repo
 	repo *git.Repository
+
+	// progress is the progress of the current multi-branch operation, if
+	// any.
+	progress *progress
+
+	// fetched are the remotes that have been fetched by this instance.
+	fetched map[string]bool
+
+	github gitHubProvider
 }
 
 func New(cfg Config) (*YAS, error) {
@@ -35,16 +45,17 @@ func New(cfg Config) (*YAS, error) {
 		return nil, fmt.Errorf("failed to open git repo: %w", err)
 	}
 
-	data, err := loadData(path.Join(cfg.RepoDirectory, yasStateFile))
+	data, err := loadData(gitFile(cfg.RepoDirectory, yasStateFile))
 	if err != nil {
 		return nil, fmt.Errorf("failed to load YAS state: %w", err)
 	}
 
 	yas := &YAS{
-		cfg:  cfg,
-		data: data,
-		git:  gitexec.WithRepo(cfg.RepoDirectory),
-		repo: repo,
+		cfg:    cfg,
+		data:   data,
+		git:    gitexec.WithRepo(cfg.RepoDirectory).WithCommitSigning(cfg.SignCommits).WithEditor(cfg.Editor),
+		repo:   repo,
+		github: newGitHubProvider(),
 	}
 
 	if err := yas.validate(); err != nil {
@@ -63,8 +74,13 @@ func NewFromRepository(repoDirectory string) (*YAS, error) {
 	return New(*cfg)
 }
 
+// cleanupBranch marks the branch metadata as deleted. The metadata is retained
+// until it is pruned by `yas clean` or `yas sync`.
 func (yas *YAS) cleanupBranch(name string) error {
-	yas.data.Branches.Remove(name)
+	branch := yas.data.Branches.Get(name)
+	branch.Deleted = time.Now()
+	yas.data.Branches.Set(name, branch)
+
 	return yas.data.Save()
 }
 
@@ -72,6 +88,12 @@ func (yas *YAS) Config() Config {
 	return yas.cfg
 }
 
+// CurrentBranch returns the name of the checked out branch, or "HEAD" if HEAD
+// is detached.
+func (yas *YAS) CurrentBranch() (string, error) {
+	return yas.git.GetCurrentBranchName()
+}
+
 func (yas *YAS) DeleteBranch(name string) error {
 	branchExists, err := yas.git.BranchExists(name)
 	if err != nil {
@@ -83,6 +105,8 @@ func (yas *YAS) DeleteBranch(name string) error {
 			return err
 		}
 
+		yas.emit(Event{Type: EventBranchDeleted, Branch: name})
+
 		return nil
 	}
 
@@ -106,13 +130,15 @@ func (yas *YAS) DeleteBranch(name string) error {
 		return err
 	}
 
+	yas.emit(Event{Type: EventBranchDeleted, Branch: name})
+
 	return nil
 }
 
 func (yas *YAS) fetchGitHubPullRequestStatus(branchName string) (*PullRequestMetadata, error) {
 	log.Info("Fetching PRs for branch", branchName)
 
-	b, err := xexec.Command("gh", "pr", "list", "--head", branchName, "--state", "all", "--json", "id,state").WithStdout(nil).Output()
+	b, err := yas.gh("pr", "list", "--head", branchName, "--state", "all", "--json", pullRequestFields)
 	if err != nil {
 		return nil, err
 	}
@@ -135,71 +161,98 @@ func (yas *YAS) graph() (*dag.DAG, error) {
 	trunkBranch := yas.data.Branches.Get(yas.cfg.TrunkBranch)
 	graph.AddVertexByID(yas.cfg.TrunkBranch, trunkBranch)
 
-	for _, branch := range yas.data.Branches.ToSlice().WithParents() {
+	for _, branch := range yas.data.Branches.ToSlice().NotDeleted().WithParents() {
 		graph.AddVertexByID(branch.Name, branch) // TODO handle errors
 	}
 
-	for _, branch := range yas.data.Branches.ToSlice().WithParents() {
+	for _, branch := range yas.data.Branches.ToSlice().NotDeleted().WithParents() {
+		remote, err := yas.remoteParent(branch.Parent)
+		if err != nil {
+			return nil, err
+		}
+
+		// Remote parents are shown on top of trunk
+		if remote != "" {
+			if _, err := graph.GetVertex(branch.Parent); err != nil {
+				graph.AddVertexByID(branch.Parent, remoteBranch(branch.Parent))
+				graph.AddEdge(yas.cfg.TrunkBranch, branch.Parent)
+			}
+		}
+
 		graph.AddEdge(branch.Parent, branch.Name) // TODO handle errors
 	}
 
 	return graph, nil
 }
 
-func (yas *YAS) Restack() error {
-	graph, err := yas.graph()
-	if err != nil {
-		return err
-	}
-
-	currentBranchName, err := yas.git.GetCurrentBranchName()
-	if err != nil {
-		return err
-	}
+// stack returns the names of the branches in the stack containing the
+// specified branch, i.e. its ancestors (excluding trunk), itself and its
+// descendants. Parents are always ordered before their children.
+func (yas *YAS) stack(branchName string) []string {
+	seen := map[string]bool{}
+
+	ancestors := []string{}
+	for name := branchName; name != "" && name != yas.cfg.TrunkBranch && !seen[name]; name = yas.data.Branches.Get(name).Parent {
+		// Remote parents are not part of the stack
+		if name != branchName && !yas.data.Branches.Exists(name) {
+			break
+		}
 
-	vertex, err := graph.GetVertex(currentBranchName)
-	if err != nil {
-		return err
+		seen[name] = true
+		ancestors = append([]string{name}, ancestors...)
 	}
 
-	descendents, _, err := graph.GetDescendantsGraph(vertex.(BranchMetadata).Name)
-	if err != nil {
-		return err
-	}
+	return append(ancestors, yas.descendants(branchName)...)
+}
 
-	for _, v := range descendents.GetLeaves() {
-		if err := yas.git.Rebase(yas.cfg.TrunkBranch, v.(BranchMetadata).Name); err != nil {
-			return err
+// descendants returns the names of all tracked descendants of the specified
+// branch, in breadth-first order (so parents are always ordered before their
+// children).
+func (yas *YAS) descendants(branchName string) []string {
+	branches := yas.data.Branches.ToSlice().NotDeleted()
+	seen := map[string]bool{branchName: true}
+	result := []string{}
+
+	queue := []string{branchName}
+	for len(queue) > 0 {
+		name := queue[0]
+		queue = queue[1:]
+
+		children := branches.WithParent(name).BranchNames()
+		slices.Sort(children)
+
+		for _, child := range children {
+			if seen[child] {
+				continue
+			}
+
+			seen[child] = true
+			result = append(result, child)
+			queue = append(queue, child)
 		}
 	}
 
-	return nil
+	return result
 }
 
-func (yas *YAS) toTree(graph *dag.DAG, rootNode string) (treeprint.Tree, error) {
-	tree := treeprint.NewWithRoot(rootNode)
-
-	if err := addNodesFromGraph(tree, graph, rootNode); err != nil {
-		return nil, err
-	}
+// stackRoot returns the name of the bottom branch of the stack containing the
+// specified branch.
+func (yas *YAS) stackRoot(branchName string) string {
+	seen := map[string]bool{}
 
-	return tree, nil
-}
+	name := branchName
+	for !seen[name] {
+		seen[name] = true
 
-func (yas *YAS) List() error {
-	graph, err := yas.graph()
-	if err != nil {
-		return fmt.Errorf("failed to get graph: %w", err)
-	}
+		parent := yas.data.Branches.Get(name).Parent
+		if parent == "" || parent == yas.cfg.TrunkBranch {
+			break
+		}
 
-	tree, err := yas.toTree(graph, yas.cfg.TrunkBranch)
-	if err != nil {
-		return err
+		name = parent
 	}
 
-	fmt.Print(tree.String())
-
-	return nil
+	return name
 }
 
 func (yas *YAS) SetParent(branchName, parentBranchName string) error {
@@ -235,7 +288,20 @@ func (yas *YAS) SetParent(branchName, parentBranchName string) error {
 	}
 
 	branchMetdata := yas.data.Branches.Get(branchName)
+
+	// Record where the branch diverges from its parent, so that restack
+	// knows which commits belong to the branch
+	if branchMetdata.Parent != parentBranchName || branchMetdata.BranchPoint == "" {
+		mergeBase, err := yas.git.GetMergeBase(parentBranchName, branchName)
+		if err != nil {
+			log.Debug("Not recording branch point of", branchName+":", err)
+		}
+
+		branchMetdata.BranchPoint = mergeBase
+	}
+
 	branchMetdata.Parent = parentBranchName
+	branchMetdata.Deleted = time.Time{}
 	yas.data.Branches.Set(branchName, branchMetdata)
 	yas.data.Save()
 
@@ -244,43 +310,101 @@ func (yas *YAS) SetParent(branchName, parentBranchName string) error {
 	return nil
 }
 
-func (yas *YAS) Submit() error {
-	currentBranch, err := yas.git.GetCurrentBranchName()
-	if err != nil {
-		return err
+// pullRequestBase returns the branch that the PR for the branch should target:
+// its PR base, if it is overridden, otherwise its parent (or trunk), mapped by
+// the baseBranchOverrides config.
+func (yas *YAS) pullRequestBase(metadata BranchMetadata) string {
+	if metadata.PRBase != "" {
+		return metadata.PRBase
 	}
 
-	if currentBranch == "HEAD" {
-		return errors.New("cannot submit in detached HEAD state")
+	return yas.cfg.baseBranch(metadata.PullRequestBase(yas.cfg.TrunkBranch))
+}
+
+// SetPRBase sets the base branch to use for the branch's PR, overriding the
+// parent. An empty base removes the override.
+func (yas *YAS) SetPRBase(branchName, base string) error {
+	if branchName == "" {
+		currentBranch, err := yas.git.GetCurrentBranchName()
+		if err != nil {
+			return err
+		}
+
+		branchName = currentBranch
 	}
 
-	if err := yas.refreshRemoteStatus(currentBranch); err != nil {
+	if !yas.data.Branches.Exists(branchName) {
+		return fmt.Errorf("branch '%s' is not tracked (hint: run `yas add`)", branchName)
+	}
+
+	branchMetadata := yas.data.Branches.Get(branchName)
+	branchMetadata.PRBase = base
+	yas.data.Branches.Set(branchName, branchMetadata)
+
+	if err := yas.data.Save(); err != nil {
 		return err
 	}
 
-	if err := yas.git.Push(); err != nil {
-		return fmt.Errorf("failed to push: %w", err)
+	if base == "" {
+		fmt.Printf("Removed PR base override for '%s'\n", branchName)
+	} else {
+		fmt.Printf("Set '%s' as PR base of '%s'\n", base, branchName)
 	}
 
-	prCreateArgs := []string{
-		"--draft",
-		"--fill-first",
+	return nil
+}
+
+func (yas *YAS) TrackedBranches() Branches {
+	return yas.data.Branches.ToSlice().NotDeleted()
+}
+
+// isTracked returns true if the branch is tracked, i.e. it has a parent and
+// hasn't been deleted.
+func (yas *YAS) isTracked(branchName string) bool {
+	metadata := yas.data.Branches.Get(branchName)
+	return metadata.Parent != "" && metadata.Deleted.IsZero()
+}
+
+// remoteParent returns the remote of the parent branch if it is a
+// remote-tracking branch that doesn't exist locally (e.g.
+// origin/teammate/feature), or an empty string otherwise.
+func (yas *YAS) remoteParent(parent string) (remote string, err error) {
+	if parent == "" || parent == yas.cfg.TrunkBranch || yas.data.Branches.Exists(parent) {
+		return "", nil
 	}
 
-	metadata := yas.data.Branches.Get(currentBranch)
-	if metadata.Parent != "" {
-		prCreateArgs = append(prCreateArgs, "--base", metadata.Parent)
+	if exists, err := yas.git.BranchExists(parent); err != nil || exists {
+		return "", err
 	}
 
-	if err := xexec.Command(append([]string{"gh", "pr", "create"}, prCreateArgs...)...).Run(); err != nil {
-		return err
+	exists, err := yas.git.RemoteBranchExists(parent)
+	if err != nil || !exists {
+		return "", err
 	}
 
-	return nil
+	remote, _, _ = strings.Cut(parent, "/")
+
+	return remote, nil
 }
 
-func (yas *YAS) TrackedBranches() Branches {
-	return yas.data.Branches.ToSlice()
+// fetch fetches the remote, unless it was already fetched by this instance,
+// so that operations on several branches with the same remote fetch it once.
+func (yas *YAS) fetch(remote string) error {
+	if yas.fetched[remote] {
+		return nil
+	}
+
+	if err := yas.git.Fetch(remote); err != nil {
+		return err
+	}
+
+	if yas.fetched == nil {
+		yas.fetched = map[string]bool{}
+	}
+
+	yas.fetched[remote] = true
+
+	return nil
 }
 
 // UpdateConfig sets the new config and writes it to the configuration file.
@@ -289,6 +413,8 @@ func (yas *YAS) UpdateConfig(cfg Config) (string, error) {
 	return WriteConfig(cfg)
 }
 
+// UntrackedBranches returns the local branches that yas has no metadata for,
+// excluding branches matching ignoreBranchPatterns.
 func (yas *YAS) UntrackedBranches() ([]string, error) {
 	iter, err := yas.repo.Branches()
 	if err != nil {
@@ -296,17 +422,46 @@ func (yas *YAS) UntrackedBranches() ([]string, error) {
 	}
 
 	branches := []string{}
-	iter.ForEach(func(r *plumbing.Reference) error {
+	err = iter.ForEach(func(r *plumbing.Reference) error {
 		name := string(r.Name().Short())
-		if !yas.data.Branches.Exists(name) {
-			branches = append(branches, name)
+		if yas.data.Branches.Exists(name) {
+			return nil
 		}
+
+		ignored, err := yas.isIgnoredBranch(name)
+		if err != nil || ignored {
+			return err
+		}
+
+		branches = append(branches, name)
 		return nil
 	})
+	if err != nil {
+		return nil, err
+	}
 
 	return branches, nil
 }
 
+// isIgnoredBranch returns true if the branch name, or one of its leading
+// directories, matches one of the ignoreBranchPatterns.
+func (yas *YAS) isIgnoredBranch(name string) (bool, error) {
+	for _, pattern := range yas.cfg.IgnoreBranchPatterns {
+		for prefix := name; prefix != "."; prefix = path.Dir(prefix) {
+			matches, err := path.Match(pattern, prefix)
+			if err != nil {
+				return false, fmt.Errorf("invalid ignoreBranchPatterns pattern '%s': %w", pattern, err)
+			}
+
+			if matches {
+				return true, nil
+			}
+		}
+	}
+
+	return false, nil
+}
+
 func (yas *YAS) refreshRemoteStatus(name string) error {
 	if strings.TrimSpace(name) == "" {
 		panic("branch name cannot be empty")
@@ -334,30 +489,96 @@ func (yas *YAS) refreshRemoteStatus(name string) error {
 	return nil
 }
 
+// RefreshErrors are the errors from refreshing the PR status of branches,
+// by branch name.
+type RefreshErrors map[string]error
+
+func (e RefreshErrors) Error() string {
+	names := []string{}
+	for name := range e {
+		names = append(names, name)
+	}
+
+	slices.Sort(names)
+
+	lines := []string{fmt.Sprintf("failed to fetch PR status of %d branch(es):", len(names))}
+	for _, name := range names {
+		lines = append(lines, fmt.Sprintf("    %s: %s", name, e[name]))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// RefreshRemoteStatus fetches the PR status of the branches from GitHub. If
+// the status of a branch fails to be fetched, the other branches are still
+// refreshed, and a RefreshErrors is returned with the failures.
 func (yas *YAS) RefreshRemoteStatus(branchNames ...string) error {
-	p := pool.New().WithMaxGoroutines(5).WithErrors().WithFirstError()
+	var mu sync.Mutex
+	failed := RefreshErrors{}
+
+	p := pool.New().WithMaxGoroutines(5)
 	for _, name := range branchNames {
-		p.Go(func() error {
-			return yas.refreshRemoteStatus(name)
+		p.Go(func() {
+			if err := yas.refreshRemoteStatus(name); err != nil {
+				mu.Lock()
+				failed[name] = err
+				mu.Unlock()
+			}
 		})
 	}
 
-	if err := p.Wait(); err != nil {
-		return err
+	p.Wait()
+
+	if len(failed) > 0 {
+		return failed
 	}
 
 	return nil
 }
 
-func (yas *YAS) UpdateTrunk() error {
-	if err := yas.git.Checkout(yas.cfg.TrunkBranch); err != nil {
-		return err
+// UpdateTrunk fetches origin and fast-forwards the local trunk branch to the
+// remote trunk. It returns the number of new commits. If the local trunk has
+// commits that are not on the remote, it refuses to update.
+func (yas *YAS) UpdateTrunk() (newCommits int, err error) {
+	if err := yas.fetch("origin"); err != nil {
+		return 0, fmt.Errorf("failed to fetch: %w", err)
 	}
 
-	// Switch back to original branch
-	defer yas.git.Checkout("-")
+	remoteTrunk := "origin/" + yas.cfg.TrunkBranch
+
+	localOnly, err := yas.git.CountCommits(remoteTrunk, yas.cfg.TrunkBranch)
+	if err != nil {
+		return 0, err
+	}
+
+	if localOnly > 0 {
+		return 0, fmt.Errorf("%s has diverged from %s (%d local commits not on remote)", yas.cfg.TrunkBranch, remoteTrunk, localOnly)
+	}
+
+	newCommits, err = yas.git.CountCommits(yas.cfg.TrunkBranch, remoteTrunk)
+	if err != nil {
+		return 0, err
+	}
+
+	if newCommits == 0 {
+		return 0, nil
+	}
+
+	currentBranch, _ := yas.git.GetCurrentBranchName()
+
+	// The working tree must be updated if trunk is checked out, otherwise
+	// the branch ref can be updated directly
+	if currentBranch == yas.cfg.TrunkBranch {
+		err = yas.git.MergeFastForward(remoteTrunk)
+	} else {
+		err = yas.git.FastForwardBranch(yas.cfg.TrunkBranch, remoteTrunk)
+	}
+
+	if err != nil {
+		return 0, fmt.Errorf("failed to fast-forward %s: %w", yas.cfg.TrunkBranch, err)
+	}
 
-	return yas.git.Pull()
+	return newCommits, nil
 }
 
 func (yas *YAS) validate() error {
@@ -1,12 +1,16 @@
 package yascli
 
 import (
+	"fmt"
+
+	"github.com/dansimau/yas/pkg/cliutil"
 	"github.com/dansimau/yas/pkg/yas"
 )
 
 type addCmd struct {
 	Branch string `long:"branch" description:"The name of the branch to add to stack (default: current)" required:"false"`
-	Parent string `long:"parent" description:"Parent branch name (default: autodetect)" required:"false"`
+	Parent string `long:"parent" description:"Parent branch name, a remote-tracking branch such as origin/feature, or @auto for the nearest tracked ancestor (default: autodetect)" required:"false"`
+	Yes    bool   `long:"yes" short:"y" description:"Don't ask to confirm the parent chosen by --parent=@auto"`
 }
 
 func (c *addCmd) Execute(args []string) error {
@@ -15,5 +19,18 @@ func (c *addCmd) Execute(args []string) error {
 		return NewError(err.Error())
 	}
 
-	return yasInstance.SetParent(c.Branch, c.Parent)
+	parent := c.Parent
+	if parent == yas.AutoParent {
+		if parent, err = yasInstance.InferParent(c.Branch); err != nil {
+			return NewError(err.Error())
+		}
+
+		fmt.Printf("Nearest tracked ancestor: %s\n", parent)
+
+		if !c.Yes && !cliutil.Confirm(fmt.Sprintf("Use '%s' as the parent? [Y/n]", parent), true) {
+			return NewError("aborted")
+		}
+	}
+
+	return yasInstance.SetParent(c.Branch, parent)
 }
@@ -0,0 +1,20 @@
+package yascli
+
+import (
+	"github.com/dansimau/yas/pkg/yas"
+)
+
+type adoptCmd struct{}
+
+func (c *adoptCmd) Execute(args []string) error {
+	yasInstance, err := yas.NewFromRepository(cmd.RepoDirectory)
+	if err != nil {
+		return NewError(err.Error())
+	}
+
+	if err := yasInstance.Adopt(); err != nil {
+		return NewError(err.Error())
+	}
+
+	return nil
+}
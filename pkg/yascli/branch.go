@@ -0,0 +1,61 @@
+package yascli
+
+import (
+	"github.com/dansimau/yas/pkg/yas"
+)
+
+type branchCmd struct {
+	From        string `long:"from" description:"Branch or commit to create the new branch from (default: current branch)"`
+	StackFromPR string `long:"stack-from-pr" description:"Create the new branch on top of someone else's PR (number or URL), which restack keeps up to date" value-name:"PR"`
+	TakeChanges bool   `long:"take-changes" description:"Move uncommitted changes onto the new branch, aborting if they conflict"`
+	Insert      bool   `long:"insert" description:"Make the new branch the parent of the current branch's children, restacking them onto it"`
+
+	Args struct {
+		Name string `positional-arg-name:"name" required:"true"`
+	} `positional-args:"true"`
+}
+
+func (c *branchCmd) Execute(args []string) error {
+	yasInstance, err := yas.NewFromRepository(cmd.RepoDirectory)
+	if err != nil {
+		return NewError(err.Error())
+	}
+
+	if c.StackFromPR != "" {
+		if c.From != "" || c.Insert {
+			return NewError("--stack-from-pr cannot be used with --from or --insert")
+		}
+
+		if err := yasInstance.CreateBranchFromPullRequest(c.Args.Name, c.StackFromPR); err != nil {
+			return NewError(err.Error())
+		}
+
+		return nil
+	}
+
+	if c.Insert {
+		if c.From != "" || c.TakeChanges {
+			return NewError("--insert cannot be used with --from or --take-changes")
+		}
+
+		if err := yasInstance.InsertBranch(c.Args.Name); err != nil {
+			return NewError(err.Error())
+		}
+
+		return nil
+	}
+
+	if c.TakeChanges {
+		if err := yasInstance.CreateBranchWithChanges(c.Args.Name, c.From); err != nil {
+			return NewError(err.Error())
+		}
+
+		return nil
+	}
+
+	if err := yasInstance.CreateBranch(c.Args.Name, c.From); err != nil {
+		return NewError(err.Error())
+	}
+
+	return nil
+}
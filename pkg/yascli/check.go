@@ -0,0 +1,44 @@
+package yascli
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/dansimau/yas/pkg/yas"
+)
+
+type checkCmd struct {
+	JSON bool `long:"json" description:"Print violations as a JSON array"`
+}
+
+func (c *checkCmd) Execute(args []string) error {
+	yasInstance, err := yas.NewFromRepository(cmd.RepoDirectory)
+	if err != nil {
+		return NewError(err.Error())
+	}
+
+	violations, err := yasInstance.Check()
+	if err != nil {
+		return NewError(err.Error())
+	}
+
+	if c.JSON {
+		b, err := json.Marshal(violations)
+		if err != nil {
+			return NewError(err.Error())
+		}
+
+		fmt.Println(string(b))
+	} else {
+		// One violation per line, tab-separated: branch, rule, message
+		for _, violation := range violations {
+			fmt.Printf("%s\t%s\t%s\n", violation.Branch, violation.Rule, violation.Message)
+		}
+	}
+
+	if len(violations) > 0 {
+		return NewError(fmt.Sprintf("found %d violation(s)", len(violations)))
+	}
+
+	return nil
+}
@@ -0,0 +1,107 @@
+package yascli
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/dansimau/yas/pkg/yas"
+)
+
+type cleanCmd struct {
+	Days *int `long:"days" description:"Remove metadata for branches deleted more than this many days ago (default: deletedBranchRetentionDays, or 7)"`
+}
+
+func (c *cleanCmd) Execute(args []string) error {
+	yasInstance, err := yas.NewFromRepository(cmd.RepoDirectory)
+	if err != nil {
+		return NewError(err.Error())
+	}
+
+	retention := yasInstance.Config().DeletedBranchRetention()
+	if c.Days != nil {
+		retention = time.Duration(*c.Days) * 24 * time.Hour
+	}
+
+	if err := pruneBranchMetadata(yasInstance, retention); err != nil {
+		return NewError(err.Error())
+	}
+
+	if cmd.DryRun {
+		fmt.Println("Would prune worktrees [DRY-RUN]")
+	} else {
+		if err := yasInstance.PruneWorktrees(); err != nil {
+			return NewError(err.Error())
+		}
+
+		fmt.Println("Pruned worktrees")
+	}
+
+	if err := removeOrphanedWorktreeDirs(yasInstance); err != nil {
+		return NewError(err.Error())
+	}
+
+	return nil
+}
+
+// removeOrphanedWorktreeDirs deletes directories in the worktree directory
+// that git doesn't know about.
+func removeOrphanedWorktreeDirs(yasInstance *yas.YAS) error {
+	dirs, err := yasInstance.OrphanedWorktreeDirs()
+	if err != nil {
+		return err
+	}
+
+	if cmd.DryRun {
+		for _, dir := range dirs {
+			fmt.Printf("Would remove orphaned worktree directory: %s [DRY-RUN]\n", dir)
+		}
+
+		return nil
+	}
+
+	if err := yasInstance.RemoveOrphanedWorktreeDirs(dirs...); err != nil {
+		return err
+	}
+
+	for _, dir := range dirs {
+		fmt.Printf("Removed orphaned worktree directory: %s\n", dir)
+	}
+
+	return nil
+}
+
+// pruneBranchMetadata removes the metadata of branches deleted longer ago
+// than the retention period.
+func pruneBranchMetadata(yasInstance *yas.YAS, retention time.Duration) error {
+	staleBranches, err := yasInstance.StaleBranches(retention)
+	if err != nil {
+		return err
+	}
+
+	if cmd.DryRun {
+		for _, branch := range staleBranches {
+			fmt.Printf("Would remove metadata for branch: %s (deleted %s) [DRY-RUN]\n", branch.Name, daysAgo(branch.Deleted))
+		}
+
+		return nil
+	}
+
+	if err := yasInstance.PruneBranchMetadata(staleBranches.BranchNames()...); err != nil {
+		return err
+	}
+
+	for _, branch := range staleBranches {
+		fmt.Printf("Removed metadata for branch: %s (deleted %s)\n", branch.Name, daysAgo(branch.Deleted))
+	}
+
+	return nil
+}
+
+func daysAgo(t time.Time) string {
+	days := int(time.Since(t).Hours() / 24)
+	if days == 1 {
+		return "1 day ago"
+	}
+
+	return fmt.Sprintf("%d days ago", days)
+}
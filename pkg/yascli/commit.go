@@ -0,0 +1,24 @@
+package yascli
+
+import (
+	"github.com/dansimau/yas/pkg/yas"
+)
+
+type commitCmd struct {
+	Args struct {
+		GitArgs []string `positional-arg-name:"git-args" description:"Arguments to pass to git commit, after --"`
+	} `positional-args:"true"`
+}
+
+func (c *commitCmd) Execute(args []string) error {
+	yasInstance, err := yas.NewFromRepository(cmd.RepoDirectory)
+	if err != nil {
+		return NewError(err.Error())
+	}
+
+	if err := yasInstance.Commit(c.Args.GitArgs); err != nil {
+		return NewError(err.Error())
+	}
+
+	return nil
+}
@@ -1,6 +1,9 @@
 package yascli
 
 type configCmd struct {
-	Set  *configSetCmd  `command:"set" description:"Update/set a config value"`
-	Show *configShowCmd `command:"show" description:"Show current configuration"`
+	Get   *configGetCmd   `command:"get" description:"Get a config value"`
+	List  *configListCmd  `command:"list" description:"List config values"`
+	Set   *configSetCmd   `command:"set" description:"Update/set a config value"`
+	Show  *configShowCmd  `command:"show" description:"Show current configuration"`
+	Unset *configUnsetCmd `command:"unset" description:"Remove a config value, so the default applies"`
 }
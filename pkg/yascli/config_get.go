@@ -0,0 +1,29 @@
+package yascli
+
+import (
+	"fmt"
+)
+
+type configGetCmd struct {
+	Global bool `long:"global" description:"Get the value from the global config"`
+
+	Args struct {
+		Key string `positional-arg-name:"key" description:"Config key, e.g. trunkBranch or hooks.preSubmit" required:"true"`
+	} `positional-args:"true"`
+}
+
+func (c *configGetCmd) Execute(args []string) error {
+	values, err := readConfigValues(c.Global)
+	if err != nil {
+		return NewError(err.Error())
+	}
+
+	value, ok := values[c.Args.Key]
+	if !ok {
+		return NewError(fmt.Sprintf("config key '%s' is not set", c.Args.Key))
+	}
+
+	fmt.Println(value)
+
+	return nil
+}
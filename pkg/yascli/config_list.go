@@ -0,0 +1,54 @@
+package yascli
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/dansimau/yas/pkg/yas"
+)
+
+type configListCmd struct {
+	Global bool `long:"global" description:"List values in the global config only"`
+}
+
+func (c *configListCmd) Execute(args []string) error {
+	values, err := readConfigValues(c.Global)
+	if err != nil {
+		return NewError(err.Error())
+	}
+
+	keys := []string{}
+	for key := range values {
+		keys = append(keys, key)
+	}
+
+	slices.Sort(keys)
+
+	for _, key := range keys {
+		fmt.Printf("%s=%s\n", key, values[key])
+	}
+
+	return nil
+}
+
+// readConfigValues returns the values of the global config, or the effective
+// config of the repository (global config overridden by the repository
+// config).
+func readConfigValues(global bool) (map[string]string, error) {
+	var (
+		cfg *yas.Config
+		err error
+	)
+
+	if global {
+		cfg, err = yas.ReadGlobalConfig()
+	} else {
+		cfg, err = yas.ReadConfig(cmd.RepoDirectory)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return yas.ConfigValues(*cfg)
+}
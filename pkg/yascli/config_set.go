@@ -7,21 +7,63 @@ import (
 )
 
 type configSetCmd struct {
-	TrunkBranch *string `long:"trunk-branch" description:"The name of your trunk branch, e.g. main, develop" required:"true"`
+	TrunkBranch  *string `long:"trunk-branch" description:"The name of your trunk branch, e.g. main, develop"`
+	PRBody       *string `long:"pr-body" description:"How to generate PR bodies from commit messages" choice:"commits" choice:"first-commit" choice:"empty"`
+	SignCommits  *bool   `long:"sign-commits" description:"Sign all commits created by yas, e.g. during restack"`
+	BranchPrefix *string `long:"branch-prefix" description:"Prefix of your branch names, e.g. dan/"`
+	DefaultDraft *string `long:"default-draft" description:"Create new PRs as drafts (default: true)" choice:"true" choice:"false"`
+	MergeSubject *string `long:"merge-subject" description:"Template for squash-merge subjects, e.g. '{{.Title}} (#{{.Number}})'"`
+
+	MergeStrategy *string `long:"merge-strategy" description:"How to merge branches by default" choice:"squash" choice:"merge" choice:"rebase"`
+
+	Editor *string `long:"editor" description:"Editor command for editing messages, e.g. 'code --wait' (default: the same editor as git)"`
+
+	WorktreeDir *string `long:"worktree-dir" description:"Directory to create worktrees in, relative to the repository (default: ../<repo>-worktrees)"`
+
+	CommitTemplate *string `long:"commit-template" description:"Template to prefill commit messages with in yas commit, e.g. '[{{.Ticket}}] '"`
+	TicketPattern  *string `long:"ticket-pattern" description:"Regular expression matching ticket IDs in branch names (default: [A-Z][A-Z0-9]*-[0-9]+)"`
+	TicketURL      *string `long:"ticket-url" description:"Template for ticket links appended to new PR bodies, e.g. 'https://example.atlassian.net/browse/{{.Ticket}}'"`
+
+	MaxPRLines    *int `long:"max-pr-lines" description:"Warn on submit if a PR changes more lines than this (0 for no limit)"`
+	MaxPRFiles    *int `long:"max-pr-files" description:"Warn on submit if a PR changes more files than this (0 for no limit)"`
+	MaxStackDepth *int `long:"max-stack-depth" description:"Warn on submit if a stack is deeper than this (0 for no limit)"`
+
+	IgnoreBranchPatterns []string `long:"ignore-branch-pattern" description:"Glob pattern of untracked branches to hide, e.g. 'dependabot/*' (repeat for multiple; replaces the existing patterns)" value-name:"PATTERN"`
+
+	BaseBranchOverrides map[string]string `long:"base-branch-override" description:"Open PRs for branches on a parent against another branch, e.g. main:develop (repeat for multiple; replaces the existing overrides)" value-name:"PARENT:BASE"`
+
+	GitHubAttempts *int `long:"github-attempts" description:"How many times to try gh commands that fail with rate-limit or network errors (default: 3)"`
+
+	DeletedBranchRetentionDays *int  `long:"deleted-branch-retention-days" description:"Days to keep the metadata of deleted branches before yas clean and yas sync prune it (default: 7)"`
+	DeletedBranchRetainOpenPRs *bool `long:"deleted-branch-retain-open-prs" description:"Keep the metadata of deleted branches whose PRs are still open"`
+
+	Global bool `long:"global" description:"Set values in the global config, as defaults for all repositories"`
+
+	Branch string  `long:"branch" description:"Branch to set branch-specific values on (default: current)"`
+	PRBase *string `long:"pr-base" description:"Override the PR base of the branch (empty to use the parent)"`
 }
 
 func (c *configSetCmd) Execute(args []string) error {
-	cfg := &yas.Config{
-		RepoDirectory: cmd.RepoDirectory,
+	if c.Global && c.PRBase != nil {
+		return NewError("--pr-base cannot be set globally")
 	}
 
-	if yas.IsConfigured(cmd.RepoDirectory) {
-		_cfg, err := yas.ReadConfig(cmd.RepoDirectory)
+	if c.PRBase != nil && cmd.DryRun {
+		fmt.Println("[DRY-RUN] Not setting PR base")
+	} else if c.PRBase != nil {
+		yasInstance, err := yas.NewFromRepository(cmd.RepoDirectory)
 		if err != nil {
 			return NewError(err.Error())
 		}
 
-		cfg = _cfg
+		if err := yasInstance.SetPRBase(c.Branch, *c.PRBase); err != nil {
+			return NewError(err.Error())
+		}
+	}
+
+	cfg, err := c.readConfig()
+	if err != nil {
+		return NewError(err.Error())
 	}
 
 	changed := false
@@ -31,11 +73,107 @@ func (c *configSetCmd) Execute(args []string) error {
 		changed = true
 	}
 
+	if c.PRBody != nil {
+		cfg.PRBody = *c.PRBody
+		changed = true
+	}
+
+	if c.SignCommits != nil {
+		cfg.SignCommits = *c.SignCommits
+		changed = true
+	}
+
+	if c.BranchPrefix != nil {
+		cfg.BranchPrefix = *c.BranchPrefix
+		changed = true
+	}
+
+	if c.DefaultDraft != nil {
+		defaultDraft := *c.DefaultDraft == "true"
+		cfg.DefaultDraft = &defaultDraft
+		changed = true
+	}
+
+	if c.MergeSubject != nil {
+		cfg.MergeSubject = *c.MergeSubject
+		changed = true
+	}
+
+	if c.MergeStrategy != nil {
+		cfg.MergeStrategy = *c.MergeStrategy
+		changed = true
+	}
+
+	if c.Editor != nil {
+		cfg.Editor = *c.Editor
+		changed = true
+	}
+
+	if c.WorktreeDir != nil {
+		cfg.WorktreeDir = *c.WorktreeDir
+		changed = true
+	}
+
+	if c.CommitTemplate != nil {
+		cfg.CommitTemplate = *c.CommitTemplate
+		changed = true
+	}
+
+	if c.TicketPattern != nil {
+		cfg.TicketPattern = *c.TicketPattern
+		changed = true
+	}
+
+	if c.TicketURL != nil {
+		cfg.TicketURL = *c.TicketURL
+		changed = true
+	}
+
+	if c.IgnoreBranchPatterns != nil {
+		cfg.IgnoreBranchPatterns = c.IgnoreBranchPatterns
+		changed = true
+	}
+
+	if c.BaseBranchOverrides != nil {
+		cfg.BaseBranchOverrides = c.BaseBranchOverrides
+		changed = true
+	}
+
+	if c.GitHubAttempts != nil {
+		cfg.GitHubAttempts = *c.GitHubAttempts
+		changed = true
+	}
+
+	if c.DeletedBranchRetentionDays != nil {
+		cfg.DeletedBranchRetentionDays = c.DeletedBranchRetentionDays
+		changed = true
+	}
+
+	if c.DeletedBranchRetainOpenPRs != nil {
+		cfg.DeletedBranchRetainOpenPRs = *c.DeletedBranchRetainOpenPRs
+		changed = true
+	}
+
+	if c.MaxPRLines != nil {
+		cfg.Limits.MaxPRLines = *c.MaxPRLines
+		changed = true
+	}
+
+	if c.MaxPRFiles != nil {
+		cfg.Limits.MaxPRFiles = *c.MaxPRFiles
+		changed = true
+	}
+
+	if c.MaxStackDepth != nil {
+		cfg.Limits.MaxStackDepth = *c.MaxStackDepth
+		changed = true
+	}
+
 	if changed {
 		if cmd.DryRun {
 			fmt.Println("[DRY-RUN] Not writing config")
 		} else {
-			f, err := yas.WriteConfig(*cfg)
+			f, err := c.writeConfig(*cfg)
 			if err != nil {
 				return NewError(err.Error())
 			}
@@ -46,3 +184,26 @@ func (c *configSetCmd) Execute(args []string) error {
 
 	return nil
 }
+
+// readConfig reads the config file that is being updated, i.e. the global or
+// repository config. Global defaults are not included in the repository
+// config, so that they aren't written to the repository config file.
+func (c *configSetCmd) readConfig() (*yas.Config, error) {
+	if c.Global {
+		return yas.ReadGlobalConfig()
+	}
+
+	if !yas.IsConfigured(cmd.RepoDirectory) {
+		return &yas.Config{RepoDirectory: cmd.RepoDirectory}, nil
+	}
+
+	return yas.ReadRepoConfig(cmd.RepoDirectory)
+}
+
+func (c *configSetCmd) writeConfig(cfg yas.Config) (string, error) {
+	if c.Global {
+		return yas.WriteGlobalConfig(cfg)
+	}
+
+	return yas.WriteConfig(cfg)
+}
@@ -0,0 +1,61 @@
+package yascli
+
+import (
+	"fmt"
+
+	"github.com/dansimau/yas/pkg/yas"
+)
+
+type configUnsetCmd struct {
+	Global bool `long:"global" description:"Unset the value in the global config"`
+
+	Args struct {
+		Key string `positional-arg-name:"key" description:"Config key, e.g. branchPrefix or hooks.preSubmit" required:"true"`
+	} `positional-args:"true"`
+}
+
+func (c *configUnsetCmd) Execute(args []string) error {
+	if !c.Global && c.Args.Key == "trunkBranch" {
+		return NewError("trunkBranch cannot be unset in the repository config")
+	}
+
+	var (
+		cfg *yas.Config
+		err error
+	)
+
+	if c.Global {
+		cfg, err = yas.ReadGlobalConfig()
+	} else {
+		cfg, err = yas.ReadRepoConfig(cmd.RepoDirectory)
+	}
+
+	if err != nil {
+		return NewError(err.Error())
+	}
+
+	unset, err := yas.UnsetConfigValue(*cfg, c.Args.Key)
+	if err != nil {
+		return NewError(err.Error())
+	}
+
+	if cmd.DryRun {
+		fmt.Println("[DRY-RUN] Not writing config")
+		return nil
+	}
+
+	var f string
+	if c.Global {
+		f, err = yas.WriteGlobalConfig(unset)
+	} else {
+		f, err = yas.WriteConfig(unset)
+	}
+
+	if err != nil {
+		return NewError(err.Error())
+	}
+
+	fmt.Printf("Wrote config to: %s\n", f)
+
+	return nil
+}
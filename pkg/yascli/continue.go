@@ -0,0 +1,28 @@
+package yascli
+
+import (
+	"github.com/dansimau/yas/pkg/yas"
+)
+
+type continueCmd struct {
+	AbortCurrent bool `long:"abort-current" description:"Abort the rebase of the conflicted branch and skip it and its descendants"`
+	Autostash    bool `long:"autostash" description:"Stash local changes before restacking the remaining branches and restore them afterwards"`
+	ForceDirty   bool `long:"force-dirty" description:"Restack even if there are uncommitted changes"`
+}
+
+func (c *continueCmd) Execute(args []string) error {
+	yasInstance, err := yas.NewFromRepository(cmd.RepoDirectory)
+	if err != nil {
+		return NewError(err.Error())
+	}
+
+	if err := yasInstance.Continue(yas.ContinueOptions{
+		AbortCurrent: c.AbortCurrent,
+		Autostash:    c.Autostash,
+		ForceDirty:   c.ForceDirty,
+	}); err != nil {
+		return NewError(err.Error())
+	}
+
+	return nil
+}
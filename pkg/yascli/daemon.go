@@ -0,0 +1,8 @@
+package yascli
+
+type daemonCmd struct {
+	Start  *daemonStartCmd  `command:"start" description:"Start refreshing PR metadata in the background"`
+	Stop   *daemonStopCmd   `command:"stop" description:"Stop the background daemon"`
+	Status *daemonStatusCmd `command:"status" description:"Show whether the background daemon is running"`
+	Run    *daemonRunCmd    `command:"run" description:"Run the daemon in the foreground" hidden:"true"`
+}
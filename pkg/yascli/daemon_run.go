@@ -0,0 +1,19 @@
+package yascli
+
+import (
+	"time"
+
+	"github.com/dansimau/yas/pkg/yas"
+)
+
+type daemonRunCmd struct {
+	Interval int `long:"interval" description:"Seconds between refreshes of PR metadata" default:"60"`
+}
+
+func (c *daemonRunCmd) Execute(args []string) error {
+	if err := yas.NewDaemon(cmd.RepoDirectory).Run(time.Duration(c.Interval) * time.Second); err != nil {
+		return NewError(err.Error())
+	}
+
+	return nil
+}
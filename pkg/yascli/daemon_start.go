@@ -0,0 +1,42 @@
+package yascli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/dansimau/yas/pkg/yas"
+)
+
+type daemonStartCmd struct {
+	Interval int `long:"interval" description:"Seconds between refreshes of PR metadata" default:"60"`
+}
+
+func (c *daemonStartCmd) Execute(args []string) error {
+	executable, err := os.Executable()
+	if err != nil {
+		return NewError(err.Error())
+	}
+
+	repoDirectory, err := filepath.Abs(cmd.RepoDirectory)
+	if err != nil {
+		return NewError(err.Error())
+	}
+
+	daemon := yas.NewDaemon(repoDirectory)
+
+	pid, err := daemon.Start([]string{
+		executable,
+		"--repo", repoDirectory,
+		"daemon", "run",
+		"--interval", strconv.Itoa(c.Interval),
+	})
+	if err != nil {
+		return NewError(err.Error())
+	}
+
+	fmt.Printf("Started daemon (pid %d), logging to %s\n", pid, daemon.LogFilePath())
+
+	return nil
+}
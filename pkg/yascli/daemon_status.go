@@ -0,0 +1,24 @@
+package yascli
+
+import (
+	"fmt"
+
+	"github.com/dansimau/yas/pkg/yas"
+)
+
+type daemonStatusCmd struct{}
+
+func (c *daemonStatusCmd) Execute(args []string) error {
+	pid, running, err := yas.NewDaemon(cmd.RepoDirectory).Status()
+	if err != nil {
+		return NewError(err.Error())
+	}
+
+	if running {
+		fmt.Printf("Daemon is running (pid %d)\n", pid)
+	} else {
+		fmt.Println("Daemon is not running")
+	}
+
+	return nil
+}
@@ -0,0 +1,19 @@
+package yascli
+
+import (
+	"fmt"
+
+	"github.com/dansimau/yas/pkg/yas"
+)
+
+type daemonStopCmd struct{}
+
+func (c *daemonStopCmd) Execute(args []string) error {
+	if err := yas.NewDaemon(cmd.RepoDirectory).Stop(); err != nil {
+		return NewError(err.Error())
+	}
+
+	fmt.Println("Stopped daemon")
+
+	return nil
+}
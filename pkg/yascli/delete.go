@@ -0,0 +1,123 @@
+package yascli
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/dansimau/yas/pkg/cliutil"
+	"github.com/dansimau/yas/pkg/yas"
+)
+
+type deleteCmd struct {
+	Pattern     string `long:"pattern" description:"Delete tracked branches matching the glob pattern, e.g. 'dan/spike-*'"`
+	Interactive bool   `long:"interactive" short:"i" description:"Select the tracked branches to delete from a list"`
+
+	Args struct {
+		Names []string `positional-arg-name:"name" description:"Branches to delete"`
+	} `positional-args:"true"`
+}
+
+func (c *deleteCmd) Execute(args []string) error {
+	yasInstance, err := yas.NewFromRepository(cmd.RepoDirectory)
+	if err != nil {
+		return NewError(err.Error())
+	}
+
+	names, err := c.branchNames(yasInstance)
+	if err != nil {
+		return NewError(err.Error())
+	}
+
+	if len(names) == 0 {
+		fmt.Println("No branches to delete")
+		return nil
+	}
+
+	if cmd.DryRun {
+		for _, name := range names {
+			fmt.Printf("Would delete branch: %s [DRY-RUN]\n", name)
+		}
+
+		return nil
+	}
+
+	fmt.Println("Branches to delete:")
+	for _, name := range names {
+		fmt.Printf("    %s\n", name)
+	}
+
+	if !cliutil.Confirm(fmt.Sprintf("Delete %d branch(es)? [y/N]", len(names)), false) {
+		return NewError("aborted")
+	}
+
+	results, err := yasInstance.DeleteBranches(names)
+	if err != nil {
+		return NewError(err.Error())
+	}
+
+	rows := [][]string{{"BRANCH", "RESULT"}}
+	failed := 0
+
+	for _, result := range results {
+		switch {
+		case result.Err != nil:
+			rows = append(rows, []string{result.Branch, "failed: " + result.Err.Error()})
+			failed++
+		case result.Worktree != "":
+			rows = append(rows, []string{result.Branch, "deleted (removed worktree " + result.Worktree + ")"})
+		default:
+			rows = append(rows, []string{result.Branch, "deleted"})
+		}
+	}
+
+	cliutil.PrintTable(rows)
+
+	if failed > 0 {
+		return NewError(fmt.Sprintf("failed to delete %d of %d branch(es)", failed, len(results)))
+	}
+
+	return nil
+}
+
+// branchNames returns the branches to delete: those specified as arguments,
+// matching the pattern and selected interactively.
+func (c *deleteCmd) branchNames(yasInstance *yas.YAS) ([]string, error) {
+	if len(c.Args.Names) == 0 && c.Pattern == "" && !c.Interactive {
+		return nil, errors.New("specify branches to delete, --pattern or --interactive")
+	}
+
+	names := slices.Clone(c.Args.Names)
+
+	if c.Pattern != "" {
+		matches, err := yasInstance.BranchesMatching(c.Pattern)
+		if err != nil {
+			return nil, err
+		}
+
+		names = append(names, matches...)
+	}
+
+	if c.Interactive {
+		tracked := yasInstance.TrackedBranches().SortedByName().BranchNames()
+		if len(tracked) == 0 {
+			return nil, nil
+		}
+
+		selected, err := cliutil.MultiSelect("Select branches to delete (e.g. 1,3-4):", tracked)
+		if err != nil {
+			return nil, err
+		}
+
+		names = append(names, selected...)
+	}
+
+	unique := []string{}
+	for _, name := range names {
+		if !slices.Contains(unique, name) {
+			unique = append(unique, name)
+		}
+	}
+
+	return unique, nil
+}
@@ -0,0 +1,49 @@
+package yascli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/dansimau/yas/pkg/yas"
+)
+
+type demoCmd struct {
+	Args struct {
+		Dir string `positional-arg-name:"dir" description:"Directory to create the demo repository in (default: a new temporary directory)"`
+	} `positional-args:"true"`
+}
+
+func (c *demoCmd) Execute(args []string) error {
+	dir := c.Args.Dir
+	if dir == "" {
+		tmpDir, err := os.MkdirTemp("", "yas-demo-")
+		if err != nil {
+			return NewError(err.Error())
+		}
+
+		dir = tmpDir
+	} else if entries, err := os.ReadDir(dir); err == nil && len(entries) > 0 {
+		return NewError(fmt.Sprintf("directory %s is not empty", dir))
+	}
+
+	repoDir, gitHubStubFile, err := yas.CreateDemoRepository(dir)
+	if err != nil {
+		return NewError(err.Error())
+	}
+
+	fmt.Printf("Created demo repository: %s\n", repoDir)
+	fmt.Println()
+	fmt.Println("It has a stack of three branches with PRs, and main has a new commit since")
+	fmt.Println("the stack was created. Its origin is a local repository and its PRs are on a")
+	fmt.Println("fake GitHub, so nothing is pushed to GitHub.")
+	fmt.Println()
+	fmt.Println("Try:")
+	fmt.Printf("    cd %s\n", repoDir)
+	fmt.Printf("    export YAS_GH_STUB=%s\n", gitHubStubFile)
+	fmt.Println("    yas list")
+	fmt.Println("    yas restack")
+	fmt.Println("    yas submit --stack")
+	fmt.Println("    yas branch demo/topic-d")
+
+	return nil
+}
@@ -0,0 +1,49 @@
+package yascli
+
+import (
+	"fmt"
+
+	"github.com/dansimau/yas/pkg/yas"
+)
+
+type doctorCmd struct {
+	Fix bool `long:"fix" description:"Fix problems that can be fixed automatically, e.g. record missing branch points"`
+}
+
+func (c *doctorCmd) Execute(args []string) error {
+	yasInstance, err := yas.NewFromRepository(cmd.RepoDirectory)
+	if err != nil {
+		return NewError(err.Error())
+	}
+
+	if c.Fix {
+		if cmd.DryRun {
+			fmt.Println("[DRY-RUN] Not fixing problems")
+		} else {
+			fixes, err := yasInstance.DoctorFix()
+			if err != nil {
+				return NewError(err.Error())
+			}
+
+			for _, fix := range fixes {
+				fmt.Printf("🔧 Fixed: %s\n", fix)
+			}
+		}
+	}
+
+	problems, err := yasInstance.Doctor()
+	if err != nil {
+		return NewError(err.Error())
+	}
+
+	if len(problems) == 0 {
+		fmt.Println("✅ No problems found")
+		return nil
+	}
+
+	for _, problem := range problems {
+		fmt.Printf("⚠️  %s\n", problem)
+	}
+
+	return NewError(fmt.Sprintf("found %d problem(s)", len(problems)))
+}
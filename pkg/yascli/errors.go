@@ -4,13 +4,20 @@ package yascli
 // message, e.g. "ERROR: Aborted." or similar. If the CLI exits with an error
 // that is not Error, it will attempt to print a stack trace.
 type Error struct {
-	msg string
+	msg      string
+	exitCode int
 }
 
 func NewError(msg string) *Error {
 	return &Error{msg: msg}
 }
 
+// NewExitCode returns an Error that makes the CLI exit with the given exit
+// code. If msg is empty, nothing is printed.
+func NewExitCode(exitCode int, msg string) *Error {
+	return &Error{msg: msg, exitCode: exitCode}
+}
+
 func (e *Error) Error() string {
 	return e.msg
 }
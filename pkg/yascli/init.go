@@ -16,7 +16,7 @@ func (c *initCmd) Execute(args []string) error {
 	}
 
 	if yas.IsConfigured(cmd.RepoDirectory) {
-		_cfg, err := yas.ReadConfig(cmd.RepoDirectory)
+		_cfg, err := yas.ReadRepoConfig(cmd.RepoDirectory)
 		if err != nil {
 			return NewError(err.Error())
 		}
@@ -24,7 +24,7 @@ func (c *initCmd) Execute(args []string) error {
 		cfg = _cfg
 	}
 
-	cfg.TrunkBranch = cliutil.Prompt(cliutil.PromptOptions{
+	trunkBranch, err := cliutil.Prompt(cliutil.PromptOptions{
 		Text:    "What is your trunk branch name?",
 		Default: cfg.TrunkBranch,
 		Validator: func(input string) error {
@@ -35,6 +35,11 @@ func (c *initCmd) Execute(args []string) error {
 			return nil
 		},
 	})
+	if err != nil {
+		return NewError(err.Error())
+	}
+
+	cfg.TrunkBranch = trunkBranch
 
 	dest, err := yas.WriteConfig(*cfg)
 	if err != nil {
@@ -1,10 +1,30 @@
 package yascli
 
 import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dansimau/yas/pkg/log"
+	"github.com/dansimau/yas/pkg/termutil"
 	"github.com/dansimau/yas/pkg/yas"
 )
 
-type listCmd struct{}
+type listCmd struct {
+	All       bool   `long:"all" short:"a" description:"Include untracked branches"`
+	Mine      bool   `long:"mine" description:"Only show branches with my branch prefix"`
+	Author    string `long:"author" description:"Only show branches with PRs by the specified GitHub user"`
+	Stale     bool   `long:"stale" description:"Only show branches with no commits recently (see --older-than)"`
+	OlderThan string `long:"older-than" description:"Age for --stale, e.g. 30d, 2w, 12h" default:"30d"`
+	Stack     string `long:"stack" description:"Only show the stack with the specified name, or containing the specified branch"`
+	Porcelain bool   `long:"porcelain" description:"Print one line per branch in a stable, tab-separated format for scripts: name, parent, PR URL and flags"`
+
+	Watch          bool `long:"watch" short:"w" description:"Redraw the list periodically"`
+	Interval       int  `long:"interval" description:"Seconds between redraws in watch mode" default:"2"`
+	RemoteInterval int  `long:"remote-interval" description:"Seconds between PR status refreshes in watch mode" default:"60"`
+}
 
 func (c *listCmd) Execute(args []string) error {
 	yasInstance, err := yas.NewFromRepository(cmd.RepoDirectory)
@@ -12,5 +32,112 @@ func (c *listCmd) Execute(args []string) error {
 		return NewError(err.Error())
 	}
 
-	return yasInstance.List()
+	opts, err := c.listOptions()
+	if err != nil {
+		return NewError(err.Error())
+	}
+
+	if c.Watch {
+		return c.watch(opts)
+	}
+
+	return yasInstance.List(opts)
+}
+
+func (c *listCmd) listOptions() (yas.ListOptions, error) {
+	opts := yas.ListOptions{
+		All:    c.All,
+		Mine:   c.Mine,
+		Author: c.Author,
+		Stack:  c.Stack,
+
+		Porcelain: c.Porcelain,
+		// Uses the global --verbose flag
+		Verbose: len(cmd.Verbose) > 0,
+	}
+
+	if c.Stale {
+		olderThan, err := parseAge(c.OlderThan)
+		if err != nil {
+			return opts, err
+		}
+
+		opts.OlderThan = olderThan
+	}
+
+	return opts, nil
+}
+
+// parseAge parses an age such as "30d" or "2w". Any value accepted by
+// time.ParseDuration is also accepted.
+func parseAge(s string) (time.Duration, error) {
+	units := map[string]time.Duration{
+		"d": 24 * time.Hour,
+		"w": 7 * 24 * time.Hour,
+	}
+
+	for suffix, unit := range units {
+		if n, err := strconv.Atoi(strings.TrimSuffix(s, suffix)); err == nil && strings.HasSuffix(s, suffix) {
+			return time.Duration(n) * unit, nil
+		}
+	}
+
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid age: %s", s)
+	}
+
+	return d, nil
+}
+
+// watch redraws the list every interval until the process is interrupted.
+// Remote PR status is refreshed on a slower interval since it requires calls
+// to GitHub.
+func (c *listCmd) watch(opts yas.ListOptions) error {
+	interval := time.Duration(c.Interval) * time.Second
+	remoteInterval := time.Duration(c.RemoteInterval) * time.Second
+
+	var lastRemoteRefresh time.Time
+
+	for {
+		refreshRemote := time.Since(lastRemoteRefresh) >= remoteInterval
+		if refreshRemote {
+			lastRemoteRefresh = time.Now()
+		}
+
+		if err := c.redraw(opts, interval, refreshRemote); err != nil {
+			return err
+		}
+
+		time.Sleep(interval)
+	}
+}
+
+// redraw is one iteration of watch mode: it refreshes the PR status if
+// refreshRemote is true, then clears the screen and prints the list.
+func (c *listCmd) redraw(opts yas.ListOptions, interval time.Duration, refreshRemote bool) error {
+	if refreshRemote {
+		// Like the daemon, skip the refresh while another yas operation is
+		// modifying the state, so that its changes aren't overwritten
+		if err := yas.RefreshAllRemoteStatus(cmd.RepoDirectory, "list --watch"); err != nil {
+			log.Warn("failed to refresh PR status:", err)
+		}
+	}
+
+	// Reload from disk each tick to pick up changes made by other yas
+	// invocations
+	yasInstance, err := yas.NewFromRepository(cmd.RepoDirectory)
+	if err != nil {
+		return NewError(err.Error())
+	}
+
+	if termutil.ColorEnabled(os.Stdout) {
+		// Clear screen and move the cursor to the top left
+		fmt.Print("\033[H\033[2J")
+	} else {
+		fmt.Println()
+	}
+	fmt.Printf("Every %s: yas list (%s)\n\n", interval, time.Now().Format(time.TimeOnly))
+
+	return yasInstance.List(opts)
 }
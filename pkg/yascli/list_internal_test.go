@@ -0,0 +1,62 @@
+package yascli
+
+import (
+	"os"
+	"path"
+	"testing"
+
+	"github.com/dansimau/yas/pkg/testutil"
+	"github.com/dansimau/yas/pkg/yas"
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/assert/cmp"
+)
+
+func TestListWatchRedrawSkipsRefreshWhileLocked(t *testing.T) {
+	testutil.WithTempWorkingDir(t, func() {
+		testutil.ExecOrFail(t, `
+			git init --initial-branch=main
+
+			touch main
+			git add main
+			git commit -m "main-0"
+
+			git branch topic-a
+		`)
+
+		wd, err := os.Getwd()
+		assert.NilError(t, err)
+
+		// Records each call to gh, and responds with no PRs
+		binDir := t.TempDir()
+		assert.NilError(t, os.WriteFile(path.Join(binDir, "gh"), []byte("#!/bin/sh\necho x >> \"$0.calls\"\necho '[]'\n"), 0o755))
+		t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+		assert.Equal(t, Run("config", "set", "--trunk-branch=main"), 0)
+		assert.Equal(t, Run("add", "--branch=topic-a", "--parent=main"), 0)
+
+		cmd = &Cmd{RepoDirectory: wd}
+		c := &listCmd{}
+
+		lock, err := yas.LockRepository(wd, "test")
+		assert.NilError(t, err)
+
+		stdout, _, err := testutil.CaptureOutput(func() {
+			assert.NilError(t, c.redraw(yas.ListOptions{}, 0, true))
+		})
+		assert.NilError(t, err)
+		assert.Assert(t, cmp.Contains(stdout, "topic-a"))
+
+		_, err = os.Stat(path.Join(binDir, "gh.calls"))
+		assert.Assert(t, os.IsNotExist(err))
+
+		assert.NilError(t, lock.Release())
+
+		_, _, err = testutil.CaptureOutput(func() {
+			assert.NilError(t, c.redraw(yas.ListOptions{}, 0, true))
+		})
+		assert.NilError(t, err)
+
+		_, err = os.Stat(path.Join(binDir, "gh.calls"))
+		assert.NilError(t, err)
+	})
+}
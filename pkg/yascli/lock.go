@@ -0,0 +1,61 @@
+package yascli
+
+import (
+	"strings"
+
+	"github.com/jessevdk/go-flags"
+)
+
+// mutatingCommand is implemented by commands that modify branches or yas
+// state. If locksRepository returns true, the repository is locked while the
+// command runs, so that it can't run concurrently with other such commands.
+type mutatingCommand interface {
+	locksRepository() bool
+}
+
+func (*addCmd) locksRepository() bool            { return true }
+func (*adoptCmd) locksRepository() bool          { return true }
+func (*branchCmd) locksRepository() bool         { return true }
+func (*cleanCmd) locksRepository() bool          { return true }
+func (*configSetCmd) locksRepository() bool      { return true }
+func (*configUnsetCmd) locksRepository() bool    { return true }
+func (*continueCmd) locksRepository() bool       { return true }
+func (*deleteCmd) locksRepository() bool         { return true }
+func (*initCmd) locksRepository() bool           { return true }
+func (*moveCmd) locksRepository() bool           { return true }
+func (*prAutoMergeCmd) locksRepository() bool    { return true }
+func (*prCheckoutCmd) locksRepository() bool     { return true }
+func (*prReadyCmd) locksRepository() bool        { return true }
+func (*prViewCmd) locksRepository() bool         { return true }
+func (*recoverCmd) locksRepository() bool        { return true }
+func (*refreshCmd) locksRepository() bool        { return true }
+func (*restackCmd) locksRepository() bool        { return true }
+func (*rewordCmd) locksRepository() bool         { return true }
+func (*stackNameCmd) locksRepository() bool      { return true }
+func (*stackRestackCmd) locksRepository() bool   { return true }
+func (*stackSubmitCmd) locksRepository() bool    { return true }
+func (*stackWIPCmd) locksRepository() bool       { return true }
+func (*stateImportCmd) locksRepository() bool    { return true }
+func (*submitCmd) locksRepository() bool         { return true }
+func (*switchCmd) locksRepository() bool         { return true }
+func (*syncCmd) locksRepository() bool           { return true }
+func (*worktreeAddCmd) locksRepository() bool    { return true }
+func (*worktreeRemoveCmd) locksRepository() bool { return true }
+
+// Doctor only modifies the state when fixing problems
+func (c *doctorCmd) locksRepository() bool { return c.Fix }
+
+// Merge locks the repository itself once the checks have passed
+func (c *mergeCmd) locksRepository() bool      { return !c.Wait }
+func (c *stackMergeCmd) locksRepository() bool { return !c.Wait }
+
+// activeCommandName returns the full name of the command being run, e.g.
+// "pr checkout".
+func activeCommandName(parser *flags.Parser) string {
+	names := []string{}
+	for c := parser.Active; c != nil; c = c.Active {
+		names = append(names, c.Name)
+	}
+
+	return strings.Join(names, " ")
+}
@@ -6,16 +6,47 @@ import (
 	"os"
 	"path"
 
+	"github.com/dansimau/yas/pkg/cliutil"
 	"github.com/dansimau/yas/pkg/fsutil"
+	"github.com/dansimau/yas/pkg/log"
+	"github.com/dansimau/yas/pkg/yas"
 	"github.com/jessevdk/go-flags"
 )
 
 var cmd *Cmd
 
+// restoreEnv restores the environment variables set with setenv.
+var restoreEnv []func()
+
+// setenv sets an environment variable until Run returns, when its previous
+// value is restored, so that flags don't carry over to later invocations in
+// the same process, e.g. in tests. Commands run by yas inherit the variable.
+func setenv(key, value string) {
+	previous, wasSet := os.LookupEnv(key)
+	restoreEnv = append(restoreEnv, func() {
+		if wasSet {
+			os.Setenv(key, previous)
+		} else {
+			os.Unsetenv(key)
+		}
+	})
+
+	os.Setenv(key, value)
+}
+
 type Cmd struct {
 	DryRun        bool   `long:"dry-run" description:"Don't make any changes, just show what will happen"`
+	NoHooks       bool   `long:"no-hooks" description:"Don't run configured hooks"`
+	NoColor       bool   `long:"no-color" description:"Disable colors and other terminal escape sequences in output"`
 	RepoDirectory string `long:"repo" short:"r" description:"Repo directory"`
-	Verbose       bool   `long:"verbose" short:"v" description:"Verbose output"`
+	Verbose       []bool `long:"verbose" short:"v" description:"Verbose output (repeat for debug output)"`
+	Quiet         bool   `long:"quiet" short:"q" description:"Print nothing except errors, e.g. for cron jobs (also YAS_QUIET=1)"`
+	LogFormat     string `long:"log-format" description:"Log output format" choice:"text" choice:"json" default:"text"`
+	LogFile       string `long:"log-file" description:"Append log output to a file instead of stderr"`
+	Output        string `long:"output" description:"Output format of the result of submit, restack, merge, delete and sync" choice:"text" choice:"json" default:"text"`
+
+	NonInteractive bool `long:"non-interactive" description:"Never prompt: use defaults, including for confirmations, and fail if input is required (also YAS_NONINTERACTIVE=1)"`
+	Yes            bool `long:"yes" description:"Like --non-interactive, but answer yes to confirmations (also YAS_YES=1)"`
 }
 
 func mustAddCommand(f *flags.Command, err error) *flags.Command {
@@ -25,6 +56,19 @@ func mustAddCommand(f *flags.Command, err error) *flags.Command {
 	return f
 }
 
+// logLevel returns the log level for the number of times the verbose flag was
+// specified.
+func logLevel(verbosity int) log.Level {
+	switch verbosity {
+	case 0:
+		return log.LevelWarn
+	case 1:
+		return log.LevelInfo
+	default:
+		return log.LevelDebug
+	}
+}
+
 // Run executes the program with the specified arguments and returns the code
 // the process should exit with.
 func Run(args ...string) (exitCode int) {
@@ -32,11 +76,18 @@ func Run(args ...string) (exitCode int) {
 	// between invocations.
 	cmd = &Cmd{}
 
-	parser := flags.NewParser(cmd, flags.HelpFlag)
+	restoreEnv = nil
+	defer func() {
+		for i := len(restoreEnv) - 1; i >= 0; i-- {
+			restoreEnv[i]()
+		}
+	}()
+
+	parser := flags.NewParser(cmd, flags.HelpFlag|flags.PassDoubleDash)
 
 	parser.CommandHandler = func(command flags.Commander, args []string) error {
-		// Apply defaults to cmd
-		if cmd.RepoDirectory == "" {
+		// Apply defaults to cmd. The demo command creates its own repository.
+		if _, isDemo := command.(*demoCmd); cmd.RepoDirectory == "" && !isDemo {
 			gitDir, err := fsutil.SearchParentsForPathFromCwd(".git")
 			if err != nil {
 				return NewError("cannot find repository (.git directory) (hint: specify --repo or run yas from inside repostory)")
@@ -46,22 +97,110 @@ func Run(args ...string) (exitCode int) {
 			cmd.RepoDirectory = repoDir
 		}
 
-		if cmd.Verbose {
-			os.Setenv("YAS_VERBOSE", "1")
-			os.Setenv("XEXEC_VERBOSE", "1")
+		if cmd.NoHooks {
+			setenv("YAS_NO_HOOKS", "1")
+		}
+
+		if cmd.NoColor {
+			setenv("NO_COLOR", "1")
+		}
+
+		if cmd.NonInteractive || cmd.Yes {
+			setenv("YAS_NONINTERACTIVE", "1")
+		}
+
+		if cmd.Yes {
+			setenv("YAS_YES", "1")
+		}
+
+		if cmd.Quiet {
+			setenv("YAS_QUIET", "1")
+		}
+
+		quiet := cliutil.Quiet()
+		if quiet && len(cmd.Verbose) > 0 {
+			return NewError("--quiet and --verbose can't be used together")
+		}
+
+		if len(cmd.Verbose) > 0 {
+			setenv("YAS_VERBOSE", "1")
+			setenv("XEXEC_VERBOSE", "1")
+		}
+
+		level := logLevel(len(cmd.Verbose))
+		if quiet {
+			level = log.LevelError
+			setenv("XEXEC_QUIET", "1")
+		}
+
+		if err := log.Configure(log.Options{
+			Level:  level,
+			Format: cmd.LogFormat,
+			File:   cmd.LogFile,
+		}); err != nil {
+			return NewError(err.Error())
+		}
+
+		if _, ok := command.(resultCommand); !ok && cmd.Output == "json" {
+			return NewError(fmt.Sprintf("--output json is not supported by `yas %s`", activeCommandName(parser)))
+		}
+
+		if c, ok := command.(mutatingCommand); ok && c.locksRepository() {
+			lock, err := yas.LockRepository(cmd.RepoDirectory, activeCommandName(parser))
+			if err != nil {
+				return NewError(err.Error())
+			}
+			defer lock.Release()
+		}
+
+		execute := func() error {
+			return command.Execute(args)
+		}
+
+		if quiet {
+			execute = withoutOutput(execute)
+		}
+
+		if cmd.Output == "json" {
+			return executeWithJSONResult(activeCommandName(parser), execute)
 		}
 
 		// Run command
-		return command.Execute(args)
+		return execute()
 	}
 
 	mustAddCommand(parser.AddCommand("add", "Add/set parent of branch", "", &addCmd{}))
+	mustAddCommand(parser.AddCommand("adopt", "Track the branches of your open PRs, using PR bases as parents", "", &adoptCmd{}))
+	mustAddCommand(parser.AddCommand("branch", "Create a new branch on top of the current branch", "", &branchCmd{}))
+	mustAddCommand(parser.AddCommand("check", "Check stack invariants, exiting non-zero if any are violated", "", &checkCmd{}))
+	mustAddCommand(parser.AddCommand("clean", "Remove stale branch metadata and prune worktrees", "", &cleanCmd{}))
+	mustAddCommand(parser.AddCommand("commit", "Commit staged changes, prefilling the message from the branch's ticket ID", "", &commitCmd{}))
 	mustAddCommand(parser.AddCommand("config", "Manage repository-specific configuration", "", &configCmd{}))
+	mustAddCommand(parser.AddCommand("continue", "Resume a restack that stopped due to conflicts", "", &continueCmd{}))
+	mustAddCommand(parser.AddCommand("daemon", "Refresh PR metadata in the background", "", &daemonCmd{}))
+	mustAddCommand(parser.AddCommand("delete", "Delete branches, by name, pattern or interactively", "", &deleteCmd{}))
+	mustAddCommand(parser.AddCommand("demo", "Create a playground repository with a stack to try yas on", "", &demoCmd{}))
+	mustAddCommand(parser.AddCommand("doctor", "Check for problems with the repository and stacks", "", &doctorCmd{}))
 	mustAddCommand(parser.AddCommand("init", "Set up initial configuration", "", &initCmd{}))
 	mustAddCommand(parser.AddCommand("list", "List stacks", "", &listCmd{}))
+	mustAddCommand(parser.AddCommand("merge", "Merge the current branch into trunk", "", &mergeCmd{}))
+	mustAddCommand(parser.AddCommand("move", "Move a branch (and its descendants) onto another branch", "", &moveCmd{}))
+	mustAddCommand(parser.AddCommand("owns", "Show which tracked branches modify a file", "", &ownsCmd{}))
+	mustAddCommand(parser.AddCommand("pr", "Work with pull requests", "", &prCmd{}))
+	mustAddCommand(parser.AddCommand("prompt", "Print a summary of the current stack for shell prompts", promptLongDescription, &promptCmd{}))
+	mustAddCommand(parser.AddCommand("recover", "Rebuild the state file from git and GitHub", "", &recoverCmd{}))
+	mustAddCommand(parser.AddCommand("refresh", "Fetch the PR status of branches from GitHub", "", &refreshCmd{}))
+	mustAddCommand(parser.AddCommand("report", "Generate a markdown report of recent work for standups", "", &reportCmd{}))
+	mustAddCommand(parser.AddCommand("reword", "Edit the commit messages of the current branch", "", &rewordCmd{}))
+	mustAddCommand(parser.AddCommand("stack", "Work with the current stack, or the stack containing a branch", "", &stackCmd{}))
+	mustAddCommand(parser.AddCommand("state", "Export or import the state for use in another clone", "", &stateCmd{}))
+	mustAddCommand(parser.AddCommand("stats", "Show stack and PR throughput metrics", "", &statsCmd{}))
+	mustAddCommand(parser.AddCommand("status", "Show the current branch, its parent, PR and worktree", "", &statusCmd{}))
 	mustAddCommand(parser.AddCommand("submit", "Submit", "", &submitCmd{}))
 	mustAddCommand(parser.AddCommand("restack", "Rebase all branches in the current stack", "", &restackCmd{}))
+	mustAddCommand(parser.AddCommand("switch", "Switch to a branch (- for the previous branch)", "", &switchCmd{}))
 	mustAddCommand(parser.AddCommand("sync", "Sync", "", &syncCmd{}))
+	mustAddCommand(parser.AddCommand("worktree", "Work with worktrees", "", &worktreeCmd{}))
 
 	_, err := parser.ParseArgs(args)
 	if err != nil {
@@ -71,6 +210,14 @@ func Run(args ...string) (exitCode int) {
 			return 0
 		}
 
+		if cliErr := (*Error)(nil); errors.As(err, &cliErr) && cliErr.exitCode != 0 {
+			if cliErr.msg != "" {
+				fmt.Fprintf(os.Stderr, "ERROR: %v\n", err)
+			}
+
+			return cliErr.exitCode
+		}
+
 		if errors.Is(err, &Error{}) {
 			// Error, just exit with a message
 			fmt.Fprintf(os.Stderr, "ERROR: %v\n", err)
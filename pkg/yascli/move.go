@@ -0,0 +1,54 @@
+package yascli
+
+import (
+	"github.com/dansimau/yas/pkg/yas"
+)
+
+type moveCmd struct {
+	Onto               string `long:"onto" description:"The new parent branch"`
+	After              string `long:"after" description:"Move only the branch, in between this branch and its children"`
+	Before             string `long:"before" description:"Move only the branch, in between this branch and its parent"`
+	WithoutDescendants bool   `long:"without-descendants" description:"Move only the branch; reattach its children to its old parent"`
+	Autostash          bool   `long:"autostash" description:"Stash local changes before moving and restore them afterwards"`
+	ForceDirty         bool   `long:"force-dirty" description:"Move even if there are uncommitted changes"`
+
+	Args struct {
+		Branch string `positional-arg-name:"branch" description:"Branch to move (default: current)"`
+	} `positional-args:"true"`
+}
+
+func (c *moveCmd) Execute(args []string) error {
+	targets := 0
+	for _, target := range []string{c.Onto, c.After, c.Before} {
+		if target != "" {
+			targets++
+		}
+	}
+
+	if targets != 1 {
+		return NewError("specify exactly one of --onto, --after or --before")
+	}
+
+	if c.Onto == "" && c.WithoutDescendants {
+		return NewError("--without-descendants is implied by --after and --before")
+	}
+
+	yasInstance, err := yas.NewFromRepository(cmd.RepoDirectory)
+	if err != nil {
+		return NewError(err.Error())
+	}
+
+	if err := yasInstance.Move(yas.MoveOptions{
+		Branch:             c.Args.Branch,
+		Onto:               c.Onto,
+		After:              c.After,
+		Before:             c.Before,
+		WithoutDescendants: c.WithoutDescendants,
+		Autostash:          c.Autostash,
+		ForceDirty:         c.ForceDirty,
+	}); err != nil {
+		return NewError(err.Error())
+	}
+
+	return nil
+}
@@ -0,0 +1,68 @@
+package yascli
+
+import (
+	"encoding/json"
+	"os"
+
+	"github.com/dansimau/yas/pkg/yas"
+)
+
+// resultCommand is implemented by commands that can print a machine-readable
+// result of what they did with `--output json`.
+type resultCommand interface {
+	reportsResult()
+}
+
+func (*deleteCmd) reportsResult()       {}
+func (*mergeCmd) reportsResult()        {}
+func (*restackCmd) reportsResult()      {}
+func (*stackMergeCmd) reportsResult()   {}
+func (*stackRestackCmd) reportsResult() {}
+func (*stackSubmitCmd) reportsResult()  {}
+func (*submitCmd) reportsResult()       {}
+func (*syncCmd) reportsResult()         {}
+
+// executeWithJSONResult runs the command and prints its result as JSON to
+// stdout. Other output that would go to stdout goes to stderr instead, so
+// that stdout only contains the result.
+func executeWithJSONResult(name string, execute func() error) error {
+	result := yas.RecordResult(name)
+	defer yas.StopRecording()
+
+	stdout := os.Stdout
+	os.Stdout = os.Stderr
+
+	err := execute()
+
+	os.Stdout = stdout
+
+	result.Success = err == nil
+	if err != nil {
+		result.Error = err.Error()
+	}
+
+	if encodeErr := json.NewEncoder(stdout).Encode(result); encodeErr != nil && err == nil {
+		return NewError(encodeErr.Error())
+	}
+
+	return err
+}
+
+// withoutOutput returns a function that runs execute with its output to
+// stdout discarded, including the output of commands it runs. Errors are
+// still returned, to be printed to stderr.
+func withoutOutput(execute func() error) func() error {
+	return func() error {
+		devNull, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+		if err != nil {
+			return NewError(err.Error())
+		}
+		defer devNull.Close()
+
+		stdout := os.Stdout
+		os.Stdout = devNull
+		defer func() { os.Stdout = stdout }()
+
+		return execute()
+	}
+}
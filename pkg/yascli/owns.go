@@ -0,0 +1,42 @@
+package yascli
+
+import (
+	"fmt"
+
+	"github.com/dansimau/yas/pkg/cliutil"
+	"github.com/dansimau/yas/pkg/yas"
+)
+
+type ownsCmd struct {
+	Stack string `long:"stack" description:"Only check the stack with the specified name, or containing the specified branch"`
+
+	Args struct {
+		Path string `positional-arg-name:"path" description:"File or directory to look up" required:"true"`
+	} `positional-args:"true"`
+}
+
+func (c *ownsCmd) Execute(args []string) error {
+	yasInstance, err := yas.NewFromRepository(cmd.RepoDirectory)
+	if err != nil {
+		return NewError(err.Error())
+	}
+
+	owners, err := yasInstance.Owns(c.Args.Path, c.Stack)
+	if err != nil {
+		return NewError(err.Error())
+	}
+
+	if len(owners) == 0 {
+		fmt.Printf("No tracked branches modify %s\n", c.Args.Path)
+		return nil
+	}
+
+	rows := [][]string{{"BRANCH", "CHANGES"}}
+	for _, owner := range owners {
+		rows = append(rows, []string{owner.Branch, fmt.Sprintf("+%d -%d, %d files", owner.Stat.Insertions, owner.Stat.Deletions, owner.Stat.Files)})
+	}
+
+	cliutil.PrintTable(rows)
+
+	return nil
+}
@@ -0,0 +1,8 @@
+package yascli
+
+type prCmd struct {
+	AutoMerge *prAutoMergeCmd `command:"automerge" description:"Enable or disable auto-merge of a pull request"`
+	Checkout  *prCheckoutCmd  `command:"checkout" description:"Check out a pull request and track it as a stacked branch"`
+	Ready     *prReadyCmd     `command:"ready" description:"Mark draft pull requests as ready for review"`
+	View      *prViewCmd      `command:"view" description:"Summarize requested changes and unresolved review comments"`
+}
@@ -0,0 +1,34 @@
+package yascli
+
+import (
+	"github.com/dansimau/yas/pkg/yas"
+)
+
+type prAutoMergeCmd struct {
+	Enable  bool `long:"enable" description:"Enable auto-merge, using the mergeStrategy config"`
+	Disable bool `long:"disable" description:"Disable auto-merge"`
+
+	Args struct {
+		Branch string `positional-arg-name:"branch" description:"Branch whose PR to change (default: current)"`
+	} `positional-args:"true"`
+}
+
+func (c *prAutoMergeCmd) Execute(args []string) error {
+	if c.Enable == c.Disable {
+		return NewError("specify one of --enable or --disable")
+	}
+
+	yasInstance, err := yas.NewFromRepository(cmd.RepoDirectory)
+	if err != nil {
+		return NewError(err.Error())
+	}
+
+	if err := yasInstance.SetAutoMerge(yas.AutoMergeOptions{
+		Branch: c.Args.Branch,
+		Enable: c.Enable,
+	}); err != nil {
+		return NewError(err.Error())
+	}
+
+	return nil
+}
@@ -0,0 +1,24 @@
+package yascli
+
+import (
+	"github.com/dansimau/yas/pkg/yas"
+)
+
+type prCheckoutCmd struct {
+	Args struct {
+		PullRequest string `positional-arg-name:"pr-number-or-url" required:"true"`
+	} `positional-args:"true"`
+}
+
+func (c *prCheckoutCmd) Execute(args []string) error {
+	yasInstance, err := yas.NewFromRepository(cmd.RepoDirectory)
+	if err != nil {
+		return NewError(err.Error())
+	}
+
+	if err := yasInstance.CheckoutPullRequest(c.Args.PullRequest); err != nil {
+		return NewError(err.Error())
+	}
+
+	return nil
+}
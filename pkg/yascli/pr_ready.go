@@ -0,0 +1,29 @@
+package yascli
+
+import (
+	"github.com/dansimau/yas/pkg/yas"
+)
+
+type prReadyCmd struct {
+	Stack bool `long:"stack" description:"Mark the PRs of all branches in the stack as ready"`
+
+	Args struct {
+		Branch string `positional-arg-name:"branch" description:"Branch whose PR to mark as ready (default: current)"`
+	} `positional-args:"true"`
+}
+
+func (c *prReadyCmd) Execute(args []string) error {
+	yasInstance, err := yas.NewFromRepository(cmd.RepoDirectory)
+	if err != nil {
+		return NewError(err.Error())
+	}
+
+	if err := yasInstance.MarkPullRequestsReady(yas.ReadyOptions{
+		Branch: c.Args.Branch,
+		Stack:  c.Stack,
+	}); err != nil {
+		return NewError(err.Error())
+	}
+
+	return nil
+}
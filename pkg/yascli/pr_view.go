@@ -0,0 +1,69 @@
+package yascli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/dansimau/yas/pkg/yas"
+)
+
+type prViewCmd struct {
+	Stack bool `long:"stack" description:"Summarize the PRs of all branches in the stack"`
+
+	Args struct {
+		Branch string `positional-arg-name:"branch" description:"Branch whose PR to summarize, or with --stack, the stack name (default: current)"`
+	} `positional-args:"true"`
+}
+
+// reviewDecisions are the descriptions of PR review decisions.
+var reviewDecisions = map[string]string{
+	"APPROVED":          "approved",
+	"CHANGES_REQUESTED": "changes requested",
+	"REVIEW_REQUIRED":   "review required",
+}
+
+func (c *prViewCmd) Execute(args []string) error {
+	yasInstance, err := yas.NewFromRepository(cmd.RepoDirectory)
+	if err != nil {
+		return NewError(err.Error())
+	}
+
+	reviews, err := yasInstance.Reviews(yas.ReviewOptions{
+		Branch: c.Args.Branch,
+		Stack:  c.Stack,
+	})
+	if err != nil {
+		return NewError(err.Error())
+	}
+
+	for _, review := range reviews {
+		if review.URL == "" {
+			fmt.Printf("%s: no open PR\n", review.Branch)
+			continue
+		}
+
+		summary := []string{}
+		if decision := reviewDecisions[review.ReviewDecision]; decision != "" {
+			summary = append(summary, decision)
+		}
+
+		summary = append(summary, fmt.Sprintf("%d unresolved thread(s)", len(review.Threads)))
+
+		fmt.Printf("%s: %s (%s)\n", review.Branch, strings.Join(summary, ", "), review.URL)
+
+		for _, comment := range review.ChangesRequested {
+			fmt.Printf("    ✋ %s: %s\n", comment.Author, comment.Body)
+		}
+
+		for _, comment := range review.Threads {
+			location := fmt.Sprintf("%s:%d", comment.Path, comment.Line)
+			if comment.Outdated {
+				location += " (outdated)"
+			}
+
+			fmt.Printf("    %s %s: %s\n", location, comment.Author, comment.Body)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,66 @@
+package yascli
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/dansimau/yas/pkg/yas"
+)
+
+const promptLongDescription = `Print a summary of the current stack for use in a shell prompt, e.g. "3↕ 1⚠"
+means the current branch is third in its stack and one branch in the stack
+needs a restack. Only local state is used, so it is fast enough to run on
+every prompt.
+
+Exit codes:
+  0  on a tracked branch, and the stack is up to date
+  1  an error occurred
+  2  not on a tracked branch (nothing is printed)
+  3  on a tracked branch, but some branches in the stack need a restack`
+
+const (
+	promptExitNotTracked   = 2
+	promptExitNeedsRestack = 3
+)
+
+type promptCmd struct {
+	JSON bool `long:"json" description:"Print the summary as JSON"`
+}
+
+func (c *promptCmd) Execute(args []string) error {
+	yasInstance, err := yas.NewFromRepository(cmd.RepoDirectory)
+	if err != nil {
+		return NewError(err.Error())
+	}
+
+	info, err := yasInstance.PromptInfo()
+	if err != nil {
+		return NewError(err.Error())
+	}
+
+	if info == nil {
+		return NewExitCode(promptExitNotTracked, "")
+	}
+
+	if c.JSON {
+		b, err := json.Marshal(info)
+		if err != nil {
+			return NewError(err.Error())
+		}
+
+		fmt.Println(string(b))
+	} else {
+		s := fmt.Sprintf("%d↕", info.StackDepth)
+		if info.NeedsRestack > 0 {
+			s += fmt.Sprintf(" %d⚠", info.NeedsRestack)
+		}
+
+		fmt.Println(s)
+	}
+
+	if info.NeedsRestack > 0 {
+		return NewExitCode(promptExitNeedsRestack, "")
+	}
+
+	return nil
+}
@@ -0,0 +1,15 @@
+package yascli
+
+import (
+	"github.com/dansimau/yas/pkg/yas"
+)
+
+type recoverCmd struct{}
+
+func (c *recoverCmd) Execute(args []string) error {
+	if err := yas.Recover(cmd.RepoDirectory); err != nil {
+		return NewError(err.Error())
+	}
+
+	return nil
+}
@@ -0,0 +1,26 @@
+package yascli
+
+import (
+	"github.com/dansimau/yas/pkg/yas"
+)
+
+type refreshCmd struct {
+	Stack bool `long:"stack" description:"Refresh all branches in the current stack"`
+	All   bool `long:"all" description:"Refresh all tracked branches"`
+}
+
+func (c *refreshCmd) Execute(args []string) error {
+	yasInstance, err := yas.NewFromRepository(cmd.RepoDirectory)
+	if err != nil {
+		return NewError(err.Error())
+	}
+
+	if err := yasInstance.Refresh(yas.RefreshOptions{
+		Stack: c.Stack,
+		All:   c.All,
+	}); err != nil {
+		return NewError(err.Error())
+	}
+
+	return nil
+}
@@ -0,0 +1,75 @@
+package yascli
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/dansimau/yas/pkg/yas"
+)
+
+type reportCmd struct {
+	Since  string `long:"since" description:"First day to report on, as YYYY-MM-DD (default: 7 days ago)"`
+	Until  string `long:"until" description:"Last day to report on, as YYYY-MM-DD (default: today)"`
+	Output string `long:"output" short:"o" description:"Write the report to a file instead of stdout"`
+}
+
+func (c *reportCmd) Execute(args []string) error {
+	yasInstance, err := yas.NewFromRepository(cmd.RepoDirectory)
+	if err != nil {
+		return NewError(err.Error())
+	}
+
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+
+	since, err := parseDate(c.Since, today.AddDate(0, 0, -7))
+	if err != nil {
+		return NewError(err.Error())
+	}
+
+	until, err := parseDate(c.Until, today)
+	if err != nil {
+		return NewError(err.Error())
+	}
+
+	if until.Before(since) {
+		return NewError("--until must not be before --since")
+	}
+
+	report, err := yasInstance.Report(yas.ReportOptions{
+		Since: since,
+		Until: until,
+	})
+	if err != nil {
+		return NewError(err.Error())
+	}
+
+	if c.Output == "" {
+		fmt.Print(report)
+		return nil
+	}
+
+	if err := os.WriteFile(c.Output, []byte(report), 0o644); err != nil {
+		return NewError(err.Error())
+	}
+
+	fmt.Printf("Wrote report to: %s\n", c.Output)
+
+	return nil
+}
+
+// parseDate parses a date in YYYY-MM-DD format in the local time zone, or
+// returns defaultValue if s is empty.
+func parseDate(s string, defaultValue time.Time) (time.Time, error) {
+	if s == "" {
+		return defaultValue, nil
+	}
+
+	t, err := time.ParseInLocation(time.DateOnly, s, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date '%s' (expected YYYY-MM-DD)", s)
+	}
+
+	return t, nil
+}
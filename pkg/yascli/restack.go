@@ -1,10 +1,25 @@
 package yascli
 
 import (
+	"fmt"
+
 	"github.com/dansimau/yas/pkg/yas"
 )
 
-type restackCmd struct{}
+type restackCmd struct {
+	All        bool `long:"all" description:"Restack all tracked branches, not just the current stack"`
+	Autostash  bool `long:"autostash" description:"Stash local changes before restacking and restore them afterwards"`
+	ForceDirty bool `long:"force-dirty" description:"Restack even if there are uncommitted changes"`
+	Check      bool `long:"check" description:"Check which branches would conflict, without restacking"`
+
+	ContinueOnError bool `long:"continue-on-error" description:"Skip branches that conflict (and their descendants) until the other branches have been restacked"`
+
+	Stack string `long:"stack" description:"Restack the stack with the specified name, or containing the specified branch"`
+
+	Args struct {
+		Branch string `positional-arg-name:"branch" description:"Restack only this branch and its descendants"`
+	} `positional-args:"true"`
+}
 
 func (c *restackCmd) Execute(args []string) error {
 	yasInstance, err := yas.NewFromRepository(cmd.RepoDirectory)
@@ -12,5 +27,38 @@ func (c *restackCmd) Execute(args []string) error {
 		return NewError(err.Error())
 	}
 
-	return yasInstance.Restack()
+	if c.Check {
+		return c.check(yasInstance)
+	}
+
+	return yasInstance.Restack(yas.RestackOptions{
+		All:        c.All,
+		Branch:     c.Args.Branch,
+		Stack:      c.Stack,
+		Autostash:  c.Autostash,
+		ForceDirty: c.ForceDirty,
+
+		ContinueOnError: c.ContinueOnError,
+	})
+}
+
+func (c *restackCmd) check(yasInstance *yas.YAS) error {
+	conflicts, err := yasInstance.RestackConflicts()
+	if err != nil {
+		return NewError(err.Error())
+	}
+
+	if len(conflicts) == 0 {
+		fmt.Println("✅ No conflicts expected")
+		return nil
+	}
+
+	for _, conflict := range conflicts {
+		fmt.Printf("⚠️  %s conflicts with %s:\n", conflict.Branch, conflict.Parent)
+		for _, file := range conflict.Files {
+			fmt.Printf("    %s\n", file)
+		}
+	}
+
+	return NewError(fmt.Sprintf("%d branch(es) would conflict", len(conflicts)))
 }
@@ -0,0 +1,24 @@
+package yascli
+
+import (
+	"github.com/dansimau/yas/pkg/yas"
+)
+
+type rewordCmd struct {
+	UpdatePRTitle bool `long:"update-pr-title" description:"Update the PR title if the first commit subject changes"`
+}
+
+func (c *rewordCmd) Execute(args []string) error {
+	yasInstance, err := yas.NewFromRepository(cmd.RepoDirectory)
+	if err != nil {
+		return NewError(err.Error())
+	}
+
+	if err := yasInstance.Reword(yas.RewordOptions{
+		UpdatePRTitle: c.UpdatePRTitle,
+	}); err != nil {
+		return NewError(err.Error())
+	}
+
+	return nil
+}
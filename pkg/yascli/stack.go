@@ -0,0 +1,15 @@
+package yascli
+
+type stackCmd struct {
+	List    *stackListCmd    `command:"list" description:"List the branches in the stack"`
+	Merge   *stackMergeCmd   `command:"merge" description:"Squash-merge each branch in the stack into trunk, bottom first"`
+	Name    *stackNameCmd    `command:"name" description:"Show or set the name of the stack"`
+	Restack *stackRestackCmd `command:"restack" description:"Rebase all branches in the stack"`
+	Submit  *stackSubmitCmd  `command:"submit" description:"Submit all branches in the stack"`
+	WIP     *stackWIPCmd     `command:"wip" description:"Mark the stack as a work in progress, so that it isn't submitted or merged"`
+}
+
+// stackArgs selects the stack that a stack command operates on.
+type stackArgs struct {
+	Branch string `positional-arg-name:"branch" description:"Any branch in the stack, or the name of the stack (default: current branch)"`
+}
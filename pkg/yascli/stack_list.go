@@ -0,0 +1,33 @@
+package yascli
+
+import (
+	"github.com/dansimau/yas/pkg/yas"
+)
+
+type stackListCmd struct {
+	Args stackArgs `positional-args:"true"`
+}
+
+func (c *stackListCmd) Execute(args []string) error {
+	yasInstance, err := yas.NewFromRepository(cmd.RepoDirectory)
+	if err != nil {
+		return NewError(err.Error())
+	}
+
+	branch := c.Args.Branch
+	if branch == "" {
+		if branch, err = yasInstance.CurrentBranch(); err != nil {
+			return NewError(err.Error())
+		}
+	}
+
+	if err := yasInstance.List(yas.ListOptions{
+		Stack: branch,
+		// Uses the global --verbose flag
+		Verbose: len(cmd.Verbose) > 0,
+	}); err != nil {
+		return NewError(err.Error())
+	}
+
+	return nil
+}
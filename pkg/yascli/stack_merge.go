@@ -0,0 +1,46 @@
+package yascli
+
+import (
+	"time"
+
+	"github.com/dansimau/yas/pkg/yas"
+)
+
+type stackMergeCmd struct {
+	Strategy     string        `long:"strategy" description:"How to merge (default: mergeStrategy config, or squash)" choice:"squash" choice:"merge" choice:"rebase"`
+	Local        bool          `long:"local" description:"Merge into trunk locally and push trunk, instead of merging the PRs"`
+	Wait         bool          `long:"wait" description:"Wait for each PR's checks to pass before merging it"`
+	Timeout      time.Duration `long:"timeout" description:"Maximum time to wait for checks, per PR" default:"30m"`
+	PollInterval time.Duration `long:"poll-interval" description:"Time between polls of check status" default:"30s"`
+	Admin        bool          `long:"admin" description:"Merge the PRs with gh pr merge --admin, bypassing branch protection rules (asks for confirmation)"`
+
+	Args stackArgs `positional-args:"true"`
+}
+
+func (c *stackMergeCmd) Execute(args []string) error {
+	if c.Admin && c.Local {
+		return NewError("--admin cannot be used with --local")
+	}
+
+	yasInstance, err := yas.NewFromRepository(cmd.RepoDirectory)
+	if err != nil {
+		return NewError(err.Error())
+	}
+
+	if c.Admin && !confirmAdminMerge() {
+		return NewError("aborted")
+	}
+
+	if err := yasInstance.MergeStack(c.Args.Branch, yas.MergeOptions{
+		Strategy:     c.Strategy,
+		Local:        c.Local,
+		Wait:         c.Wait,
+		Timeout:      c.Timeout,
+		PollInterval: c.PollInterval,
+		Admin:        c.Admin,
+	}); err != nil {
+		return NewError(err.Error())
+	}
+
+	return nil
+}
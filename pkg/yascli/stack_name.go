@@ -0,0 +1,53 @@
+package yascli
+
+import (
+	"fmt"
+
+	"github.com/dansimau/yas/pkg/yas"
+)
+
+type stackNameCmd struct {
+	Branch string `long:"branch" description:"Any branch in the stack (default: current branch)"`
+	Clear  bool   `long:"clear" description:"Remove the name of the stack"`
+
+	Args struct {
+		Name string `positional-arg-name:"name" description:"Name for the stack, e.g. payments-refactor (default: show the current name)"`
+	} `positional-args:"true"`
+}
+
+func (c *stackNameCmd) Execute(args []string) error {
+	yasInstance, err := yas.NewFromRepository(cmd.RepoDirectory)
+	if err != nil {
+		return NewError(err.Error())
+	}
+
+	if c.Clear && c.Args.Name != "" {
+		return NewError("specify a name or --clear, not both")
+	}
+
+	if c.Args.Name == "" && !c.Clear {
+		name, err := yasInstance.StackName(c.Branch)
+		if err != nil {
+			return NewError(err.Error())
+		}
+
+		if name == "" {
+			return NewError("stack has no name (hint: run `yas stack name <name>`)")
+		}
+
+		fmt.Println(name)
+
+		return nil
+	}
+
+	if cmd.DryRun {
+		fmt.Println("[DRY-RUN] Not setting stack name")
+		return nil
+	}
+
+	if err := yasInstance.SetStackName(c.Branch, c.Args.Name); err != nil {
+		return NewError(err.Error())
+	}
+
+	return nil
+}
@@ -0,0 +1,29 @@
+package yascli
+
+import (
+	"github.com/dansimau/yas/pkg/yas"
+)
+
+type stackRestackCmd struct {
+	Autostash  bool `long:"autostash" description:"Stash local changes before restacking and restore them afterwards"`
+	ForceDirty bool `long:"force-dirty" description:"Restack even if there are uncommitted changes"`
+
+	Args stackArgs `positional-args:"true"`
+}
+
+func (c *stackRestackCmd) Execute(args []string) error {
+	yasInstance, err := yas.NewFromRepository(cmd.RepoDirectory)
+	if err != nil {
+		return NewError(err.Error())
+	}
+
+	if err := yasInstance.Restack(yas.RestackOptions{
+		Stack:      c.Args.Branch,
+		Autostash:  c.Autostash,
+		ForceDirty: c.ForceDirty,
+	}); err != nil {
+		return NewError(err.Error())
+	}
+
+	return nil
+}
@@ -0,0 +1,41 @@
+package yascli
+
+import (
+	"github.com/dansimau/yas/pkg/yas"
+)
+
+type stackSubmitCmd struct {
+	AllowDivergence bool `long:"allow-divergence" description:"Submit even if branches contain commits from other branches"`
+	Strict          bool `long:"strict" description:"Fail instead of warning when a PR exceeds the configured size or stack depth limits"`
+	Draft           bool `long:"draft" description:"Create PRs as drafts, and convert existing open PRs to drafts"`
+	Ready           bool `long:"ready" description:"Create PRs ready for review, and mark existing draft PRs as ready"`
+	Force           bool `long:"force" description:"Submit even if the stack is marked as WIP"`
+
+	Args stackArgs `positional-args:"true"`
+}
+
+func (c *stackSubmitCmd) Execute(args []string) error {
+	draft, err := draftOption(c.Draft, c.Ready)
+	if err != nil {
+		return err
+	}
+
+	yasInstance, err := yas.NewFromRepository(cmd.RepoDirectory)
+	if err != nil {
+		return NewError(err.Error())
+	}
+
+	results, err := yasInstance.Submit(yas.SubmitOptions{
+		Stack:           true,
+		Branch:          c.Args.Branch,
+		AllowDivergence: c.AllowDivergence,
+		Strict:          c.Strict,
+		Draft:           draft,
+		Force:           c.Force,
+	})
+	if err != nil {
+		return NewError(err.Error())
+	}
+
+	return printSubmitSummary(results)
+}
@@ -0,0 +1,31 @@
+package yascli
+
+import (
+	"fmt"
+
+	"github.com/dansimau/yas/pkg/yas"
+)
+
+type stackWIPCmd struct {
+	Off bool `long:"off" description:"Unmark the stack as WIP"`
+
+	Args stackArgs `positional-args:"true"`
+}
+
+func (c *stackWIPCmd) Execute(args []string) error {
+	yasInstance, err := yas.NewFromRepository(cmd.RepoDirectory)
+	if err != nil {
+		return NewError(err.Error())
+	}
+
+	if cmd.DryRun {
+		fmt.Println("[DRY-RUN] Not changing the WIP state of the stack")
+		return nil
+	}
+
+	if err := yasInstance.SetStackWIP(c.Args.Branch, !c.Off); err != nil {
+		return NewError(err.Error())
+	}
+
+	return nil
+}
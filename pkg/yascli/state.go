@@ -0,0 +1,6 @@
+package yascli
+
+type stateCmd struct {
+	Export *stateExportCmd `command:"export" description:"Export the state and config as a portable JSON bundle"`
+	Import *stateImportCmd `command:"import" description:"Import a bundle created by state export"`
+}
@@ -0,0 +1,35 @@
+package yascli
+
+import (
+	"os"
+
+	"github.com/dansimau/yas/pkg/yas"
+)
+
+type stateExportCmd struct {
+	Output string `long:"output" short:"o" description:"File to write the bundle to (default: stdout)"`
+}
+
+func (c *stateExportCmd) Execute(args []string) error {
+	yasInstance, err := yas.NewFromRepository(cmd.RepoDirectory)
+	if err != nil {
+		return NewError(err.Error())
+	}
+
+	w := os.Stdout
+	if c.Output != "" {
+		f, err := os.Create(c.Output)
+		if err != nil {
+			return NewError(err.Error())
+		}
+		defer f.Close()
+
+		w = f
+	}
+
+	if err := yasInstance.ExportState(w); err != nil {
+		return NewError(err.Error())
+	}
+
+	return nil
+}
@@ -0,0 +1,41 @@
+package yascli
+
+import (
+	"os"
+
+	"github.com/dansimau/yas/pkg/yas"
+)
+
+type stateImportCmd struct {
+	Merge bool `long:"merge" description:"Merge into the local state, keeping whichever branch metadata was updated most recently"`
+
+	Args struct {
+		File string `positional-arg-name:"file" description:"Bundle to import (- for stdin)" required:"true"`
+	} `positional-args:"true"`
+}
+
+func (c *stateImportCmd) Execute(args []string) error {
+	yasInstance, err := yas.NewFromRepository(cmd.RepoDirectory)
+	if err != nil {
+		return NewError(err.Error())
+	}
+
+	r := os.Stdin
+	if c.Args.File != "-" {
+		f, err := os.Open(c.Args.File)
+		if err != nil {
+			return NewError(err.Error())
+		}
+		defer f.Close()
+
+		r = f
+	}
+
+	if err := yasInstance.ImportState(r, yas.ImportOptions{
+		Merge: c.Merge,
+	}); err != nil {
+		return NewError(err.Error())
+	}
+
+	return nil
+}
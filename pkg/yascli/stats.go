@@ -0,0 +1,43 @@
+package yascli
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/dansimau/yas/pkg/yas"
+)
+
+type statsCmd struct {
+	Days int `long:"days" description:"Number of days of merged PRs to include" default:"30"`
+}
+
+func (c *statsCmd) Execute(args []string) error {
+	yasInstance, err := yas.NewFromRepository(cmd.RepoDirectory)
+	if err != nil {
+		return NewError(err.Error())
+	}
+
+	stats, err := yasInstance.Stats(time.Duration(c.Days) * 24 * time.Hour)
+	if err != nil {
+		return NewError(err.Error())
+	}
+
+	fmt.Printf("Open stacks:            %d\n", stats.OpenStacks)
+	fmt.Printf("Average stack depth:    %.1f\n", stats.AverageStackDepth)
+	fmt.Printf("PRs merged (%d days):   %d\n", c.Days, stats.MergedPRs)
+	fmt.Printf("Average time to merge:  %s\n", formatDuration(stats.AverageTimeToMerge))
+
+	return nil
+}
+
+// formatDuration formats a duration in days and hours, e.g. "2d 4h".
+func formatDuration(d time.Duration) string {
+	days := int(d.Hours()) / 24
+	hours := int(d.Hours()) % 24
+
+	if days == 0 {
+		return fmt.Sprintf("%dh", hours)
+	}
+
+	return fmt.Sprintf("%dd %dh", days, hours)
+}
@@ -0,0 +1,20 @@
+package yascli
+
+import (
+	"github.com/dansimau/yas/pkg/yas"
+)
+
+type statusCmd struct{}
+
+func (c *statusCmd) Execute(args []string) error {
+	yasInstance, err := yas.NewFromRepository(cmd.RepoDirectory)
+	if err != nil {
+		return NewError(err.Error())
+	}
+
+	if err := yasInstance.Status(); err != nil {
+		return NewError(err.Error())
+	}
+
+	return nil
+}
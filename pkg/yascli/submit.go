@@ -1,16 +1,100 @@
 package yascli
 
 import (
+	"fmt"
+	"strings"
+
 	"github.com/dansimau/yas/pkg/yas"
 )
 
-type submitCmd struct{}
+type submitCmd struct {
+	Stack           bool `long:"stack" description:"Submit all branches in the current stack"`
+	Combined        bool `long:"combined" description:"Submit the whole stack as a single PR from the stack tip to trunk"`
+	AllowDivergence bool `long:"allow-divergence" description:"Submit even if branches contain commits from other branches"`
+	Strict          bool `long:"strict" description:"Fail instead of warning when a PR exceeds the configured size or stack depth limits"`
+	Draft           bool `long:"draft" description:"Create PRs as drafts, and convert existing open PRs to drafts"`
+	Ready           bool `long:"ready" description:"Create PRs ready for review, and mark existing draft PRs as ready"`
+	Force           bool `long:"force" description:"Submit even if the stack is marked as WIP"`
+
+	Range string `long:"range" description:"Submit a contiguous range of branches in the current stack, e.g. topic-a..topic-b" value-name:"BASE..TOP"`
+}
 
 func (c *submitCmd) Execute(args []string) error {
+	draft, err := draftOption(c.Draft, c.Ready)
+	if err != nil {
+		return err
+	}
+
 	yasInstance, err := yas.NewFromRepository(cmd.RepoDirectory)
 	if err != nil {
 		return NewError(err.Error())
 	}
 
-	return yasInstance.Submit()
+	results, err := yasInstance.Submit(yas.SubmitOptions{
+		Stack:           c.Stack,
+		Combined:        c.Combined,
+		AllowDivergence: c.AllowDivergence,
+		Strict:          c.Strict,
+		Range:           c.Range,
+		Draft:           draft,
+		Force:           c.Force,
+	})
+	if err != nil {
+		return NewError(err.Error())
+	}
+
+	if len(results) == 1 && !c.Stack && c.Range == "" {
+		if results[0].Err != nil {
+			return NewError(results[0].Err.Error())
+		}
+
+		return nil
+	}
+
+	return printSubmitSummary(results)
+}
+
+// draftOption returns the draft state requested with --draft or --ready, or
+// nil if neither was specified.
+func draftOption(draft, ready bool) (*bool, error) {
+	if draft && ready {
+		return nil, NewError("--draft and --ready cannot be used together")
+	}
+
+	if !draft && !ready {
+		return nil, nil
+	}
+
+	return &draft, nil
+}
+
+// printSubmitSummary prints the errors of branches that failed to submit, and
+// returns an error if there are any. The outcome of each branch is printed as
+// part of the submit's progress.
+func printSubmitSummary(results []yas.SubmitResult) error {
+	submitted := 0
+	failed := []string{}
+
+	for _, result := range results {
+		switch {
+		case result.Err != nil:
+			if len(failed) == 0 {
+				fmt.Println()
+			}
+
+			fmt.Printf("  ✗ %s: %v\n", result.Branch, result.Err)
+			failed = append(failed, result.Branch)
+		case !result.Skipped:
+			submitted++
+		}
+	}
+
+	fmt.Printf("\nSubmitted %d of %d branches\n", submitted, len(results))
+
+	if len(failed) > 0 {
+		fmt.Println("Hint: fix the errors above and run `yas submit --stack` again to retry")
+		return NewError(fmt.Sprintf("failed to submit: %s", strings.Join(failed, ", ")))
+	}
+
+	return nil
 }
@@ -0,0 +1,34 @@
+package yascli
+
+import (
+	"github.com/dansimau/yas/pkg/yas"
+)
+
+type switchCmd struct {
+	TakeChanges bool `long:"take-changes" description:"Move uncommitted changes onto the branch (or its worktree), aborting if they conflict"`
+
+	Args struct {
+		Name string `positional-arg-name:"name" description:"Branch to switch to, or - for the previous branch" required:"true"`
+	} `positional-args:"true"`
+}
+
+func (c *switchCmd) Execute(args []string) error {
+	yasInstance, err := yas.NewFromRepository(cmd.RepoDirectory)
+	if err != nil {
+		return NewError(err.Error())
+	}
+
+	if c.TakeChanges {
+		if err := yasInstance.SwitchWithChanges(c.Args.Name); err != nil {
+			return NewError(err.Error())
+		}
+
+		return nil
+	}
+
+	if err := yasInstance.Switch(c.Args.Name); err != nil {
+		return NewError(err.Error())
+	}
+
+	return nil
+}
@@ -1,13 +1,41 @@
 package yascli
 
 import (
+	"errors"
 	"fmt"
 
 	"github.com/dansimau/yas/pkg/yas"
 )
 
 type syncCmd struct {
+	Restack bool `long:"restack" description:"Restack all branches onto trunk after pulling new commits"`
+
 	yasInstance *yas.YAS
+
+	// refreshErrors are the branches that failed to refresh, which are
+	// reported at the end instead of aborting the sync.
+	refreshErrors yas.RefreshErrors
+}
+
+// refreshRemoteStatus refreshes the PR status of the branches, collecting
+// per-branch failures so that the sync can continue with the other branches.
+func (c *syncCmd) refreshRemoteStatus(branchNames ...string) error {
+	err := c.yasInstance.RefreshRemoteStatus(branchNames...)
+
+	var failed yas.RefreshErrors
+	if !errors.As(err, &failed) {
+		return err
+	}
+
+	if c.refreshErrors == nil {
+		c.refreshErrors = yas.RefreshErrors{}
+	}
+
+	for name, err := range failed {
+		c.refreshErrors[name] = err
+	}
+
+	return nil
 }
 
 func (c *syncCmd) trackUntrackedBranches() error {
@@ -16,13 +44,13 @@ func (c *syncCmd) trackUntrackedBranches() error {
 		return err
 	}
 
-	return c.yasInstance.RefreshRemoteStatus(untrackedBranches...)
+	return c.refreshRemoteStatus(untrackedBranches...)
 }
 
 func (c *syncCmd) checkForClosedPRs() error {
 	fmt.Println("🧹 Checking for merged PRs...")
 	// Fetch latest PR metadata from GitHub for branches that have PRs
-	if err := c.yasInstance.RefreshRemoteStatus(c.yasInstance.TrackedBranches().WithPRs().BranchNames()...); err != nil {
+	if err := c.refreshRemoteStatus(c.yasInstance.TrackedBranches().WithPRs().BranchNames()...); err != nil {
 		return err
 	}
 
@@ -34,6 +62,10 @@ func (c *syncCmd) checkForClosedPRs() error {
 		}
 
 		if !cmd.DryRun {
+			if err := c.yasInstance.ReparentChildren(branch.Name); err != nil {
+				return fmt.Errorf("error reparenting children of %s: %w", branch.Name, err)
+			}
+
 			if err := c.yasInstance.DeleteBranch(branch.Name); err != nil {
 				return fmt.Errorf("error deleting branch %s: %w", branch.Name, err)
 			}
@@ -65,10 +97,32 @@ func (c *syncCmd) Execute(args []string) error {
 		return NewError(err.Error())
 	}
 
+	if err := pruneBranchMetadata(yasInstance, yasInstance.Config().DeletedBranchRetention()); err != nil {
+		return NewError(err.Error())
+	}
+
 	fmt.Printf("🔄 Pulling %s...\n", yasInstance.Config().TrunkBranch)
-	if err := yasInstance.UpdateTrunk(); err != nil {
+	newCommits, err := yasInstance.UpdateTrunk()
+	if err != nil {
 		return NewError(err.Error())
 	}
 
+	if newCommits == 0 {
+		fmt.Printf("%s is up to date\n", yasInstance.Config().TrunkBranch)
+	} else {
+		fmt.Printf("%s: %d new commit(s)\n", yasInstance.Config().TrunkBranch, newCommits)
+
+		if c.Restack {
+			fmt.Println("🔁 Restacking...")
+			if err := yasInstance.Restack(yas.RestackOptions{All: true}); err != nil {
+				return NewError(err.Error())
+			}
+		}
+	}
+
+	if len(c.refreshErrors) > 0 {
+		return NewError(c.refreshErrors.Error())
+	}
+
 	return nil
 }
@@ -0,0 +1,7 @@
+package yascli
+
+type worktreeCmd struct {
+	Add    *worktreeAddCmd    `command:"add" description:"Create a worktree with a tracked branch checked out"`
+	List   *worktreeListCmd   `command:"list" description:"Show the worktree each tracked branch is checked out in"`
+	Remove *worktreeRemoveCmd `command:"remove" description:"Remove the worktree a branch is checked out in"`
+}
@@ -0,0 +1,31 @@
+package yascli
+
+import (
+	"fmt"
+
+	"github.com/dansimau/yas/pkg/yas"
+)
+
+type worktreeAddCmd struct {
+	Path string `long:"path" description:"Path to create the worktree at (default: <worktree-dir>/<branch>)"`
+
+	Args struct {
+		Branch string `positional-arg-name:"branch" required:"true" description:"Tracked branch to check out in the worktree"`
+	} `positional-args:"true"`
+}
+
+func (c *worktreeAddCmd) Execute(args []string) error {
+	yasInstance, err := yas.NewFromRepository(cmd.RepoDirectory)
+	if err != nil {
+		return NewError(err.Error())
+	}
+
+	worktreePath, err := yasInstance.AddWorktree(c.Args.Branch, c.Path)
+	if err != nil {
+		return NewError(err.Error())
+	}
+
+	fmt.Printf("Created worktree for %s at %s\n", c.Args.Branch, worktreePath)
+
+	return nil
+}
@@ -0,0 +1,39 @@
+package yascli
+
+import (
+	"fmt"
+
+	"github.com/dansimau/yas/pkg/yas"
+)
+
+type worktreeListCmd struct{}
+
+func (c *worktreeListCmd) Execute(args []string) error {
+	yasInstance, err := yas.NewFromRepository(cmd.RepoDirectory)
+	if err != nil {
+		return NewError(err.Error())
+	}
+
+	worktrees, err := yasInstance.BranchWorktrees()
+	if err != nil {
+		return NewError(err.Error())
+	}
+
+	branches := yasInstance.TrackedBranches().SortedByName()
+
+	width := 0
+	for _, branch := range branches {
+		width = max(width, len(branch.Name))
+	}
+
+	for _, branch := range branches {
+		path := "-"
+		if worktree, ok := worktrees[branch.Name]; ok {
+			path = worktree.Path
+		}
+
+		fmt.Printf("%-*s  %s\n", width, branch.Name, path)
+	}
+
+	return nil
+}
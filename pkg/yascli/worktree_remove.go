@@ -0,0 +1,31 @@
+package yascli
+
+import (
+	"fmt"
+
+	"github.com/dansimau/yas/pkg/yas"
+)
+
+type worktreeRemoveCmd struct {
+	Force bool `long:"force" short:"f" description:"Remove the worktree even if it has local changes"`
+
+	Args struct {
+		Branch string `positional-arg-name:"branch" required:"true" description:"Branch whose worktree to remove"`
+	} `positional-args:"true"`
+}
+
+func (c *worktreeRemoveCmd) Execute(args []string) error {
+	yasInstance, err := yas.NewFromRepository(cmd.RepoDirectory)
+	if err != nil {
+		return NewError(err.Error())
+	}
+
+	worktreePath, err := yasInstance.RemoveWorktree(c.Args.Branch, c.Force)
+	if err != nil {
+		return NewError(err.Error())
+	}
+
+	fmt.Printf("Removed worktree %s\n", worktreePath)
+
+	return nil
+}
@@ -0,0 +1,67 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/dansimau/yas/pkg/testutil"
+	"github.com/dansimau/yas/pkg/yascli"
+	"gotest.tools/v3/assert"
+)
+
+func TestAddAutoParent(t *testing.T) {
+	testutil.WithTempWorkingDir(t, func() {
+		testutil.ExecOrFail(t, `
+			git init --initial-branch=main
+
+			# main
+			touch main
+			git add main
+			git commit -m "main-0"
+
+			# topic-a
+			git checkout -b topic-a
+			touch a
+			git add a
+			git commit -m "topic-a-0"
+
+			# topic-b, not tracked yet
+			git checkout -b topic-b
+			touch b
+			git add b
+			git commit -m "topic-b-0"
+
+			# topic-a moves on after topic-b was created
+			git checkout topic-a
+			echo 1 > a
+			git commit -am "topic-a-1"
+
+			# main moves on too
+			git checkout main
+			echo 1 > main
+			git commit -am "main-1"
+
+			git checkout topic-b
+		`)
+
+		assert.Equal(t, yascli.Run("config", "set", "--trunk-branch=main"), 0)
+		assert.Equal(t, yascli.Run("add", "--branch=topic-a", "--parent=main"), 0)
+
+		// Declining the inferred parent doesn't add the branch
+		withStdin(t, "n\n", func() {
+			assert.Equal(t, yascli.Run("add", "--parent=@auto"), 1)
+		})
+
+		assert.Equal(t, yascli.Run("add", "--parent=@auto", "--yes"), 0)
+
+		stdout, _, err := testutil.CaptureOutput(func() {
+			assert.Equal(t, yascli.Run("list"), 0)
+		})
+		assert.NilError(t, err)
+
+		equalLines(t, stdout, `
+			main
+			└── topic-a
+			    └── topic-b
+		`)
+	})
+}
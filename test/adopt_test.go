@@ -0,0 +1,85 @@
+package test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/dansimau/yas/pkg/testutil"
+	"github.com/dansimau/yas/pkg/yascli"
+	"gotest.tools/v3/assert"
+)
+
+func TestAdopt(t *testing.T) {
+	// Children are listed before their parents to check that the order of
+	// PRs doesn't matter
+	withFakeGH(t, `[
+		{"id":"PR_3","state":"OPEN","url":"https://github.com/test/test/pull/3","headRefName":"topic-c","baseRefName":"topic-b"},
+		{"id":"PR_2","state":"OPEN","url":"https://github.com/test/test/pull/2","headRefName":"topic-b","baseRefName":"topic-a"},
+		{"id":"PR_1","state":"OPEN","url":"https://github.com/test/test/pull/1","headRefName":"topic-a","baseRefName":"main"},
+		{"id":"PR_4","state":"OPEN","url":"https://github.com/test/test/pull/4","headRefName":"topic-d","baseRefName":"release"}
+	]`)
+
+	testutil.WithTempWorkingDir(t, func() {
+		testutil.ExecOrFail(t, `
+			git init --bare --initial-branch=main origin.git
+			git clone -q origin.git repo
+			cd repo
+
+			touch main
+			git add main
+			git commit -m "main-0"
+
+			git checkout -b topic-a
+			touch a
+			git add a
+			git commit -m "topic-a-0"
+
+			git checkout -b topic-b
+			touch b
+			git add b
+			git commit -m "topic-b-0"
+
+			git checkout -b topic-c
+			touch c
+			git add c
+			git commit -m "topic-c-0"
+
+			git checkout main
+			git checkout -b topic-d
+			touch d
+			git add d
+			git commit -m "topic-d-0"
+
+			git push -q origin main topic-a topic-b topic-c topic-d
+			git checkout main
+			git branch -D topic-b topic-c
+		`)
+
+		assert.NilError(t, os.Chdir("repo"))
+
+		assert.Equal(t, yascli.Run("config", "set", "--trunk-branch=main"), 0)
+		assert.Equal(t, yascli.Run("adopt"), 0)
+
+		// Missing branches are created from origin
+		equalLines(t, mustExecOutput("git", "log", "--pretty=%s", "topic-c", "--"), `
+			topic-c-0
+			topic-b-0
+			topic-a-0
+			main-0
+		`)
+
+		stdout, _, err := testutil.CaptureOutput(func() {
+			assert.Equal(t, yascli.Run("list"), 0)
+		})
+		assert.NilError(t, err)
+
+		// Untracked bases fall back to trunk
+		equalLines(t, stdout, `
+			main
+			├── topic-a
+			│   └── topic-b
+			│       └── topic-c
+			└── topic-d
+		`)
+	})
+}
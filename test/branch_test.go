@@ -0,0 +1,236 @@
+package test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/dansimau/yas/pkg/testutil"
+	"github.com/dansimau/yas/pkg/yascli"
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/assert/cmp"
+)
+
+func TestBranchFromCommit(t *testing.T) {
+	testutil.WithTempWorkingDir(t, func() {
+		testutil.ExecOrFail(t, `
+			git init --initial-branch=main
+
+			# main
+			touch main
+			git add main
+			git commit -m "main-0"
+
+			# topic-a
+			git checkout -b topic-a
+			touch a
+			git add a
+			git commit -m "topic-a-0"
+			git tag v1
+
+			touch a1
+			git add a1
+			git commit -m "topic-a-1"
+		`)
+
+		assert.Equal(t, yascli.Run("config", "set", "--trunk-branch=main"), 0)
+		assert.Equal(t, yascli.Run("add", "--branch=topic-a", "--parent=main"), 0)
+
+		// Tag is not on top of topic-a, so the parent is trunk
+		assert.Equal(t, yascli.Run("branch", "--from=v1", "topic-b"), 0)
+		assert.Equal(t, mustExecOutput("git", "rev-parse", "HEAD"), mustExecOutput("git", "rev-parse", "v1"))
+
+		// The tip of topic-a gives topic-a as the parent
+		assert.Equal(t, yascli.Run("branch", "--from="+mustGetShortHash("topic-a"), "topic-c"), 0)
+
+		stdout, _, err := testutil.CaptureOutput(func() {
+			assert.Equal(t, yascli.Run("list"), 0)
+		})
+		assert.NilError(t, err)
+
+		equalLines(t, stdout, `
+			main
+			├── topic-a
+			│   └── topic-c
+			└── topic-b
+		`)
+	})
+}
+
+func TestSwitchPrevious(t *testing.T) {
+	testutil.WithTempWorkingDir(t, func() {
+		testutil.ExecOrFail(t, `
+			git init --initial-branch=main
+
+			# main
+			touch main
+			git add main
+			git commit -m "main-0"
+		`)
+
+		assert.Equal(t, yascli.Run("config", "set", "--trunk-branch=main"), 0)
+		assert.Equal(t, yascli.Run("branch", "topic-a"), 0)
+
+		assert.Equal(t, yascli.Run("switch", "-"), 0)
+		equalLines(t, mustExecOutput("git", "branch", "--show-current"), "main")
+
+		assert.Equal(t, yascli.Run("switch", "-"), 0)
+		equalLines(t, mustExecOutput("git", "branch", "--show-current"), "topic-a")
+	})
+}
+
+func TestSwitchPreviousInOtherWorktree(t *testing.T) {
+	testutil.WithTempWorkingDir(t, func() {
+		testutil.ExecOrFail(t, `
+			git init -q --initial-branch=main repo
+			cd repo
+
+			# main
+			touch main
+			git add main
+			git commit -m "main-0"
+		`)
+
+		assert.NilError(t, os.Chdir("repo"))
+
+		assert.Equal(t, yascli.Run("config", "set", "--trunk-branch=main", "--worktree-dir=../wt"), 0)
+		assert.Equal(t, yascli.Run("branch", "topic-a"), 0)
+		assert.Equal(t, yascli.Run("switch", "main"), 0)
+		assert.Equal(t, yascli.Run("worktree", "add", "topic-a"), 0)
+
+		_, stderr, err := testutil.CaptureOutput(func() {
+			assert.Equal(t, yascli.Run("switch", "-"), 1)
+		})
+		assert.NilError(t, err)
+		assert.Assert(t, cmp.Contains(stderr, "branch 'topic-a' is checked out in another worktree: "))
+		assert.Assert(t, cmp.Contains(stderr, "/wt/topic-a"))
+
+		equalLines(t, mustExecOutput("git", "branch", "--show-current"), "main")
+	})
+}
+
+func TestBranchTakeChanges(t *testing.T) {
+	testutil.WithTempWorkingDir(t, func() {
+		testutil.ExecOrFail(t, `
+			git init --initial-branch=main
+
+			echo 0 > main
+			git add main
+			git commit -m "main-0"
+
+			git checkout -b topic-a
+			echo a > main
+			git commit -a -m "topic-a-0"
+
+			git checkout main
+			echo wip > main
+		`)
+
+		assert.Equal(t, yascli.Run("config", "set", "--trunk-branch=main"), 0)
+		assert.Equal(t, yascli.Run("add", "--branch=topic-a", "--parent=main"), 0)
+
+		// The changes conflict with topic-a, so they are left on main
+		assert.Equal(t, yascli.Run("branch", "--take-changes", "--from=topic-a", "topic-b"), 1)
+
+		equalLines(t, mustExecOutput("git", "branch", "--show-current"), "main")
+		equalLines(t, mustExecOutput("git", "branch", "--list", "topic-b"), "")
+		equalLines(t, mustExecOutput("git", "status", "--porcelain"), "M main")
+		equalLines(t, mustExecOutput("git", "stash", "list"), "")
+
+		assert.Equal(t, yascli.Run("branch", "--take-changes", "topic-c"), 0)
+
+		equalLines(t, mustExecOutput("git", "branch", "--show-current"), "topic-c")
+		equalLines(t, mustExecOutput("git", "status", "--porcelain"), "M main")
+		equalLines(t, mustExecOutput("git", "stash", "list"), "")
+	})
+}
+
+func TestSwitchTakeChangesToWorktree(t *testing.T) {
+	testutil.WithTempWorkingDir(t, func() {
+		testutil.ExecOrFail(t, `
+			git init --initial-branch=main repo
+			cd repo
+
+			touch main
+			git add main
+			git commit -m "main-0"
+
+			git branch topic-a
+			git worktree add -q ../wt-a topic-a
+
+			echo wip > main
+		`)
+
+		assert.NilError(t, os.Chdir("repo"))
+
+		assert.Equal(t, yascli.Run("config", "set", "--trunk-branch=main"), 0)
+		assert.Equal(t, yascli.Run("add", "--branch=topic-a", "--parent=main"), 0)
+		assert.Equal(t, yascli.Run("switch", "--take-changes", "topic-a"), 0)
+
+		equalLines(t, mustExecOutput("git", "status", "--porcelain"), "")
+		equalLines(t, mustExecOutput("git", "-C", "../wt-a", "status", "--porcelain"), "M main")
+		equalLines(t, mustExecOutput("git", "stash", "list"), "")
+	})
+}
+
+func TestBranchInsert(t *testing.T) {
+	testutil.WithTempWorkingDir(t, func() {
+		testutil.ExecOrFail(t, `
+			git init --initial-branch=main
+
+			touch main
+			git add main
+			git commit -m "main-0"
+
+			git checkout -b topic-a
+			touch a
+			git add a
+			git commit -m "topic-a-0"
+
+			git checkout -b topic-c
+			touch c
+			git add c
+			git commit -m "topic-c-0"
+
+			git checkout topic-a
+			echo 1 > a
+			git commit -a -m "topic-a-1"
+
+			git checkout -b topic-b
+			touch b
+			git add b
+			git commit -m "topic-b-0"
+
+			git checkout topic-a
+		`)
+
+		assert.Equal(t, yascli.Run("config", "set", "--trunk-branch=main"), 0)
+		assert.Equal(t, yascli.Run("add", "--branch=topic-a", "--parent=main"), 0)
+		assert.Equal(t, yascli.Run("add", "--branch=topic-b", "--parent=topic-a"), 0)
+		assert.Equal(t, yascli.Run("add", "--branch=topic-c", "--parent=topic-a"), 0)
+
+		assert.Equal(t, yascli.Run("branch", "--insert", "--from=main", "refactor"), 1)
+		assert.Equal(t, yascli.Run("branch", "--insert", "refactor"), 0)
+
+		equalLines(t, mustExecOutput("git", "branch", "--show-current"), "refactor")
+
+		stdout, _, err := testutil.CaptureOutput(func() {
+			assert.Equal(t, yascli.Run("list"), 0)
+		})
+		assert.NilError(t, err)
+		equalLines(t, stdout, `
+			main
+			└── topic-a
+			    └── refactor
+			        ├── topic-b
+			        └── topic-c
+		`)
+
+		// Children that were behind the current branch are restacked
+		equalLines(t, mustExecOutput("git", "log", "--pretty=%D : %s", "topic-c"), `
+			topic-c : topic-c-0
+			HEAD -> refactor, topic-a : topic-a-1
+			: topic-a-0
+			main : main-0
+		`)
+	})
+}
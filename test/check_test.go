@@ -0,0 +1,87 @@
+package test
+
+import (
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/dansimau/yas/pkg/testutil"
+	"github.com/dansimau/yas/pkg/yascli"
+	"gotest.tools/v3/assert"
+)
+
+func TestCheck(t *testing.T) {
+	testutil.WithTempWorkingDir(t, func() {
+		wd, err := os.Getwd()
+		assert.NilError(t, err)
+		prDir := path.Join(wd, "prs")
+		assert.NilError(t, os.Mkdir(prDir, 0o755))
+
+		// Responds with the contents of prs/<branch>.json
+		withFakeGHScript(t, `cat `+prDir+`/"$4".json 2>/dev/null || echo '[]'`)
+
+		testutil.ExecOrFail(t, `
+			git init --initial-branch=main repo
+			cd repo
+
+			touch main
+			git add main
+			git commit -m "main-0"
+			echo 1 > main
+			git commit -am "main-1"
+
+			git checkout -b topic-a
+			touch a
+			git add a
+			git commit -m "topic-a-0"
+
+			git checkout -b topic-b
+			touch b
+			git add b
+			git commit -m "topic-b-0"
+
+			git checkout main
+		`)
+
+		assert.NilError(t, os.Chdir("repo"))
+
+		assert.Equal(t, yascli.Run("config", "set", "--trunk-branch=main"), 0)
+		assert.Equal(t, yascli.Run("add", "--branch=topic-a", "--parent=main"), 0)
+		assert.Equal(t, yascli.Run("add", "--branch=topic-b", "--parent=topic-a"), 0)
+		assert.Equal(t, yascli.Run("check"), 0)
+
+		// PR base doesn't match the parent
+		assert.NilError(t, os.WriteFile(path.Join(prDir, "topic-b.json"), []byte(`[{"id":"PR_2","state":"OPEN","url":"https://github.com/test/test/pull/2","baseRefName":"main"}]`), 0o644))
+		assert.Equal(t, yascli.Run("refresh", "--all"), 0)
+
+		// Branch point is no longer an ancestor of the branch
+		assert.Equal(t, yascli.Run("branch", "topic-c"), 0)
+		testutil.ExecOrFail(t, `git reset -q --hard main~1`)
+
+		// Parent chain doesn't reach trunk
+		testutil.ExecOrFail(t, `git branch topic-d main`)
+		assert.Equal(t, yascli.Run("add", "--branch=topic-d", "--parent=missing"), 0)
+
+		stdout, _, err := testutil.CaptureOutput(func() {
+			assert.Equal(t, yascli.Run("check"), 1)
+		})
+		assert.NilError(t, err)
+
+		equalLines(t, stdout, `
+			topic-b	pr-base	PR base is 'main' but should be 'topic-a' (hint: run `+"`yas submit`"+`)
+			topic-c	branch-point	branch point `+strings.TrimSpace(mustExecOutput("git", "rev-parse", "--short=7", "main"))+` is not an ancestor of the branch (hint: run `+"`yas restack`"+`)
+			topic-d	parent-chain	ancestor 'missing' is not tracked and not trunk (hint: run `+"`yas add --branch=missing`"+`)
+		`)
+
+		// Cycles
+		assert.Equal(t, yascli.Run("add", "--branch=topic-a", "--parent=topic-b"), 0)
+
+		stdout, _, err = testutil.CaptureOutput(func() {
+			assert.Equal(t, yascli.Run("check", "--json"), 1)
+		})
+		assert.NilError(t, err)
+		assert.Assert(t, strings.Contains(stdout, `{"branch":"topic-a","rule":"cycle","message":"branch is its own ancestor (hint: run `+"`yas move`"+`)"}`))
+		assert.Assert(t, strings.Contains(stdout, `{"branch":"topic-b","rule":"cycle"`))
+	})
+}
@@ -0,0 +1,178 @@
+package test
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/dansimau/yas/pkg/testutil"
+	"github.com/dansimau/yas/pkg/yascli"
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/assert/cmp"
+)
+
+func TestClean(t *testing.T) {
+	testutil.WithTempWorkingDir(t, func() {
+		testutil.ExecOrFail(t, `
+			git init --initial-branch=main
+
+			# main
+			touch main
+			git add main
+			git commit -m "main-0"
+
+			# topic-a
+			git checkout -b topic-a
+			touch a
+			git add a
+			git commit -m "topic-a-0"
+
+			git checkout main
+		`)
+
+		assert.Equal(t, yascli.Run("config", "set", "--trunk-branch=main"), 0)
+		assert.Equal(t, yascli.Run("add", "--branch=topic-a", "--parent=main"), 0)
+
+		testutil.ExecOrFail(t, `git branch -D topic-a`)
+
+		// Branch was only just deleted so is retained
+		stdout, _, err := testutil.CaptureOutput(func() {
+			assert.Equal(t, yascli.Run("clean"), 0)
+		})
+		assert.NilError(t, err)
+		assert.Assert(t, !strings.Contains(stdout, "topic-a"))
+
+		stdout, _, err = testutil.CaptureOutput(func() {
+			assert.Equal(t, yascli.Run("clean", "--days=0"), 0)
+		})
+		assert.NilError(t, err)
+		assert.Assert(t, cmp.Contains(stdout, "Removed metadata for branch: topic-a"))
+	})
+}
+
+func TestCleanRetentionPolicy(t *testing.T) {
+	testutil.WithTempWorkingDir(t, func() {
+		testutil.ExecOrFail(t, `
+			git init --initial-branch=main
+
+			touch main
+			git add main
+			git commit -m "main-0"
+
+			git checkout -b topic-a
+			touch a
+			git add a
+			git commit -m "topic-a-0"
+
+			git checkout -b topic-b main
+			touch b
+			git add b
+			git commit -m "topic-b-0"
+
+			git checkout main
+		`)
+
+		assert.Equal(t, yascli.Run("config", "set", "--trunk-branch=main"), 0)
+		assert.Equal(t, yascli.Run("add", "--branch=topic-a", "--parent=main"), 0)
+		assert.Equal(t, yascli.Run("add", "--branch=topic-b", "--parent=main"), 0)
+
+		// topic-b has an open PR
+		withFakeGHScript(t, `
+			if [ "$4" = "topic-b" ]; then
+				echo '[{"id":"PR_2","state":"OPEN","url":"https://github.com/test/test/pull/2","baseRefName":"main"}]'
+			else
+				echo '[]'
+			fi
+		`)
+		assert.Equal(t, yascli.Run("refresh", "--all"), 0)
+
+		assert.Equal(t, yascli.Run("config", "set", "--deleted-branch-retention-days=0", "--deleted-branch-retain-open-prs"), 0)
+
+		testutil.ExecOrFail(t, `git branch -D topic-a topic-b`)
+
+		stdout, _, err := testutil.CaptureOutput(func() {
+			assert.Equal(t, yascli.Run("clean"), 0)
+		})
+		assert.NilError(t, err)
+		assert.Assert(t, cmp.Contains(stdout, "Removed metadata for branch: topic-a"))
+		assert.Assert(t, !strings.Contains(stdout, "topic-b"))
+
+		assert.Equal(t, yascli.Run("config", "unset", "deletedBranchRetainOpenPRs"), 0)
+		assert.Equal(t, yascli.Run("config", "set", "--deleted-branch-retention-days=30"), 0)
+
+		stdout, _, err = testutil.CaptureOutput(func() {
+			assert.Equal(t, yascli.Run("clean"), 0)
+		})
+		assert.NilError(t, err)
+		assert.Assert(t, !strings.Contains(stdout, "topic-b"))
+
+		// The flag overrides the configured retention period
+		stdout, _, err = testutil.CaptureOutput(func() {
+			assert.Equal(t, yascli.Run("clean", "--days=0"), 0)
+		})
+		assert.NilError(t, err)
+		assert.Assert(t, cmp.Contains(stdout, "Removed metadata for branch: topic-b"))
+	})
+}
+
+func TestCleanWorktrees(t *testing.T) {
+	testutil.WithTempWorkingDir(t, func() {
+		testutil.ExecOrFail(t, `
+			git init -q --initial-branch=main repo
+			cd repo
+
+			touch main
+			git add main
+			git commit -m "main-0"
+
+			git branch topic-a
+			git branch topic-b
+		`)
+
+		assert.NilError(t, os.Chdir("repo"))
+
+		assert.Equal(t, yascli.Run("config", "set", "--trunk-branch=main", "--worktree-dir=../wt"), 0)
+		assert.Equal(t, yascli.Run("add", "--branch=topic-a", "--parent=main"), 0)
+		assert.Equal(t, yascli.Run("add", "--branch=topic-b", "--parent=main"), 0)
+		assert.Equal(t, yascli.Run("worktree", "add", "topic-a"), 0)
+
+		// A directory left behind that git doesn't know about
+		assert.NilError(t, os.MkdirAll("../wt/stale/subdir", 0o755))
+
+		testutil.ExecOrFail(t, `git branch -D topic-b`)
+
+		state, err := os.ReadFile(".git/.yasstate")
+		assert.NilError(t, err)
+
+		stdout, _, err := testutil.CaptureOutput(func() {
+			assert.Equal(t, yascli.Run("--dry-run", "clean", "--days=0"), 0)
+		})
+		assert.NilError(t, err)
+		assert.Assert(t, cmp.Contains(stdout, "Would remove metadata for branch: topic-b"))
+		assert.Assert(t, cmp.Contains(stdout, "Would remove orphaned worktree directory: "))
+		assert.Assert(t, cmp.Contains(stdout, "/wt/stale [DRY-RUN]"))
+		assert.Assert(t, !strings.Contains(stdout, "topic-a"))
+
+		// Nothing is modified in dry-run mode
+		newState, err := os.ReadFile(".git/.yasstate")
+		assert.NilError(t, err)
+		assert.Equal(t, string(newState), string(state))
+
+		_, err = os.Stat("../wt/stale")
+		assert.NilError(t, err)
+
+		stdout, _, err = testutil.CaptureOutput(func() {
+			assert.Equal(t, yascli.Run("clean", "--days=0"), 0)
+		})
+		assert.NilError(t, err)
+		assert.Assert(t, cmp.Contains(stdout, "Removed metadata for branch: topic-b"))
+		assert.Assert(t, cmp.Contains(stdout, "/wt/stale\n"))
+
+		_, err = os.Stat("../wt/stale")
+		assert.Assert(t, os.IsNotExist(err))
+
+		// The worktree that git knows about is kept
+		_, err = os.Stat("../wt/topic-a")
+		assert.NilError(t, err)
+	})
+}
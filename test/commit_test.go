@@ -0,0 +1,107 @@
+package test
+
+import (
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/dansimau/yas/pkg/testutil"
+	"github.com/dansimau/yas/pkg/yascli"
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/assert/cmp"
+)
+
+func TestCommitTemplate(t *testing.T) {
+	testutil.WithTempWorkingDir(t, func() {
+		// Append the subject to the first line of the prefilled message
+		for _, envVar := range []string{"GIT_EDITOR", "VISUAL"} {
+			t.Setenv(envVar, "")
+			os.Unsetenv(envVar)
+		}
+		t.Setenv("EDITOR", "sed -i -e '1s/$/fix thing/'")
+
+		testutil.ExecOrFail(t, `
+			git init --initial-branch=main
+
+			touch main
+			git add main
+			git commit -m "main-0"
+
+			git checkout -b user/ABC-123-fix-thing
+			touch a
+			git add a
+		`)
+
+		assert.Equal(t, yascli.Run("config", "set", "--trunk-branch=main", "--commit-template=[{{.Ticket}}] "), 0)
+		assert.Equal(t, yascli.Run("add", "--branch=user/ABC-123-fix-thing", "--parent=main"), 0)
+		assert.Equal(t, yascli.Run("commit"), 0)
+
+		equalLines(t, mustExecOutput("git", "log", "-1", "--pretty=%s"), `
+			[ABC-123] fix thing
+		`)
+
+		// Branches without a ticket ID aren't prefilled
+		testutil.ExecOrFail(t, `
+			git checkout -b topic-b
+			touch b
+			git add b
+		`)
+
+		assert.Equal(t, yascli.Run("commit", "--", "-m", "topic-b-0"), 0)
+
+		equalLines(t, mustExecOutput("git", "log", "-1", "--pretty=%s"), `
+			topic-b-0
+		`)
+	})
+}
+
+func TestSubmitAppendsTicketLink(t *testing.T) {
+	testutil.WithTempWorkingDir(t, func() {
+		wd, err := os.Getwd()
+		assert.NilError(t, err)
+		ghLog := path.Join(wd, "gh.log")
+
+		withFakeGHScript(t, `
+			case "$2" in
+			list)
+				echo '[]'
+				;;
+			create)
+				echo "$@" >> `+ghLog+`
+				;;
+			esac
+		`)
+
+		testutil.ExecOrFail(t, `
+			git init --bare --initial-branch=main origin.git
+			git clone -q origin.git repo
+			cd repo
+
+			touch main
+			git add main
+			git commit -m "main-0"
+			git push -q origin main
+
+			git checkout -b user/abc-123-fix-thing
+			touch a
+			git add a
+			git commit -m "Fix thing" -m "Details."
+		`)
+
+		assert.NilError(t, os.Chdir("repo"))
+
+		assert.Equal(t, yascli.Run("config", "set",
+			"--trunk-branch=main",
+			"--ticket-pattern=(?i)(abc-[0-9]+)",
+			"--ticket-url=https://tickets.example.com/{{.Ticket}}",
+		), 0)
+		assert.Equal(t, yascli.Run("add", "--branch=user/abc-123-fix-thing", "--parent=main"), 0)
+		assert.Equal(t, yascli.Run("submit"), 0)
+
+		b, err := os.ReadFile(ghLog)
+		assert.NilError(t, err)
+		assert.Assert(t, cmp.Contains(string(b), "--body Details.\n\nhttps://tickets.example.com/abc-123"))
+		assert.Assert(t, !strings.Contains(string(b), "ABC"))
+	})
+}
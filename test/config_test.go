@@ -1,6 +1,8 @@
 package test
 
 import (
+	"os"
+	"strings"
 	"testing"
 
 	"github.com/dansimau/yas/pkg/testutil"
@@ -35,3 +37,72 @@ func TestNotInitialized(t *testing.T) {
 		assert.Assert(t, cmp.Contains(stderr, "repository not configured"))
 	})
 }
+
+func TestGlobalConfig(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	testutil.WithTempWorkingDir(t, func() {
+		testutil.ExecOrFail(t, `git init --initial-branch=main`)
+
+		assert.Equal(t, yascli.Run("config", "set", "--global", "--branch-prefix=global/", "--pr-body=empty"), 0)
+		assert.Equal(t, yascli.Run("config", "set", "--trunk-branch=main", "--branch-prefix=repo/"), 0)
+
+		// Repo config overrides global config
+		stdout, _, err := testutil.CaptureOutput(func() {
+			assert.Equal(t, yascli.Run("config", "list"), 0)
+		})
+		assert.NilError(t, err)
+		equalLines(t, stdout, `
+			branchPrefix=repo/
+			prBody=empty
+			trunkBranch=main
+		`)
+
+		stdout, _, err = testutil.CaptureOutput(func() {
+			assert.Equal(t, yascli.Run("config", "get", "--global", "branchPrefix"), 0)
+		})
+		assert.NilError(t, err)
+		equalLines(t, stdout, "global/")
+
+		// Global values are not written to the repo config
+		b, err := os.ReadFile(".git/yas.yaml")
+		assert.NilError(t, err)
+		assert.Assert(t, !strings.Contains(string(b), "prBody"))
+	})
+}
+
+func TestConfigUnset(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	testutil.WithTempWorkingDir(t, func() {
+		testutil.ExecOrFail(t, `git init --initial-branch=main`)
+
+		assert.Equal(t, yascli.Run("config", "set", "--global", "--branch-prefix=global/"), 0)
+		assert.Equal(t, yascli.Run("config", "set", "--trunk-branch=main", "--branch-prefix=repo/", "--max-pr-lines=500"), 0)
+
+		assert.Equal(t, yascli.Run("config", "unset", "branchPrefix"), 0)
+		assert.Equal(t, yascli.Run("config", "unset", "limits.maxPRLines"), 0)
+
+		// The global value applies again
+		stdout, _, err := testutil.CaptureOutput(func() {
+			assert.Equal(t, yascli.Run("config", "list"), 0)
+		})
+		assert.NilError(t, err)
+		equalLines(t, stdout, `
+			branchPrefix=global/
+			trunkBranch=main
+		`)
+
+		_, stderr, err := testutil.CaptureOutput(func() {
+			assert.Equal(t, yascli.Run("config", "unset", "branchPrefix"), 1)
+		})
+		assert.NilError(t, err)
+		assert.Assert(t, cmp.Contains(stderr, "config key 'branchPrefix' is not set"))
+
+		_, stderr, err = testutil.CaptureOutput(func() {
+			assert.Equal(t, yascli.Run("config", "unset", "trunkBranch"), 1)
+		})
+		assert.NilError(t, err)
+		assert.Assert(t, cmp.Contains(stderr, "cannot be unset"))
+	})
+}
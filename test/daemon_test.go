@@ -0,0 +1,59 @@
+package test
+
+import (
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/dansimau/yas/pkg/testutil"
+	"github.com/dansimau/yas/pkg/yas"
+	"github.com/dansimau/yas/pkg/yascli"
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/poll"
+)
+
+func TestDaemon(t *testing.T) {
+	withFakeGH(t, "[]")
+
+	testutil.WithTempWorkingDir(t, func() {
+		testutil.ExecOrFail(t, `
+			git init --initial-branch=main
+			git commit --allow-empty -m "main-0"
+		`)
+
+		assert.Equal(t, yascli.Run("config", "set", "--trunk-branch=main"), 0)
+		assert.Equal(t, yascli.Run("daemon", "stop"), 1)
+
+		wd, err := os.Getwd()
+		assert.NilError(t, err)
+
+		daemon := yas.NewDaemon(wd)
+
+		exited := make(chan int)
+		go func() {
+			exited <- yascli.Run("daemon", "run", "--interval=1")
+		}()
+
+		poll.WaitOn(t, func(poll.LogT) poll.Result {
+			if _, running, _ := daemon.Status(); running {
+				return poll.Success()
+			}
+			return poll.Continue("daemon not running")
+		}, poll.WithTimeout(5*time.Second))
+
+		pid, _, err := daemon.Status()
+		assert.NilError(t, err)
+		assert.Equal(t, pid, os.Getpid())
+
+		// Only one daemon per repo
+		assert.Equal(t, yascli.Run("daemon", "run"), 1)
+
+		assert.NilError(t, syscall.Kill(os.Getpid(), syscall.SIGTERM))
+		assert.Equal(t, <-exited, 0)
+
+		_, running, err := daemon.Status()
+		assert.NilError(t, err)
+		assert.Assert(t, !running)
+	})
+}
@@ -0,0 +1,106 @@
+package test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/dansimau/yas/pkg/testutil"
+	"github.com/dansimau/yas/pkg/yascli"
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/assert/cmp"
+)
+
+func TestDeletePattern(t *testing.T) {
+	testutil.WithTempWorkingDir(t, func() {
+		testutil.ExecOrFail(t, `
+			git init --initial-branch=main repo
+			cd repo
+
+			touch main
+			git add main
+			git commit -m "main-0"
+
+			git branch spike-a
+			git branch spike-b
+			git branch spike-c
+			git branch topic-a
+
+			git worktree add -q ../wt-c spike-c
+			touch ../wt-c/dirty
+		`)
+
+		assert.NilError(t, os.Chdir("repo"))
+
+		assert.Equal(t, yascli.Run("config", "set", "--trunk-branch=main"), 0)
+		assert.Equal(t, yascli.Run("add", "--branch=spike-a", "--parent=main"), 0)
+		assert.Equal(t, yascli.Run("add", "--branch=spike-b", "--parent=spike-a"), 0)
+		assert.Equal(t, yascli.Run("add", "--branch=spike-c", "--parent=main"), 0)
+		assert.Equal(t, yascli.Run("add", "--branch=topic-a", "--parent=main"), 0)
+
+		stdout, stderr, err := testutil.CaptureOutput(func() {
+			withStdin(t, "y\n", func() {
+				assert.Equal(t, yascli.Run("delete", "--pattern=spike-*"), 1)
+			})
+		})
+		assert.NilError(t, err)
+
+		// Children are deleted first; the worktree has untracked files so
+		// isn't removed
+		assert.Assert(t, cmp.Regexp(`spike-b\s+\|\s+deleted\s*\n`, stdout))
+		assert.Assert(t, cmp.Regexp(`spike-a\s+\|\s+deleted\s*\n`, stdout))
+		assert.Assert(t, cmp.Regexp(`spike-c\s+\|\s+failed: failed to remove worktree`, stdout))
+		assert.Assert(t, cmp.Contains(stderr, "failed to delete 1 of 3 branch(es)"))
+
+		equalLines(t, mustExecOutput("git", "branch", "--format=%(refname:short)"), `
+			main
+			spike-c
+			topic-a
+		`)
+
+		// Removing the untracked file allows the worktree to be removed
+		assert.NilError(t, os.Remove("../wt-c/dirty"))
+
+		stdout, _, err = testutil.CaptureOutput(func() {
+			assert.Equal(t, yascli.Run("delete", "--yes", "spike-c"), 0)
+		})
+		assert.NilError(t, err)
+		assert.Assert(t, cmp.Regexp(`spike-c\s+\|\s+deleted \(removed worktree .*wt-c\)`, stdout))
+	})
+}
+
+func TestDeleteInteractive(t *testing.T) {
+	testutil.WithTempWorkingDir(t, func() {
+		testutil.ExecOrFail(t, `
+			git init --initial-branch=main
+
+			touch main
+			git add main
+			git commit -m "main-0"
+
+			git branch topic-a
+			git branch topic-b
+			git branch topic-c
+		`)
+
+		assert.Equal(t, yascli.Run("config", "set", "--trunk-branch=main"), 0)
+		assert.Equal(t, yascli.Run("add", "--branch=topic-a", "--parent=main"), 0)
+		assert.Equal(t, yascli.Run("add", "--branch=topic-b", "--parent=topic-a"), 0)
+		assert.Equal(t, yascli.Run("add", "--branch=topic-c", "--parent=main"), 0)
+
+		// Deleting a parent without its child fails
+		stdout, _, err := testutil.CaptureOutput(func() {
+			withStdin(t, "9\n1,3\ny\n", func() {
+				assert.Equal(t, yascli.Run("delete", "--interactive"), 1)
+			})
+		})
+		assert.NilError(t, err)
+		assert.Assert(t, cmp.Regexp(`topic-a\s+\|\s+failed: branch has children`, stdout))
+		assert.Assert(t, cmp.Regexp(`topic-c\s+\|\s+deleted\s*\n`, stdout))
+
+		equalLines(t, mustExecOutput("git", "branch", "--format=%(refname:short)"), `
+			main
+			topic-a
+			topic-b
+		`)
+	})
+}
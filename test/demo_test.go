@@ -0,0 +1,50 @@
+package test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/dansimau/yas/pkg/testutil"
+	"github.com/dansimau/yas/pkg/yascli"
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/assert/cmp"
+)
+
+func TestDemo(t *testing.T) {
+	testutil.WithTempWorkingDir(t, func() {
+		assert.Equal(t, yascli.Run("demo", "playground"), 0)
+
+		// Refuses to overwrite an existing directory
+		assert.Equal(t, yascli.Run("demo", "playground"), 1)
+
+		assert.NilError(t, os.Chdir("playground/repo"))
+
+		stdout, _, err := testutil.CaptureOutput(func() {
+			assert.Equal(t, yascli.Run("list"), 0)
+		})
+		assert.NilError(t, err)
+		equalLines(t, stdout, `
+			main
+			└── demo/topic-a
+			    └── demo/topic-b
+			        └── demo/topic-c [draft]
+		`)
+
+		stdout, _, err = testutil.CaptureOutput(func() {
+			assert.Equal(t, yascli.Run("status"), 0)
+		})
+		assert.NilError(t, err)
+		assert.Assert(t, cmp.Contains(stdout, "https://github.com/example/yas-demo/pull/3"))
+
+		// Trunk has moved on, so the stack needs restacking
+		assert.Equal(t, yascli.Run("restack"), 0)
+
+		equalLines(t, mustExecOutput("git", "log", "--pretty=%D : %s"), `
+			HEAD -> demo/topic-c : Add c
+			demo/topic-b : Add b
+			demo/topic-a : Add a
+			origin/main, main : Update README
+			: Initial commit
+		`)
+	})
+}
@@ -0,0 +1,169 @@
+package test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/dansimau/yas/pkg/testutil"
+	"github.com/dansimau/yas/pkg/yascli"
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/assert/cmp"
+)
+
+func TestDoctorUnsignedCommits(t *testing.T) {
+	testutil.WithTempWorkingDir(t, func() {
+		testutil.ExecOrFail(t, `
+			git init --initial-branch=main
+
+			# main
+			touch main
+			git add main
+			git commit -m "main-0"
+
+			# topic-a
+			git checkout -b topic-a
+			touch a
+			git add a
+			git commit -m "topic-a-0"
+		`)
+
+		assert.Equal(t, yascli.Run("config", "set", "--trunk-branch=main"), 0)
+		assert.Equal(t, yascli.Run("add", "--branch=topic-a", "--parent=main"), 0)
+
+		stdout, _, err := testutil.CaptureOutput(func() {
+			assert.Equal(t, yascli.Run("doctor"), 0)
+		})
+		assert.NilError(t, err)
+		assert.Assert(t, cmp.Contains(stdout, "No problems found"))
+
+		assert.Equal(t, yascli.Run("config", "set", "--sign-commits"), 0)
+
+		stdout, _, err = testutil.CaptureOutput(func() {
+			assert.Equal(t, yascli.Run("doctor"), 1)
+		})
+		assert.NilError(t, err)
+		assert.Assert(t, cmp.Contains(stdout, "branch 'topic-a' has 1 unsigned commit(s)"))
+	})
+}
+
+func TestDoctorForeignCommits(t *testing.T) {
+	testutil.WithTempWorkingDir(t, func() {
+		testutil.ExecOrFail(t, `
+			git init --initial-branch=main
+
+			# main
+			touch main
+			git add main
+			git commit -m "main-0"
+
+			# topic-a
+			git checkout -b topic-a
+			touch a
+			git add a
+			git commit -m "topic-a-0"
+
+			# topic-b, accidentally based on topic-a
+			git checkout -b topic-b
+			touch b
+			git add b
+			git commit -m "topic-b-0"
+		`)
+
+		assert.Equal(t, yascli.Run("config", "set", "--trunk-branch=main"), 0)
+		assert.Equal(t, yascli.Run("add", "--branch=topic-a", "--parent=main"), 0)
+		assert.Equal(t, yascli.Run("add", "--branch=topic-b", "--parent=main"), 0)
+
+		stdout, _, err := testutil.CaptureOutput(func() {
+			assert.Equal(t, yascli.Run("doctor"), 1)
+		})
+		assert.NilError(t, err)
+		assert.Assert(t, cmp.Contains(stdout, "branch 'topic-b' contains 1 commit(s) from other branches (topic-a)"))
+		assert.Assert(t, !strings.Contains(stdout, "branch 'topic-a' contains"))
+
+		// Refuses to submit
+		assert.Equal(t, yascli.Run("submit"), 1)
+	})
+}
+
+func TestDoctorFixBranchPoints(t *testing.T) {
+	testutil.WithTempWorkingDir(t, func() {
+		testutil.ExecOrFail(t, `
+			git init --initial-branch=main
+
+			# main
+			touch main
+			git add main
+			git commit -m "main-0"
+
+			# topic-a
+			git checkout -b topic-a
+			touch a
+			git add a
+			git commit -m "topic-a-0"
+		`)
+
+		assert.Equal(t, yascli.Run("config", "set", "--trunk-branch=main"), 0)
+		assert.Equal(t, yascli.Run("add", "--branch=topic-a", "--parent=main"), 0)
+
+		clearBranchPoints(t)
+
+		stdout, _, err := testutil.CaptureOutput(func() {
+			assert.Equal(t, yascli.Run("doctor"), 1)
+		})
+		assert.NilError(t, err)
+		assert.Assert(t, cmp.Contains(stdout, "branch 'topic-a' has no branch point recorded"))
+
+		stdout, _, err = testutil.CaptureOutput(func() {
+			assert.Equal(t, yascli.Run("doctor", "--fix"), 0)
+		})
+		assert.NilError(t, err)
+		assert.Assert(t, cmp.Contains(stdout, "Fixed: recorded branch point of 'topic-a' ("+mustGetShortHash("main")+")"))
+		assert.Assert(t, cmp.Contains(stdout, "No problems found"))
+	})
+}
+
+func TestDoctorFixStaleBranchPoints(t *testing.T) {
+	testutil.WithTempWorkingDir(t, func() {
+		testutil.ExecOrFail(t, `
+			git init --initial-branch=main
+
+			# main
+			touch main
+			git add main
+			git commit -m "main-0"
+
+			# topic-a
+			git checkout -b topic-a
+			touch a
+			git add a
+			git commit -m "topic-a-0"
+
+			# topic-b
+			git checkout -b topic-b
+			touch b
+			git add b
+			git commit -m "topic-b-0"
+		`)
+
+		assert.Equal(t, yascli.Run("config", "set", "--trunk-branch=main"), 0)
+		assert.Equal(t, yascli.Run("add", "--branch=topic-a", "--parent=main"), 0)
+		assert.Equal(t, yascli.Run("add", "--branch=topic-b", "--parent=topic-a"), 0)
+
+		// Rebase topic-b off topic-a outside of yas, so its recorded branch
+		// point is no longer in the branch
+		testutil.ExecOrFail(t, `git rebase -q --onto main topic-a topic-b`)
+
+		stdout, _, err := testutil.CaptureOutput(func() {
+			assert.Equal(t, yascli.Run("doctor", "--fix"), 0)
+		})
+		assert.NilError(t, err)
+		assert.Assert(t, cmp.Contains(stdout, "Fixed: updated stale branch point of 'topic-b' ("+mustGetShortHash("main")+")"))
+
+		// The repaired branch point was saved
+		stdout, _, err = testutil.CaptureOutput(func() {
+			assert.Equal(t, yascli.Run("doctor", "--fix"), 0)
+		})
+		assert.NilError(t, err)
+		assert.Assert(t, !strings.Contains(stdout, "Fixed:"))
+	})
+}
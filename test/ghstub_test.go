@@ -0,0 +1,91 @@
+package test
+
+import (
+	"os"
+	"path"
+	"testing"
+
+	"github.com/dansimau/yas/pkg/ghstub"
+	"github.com/dansimau/yas/pkg/testutil"
+	"github.com/dansimau/yas/pkg/yascli"
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/assert/cmp"
+)
+
+func TestGitHubStub(t *testing.T) {
+	testutil.WithTempWorkingDir(t, func() {
+		wd, err := os.Getwd()
+		assert.NilError(t, err)
+		stubFile := path.Join(wd, "github.json")
+		t.Setenv("YAS_GH_STUB", stubFile)
+
+		testutil.ExecOrFail(t, `
+			git init -q --bare --initial-branch=main origin.git
+			git clone -q origin.git repo
+			cd repo
+
+			touch main
+			git add main
+			git commit -m "main-0"
+			git push -q origin main
+
+			git checkout -b topic-a
+			touch a
+			git add a
+			git commit -m "topic-a-0"
+
+			git checkout -b topic-b
+			touch b
+			git add b
+			git commit -m "topic-b-0"
+		`)
+
+		assert.NilError(t, os.Chdir("repo"))
+
+		assert.Equal(t, yascli.Run("config", "set", "--trunk-branch=main", "--default-draft=false"), 0)
+		assert.Equal(t, yascli.Run("add", "--branch=topic-a", "--parent=main"), 0)
+		assert.Equal(t, yascli.Run("add", "--branch=topic-b", "--parent=topic-a"), 0)
+		assert.Equal(t, yascli.Run("submit", "--stack"), 0)
+
+		stub, err := ghstub.Load(stubFile)
+		assert.NilError(t, err)
+		assert.Equal(t, len(stub.PullRequests), 2)
+		assert.Equal(t, stub.PullRequests[0].HeadRefName, "topic-a")
+		assert.Equal(t, stub.PullRequests[0].BaseRefName, "main")
+		assert.Equal(t, stub.PullRequests[0].Title, "topic-a-0")
+		assert.Equal(t, stub.PullRequests[1].HeadRefName, "topic-b")
+		assert.Equal(t, stub.PullRequests[1].BaseRefName, "topic-a")
+
+		assert.Equal(t, yascli.Run("refresh"), 0)
+
+		stdout, _, err := testutil.CaptureOutput(func() {
+			assert.Equal(t, yascli.Run("status"), 0)
+		})
+		assert.NilError(t, err)
+		assert.Assert(t, cmp.Contains(stdout, "https://github.com/example/repo/pull/2"))
+
+		testutil.ExecOrFail(t, "git checkout topic-a")
+		assert.Equal(t, yascli.Run("merge"), 0)
+
+		stub, err = ghstub.Load(stubFile)
+		assert.NilError(t, err)
+		assert.Equal(t, stub.PullRequests[0].State, "MERGED")
+		assert.Equal(t, stub.PullRequests[1].State, "OPEN")
+		assert.Equal(t, stub.PullRequests[1].BaseRefName, "main")
+
+		// The PR was merged into main on origin
+		equalLines(t, mustExecOutput("git", "log", "--pretty=%s", "origin/main", "--"), `
+			topic-a-0
+			main-0
+		`)
+
+		stdout, _, err = testutil.CaptureOutput(func() {
+			assert.Equal(t, yascli.Run("list"), 0)
+		})
+		assert.NilError(t, err)
+		equalLines(t, stdout, `
+			main
+			└── topic-b
+		`)
+	})
+}
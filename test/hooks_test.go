@@ -0,0 +1,89 @@
+package test
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/dansimau/yas/pkg/testutil"
+	"github.com/dansimau/yas/pkg/yascli"
+	"gotest.tools/v3/assert"
+)
+
+func TestHooks(t *testing.T) {
+	testutil.WithTempWorkingDir(t, func() {
+		testutil.ExecOrFail(t, `
+			git init --initial-branch=main
+
+			# main
+			touch main
+			git add main
+			git commit -m "main-0"
+
+			# topic-a
+			git checkout -b topic-a
+			touch a
+			git add a
+			git commit -m "topic-a-0"
+
+			cat > .git/yas.yaml <<-'EOF'
+			trunkBranch: main
+			hooks:
+			  preRestack: test ! -f .git/block-restack
+			  postRestack: echo "$YAS_BRANCH $YAS_PARENT" > .git/post-restack
+			EOF
+		`)
+
+		assert.Equal(t, yascli.Run("add", "--branch=topic-a", "--parent=main"), 0)
+		assert.Equal(t, yascli.Run("restack"), 0)
+
+		b, err := os.ReadFile(".git/post-restack")
+		assert.NilError(t, err)
+		assert.Equal(t, string(b), "topic-a main\n")
+
+		// Failing pre hook aborts the operation
+		testutil.ExecOrFail(t, `touch .git/block-restack`)
+		assert.Equal(t, yascli.Run("restack"), 1)
+		assert.Equal(t, yascli.Run("--no-hooks", "restack"), 0)
+
+		// Hooks run again without the flag
+		assert.Equal(t, yascli.Run("restack"), 1)
+	})
+}
+
+func TestPluginsReceiveEvents(t *testing.T) {
+	testutil.WithTempWorkingDir(t, func() {
+		testutil.ExecOrFail(t, `
+			git init --initial-branch=main
+
+			# main
+			touch main
+			git add main
+			git commit -m "main-0"
+
+			mkdir .git/yas-plugins
+			cat > .git/yas-plugins/record <<-'EOF'
+			#!/bin/sh
+			echo "$YAS_EVENT $(cat)" >> .git/events
+			EOF
+			chmod +x .git/yas-plugins/record
+
+			# Not executable, so not run
+			touch .git/yas-plugins/README
+		`)
+
+		assert.Equal(t, yascli.Run("config", "set", "--trunk-branch=main"), 0)
+		assert.Equal(t, yascli.Run("branch", "topic-a"), 0)
+		assert.Equal(t, yascli.Run("restack"), 0)
+
+		b, err := os.ReadFile(".git/events")
+		assert.NilError(t, err)
+
+		lines := strings.Split(strings.TrimSpace(string(b)), "\n")
+		assert.Equal(t, len(lines), 2)
+		assert.Assert(t, strings.HasPrefix(lines[0], `BranchCreated {"type":"BranchCreated",`))
+		assert.Assert(t, strings.Contains(lines[0], `"branch":"topic-a","parent":"main"`))
+		assert.Assert(t, strings.HasPrefix(lines[1], `Restacked {"type":"Restacked",`))
+		assert.Assert(t, strings.Contains(lines[1], `"branches":["topic-a"]`))
+	})
+}
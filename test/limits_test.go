@@ -0,0 +1,76 @@
+package test
+
+import (
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/dansimau/yas/pkg/testutil"
+	"github.com/dansimau/yas/pkg/yascli"
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/assert/cmp"
+)
+
+func TestSubmitLimits(t *testing.T) {
+	testutil.WithTempWorkingDir(t, func() {
+		wd, err := os.Getwd()
+		assert.NilError(t, err)
+		ghLog := path.Join(wd, "gh.log")
+
+		withFakeGHScript(t, `
+			echo "$@" >> `+ghLog+`
+			case "$2" in
+			list)
+				echo '[]'
+				;;
+			esac
+		`)
+
+		testutil.ExecOrFail(t, `
+			git init --bare --initial-branch=main origin.git
+			git clone -q origin.git repo
+			cd repo
+
+			touch main
+			git add main
+			git commit -m "main-0"
+			git push -q origin main
+
+			git checkout -b topic-a
+			printf "1\n2\n3\n" > a
+			git add a
+			git commit -m "topic-a-0"
+		`)
+
+		assert.NilError(t, os.Chdir("repo"))
+
+		assert.Equal(t, yascli.Run("config", "set", "--trunk-branch=main", "--max-pr-lines=2", "--max-stack-depth=1"), 0)
+		assert.Equal(t, yascli.Run("add", "--branch=topic-a", "--parent=main"), 0)
+
+		stdout, _, err := testutil.CaptureOutput(func() {
+			assert.Equal(t, yascli.Run("list", "--verbose"), 0)
+		})
+		assert.NilError(t, err)
+		assert.Assert(t, cmp.Contains(stdout, "topic-a (+3 -0, 1 files)"))
+
+		_, stderr, err := testutil.CaptureOutput(func() {
+			assert.Equal(t, yascli.Run("submit", "--strict"), 1)
+		})
+		assert.NilError(t, err)
+		assert.Assert(t, cmp.Contains(stderr, "branch 'topic-a' exceeds limits: 3 lines changed (max 2)"))
+
+		_, err = os.Stat(ghLog)
+		assert.Assert(t, os.IsNotExist(err))
+
+		stdout, _, err = testutil.CaptureOutput(func() {
+			assert.Equal(t, yascli.Run("submit"), 0)
+		})
+		assert.NilError(t, err)
+		assert.Assert(t, cmp.Contains(stdout, "topic-a exceeds limits: 3 lines changed (max 2)"))
+
+		b, err := os.ReadFile(ghLog)
+		assert.NilError(t, err)
+		assert.Assert(t, strings.Contains(string(b), "pr create --head topic-a --base main"))
+	})
+}
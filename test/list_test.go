@@ -0,0 +1,258 @@
+package test
+
+import (
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/dansimau/yas/pkg/testutil"
+	"github.com/dansimau/yas/pkg/yascli"
+	"gotest.tools/v3/assert"
+)
+
+func TestListShowsPRBaseOverride(t *testing.T) {
+	testutil.WithTempWorkingDir(t, func() {
+		testutil.ExecOrFail(t, `
+			git init --initial-branch=main
+
+			# main
+			touch main
+			git add main
+			git commit -m "main-0"
+
+			# topic-a
+			git checkout -b topic-a
+			touch a
+			git add a
+			git commit -m "topic-a-0"
+
+			# topic-b
+			git checkout -b topic-b
+			touch b
+			git add b
+			git commit -m "topic-b-0"
+		`)
+
+		assert.Equal(t, yascli.Run("config", "set", "--trunk-branch=main"), 0)
+		assert.Equal(t, yascli.Run("add", "--branch=topic-a", "--parent=main"), 0)
+		assert.Equal(t, yascli.Run("add", "--branch=topic-b", "--parent=topic-a"), 0)
+		assert.Equal(t, yascli.Run("config", "set", "--branch=topic-b", "--pr-base=release/1.2"), 0)
+
+		stdout, _, err := testutil.CaptureOutput(func() {
+			assert.Equal(t, yascli.Run("list"), 0)
+		})
+		assert.NilError(t, err)
+
+		equalLines(t, stdout, `
+			main
+			└── topic-a
+			    └── topic-b (PR base: release/1.2)
+		`)
+	})
+}
+
+func TestListMine(t *testing.T) {
+	testutil.WithTempWorkingDir(t, func() {
+		testutil.ExecOrFail(t, `
+			git init --initial-branch=main
+
+			# main
+			touch main
+			git add main
+			git commit -m "main-0"
+
+			git branch dan/topic-a
+			git branch other/topic-b
+			git branch dan/spike
+			git branch other/spike
+		`)
+
+		assert.Equal(t, yascli.Run("config", "set", "--trunk-branch=main", "--branch-prefix=dan/"), 0)
+		assert.Equal(t, yascli.Run("add", "--branch=dan/topic-a", "--parent=main"), 0)
+		assert.Equal(t, yascli.Run("add", "--branch=other/topic-b", "--parent=main"), 0)
+
+		stdout, _, err := testutil.CaptureOutput(func() {
+			assert.Equal(t, yascli.Run("list", "--all", "--mine"), 0)
+		})
+		assert.NilError(t, err)
+
+		equalLines(t, stdout, `
+			main
+			└── dan/topic-a
+
+			Untracked branches:
+			dan/spike
+		`)
+	})
+}
+
+func TestListVerboseActivity(t *testing.T) {
+	commitDate := time.Now().Add(-40 * 24 * time.Hour).Format(time.RFC3339)
+
+	testutil.WithTempWorkingDir(t, func() {
+		testutil.ExecOrFail(t, `
+			git init --initial-branch=main
+
+			# main
+			touch main
+			git add main
+			git commit -m "main-0"
+
+			# topic-a
+			git checkout -b topic-a
+			echo a > a
+			git add a
+			GIT_COMMITTER_DATE=`+commitDate+` git commit -m "topic-a-0"
+		`)
+
+		assert.Equal(t, yascli.Run("config", "set", "--trunk-branch=main"), 0)
+		assert.Equal(t, yascli.Run("add", "--branch=topic-a", "--parent=main"), 0)
+
+		stdout, _, err := testutil.CaptureOutput(func() {
+			assert.Equal(t, yascli.Run("list", "--verbose"), 0)
+		})
+		assert.NilError(t, err)
+
+		equalLines(t, stdout, `
+			main
+			└── topic-a (+1 -0, 1 files) created just now, last commit 40d ago
+		`)
+	})
+}
+
+func TestListShowsChecksAndReviews(t *testing.T) {
+	testutil.WithTempWorkingDir(t, func() {
+		wd, err := os.Getwd()
+		assert.NilError(t, err)
+		prDir := path.Join(wd, "prs")
+		assert.NilError(t, os.Mkdir(prDir, 0o755))
+
+		// Responds with the contents of prs/<branch>.json
+		withFakeGHScript(t, `cat `+prDir+`/"$4".json 2>/dev/null || echo '[]'`)
+
+		writePR := func(branch, json string) {
+			assert.NilError(t, os.WriteFile(path.Join(prDir, branch+".json"), []byte(json), 0o644))
+		}
+
+		testutil.ExecOrFail(t, `
+			git init --initial-branch=main repo
+			cd repo
+
+			touch main
+			git add main
+			git commit -m "main-0"
+
+			git branch topic-a
+			git branch topic-b
+			git branch topic-c
+		`)
+
+		assert.NilError(t, os.Chdir("repo"))
+
+		assert.Equal(t, yascli.Run("config", "set", "--trunk-branch=main"), 0)
+		assert.Equal(t, yascli.Run("add", "--branch=topic-a", "--parent=main"), 0)
+		assert.Equal(t, yascli.Run("add", "--branch=topic-b", "--parent=main"), 0)
+		assert.Equal(t, yascli.Run("add", "--branch=topic-c", "--parent=main"), 0)
+
+		writePR("topic-a", `[{"id":"PR_1","state":"OPEN","reviewDecision":"APPROVED","statusCheckRollup":[
+			{"__typename":"CheckRun","name":"build","status":"COMPLETED","conclusion":"SUCCESS"},
+			{"__typename":"StatusContext","context":"lint","state":"SUCCESS"}
+		]}]`)
+		writePR("topic-b", `[{"id":"PR_2","state":"OPEN","reviewDecision":"CHANGES_REQUESTED","statusCheckRollup":[
+			{"__typename":"CheckRun","name":"build","status":"COMPLETED","conclusion":"FAILURE"},
+			{"__typename":"CheckRun","name":"test","status":"IN_PROGRESS","conclusion":""}
+		]}]`)
+		writePR("topic-c", `[{"id":"PR_3","state":"OPEN","reviewDecision":"REVIEW_REQUIRED","statusCheckRollup":[
+			{"__typename":"CheckRun","name":"build","status":"IN_PROGRESS","conclusion":""}
+		]}]`)
+		assert.Equal(t, yascli.Run("refresh", "--all"), 0)
+
+		stdout, _, err := testutil.CaptureOutput(func() {
+			assert.Equal(t, yascli.Run("list"), 0)
+		})
+		assert.NilError(t, err)
+		equalLines(t, stdout, `
+			main
+			├── topic-a ✓ 👍
+			├── topic-b ✗ ✋
+			└── topic-c ●
+		`)
+	})
+}
+
+func TestListAllIgnoresBranchPatterns(t *testing.T) {
+	testutil.WithTempWorkingDir(t, func() {
+		testutil.ExecOrFail(t, `
+			git init --initial-branch=main
+
+			# main
+			touch main
+			git add main
+			git commit -m "main-0"
+
+			git branch spike
+			git branch dependabot/npm/lodash
+			git branch renovate/go-deps
+			git branch backport-1
+		`)
+
+		assert.Equal(t, yascli.Run("config", "set",
+			"--trunk-branch=main",
+			"--ignore-branch-pattern=dependabot",
+			"--ignore-branch-pattern=renovate/*",
+			"--ignore-branch-pattern=backport-*",
+		), 0)
+
+		stdout, _, err := testutil.CaptureOutput(func() {
+			assert.Equal(t, yascli.Run("list", "--all"), 0)
+		})
+		assert.NilError(t, err)
+
+		equalLines(t, stdout, `
+			main
+
+			Untracked branches:
+			spike
+		`)
+	})
+}
+
+// The porcelain format is used by scripts, so changes to this test must be
+// backwards compatible.
+func TestListPorcelain(t *testing.T) {
+	testutil.WithTempWorkingDir(t, func() {
+		_, _, err := testutil.CaptureOutput(func() {
+			assert.Equal(t, yascli.Run("demo", "playground"), 0)
+		})
+		assert.NilError(t, err)
+
+		assert.NilError(t, os.Chdir("playground/repo"))
+
+		testutil.ExecOrFail(t, `
+			git branch spike main
+			git checkout -q demo/topic-b
+			echo more >> b.txt
+			git commit -q -am "More b"
+		`)
+
+		stdout, _, err := testutil.CaptureOutput(func() {
+			assert.Equal(t, yascli.Run("list", "--porcelain", "--all"), 0)
+		})
+		assert.NilError(t, err)
+		assert.Equal(t, stdout, ""+
+			"demo/topic-a\tmain\thttps://github.com/example/yas-demo/pull/1\tneeds-restack\n"+
+			"demo/topic-b\tdemo/topic-a\thttps://github.com/example/yas-demo/pull/2\tcurrent,needs-submit\n"+
+			"demo/topic-c\tdemo/topic-b\thttps://github.com/example/yas-demo/pull/3\tdraft,needs-restack\n"+
+			"spike\t-\t-\tuntracked\n")
+
+		stdout, _, err = testutil.CaptureOutput(func() {
+			assert.Equal(t, yascli.Run("list", "--porcelain", "--stack=demo/topic-a"), 0)
+		})
+		assert.NilError(t, err)
+		assert.Equal(t, stdout, ""+
+			"demo/topic-a\tmain\thttps://github.com/example/yas-demo/pull/1\tneeds-restack\n"+
+			"demo/topic-b\tdemo/topic-a\thttps://github.com/example/yas-demo/pull/2\tcurrent,needs-submit\n"+
+			"demo/topic-c\tdemo/topic-b\thttps://github.com/example/yas-demo/pull/3\tdraft,needs-restack\n")
+	})
+}
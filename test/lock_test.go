@@ -0,0 +1,65 @@
+package test
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dansimau/yas/pkg/testutil"
+	"github.com/dansimau/yas/pkg/yascli"
+	"gotest.tools/v3/assert"
+)
+
+func TestLockPreventsConcurrentOperations(t *testing.T) {
+	testutil.WithTempWorkingDir(t, func() {
+		testutil.ExecOrFail(t, `
+			git init --initial-branch=main
+			git commit --allow-empty -m "main-0"
+		`)
+
+		assert.Equal(t, yascli.Run("config", "set", "--trunk-branch=main"), 0)
+
+		// Lock held by a running process
+		lock := fmt.Sprintf(`{"pid":%d,"operation":"restack","acquired":%q}`, os.Getpid(), time.Now().Format(time.RFC3339))
+		assert.NilError(t, os.WriteFile(".git/yas.lock", []byte(lock), 0o644))
+
+		_, stderr, err := testutil.CaptureOutput(func() {
+			assert.Equal(t, yascli.Run("restack"), 1)
+		})
+		assert.NilError(t, err)
+		assert.Assert(t, strings.Contains(stderr, "another yas operation is running (restack"))
+
+		// Read-only commands are not blocked
+		assert.Equal(t, yascli.Run("list"), 0)
+		assert.Equal(t, yascli.Run("doctor"), 0)
+
+		// Fixing problems modifies the state, so it is blocked
+		_, stderr, err = testutil.CaptureOutput(func() {
+			assert.Equal(t, yascli.Run("doctor", "--fix"), 1)
+		})
+		assert.NilError(t, err)
+		assert.Assert(t, strings.Contains(stderr, "another yas operation is running (restack"))
+	})
+}
+
+func TestLockRemovesStaleLock(t *testing.T) {
+	testutil.WithTempWorkingDir(t, func() {
+		testutil.ExecOrFail(t, `
+			git init --initial-branch=main
+			git commit --allow-empty -m "main-0"
+		`)
+
+		assert.Equal(t, yascli.Run("config", "set", "--trunk-branch=main"), 0)
+
+		// Lock held by a process that is no longer running
+		lock := fmt.Sprintf(`{"pid":%d,"operation":"restack","acquired":%q}`, 999999999, time.Now().Format(time.RFC3339))
+		assert.NilError(t, os.WriteFile(".git/yas.lock", []byte(lock), 0o644))
+
+		assert.Equal(t, yascli.Run("restack"), 0)
+
+		_, err := os.Stat(".git/yas.lock")
+		assert.Assert(t, os.IsNotExist(err))
+	})
+}
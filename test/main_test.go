@@ -0,0 +1,21 @@
+package test
+
+import (
+	"os"
+	"testing"
+)
+
+func TestMain(m *testing.M) {
+	// Don't let the user's global config affect tests
+	configDir, err := os.MkdirTemp("", "yas-test-config-")
+	if err != nil {
+		panic(err)
+	}
+
+	os.Setenv("XDG_CONFIG_HOME", configDir)
+
+	code := m.Run()
+
+	os.RemoveAll(configDir)
+	os.Exit(code)
+}
@@ -0,0 +1,566 @@
+package test
+
+import (
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/dansimau/yas/pkg/testutil"
+	"github.com/dansimau/yas/pkg/yascli"
+	"gotest.tools/v3/assert"
+)
+
+func TestMergeLocal(t *testing.T) {
+	t.Setenv("GIT_EDITOR", "true")
+
+	testutil.WithTempWorkingDir(t, func() {
+		testutil.ExecOrFail(t, `
+			git init --initial-branch=main
+
+			# main
+			touch main
+			git add main
+			git commit -m "main-0"
+
+			# topic-a
+			git checkout -b topic-a
+			touch a
+			git add a
+			git commit -m "topic-a-0"
+
+			# topic-b
+			git checkout -b topic-b
+			touch b
+			git add b
+			git commit -m "topic-b-0"
+
+			git checkout topic-a
+		`)
+
+		assert.Equal(t, yascli.Run("config", "set", "--trunk-branch=main"), 0)
+		assert.Equal(t, yascli.Run("add", "--branch=topic-a", "--parent=main"), 0)
+		assert.Equal(t, yascli.Run("add", "--branch=topic-b", "--parent=topic-a"), 0)
+		assert.Equal(t, yascli.Run("merge", "--local"), 0)
+
+		equalLines(t, mustExecOutput("git", "log", "--pretty=%D : %s", "topic-b"), `
+			topic-b : topic-b-0
+			HEAD -> main : topic-a-0
+			: main-0
+		`)
+
+		equalLines(t, mustExecOutput("git", "branch", "--list", "topic-a"), "")
+	})
+}
+
+func TestMergeWaitForChecks(t *testing.T) {
+	t.Setenv("GIT_EDITOR", "true")
+
+	testutil.WithTempWorkingDir(t, func() {
+		wd, err := os.Getwd()
+		assert.NilError(t, err)
+
+		// Checks are pending on the first poll and pass on the second
+		withFakeGHScript(t, `
+			if [ -f `+path.Join(wd, "polled")+` ]; then
+				echo '{"statusCheckRollup":[{"name":"build","status":"COMPLETED","conclusion":"SUCCESS"},{"context":"ci/lint","state":"SUCCESS"}]}'
+			else
+				touch `+path.Join(wd, "polled")+`
+				echo '{"statusCheckRollup":[{"name":"build","status":"IN_PROGRESS","conclusion":""},{"context":"ci/lint","state":"SUCCESS"}]}'
+			fi
+		`)
+
+		testutil.ExecOrFail(t, `
+			git init --initial-branch=main
+
+			# main
+			touch main
+			git add main
+			git commit -m "main-0"
+
+			# topic-a
+			git checkout -b topic-a
+			touch a
+			git add a
+			git commit -m "topic-a-0"
+		`)
+
+		assert.Equal(t, yascli.Run("config", "set", "--trunk-branch=main"), 0)
+		assert.Equal(t, yascli.Run("add", "--branch=topic-a", "--parent=main"), 0)
+
+		stdout, _, err := testutil.CaptureOutput(func() {
+			assert.Equal(t, yascli.Run("merge", "--local", "--wait", "--poll-interval=10ms"), 0)
+		})
+		assert.NilError(t, err)
+		assert.Assert(t, strings.Contains(stdout, "Waiting for 1 of 2 check(s)"))
+		assert.Assert(t, strings.Contains(stdout, "All 2 check(s) passed"))
+
+		equalLines(t, mustExecOutput("git", "log", "--pretty=%D : %s", "main", "--"), `
+			HEAD -> main : topic-a-0
+			: main-0
+		`)
+	})
+}
+
+func TestMergeWaitForChecksToStart(t *testing.T) {
+	t.Setenv("GIT_EDITOR", "true")
+
+	testutil.WithTempWorkingDir(t, func() {
+		wd, err := os.Getwd()
+		assert.NilError(t, err)
+
+		// CI hasn't registered any checks on the first poll
+		withFakeGHScript(t, `
+			if [ -f `+path.Join(wd, "polled")+` ]; then
+				echo '{"statusCheckRollup":[{"name":"build","status":"COMPLETED","conclusion":"SUCCESS"}]}'
+			else
+				touch `+path.Join(wd, "polled")+`
+				echo '{"statusCheckRollup":[]}'
+			fi
+		`)
+
+		testutil.ExecOrFail(t, `
+			git init --initial-branch=main
+
+			# main
+			touch main
+			git add main
+			git commit -m "main-0"
+
+			# topic-a
+			git checkout -b topic-a
+			touch a
+			git add a
+			git commit -m "topic-a-0"
+		`)
+
+		assert.Equal(t, yascli.Run("config", "set", "--trunk-branch=main"), 0)
+		assert.Equal(t, yascli.Run("add", "--branch=topic-a", "--parent=main"), 0)
+
+		stdout, _, err := testutil.CaptureOutput(func() {
+			assert.Equal(t, yascli.Run("merge", "--local", "--wait", "--poll-interval=10ms"), 0)
+		})
+		assert.NilError(t, err)
+		assert.Assert(t, strings.Contains(stdout, "Waiting for checks to start"))
+		assert.Assert(t, strings.Contains(stdout, "All 1 check(s) passed"))
+	})
+}
+
+func TestMergeWaitTimesOutWithoutChecks(t *testing.T) {
+	t.Setenv("GIT_EDITOR", "true")
+
+	testutil.WithTempWorkingDir(t, func() {
+		withFakeGH(t, `{"statusCheckRollup":[]}`)
+
+		testutil.ExecOrFail(t, `
+			git init --initial-branch=main
+
+			# main
+			touch main
+			git add main
+			git commit -m "main-0"
+
+			# topic-a
+			git checkout -b topic-a
+			touch a
+			git add a
+			git commit -m "topic-a-0"
+		`)
+
+		assert.Equal(t, yascli.Run("config", "set", "--trunk-branch=main"), 0)
+		assert.Equal(t, yascli.Run("add", "--branch=topic-a", "--parent=main"), 0)
+
+		_, stderr, err := testutil.CaptureOutput(func() {
+			assert.Equal(t, yascli.Run("merge", "--local", "--wait", "--poll-interval=10ms", "--timeout=50ms"), 1)
+		})
+		assert.NilError(t, err)
+		assert.Assert(t, strings.Contains(stderr, "timed out after 50ms waiting for checks to start"))
+
+		// Nothing was merged
+		equalLines(t, mustExecOutput("git", "log", "--pretty=%s", "main", "--"), "main-0")
+	})
+}
+
+func TestMergeWaitFailedCheck(t *testing.T) {
+	t.Setenv("GIT_EDITOR", "true")
+
+	withFakeGH(t, `{"statusCheckRollup":[{"name":"build","status":"COMPLETED","conclusion":"FAILURE"}]}`)
+
+	testutil.WithTempWorkingDir(t, func() {
+		testutil.ExecOrFail(t, `
+			git init --initial-branch=main
+
+			# main
+			touch main
+			git add main
+			git commit -m "main-0"
+
+			# topic-a
+			git checkout -b topic-a
+			touch a
+			git add a
+			git commit -m "topic-a-0"
+		`)
+
+		assert.Equal(t, yascli.Run("config", "set", "--trunk-branch=main"), 0)
+		assert.Equal(t, yascli.Run("add", "--branch=topic-a", "--parent=main"), 0)
+		assert.Equal(t, yascli.Run("merge", "--local", "--wait"), 1)
+
+		equalLines(t, mustExecOutput("git", "log", "--pretty=%s", "main", "--"), "main-0")
+	})
+}
+
+func TestMergeSubjectTemplate(t *testing.T) {
+	t.Setenv("GIT_EDITOR", "true")
+
+	testutil.WithTempWorkingDir(t, func() {
+		testutil.ExecOrFail(t, `
+			git init --initial-branch=main
+
+			# main
+			touch main
+			git add main
+			git commit -m "main-0"
+
+			# PROJ-12-fix
+			git checkout -b dan/PROJ-12-fix
+			touch a
+			git add a
+			git commit -m "Fix the thing" -m "Details"
+		`)
+
+		assert.Equal(t, yascli.Run("config", "set", "--trunk-branch=main", "--merge-subject={{.Ticket}}: {{.Title}} ({{.StackSize}})"), 0)
+		assert.Equal(t, yascli.Run("add", "--branch=dan/PROJ-12-fix", "--parent=main"), 0)
+		assert.Equal(t, yascli.Run("merge", "--local"), 0)
+
+		equalLines(t, mustExecOutput("git", "log", "-1", "--pretty=%B", "main", "--"), `
+			PROJ-12: Fix the thing (1)
+
+			Details
+		`)
+	})
+}
+
+func TestMergeLocalRebaseStrategy(t *testing.T) {
+	t.Setenv("GIT_EDITOR", "true")
+
+	testutil.WithTempWorkingDir(t, func() {
+		testutil.ExecOrFail(t, `
+			git init --initial-branch=main
+
+			# main
+			touch main
+			git add main
+			git commit -m "main-0"
+
+			# topic-a
+			git checkout -b topic-a
+			touch a
+			git add a
+			git commit -m "topic-a-0"
+			echo 1 > a
+			git commit -am "topic-a-1"
+
+			# topic-b
+			git checkout -b topic-b
+			touch b
+			git add b
+			git commit -m "topic-b-0"
+
+			# update main
+			git checkout main
+			echo 1 > main
+			git commit -am "main-1"
+
+			git checkout topic-a
+		`)
+
+		assert.Equal(t, yascli.Run("config", "set", "--trunk-branch=main"), 0)
+		assert.Equal(t, yascli.Run("add", "--branch=topic-a", "--parent=main"), 0)
+		assert.Equal(t, yascli.Run("add", "--branch=topic-b", "--parent=topic-a"), 0)
+		assert.Equal(t, yascli.Run("merge", "--local", "--strategy=rebase"), 0)
+
+		// The commits of topic-a are rebased onto main, and topic-b is
+		// rebased onto the rewritten commits
+		equalLines(t, mustExecOutput("git", "log", "--pretty=%D : %s", "topic-b"), `
+			topic-b : topic-b-0
+			HEAD -> main : topic-a-1
+			: topic-a-0
+			: main-1
+			: main-0
+		`)
+
+		equalLines(t, mustExecOutput("git", "branch", "--list", "topic-a"), "")
+	})
+}
+
+func TestMergeLocalMergeStrategy(t *testing.T) {
+	t.Setenv("GIT_EDITOR", "true")
+
+	testutil.WithTempWorkingDir(t, func() {
+		testutil.ExecOrFail(t, `
+			git init --initial-branch=main
+
+			# main
+			touch main
+			git add main
+			git commit -m "main-0"
+
+			# topic-a
+			git checkout -b topic-a
+			touch a
+			git add a
+			git commit -m "topic-a-0"
+		`)
+
+		assert.Equal(t, yascli.Run("config", "set", "--trunk-branch=main", "--merge-strategy=merge"), 0)
+		assert.Equal(t, yascli.Run("add", "--branch=topic-a", "--parent=main"), 0)
+		assert.Equal(t, yascli.Run("merge", "--local"), 0)
+
+		equalLines(t, mustExecOutput("git", "log", "--first-parent", "--pretty=%D : %s", "main", "--"), `
+			HEAD -> main : Merge branch 'topic-a'
+			: main-0
+		`)
+
+		equalLines(t, mustExecOutput("git", "log", "-1", "--pretty=%s", "main^2", "--"), "topic-a-0")
+	})
+}
+
+func TestMergeAdmin(t *testing.T) {
+	testutil.WithTempWorkingDir(t, func() {
+		wd, err := os.Getwd()
+		assert.NilError(t, err)
+		argsFile := path.Join(wd, "gh-args")
+
+		// Records the args of gh pr merge, and responds with no PRs otherwise
+		withFakeGHScript(t, `
+			if [ "$2" = "merge" ]; then
+				echo "$@" > `+argsFile+`
+			else
+				echo '[]'
+			fi
+		`)
+
+		testutil.ExecOrFail(t, `
+			git init --bare --initial-branch=main origin.git
+			git clone -q origin.git repo
+			cd repo
+
+			touch main
+			git add main
+			git commit -m "main-0"
+			git push -q origin main
+
+			git checkout -b topic-a
+			touch a
+			git add a
+			git commit -m "topic-a-0"
+		`)
+
+		assert.NilError(t, os.Chdir("repo"))
+
+		assert.Equal(t, yascli.Run("config", "set", "--trunk-branch=main"), 0)
+		assert.Equal(t, yascli.Run("add", "--branch=topic-a", "--parent=main"), 0)
+
+		assert.Equal(t, yascli.Run("merge", "--admin", "--local"), 1)
+
+		// Declining the confirmation doesn't merge
+		withStdin(t, "n\n", func() {
+			assert.Equal(t, yascli.Run("merge", "--admin"), 1)
+		})
+		_, err = os.Stat(argsFile)
+		assert.Assert(t, os.IsNotExist(err))
+
+		withStdin(t, "y\n", func() {
+			stdout, _, err := testutil.CaptureOutput(func() {
+				assert.Equal(t, yascli.Run("merge", "--admin"), 0)
+			})
+			assert.NilError(t, err)
+			assert.Assert(t, strings.Contains(stdout, "⚠️  Admin merge of 'topic-a': bypassing branch protection rules"))
+		})
+
+		args, err := os.ReadFile(argsFile)
+		assert.NilError(t, err)
+		assert.Equal(t, strings.TrimSpace(string(args)), "pr merge topic-a --squash --admin --subject topic-a-0 --body")
+	})
+}
+
+func TestMergeUsesPullRequestNumber(t *testing.T) {
+	testutil.WithTempWorkingDir(t, func() {
+		wd, err := os.Getwd()
+		assert.NilError(t, err)
+		argsFile := path.Join(wd, "gh-args")
+
+		// The PR URL has a GitHub Enterprise path prefix, so the number can't
+		// be taken from the end of the URL path
+		withFakeGHScript(t, `
+			if [ "$2" = "merge" ]; then
+				echo "$@" > `+argsFile+`
+			else
+				echo '[{"id":"PR_7","number":7,"state":"OPEN","url":"https://github.example.com/test/test/pull/7/","baseRefName":"main"}]'
+			fi
+		`)
+
+		testutil.ExecOrFail(t, `
+			git init --bare --initial-branch=main origin.git
+			git clone -q origin.git repo
+			cd repo
+
+			touch main
+			git add main
+			git commit -m "main-0"
+			git push -q origin main
+
+			git checkout -b topic-a
+			touch a
+			git add a
+			git commit -m "topic-a-0"
+		`)
+
+		assert.NilError(t, os.Chdir("repo"))
+
+		assert.Equal(t, yascli.Run("config", "set", "--trunk-branch=main", "--merge-subject={{.Title}} (#{{.Number}})"), 0)
+		assert.Equal(t, yascli.Run("add", "--branch=topic-a", "--parent=main"), 0)
+		assert.Equal(t, yascli.Run("refresh"), 0)
+
+		assert.Equal(t, yascli.Run("merge"), 0)
+
+		args, err := os.ReadFile(argsFile)
+		assert.NilError(t, err)
+		assert.Equal(t, strings.TrimSpace(string(args)), "pr merge 7 --squash --subject topic-a-0 (#7) --body")
+	})
+}
+
+func TestMergeLocalSavesParentIfRetargetFails(t *testing.T) {
+	testutil.WithTempWorkingDir(t, func() {
+		testutil.ExecOrFail(t, `
+			git init --initial-branch=main
+
+			# main
+			touch main
+			git add main
+			git commit -m "main-0"
+
+			# topic-a
+			git checkout -b topic-a
+			touch a
+			git add a
+			git commit -m "topic-a-0"
+
+			# topic-b
+			git checkout -b topic-b
+			touch b
+			git add b
+			git commit -m "topic-b-0"
+
+			git checkout topic-a
+		`)
+
+		// topic-b has an open PR, which can't be retargeted
+		withFakeGHScript(t, `
+			if [ "$2" = "edit" ]; then
+				exit 1
+			elif [ "$4" = "topic-b" ]; then
+				echo '[{"id":"PR_2","state":"OPEN","url":"https://github.com/test/test/pull/2","baseRefName":"topic-a"}]'
+			else
+				echo '[]'
+			fi
+		`)
+
+		assert.Equal(t, yascli.Run("config", "set", "--trunk-branch=main"), 0)
+		assert.Equal(t, yascli.Run("add", "--branch=topic-a", "--parent=main"), 0)
+		assert.Equal(t, yascli.Run("add", "--branch=topic-b", "--parent=topic-a"), 0)
+		assert.Equal(t, yascli.Run("refresh", "--all"), 0)
+
+		assert.Equal(t, yascli.Run("merge", "--local"), 1)
+
+		// topic-b was rebased onto main, so its parent is saved as main
+		equalLines(t, mustExecOutput("git", "log", "--pretty=%s", "topic-b"), `
+			topic-b-0
+			topic-a-0
+			main-0
+		`)
+
+		stdout, _, err := testutil.CaptureOutput(func() {
+			assert.Equal(t, yascli.Run("list"), 0)
+		})
+		assert.NilError(t, err)
+		equalLines(t, stdout, `
+			main
+			├── topic-a
+			└── topic-b
+		`)
+	})
+}
+
+func TestMergeLocalFromLinkedWorktree(t *testing.T) {
+	t.Setenv("GIT_EDITOR", "true")
+
+	testutil.WithTempWorkingDir(t, func() {
+		testutil.ExecOrFail(t, `
+			git init -q --initial-branch=main repo
+			cd repo
+
+			# main
+			touch main
+			git add main
+			git commit -m "main-0"
+
+			# topic-a
+			git checkout -b topic-a
+			touch a
+			git add a
+			git commit -m "topic-a-0"
+
+			# topic-b
+			git checkout -b topic-b
+			touch b
+			git add b
+			git commit -m "topic-b-0"
+
+			git checkout main
+		`)
+
+		wd, err := os.Getwd()
+		assert.NilError(t, err)
+		repoDir := path.Join(wd, "repo")
+
+		assert.NilError(t, os.Chdir(repoDir))
+
+		assert.Equal(t, yascli.Run("config", "set", "--trunk-branch=main", "--worktree-dir=../wt"), 0)
+		assert.Equal(t, yascli.Run("add", "--branch=topic-a", "--parent=main"), 0)
+		assert.Equal(t, yascli.Run("add", "--branch=topic-b", "--parent=topic-a"), 0)
+		assert.Equal(t, yascli.Run("worktree", "add", "topic-a"), 0)
+
+		// main is checked out in the main worktree, so the merge is done
+		// there
+		assert.NilError(t, os.Chdir(path.Join(wd, "wt", "topic-a")))
+
+		stdout, _, err := testutil.CaptureOutput(func() {
+			assert.Equal(t, yascli.Run("merge", "--local"), 0)
+		})
+		assert.NilError(t, err)
+		assert.Assert(t, strings.Contains(stdout, "Removed worktree "+path.Join(wd, "wt", "topic-a")), stdout)
+
+		assert.NilError(t, os.Chdir(repoDir))
+
+		_, err = os.Stat(path.Join(wd, "wt", "topic-a"))
+		assert.Assert(t, os.IsNotExist(err))
+
+		equalLines(t, mustExecOutput("git", "branch", "--list", "topic-a"), "")
+		equalLines(t, mustExecOutput("git", "log", "--pretty=%D : %s", "topic-b"), `
+			topic-b : topic-b-0
+			HEAD -> main : topic-a-0
+			: main-0
+		`)
+
+		stdout, _, err = testutil.CaptureOutput(func() {
+			assert.Equal(t, yascli.Run("list"), 0)
+		})
+		assert.NilError(t, err)
+		equalLines(t, stdout, `
+			main
+			└── topic-b
+		`)
+	})
+}
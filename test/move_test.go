@@ -0,0 +1,163 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/dansimau/yas/pkg/testutil"
+	"github.com/dansimau/yas/pkg/yascli"
+	"gotest.tools/v3/assert"
+)
+
+func setupMoveRepo(t *testing.T) {
+	testutil.ExecOrFail(t, `
+		git init --initial-branch=main
+
+		# main
+		touch main
+		git add main
+		git commit -m "main-0"
+
+		# topic-x
+		git checkout -b topic-x
+		touch x
+		git add x
+		git commit -m "topic-x-0"
+
+		# topic-a
+		git checkout main
+		git checkout -b topic-a
+		touch a
+		git add a
+		git commit -m "topic-a-0"
+
+		# topic-b
+		git checkout -b topic-b
+		touch b
+		git add b
+		git commit -m "topic-b-0"
+
+		# topic-c
+		git checkout -b topic-c
+		touch c
+		git add c
+		git commit -m "topic-c-0"
+
+		git checkout main
+	`)
+
+	assert.Equal(t, yascli.Run("config", "set", "--trunk-branch=main"), 0)
+	assert.Equal(t, yascli.Run("add", "--branch=topic-x", "--parent=main"), 0)
+	assert.Equal(t, yascli.Run("add", "--branch=topic-a", "--parent=main"), 0)
+	assert.Equal(t, yascli.Run("add", "--branch=topic-b", "--parent=topic-a"), 0)
+	assert.Equal(t, yascli.Run("add", "--branch=topic-c", "--parent=topic-b"), 0)
+}
+
+func TestMoveWithDescendants(t *testing.T) {
+	testutil.WithTempWorkingDir(t, func() {
+		setupMoveRepo(t)
+
+		assert.Equal(t, yascli.Run("move", "topic-b", "--onto=topic-x"), 0)
+
+		equalLines(t, mustExecOutput("git", "branch", "--show-current"), "main")
+		equalLines(t, mustExecOutput("git", "log", "--pretty=%D : %s", "topic-c"), `
+			topic-c : topic-c-0
+			topic-b : topic-b-0
+			topic-x : topic-x-0
+			HEAD -> main : main-0
+		`)
+	})
+}
+
+func TestMoveWithoutDescendants(t *testing.T) {
+	testutil.WithTempWorkingDir(t, func() {
+		setupMoveRepo(t)
+
+		assert.Equal(t, yascli.Run("move", "topic-b", "--onto=topic-x", "--without-descendants"), 0)
+
+		equalLines(t, mustExecOutput("git", "log", "--pretty=%D : %s", "topic-b"), `
+			topic-b : topic-b-0
+			topic-x : topic-x-0
+			HEAD -> main : main-0
+		`)
+
+		equalLines(t, mustExecOutput("git", "log", "--pretty=%D : %s", "topic-c"), `
+			topic-c : topic-c-0
+			topic-a : topic-a-0
+			HEAD -> main : main-0
+		`)
+
+		stdout, _, err := testutil.CaptureOutput(func() {
+			assert.Equal(t, yascli.Run("list"), 0)
+		})
+		assert.NilError(t, err)
+
+		equalLines(t, stdout, `
+			main
+			├── topic-a
+			│   └── topic-c
+			└── topic-x
+			    └── topic-b
+		`)
+	})
+}
+
+func TestMoveOntoDescendant(t *testing.T) {
+	testutil.WithTempWorkingDir(t, func() {
+		setupMoveRepo(t)
+
+		assert.Equal(t, yascli.Run("move", "topic-a", "--onto=topic-c"), 1)
+	})
+}
+
+func TestMoveAfter(t *testing.T) {
+	testutil.WithTempWorkingDir(t, func() {
+		setupMoveRepo(t)
+
+		assert.Equal(t, yascli.Run("move", "topic-a", "--after=topic-b"), 0)
+
+		equalLines(t, mustExecOutput("git", "log", "--pretty=%D : %s", "topic-c"), `
+			topic-c : topic-c-0
+			topic-a : topic-a-0
+			topic-b : topic-b-0
+			HEAD -> main : main-0
+		`)
+
+		stdout, _, err := testutil.CaptureOutput(func() {
+			assert.Equal(t, yascli.Run("list"), 0)
+		})
+		assert.NilError(t, err)
+
+		equalLines(t, stdout, `
+			main
+			├── topic-b
+			│   └── topic-a
+			│       └── topic-c
+			└── topic-x
+		`)
+	})
+}
+
+func TestMoveBefore(t *testing.T) {
+	testutil.WithTempWorkingDir(t, func() {
+		setupMoveRepo(t)
+
+		assert.Equal(t, yascli.Run("move", "topic-x", "--before=topic-b"), 0)
+
+		equalLines(t, mustExecOutput("git", "log", "--pretty=%D : %s", "topic-c"), `
+			topic-c : topic-c-0
+			topic-b : topic-b-0
+			topic-x : topic-x-0
+			topic-a : topic-a-0
+			HEAD -> main : main-0
+		`)
+	})
+}
+
+func TestMoveBeforeTrunk(t *testing.T) {
+	testutil.WithTempWorkingDir(t, func() {
+		setupMoveRepo(t)
+
+		assert.Equal(t, yascli.Run("move", "topic-x", "--before=main"), 1)
+		assert.Equal(t, yascli.Run("move", "topic-x", "--onto=main", "--after=topic-a"), 1)
+	})
+}
@@ -0,0 +1,101 @@
+package test
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+	"unsafe"
+
+	"github.com/dansimau/yas/pkg/testutil"
+	"github.com/dansimau/yas/pkg/yascli"
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/assert/cmp"
+)
+
+// withTerminalStdout runs fn with stdout connected to a pseudo-terminal, so
+// that colors are enabled, and returns what was written to it.
+func withTerminalStdout(t *testing.T, fn func()) string {
+	master, err := os.OpenFile("/dev/ptmx", os.O_RDWR, 0)
+	assert.NilError(t, err)
+	defer master.Close()
+
+	unlock := int32(0)
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, master.Fd(), syscall.TIOCSPTLCK, uintptr(unsafe.Pointer(&unlock))); errno != 0 {
+		t.Fatal(errno)
+	}
+
+	n := uint32(0)
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, master.Fd(), syscall.TIOCGPTN, uintptr(unsafe.Pointer(&n))); errno != 0 {
+		t.Fatal(errno)
+	}
+
+	terminal, err := os.OpenFile(fmt.Sprintf("/dev/pts/%d", n), os.O_RDWR|syscall.O_NOCTTY, 0)
+	assert.NilError(t, err)
+
+	output := make(chan string)
+	go func() {
+		// Reading fails with EIO once the terminal is closed
+		b, _ := io.ReadAll(master)
+		output <- string(b)
+	}()
+
+	stdout := os.Stdout
+	os.Stdout = terminal
+	defer func() { os.Stdout = stdout }()
+
+	fn()
+
+	assert.NilError(t, terminal.Close())
+
+	return <-output
+}
+
+func TestListNoColor(t *testing.T) {
+	commitDate := time.Now().Add(-40 * 24 * time.Hour).Format(time.RFC3339)
+
+	testutil.WithTempWorkingDir(t, func() {
+		testutil.ExecOrFail(t, `
+			git init --initial-branch=main
+
+			# main
+			touch main
+			git add main
+			git commit -m "main-0"
+
+			# topic-a
+			git checkout -b topic-a
+			echo a > a
+			git add a
+			GIT_COMMITTER_DATE=`+commitDate+` git commit -m "topic-a-0"
+		`)
+
+		assert.Equal(t, yascli.Run("config", "set", "--trunk-branch=main"), 0)
+		assert.Equal(t, yascli.Run("add", "--branch=topic-a", "--parent=main"), 0)
+
+		t.Setenv("NO_COLOR", "")
+
+		// The stale activity is colored on a terminal
+		stdout := withTerminalStdout(t, func() {
+			assert.Equal(t, yascli.Run("list", "--verbose"), 0)
+		})
+		assert.Assert(t, cmp.Contains(stdout, "\033["))
+
+		stdout = withTerminalStdout(t, func() {
+			assert.Equal(t, yascli.Run("--no-color", "list", "--verbose"), 0)
+		})
+		assert.Assert(t, cmp.Contains(stdout, "last commit 40d ago"))
+		assert.Assert(t, !strings.Contains(stdout, "\033["), stdout)
+
+		t.Setenv("NO_COLOR", "1")
+
+		stdout = withTerminalStdout(t, func() {
+			assert.Equal(t, yascli.Run("list", "--verbose"), 0)
+		})
+		assert.Assert(t, cmp.Contains(stdout, "last commit 40d ago"))
+		assert.Assert(t, !strings.Contains(stdout, "\033["), stdout)
+	})
+}
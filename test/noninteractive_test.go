@@ -0,0 +1,84 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/dansimau/yas/pkg/testutil"
+	"github.com/dansimau/yas/pkg/yascli"
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/assert/cmp"
+)
+
+func TestNonInteractive(t *testing.T) {
+	t.Setenv("GIT_EDITOR", "false")
+
+	testutil.WithTempWorkingDir(t, func() {
+		testutil.ExecOrFail(t, `
+			git init --initial-branch=main
+
+			# main
+			touch main
+			git add main
+			git commit -m "main-0"
+
+			# topic-a
+			git checkout -b topic-a
+			touch a
+			git add a
+			git commit -m "topic-a-0"
+
+			# topic-b
+			git checkout -b topic-b main
+			touch b
+			git add b
+			git commit -m "topic-b-0"
+		`)
+
+		// Prompts without a default fail
+		_, stderr, err := testutil.CaptureOutput(func() {
+			assert.Equal(t, yascli.Run("--non-interactive", "init"), 1)
+		})
+		assert.NilError(t, err)
+		assert.Assert(t, cmp.Contains(stderr, "running in non-interactive mode"))
+
+		assert.Equal(t, yascli.Run("config", "set", "--trunk-branch=main"), 0)
+		assert.Equal(t, yascli.Run("add", "--branch=topic-a", "--parent=main"), 0)
+		assert.Equal(t, yascli.Run("add", "--branch=topic-b", "--parent=main"), 0)
+
+		// Interactive pickers fail
+		_, stderr, err = testutil.CaptureOutput(func() {
+			assert.Equal(t, yascli.Run("--yes", "delete", "--interactive"), 1)
+		})
+		assert.NilError(t, err)
+		assert.Assert(t, cmp.Contains(stderr, "running in non-interactive mode"))
+
+		// Confirmations use their default
+		_, stderr, err = testutil.CaptureOutput(func() {
+			assert.Equal(t, yascli.Run("--non-interactive", "delete", "topic-b"), 1)
+		})
+		assert.NilError(t, err)
+		assert.Assert(t, cmp.Contains(stderr, "no (non-interactive, hint: use --yes to answer yes)"))
+
+		// ...unless answered yes explicitly, before or after the command name
+		_, stderr, err = testutil.CaptureOutput(func() {
+			assert.Equal(t, yascli.Run("--yes", "delete", "topic-b"), 0)
+		})
+		assert.NilError(t, err)
+		assert.Assert(t, cmp.Contains(stderr, "Delete 1 branch(es)? [y/N] yes (non-interactive)"))
+
+		assert.Equal(t, yascli.Run("add", "--branch=topic-c", "--parent=main"), 0)
+		assert.Equal(t, yascli.Run("delete", "--yes", "topic-c"), 0)
+		equalLines(t, mustExecOutput("git", "branch", "--format=%(refname:short)"), `
+			main
+			topic-a
+		`)
+
+		// The merge message isn't edited (GIT_EDITOR would fail)
+		assert.Equal(t, yascli.Run("switch", "topic-a"), 0)
+		assert.Equal(t, yascli.Run("--non-interactive", "merge", "--local"), 0)
+		equalLines(t, mustExecOutput("git", "log", "--pretty=%s", "main", "--"), `
+			topic-a-0
+			main-0
+		`)
+	})
+}
@@ -0,0 +1,85 @@
+package test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/dansimau/yas/pkg/testutil"
+	"github.com/dansimau/yas/pkg/yas"
+	"github.com/dansimau/yas/pkg/yascli"
+	"gotest.tools/v3/assert"
+)
+
+func TestOutputJSON(t *testing.T) {
+	testutil.WithTempWorkingDir(t, func() {
+		testutil.ExecOrFail(t, `
+			git init --initial-branch=main
+
+			touch main
+			git add main
+			git commit -m "main-0"
+
+			git checkout -b topic-a
+			touch a
+			git add a
+			git commit -m "topic-a-0"
+
+			git checkout -b topic-b
+			touch b
+			git add b
+			git commit -m "topic-b-0"
+
+			git checkout main
+			echo 1 > main
+			git commit -a -m "main-1"
+
+			git checkout -b spike
+			touch spike
+			git add spike
+			git commit -m "spike-0"
+
+			git checkout topic-b
+		`)
+
+		assert.Equal(t, yascli.Run("config", "set", "--trunk-branch=main"), 0)
+		assert.Equal(t, yascli.Run("add", "--branch=topic-a", "--parent=main"), 0)
+		assert.Equal(t, yascli.Run("add", "--branch=topic-b", "--parent=topic-a"), 0)
+		assert.Equal(t, yascli.Run("add", "--branch=spike", "--parent=main"), 0)
+
+		// Only the result is printed to stdout
+		stdout, _, err := testutil.CaptureOutput(func() {
+			assert.Equal(t, yascli.Run("restack", "--output=json"), 0)
+		})
+		assert.NilError(t, err)
+
+		result := yas.Result{}
+		assert.NilError(t, json.Unmarshal([]byte(stdout), &result))
+		assert.Equal(t, result.Command, "restack")
+		assert.Assert(t, result.Success)
+		assert.DeepEqual(t, result.Steps, []yas.Step{
+			{Branch: "topic-a", Result: "rebased"},
+			{Branch: "topic-b", Result: "rebased"},
+		})
+		assert.Equal(t, len(result.Events), 1)
+		assert.Equal(t, result.Events[0].Type, yas.EventRestacked)
+
+		stdout, _, err = testutil.CaptureOutput(func() {
+			assert.Equal(t, yascli.Run("delete", "--yes", "--output=json", "spike", "topic-a"), 1)
+		})
+		assert.NilError(t, err)
+
+		result = yas.Result{}
+		assert.NilError(t, json.Unmarshal([]byte(stdout), &result))
+		assert.Assert(t, !result.Success)
+		assert.Equal(t, result.Error, "failed to delete 1 of 2 branch(es)")
+		assert.Equal(t, len(result.Steps), 2)
+		assert.DeepEqual(t, result.Steps[0], yas.Step{Branch: "spike", Result: "deleted"})
+		assert.Equal(t, result.Steps[1].Branch, "topic-a")
+		assert.Equal(t, result.Steps[1].Result, "failed")
+		assert.Equal(t, len(result.Events), 1)
+		assert.Equal(t, result.Events[0].Type, yas.EventBranchDeleted)
+
+		// Commands that don't report results
+		assert.Equal(t, yascli.Run("list", "--output=json"), 1)
+	})
+}
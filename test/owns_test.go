@@ -0,0 +1,80 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/dansimau/yas/pkg/testutil"
+	"github.com/dansimau/yas/pkg/yascli"
+	"gotest.tools/v3/assert"
+)
+
+func TestOwns(t *testing.T) {
+	testutil.WithTempWorkingDir(t, func() {
+		testutil.ExecOrFail(t, `
+			git init --initial-branch=main
+
+			echo 1 > shared
+			touch main
+			git add shared main
+			git commit -m "main-0"
+
+			git checkout -b topic-a
+			echo 2 >> shared
+			git commit -a -m "topic-a-0"
+
+			git checkout -b topic-b
+			touch b
+			git add b
+			git commit -m "topic-b-0"
+
+			git checkout -b topic-c
+			echo 3 >> shared
+			git commit -a -m "topic-c-0"
+
+			git checkout -b other main
+			echo 4 > shared
+			git commit -a -m "other-0"
+
+			git checkout main
+			echo 1 > main
+			git commit -a -m "main-1"
+		`)
+
+		assert.Equal(t, yascli.Run("config", "set", "--trunk-branch=main"), 0)
+		assert.Equal(t, yascli.Run("add", "--branch=topic-a", "--parent=main"), 0)
+		assert.Equal(t, yascli.Run("add", "--branch=topic-b", "--parent=topic-a"), 0)
+		assert.Equal(t, yascli.Run("add", "--branch=topic-c", "--parent=topic-b"), 0)
+		assert.Equal(t, yascli.Run("add", "--branch=other", "--parent=main"), 0)
+
+		// Changes on trunk since the branch points aren't attributed to
+		// branches
+		stdout, _, err := testutil.CaptureOutput(func() {
+			assert.Equal(t, yascli.Run("owns", "main"), 0)
+		})
+		assert.NilError(t, err)
+		equalLines(t, stdout, "No tracked branches modify main")
+
+		stdout, _, err = testutil.CaptureOutput(func() {
+			assert.Equal(t, yascli.Run("owns", "shared"), 0)
+		})
+		assert.NilError(t, err)
+		equalLines(t, stdout, `
+			BRANCH  | CHANGES
+			----------+-----------------
+			other   | +1 -1, 1 files
+			topic-a | +1 -0, 1 files
+			topic-c | +1 -0, 1 files
+		`)
+
+		stdout, _, err = testutil.CaptureOutput(func() {
+			assert.Equal(t, yascli.Run("owns", "--stack=topic-b", "shared"), 0)
+		})
+		assert.NilError(t, err)
+		equalLines(t, stdout, `
+			BRANCH  | CHANGES
+			----------+-----------------
+			topic-a | +1 -0, 1 files
+			topic-c | +1 -0, 1 files
+		`)
+	})
+}
@@ -0,0 +1,818 @@
+package test
+
+import (
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/dansimau/yas/pkg/ghstub"
+	"github.com/dansimau/yas/pkg/testutil"
+	"github.com/dansimau/yas/pkg/yascli"
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/assert/cmp"
+)
+
+func TestPRCheckout(t *testing.T) {
+	withFakeGHScript(t, `
+		case "$2" in
+		view)
+			echo '{"id":"PR_2","state":"OPEN","url":"https://github.com/test/test/pull/2","author":{"login":"alice"},"headRefName":"topic-b","baseRefName":"topic-a"}'
+			;;
+		checkout)
+			git checkout -q -b topic-b origin/topic-b
+			;;
+		esac
+	`)
+
+	testutil.WithTempWorkingDir(t, func() {
+		testutil.ExecOrFail(t, `
+			git init --bare --initial-branch=main origin.git
+			git clone -q origin.git repo
+			cd repo
+
+			touch main
+			git add main
+			git commit -m "main-0"
+
+			git checkout -b topic-a
+			touch a
+			git add a
+			git commit -m "topic-a-0"
+
+			git checkout -b topic-b
+			touch b
+			git add b
+			git commit -m "topic-b-0"
+
+			git push -q origin main topic-a topic-b
+			git checkout main
+			git branch -D topic-b
+		`)
+
+		assert.NilError(t, os.Chdir("repo"))
+
+		assert.Equal(t, yascli.Run("config", "set", "--trunk-branch=main"), 0)
+		assert.Equal(t, yascli.Run("add", "--branch=topic-a", "--parent=main"), 0)
+		assert.Equal(t, yascli.Run("pr", "checkout", "2"), 0)
+
+		equalLines(t, mustExecOutput("git", "branch", "--show-current"), "topic-b")
+
+		stdout, _, err := testutil.CaptureOutput(func() {
+			assert.Equal(t, yascli.Run("list"), 0)
+		})
+		assert.NilError(t, err)
+
+		equalLines(t, stdout, `
+			main
+			└── topic-a
+			    └── topic-b
+		`)
+	})
+}
+
+func TestPRReady(t *testing.T) {
+	testutil.WithTempWorkingDir(t, func() {
+		wd, err := os.Getwd()
+		assert.NilError(t, err)
+		ghLog := path.Join(wd, "gh.log")
+
+		withFakeGHScript(t, `
+			echo "$@" >> `+ghLog+`
+			case "$2" in
+			list)
+				echo '[{"id":"PR_1","state":"OPEN","url":"https://github.com/test/test/pull/1","isDraft":true}]'
+				;;
+			esac
+		`)
+
+		testutil.ExecOrFail(t, `
+			git init --initial-branch=main
+
+			touch main
+			git add main
+			git commit -m "main-0"
+
+			git checkout -b topic-a
+			touch a
+			git add a
+			git commit -m "topic-a-0"
+		`)
+
+		assert.Equal(t, yascli.Run("config", "set", "--trunk-branch=main"), 0)
+		assert.Equal(t, yascli.Run("add", "--branch=topic-a", "--parent=main"), 0)
+		assert.Equal(t, yascli.Run("pr", "ready"), 0)
+
+		b, err := os.ReadFile(ghLog)
+		assert.NilError(t, err)
+		assert.Assert(t, strings.Contains(string(b), "pr ready topic-a\n"))
+
+		stdout, _, err := testutil.CaptureOutput(func() {
+			assert.Equal(t, yascli.Run("list"), 0)
+		})
+		assert.NilError(t, err)
+
+		equalLines(t, stdout, `
+			main
+			└── topic-a
+		`)
+	})
+}
+
+func TestSubmitDefaultDraft(t *testing.T) {
+	testutil.WithTempWorkingDir(t, func() {
+		wd, err := os.Getwd()
+		assert.NilError(t, err)
+		ghLog := path.Join(wd, "gh.log")
+
+		withFakeGHScript(t, `
+			echo "$@" >> `+ghLog+`
+			case "$2" in
+			list)
+				echo '[]'
+				;;
+			esac
+		`)
+
+		testutil.ExecOrFail(t, `
+			git init --bare --initial-branch=main origin.git
+			git clone -q origin.git repo
+			cd repo
+
+			touch main
+			git add main
+			git commit -m "main-0"
+			git push -q origin main
+
+			git checkout -b topic-a
+			touch a
+			git add a
+			git commit -m "topic-a-0"
+		`)
+
+		assert.NilError(t, os.Chdir("repo"))
+
+		assert.Equal(t, yascli.Run("config", "set", "--trunk-branch=main", "--default-draft=false"), 0)
+		assert.Equal(t, yascli.Run("add", "--branch=topic-a", "--parent=main"), 0)
+		assert.Equal(t, yascli.Run("submit"), 0)
+
+		b, err := os.ReadFile(ghLog)
+		assert.NilError(t, err)
+		assert.Assert(t, strings.Contains(string(b), "pr create --head topic-a --base main"))
+		assert.Assert(t, !strings.Contains(string(b), "--draft"))
+	})
+}
+
+func TestBranchStackFromPR(t *testing.T) {
+	withFakeGHScript(t, `
+		case "$2" in
+		view)
+			echo '{"id":"PR_5","state":"OPEN","url":"https://github.com/test/test/pull/5","author":{"login":"alice"},"headRefName":"alice/feature","baseRefName":"main"}'
+			;;
+		checkout)
+			git checkout -q -b alice/feature --track origin/alice/feature
+			;;
+		list)
+			echo '[]'
+			;;
+		esac
+	`)
+
+	testutil.WithTempWorkingDir(t, func() {
+		testutil.ExecOrFail(t, `
+			git init --bare --initial-branch=main origin.git
+			git clone -q origin.git repo
+			cd repo
+
+			touch main
+			git add main
+			git commit -m "main-0"
+
+			git checkout -b alice/feature
+			touch alice
+			git add alice
+			git commit -m "alice-0"
+
+			git push -q origin main alice/feature
+			git checkout main
+			git branch -D alice/feature
+		`)
+
+		assert.NilError(t, os.Chdir("repo"))
+
+		assert.Equal(t, yascli.Run("config", "set", "--trunk-branch=main"), 0)
+		assert.Equal(t, yascli.Run("branch", "--stack-from-pr=5", "my-work"), 0)
+
+		testutil.ExecOrFail(t, `
+			touch mine
+			git add mine
+			git commit -m "mine-0"
+
+			# alice rewrites her PR
+			git checkout -q -b rewrite main
+			touch alice
+			git add alice
+			git commit -m "alice-0-rewritten"
+			git push -q -f origin rewrite:alice/feature
+			git checkout -q my-work
+			git branch -D rewrite
+		`)
+
+		stdout, _, err := testutil.CaptureOutput(func() {
+			assert.Equal(t, yascli.Run("list"), 0)
+		})
+		assert.NilError(t, err)
+		equalLines(t, stdout, `
+			main
+			└── alice/feature [external]
+			    └── my-work
+		`)
+
+		assert.Equal(t, yascli.Run("restack"), 0)
+
+		equalLines(t, mustExecOutput("git", "log", "--pretty=%D : %s", "my-work"), `
+			HEAD -> my-work : mine-0
+			origin/alice/feature, alice/feature : alice-0-rewritten
+			origin/main, main : main-0
+		`)
+
+		// External branches are not submitted
+		testutil.ExecOrFail(t, "git checkout -q alice/feature")
+		_, stderr, err := testutil.CaptureOutput(func() {
+			assert.Equal(t, yascli.Run("submit"), 1)
+		})
+		assert.NilError(t, err)
+		assert.Assert(t, strings.Contains(stderr, "belongs to someone else's PR"))
+	})
+}
+
+func TestSubmitBaseBranchOverrides(t *testing.T) {
+	testutil.WithTempWorkingDir(t, func() {
+		wd, err := os.Getwd()
+		assert.NilError(t, err)
+		ghLog := path.Join(wd, "gh.log")
+
+		withFakeGHScript(t, `
+			case "$2" in
+			list)
+				echo '[]'
+				;;
+			create)
+				echo "$@" >> `+ghLog+`
+				;;
+			esac
+		`)
+
+		testutil.ExecOrFail(t, `
+			git init --bare --initial-branch=main origin.git
+			git clone -q origin.git repo
+			cd repo
+
+			touch main
+			git add main
+			git commit -m "main-0"
+			git push -q origin main
+
+			git checkout -b topic-a
+			touch a
+			git add a
+			git commit -m "topic-a-0"
+
+			git checkout -b topic-b
+			touch b
+			git add b
+			git commit -m "topic-b-0"
+		`)
+
+		assert.NilError(t, os.Chdir("repo"))
+
+		assert.Equal(t, yascli.Run("config", "set", "--trunk-branch=main", "--base-branch-override=main:develop"), 0)
+		assert.Equal(t, yascli.Run("add", "--branch=topic-a", "--parent=main"), 0)
+		assert.Equal(t, yascli.Run("add", "--branch=topic-b", "--parent=topic-a"), 0)
+
+		assert.Equal(t, yascli.Run("stack", "submit"), 0)
+
+		// Only PRs for branches on main are opened against develop
+		b, err := os.ReadFile(ghLog)
+		assert.NilError(t, err)
+		equalLines(t, string(b), `
+			pr create --head topic-a --base develop --title topic-a-0 --body  --draft
+			pr create --head topic-b --base topic-a --title topic-b-0 --body  --draft
+		`)
+
+		// The stack is unchanged
+		stdout, _, err := testutil.CaptureOutput(func() {
+			assert.Equal(t, yascli.Run("list"), 0)
+		})
+		assert.NilError(t, err)
+		equalLines(t, stdout, `
+			main
+			└── topic-a
+			    └── topic-b
+		`)
+	})
+}
+
+func TestSubmitRange(t *testing.T) {
+	testutil.WithTempWorkingDir(t, func() {
+		wd, err := os.Getwd()
+		assert.NilError(t, err)
+		ghLog := path.Join(wd, "gh.log")
+
+		withFakeGHScript(t, `
+			case "$2" in
+			list)
+				echo '[]'
+				;;
+			create)
+				echo "$@" >> `+ghLog+`
+				;;
+			esac
+		`)
+
+		testutil.ExecOrFail(t, `
+			git init --bare --initial-branch=main origin.git
+			git clone -q origin.git repo
+			cd repo
+
+			touch main
+			git add main
+			git commit -m "main-0"
+			git push -q origin main
+
+			git checkout -b topic-a
+			touch a
+			git add a
+			git commit -m "topic-a-0"
+
+			git checkout -b topic-b
+			touch b
+			git add b
+			git commit -m "topic-b-0"
+
+			git checkout -b topic-c
+			touch c
+			git add c
+			git commit -m "topic-c-0"
+
+			git checkout -b other main
+			touch other
+			git add other
+			git commit -m "other-0"
+
+			git checkout topic-c
+		`)
+
+		assert.NilError(t, os.Chdir("repo"))
+
+		assert.Equal(t, yascli.Run("config", "set", "--trunk-branch=main"), 0)
+		assert.Equal(t, yascli.Run("add", "--branch=topic-a", "--parent=main"), 0)
+		assert.Equal(t, yascli.Run("add", "--branch=topic-b", "--parent=topic-a"), 0)
+		assert.Equal(t, yascli.Run("add", "--branch=topic-c", "--parent=topic-b"), 0)
+		assert.Equal(t, yascli.Run("add", "--branch=other", "--parent=main"), 0)
+
+		for _, invalid := range []string{"topic-b..topic-a", "topic-a..other", "topic-a"} {
+			_, stderr, err := testutil.CaptureOutput(func() {
+				assert.Equal(t, yascli.Run("submit", "--range="+invalid), 1)
+			})
+			assert.NilError(t, err)
+			assert.Assert(t, cmp.Contains(stderr, "ERROR"), invalid)
+		}
+
+		assert.Equal(t, yascli.Run("submit", "--range=topic-a..topic-b"), 0)
+
+		b, err := os.ReadFile(ghLog)
+		assert.NilError(t, err)
+		equalLines(t, string(b), `
+			pr create --head topic-a --base main --title topic-a-0 --body  --draft
+			pr create --head topic-b --base topic-a --title topic-b-0 --body  --draft
+		`)
+
+		equalLines(t, mustExecOutput("git", "branch", "-r"), `
+			origin/main
+			origin/topic-a
+			origin/topic-b
+		`)
+	})
+}
+
+func TestSubmitDraftTransitions(t *testing.T) {
+	testutil.WithTempWorkingDir(t, func() {
+		wd, err := os.Getwd()
+		assert.NilError(t, err)
+		ghLog := path.Join(wd, "gh.log")
+		draftFile := path.Join(wd, "draft")
+
+		// Responds with an open PR that is a draft if the draft file exists
+		withFakeGHScript(t, `
+			case "$2" in
+			list)
+				isDraft=false
+				[ -f `+draftFile+` ] && isDraft=true
+				echo '[{"id":"PR_1","state":"OPEN","url":"https://github.com/test/test/pull/1","baseRefName":"main","isDraft":'$isDraft'}]'
+				;;
+			ready)
+				echo "$@" >> `+ghLog+`
+				if [ "$4" = "--undo" ]; then touch `+draftFile+`; else rm -f `+draftFile+`; fi
+				;;
+			esac
+		`)
+
+		testutil.ExecOrFail(t, `
+			git init --bare --initial-branch=main origin.git
+			git clone -q origin.git repo
+			cd repo
+
+			touch main
+			git add main
+			git commit -m "main-0"
+			git push -q origin main
+
+			git checkout -b topic-a
+			touch a
+			git add a
+			git commit -m "topic-a-0"
+		`)
+
+		assert.NilError(t, os.Chdir("repo"))
+
+		assert.Equal(t, yascli.Run("config", "set", "--trunk-branch=main"), 0)
+		assert.Equal(t, yascli.Run("add", "--branch=topic-a", "--parent=main"), 0)
+
+		assert.Equal(t, yascli.Run("submit", "--draft", "--ready"), 1)
+
+		assert.Equal(t, yascli.Run("submit", "--draft"), 0)
+
+		stdout, _, err := testutil.CaptureOutput(func() {
+			assert.Equal(t, yascli.Run("list"), 0)
+		})
+		assert.NilError(t, err)
+		assert.Assert(t, cmp.Contains(stdout, "topic-a [draft]"))
+
+		// Already a draft, so nothing to change
+		assert.Equal(t, yascli.Run("submit", "--draft"), 0)
+
+		assert.Equal(t, yascli.Run("submit", "--ready"), 0)
+
+		stdout, _, err = testutil.CaptureOutput(func() {
+			assert.Equal(t, yascli.Run("list"), 0)
+		})
+		assert.NilError(t, err)
+		assert.Assert(t, !strings.Contains(stdout, "[draft]"))
+
+		b, err := os.ReadFile(ghLog)
+		assert.NilError(t, err)
+		equalLines(t, string(b), `
+			pr ready topic-a --undo
+			pr ready topic-a
+		`)
+	})
+}
+
+func TestPRAutoMerge(t *testing.T) {
+	testutil.WithTempWorkingDir(t, func() {
+		wd, err := os.Getwd()
+		assert.NilError(t, err)
+		ghLog := path.Join(wd, "gh.log")
+
+		withFakeGHScript(t, `
+			case "$2" in
+			list)
+				echo '[{"id":"PR_1","number":1,"state":"OPEN","url":"https://github.com/test/test/pull/1","baseRefName":"main"}]'
+				;;
+			merge)
+				echo "$@" >> `+ghLog+`
+				;;
+			esac
+		`)
+
+		testutil.ExecOrFail(t, `
+			git init --bare --initial-branch=main origin.git
+			git clone -q origin.git repo
+			cd repo
+
+			touch main
+			git add main
+			git commit -m "main-0"
+			git push -q origin main
+
+			git checkout -b topic-a
+			touch a
+			git add a
+			git commit -m "topic-a-0"
+		`)
+
+		assert.NilError(t, os.Chdir("repo"))
+
+		assert.Equal(t, yascli.Run("config", "set", "--trunk-branch=main"), 0)
+		assert.Equal(t, yascli.Run("add", "--branch=topic-a", "--parent=main"), 0)
+
+		assert.Equal(t, yascli.Run("pr", "automerge"), 1)
+		assert.Equal(t, yascli.Run("pr", "automerge", "--enable"), 0)
+
+		stdout, _, err := testutil.CaptureOutput(func() {
+			assert.Equal(t, yascli.Run("list"), 0)
+		})
+		assert.NilError(t, err)
+		assert.Assert(t, cmp.Contains(stdout, "topic-a [auto-merge]"))
+
+		// Auto-merge is re-enabled when the branch is pushed again
+		assert.Equal(t, yascli.Run("submit"), 0)
+
+		assert.Equal(t, yascli.Run("pr", "automerge", "topic-a", "--disable"), 0)
+
+		stdout, _, err = testutil.CaptureOutput(func() {
+			assert.Equal(t, yascli.Run("list"), 0)
+		})
+		assert.NilError(t, err)
+		assert.Assert(t, !strings.Contains(stdout, "[auto-merge]"))
+
+		// Not re-enabled once disabled
+		assert.Equal(t, yascli.Run("submit"), 0)
+
+		b, err := os.ReadFile(ghLog)
+		assert.NilError(t, err)
+		equalLines(t, string(b), `
+			pr merge 1 --auto --squash
+			pr merge 1 --auto --squash
+			pr merge 1 --disable-auto
+		`)
+	})
+}
+
+func TestPRView(t *testing.T) {
+	testutil.WithTempWorkingDir(t, func() {
+		wd, err := os.Getwd()
+		assert.NilError(t, err)
+		prDir := path.Join(wd, "prs")
+		assert.NilError(t, os.Mkdir(prDir, 0o755))
+
+		// Responds to gh pr list with prs/<branch>.json, and to gh api with
+		// prs/<id>.graphql.json
+		withFakeGHScript(t, `
+			case "$1" in
+			api)
+				for arg; do last=$arg; done
+				cat `+prDir+`/"${last#id=}".graphql.json
+				;;
+			pr)
+				cat `+prDir+`/"$4".json 2>/dev/null || echo '[]'
+				;;
+			esac
+		`)
+
+		writeFile := func(name, content string) {
+			assert.NilError(t, os.WriteFile(path.Join(prDir, name), []byte(content), 0o644))
+		}
+
+		testutil.ExecOrFail(t, `
+			git init --initial-branch=main
+
+			touch main
+			git add main
+			git commit -m "main-0"
+
+			git checkout -b topic-a
+			touch a
+			git add a
+			git commit -m "topic-a-0"
+
+			git checkout -b topic-b
+			touch b
+			git add b
+			git commit -m "topic-b-0"
+
+			git checkout -b topic-c
+			touch c
+			git add c
+			git commit -m "topic-c-0"
+		`)
+
+		assert.Equal(t, yascli.Run("config", "set", "--trunk-branch=main"), 0)
+		assert.Equal(t, yascli.Run("add", "--branch=topic-a", "--parent=main"), 0)
+		assert.Equal(t, yascli.Run("add", "--branch=topic-b", "--parent=topic-a"), 0)
+		assert.Equal(t, yascli.Run("add", "--branch=topic-c", "--parent=topic-b"), 0)
+
+		writeFile("topic-a.json", `[{"id":"PR_1","state":"OPEN","url":"https://github.com/test/test/pull/1","baseRefName":"main","reviewDecision":"CHANGES_REQUESTED"}]`)
+		writeFile("PR_1.graphql.json", `{"data":{"node":{
+			"latestReviews":{"nodes":[
+				{"state":"CHANGES_REQUESTED","body":"Please split this up.\n\nIt's too big.","author":{"login":"alice"}},
+				{"state":"APPROVED","body":"LGTM","author":{"login":"carol"}}
+			]},
+			"reviewThreads":{"nodes":[
+				{"isResolved":false,"isOutdated":false,"path":"a.go","line":12,"originalLine":10,"comments":{"nodes":[{"body":"Rename this\nto something clearer","author":{"login":"bob"}}]}},
+				{"isResolved":true,"isOutdated":false,"path":"a.go","line":20,"originalLine":20,"comments":{"nodes":[{"body":"Fixed","author":{"login":"bob"}}]}},
+				{"isResolved":false,"isOutdated":true,"path":"b.go","line":0,"originalLine":3,"comments":{"nodes":[{"body":"Typo","author":{"login":"alice"}}]}}
+			]}
+		}}}`)
+		writeFile("topic-b.json", `[{"id":"PR_2","state":"OPEN","url":"https://github.com/test/test/pull/2","baseRefName":"topic-a","reviewDecision":"APPROVED"}]`)
+		writeFile("PR_2.graphql.json", `{"data":{"node":{"latestReviews":{"nodes":[]},"reviewThreads":{"nodes":[]}}}}`)
+
+		stdout, _, err := testutil.CaptureOutput(func() {
+			assert.Equal(t, yascli.Run("pr", "view", "--stack"), 0)
+		})
+		assert.NilError(t, err)
+		equalLines(t, stdout, `
+			topic-a: changes requested, 2 unresolved thread(s) (https://github.com/test/test/pull/1)
+			    ✋ alice: Please split this up.
+			    a.go:12 bob: Rename this
+			    b.go:3 (outdated) alice: Typo
+			topic-b: approved, 0 unresolved thread(s) (https://github.com/test/test/pull/2)
+			topic-c: no open PR
+		`)
+
+		stdout, _, err = testutil.CaptureOutput(func() {
+			assert.Equal(t, yascli.Run("pr", "view", "topic-b"), 0)
+		})
+		assert.NilError(t, err)
+		equalLines(t, stdout, "topic-b: approved, 0 unresolved thread(s) (https://github.com/test/test/pull/2)")
+	})
+}
+
+func TestSubmitDivergedRemote(t *testing.T) {
+	testutil.WithTempWorkingDir(t, func() {
+		withFakeGH(t, `[{"id":"PR_1","number":1,"state":"OPEN","url":"https://github.com/test/test/pull/1","baseRefName":"main"}]`)
+
+		testutil.ExecOrFail(t, `
+			git init -q --bare --initial-branch=main origin.git
+			git clone -q origin.git repo
+			cd repo
+
+			touch main
+			git add main
+			git commit -m "main-0"
+			git push -q origin main
+
+			git checkout -b topic-a
+			touch a
+			git add a
+			git commit -m "topic-a-0"
+		`)
+
+		assert.NilError(t, os.Chdir("repo"))
+
+		assert.Equal(t, yascli.Run("config", "set", "--trunk-branch=main"), 0)
+		assert.Equal(t, yascli.Run("add", "--branch=topic-a", "--parent=main"), 0)
+		assert.Equal(t, yascli.Run("submit"), 0)
+
+		// Rewriting the commits that yas pushed is not a divergence
+		testutil.ExecOrFail(t, `
+			echo 1 > a
+			git commit -q -a --amend -m "topic-a-0"
+		`)
+		assert.Equal(t, yascli.Run("submit"), 0)
+
+		pushOther := func(file string) {
+			testutil.ExecOrFail(t, `
+				cd ..
+				rm -rf other
+				git clone -q --branch topic-a origin.git other
+				cd other
+				touch `+file+`
+				git add `+file+`
+				git commit -m "`+file+`"
+				git push -q origin topic-a
+			`)
+		}
+
+		pushOther("other-1")
+
+		testutil.ExecOrFail(t, `
+			echo 2 > a
+			git commit -q -a --amend -m "topic-a-0"
+		`)
+
+		// Aborts by default
+		t.Setenv("YAS_NONINTERACTIVE", "1")
+
+		stdout, _, err := testutil.CaptureOutput(func() {
+			assert.Equal(t, yascli.Run("submit"), 1)
+		})
+		assert.NilError(t, err)
+		assert.Assert(t, cmp.Contains(stdout, "origin/topic-a has 1 commit(s) that yas didn't push"))
+		assert.Assert(t, cmp.Contains(stdout, " other-1\n    (+0 -0, 1 files)\n"))
+		equalLines(t, mustExecOutput("git", "log", "--pretty=%s", "origin/topic-a", "--"), `
+			other-1
+			topic-a-0
+			main-0
+		`)
+
+		t.Setenv("YAS_NONINTERACTIVE", "0")
+
+		// Rebase the other commit onto the local branch
+		withStdin(t, "1\n", func() {
+			_, _, err = testutil.CaptureOutput(func() {
+				assert.Equal(t, yascli.Run("submit"), 0)
+			})
+		})
+		assert.NilError(t, err)
+		assert.Equal(t, mustExecOutput("git", "rev-parse", "topic-a"), mustExecOutput("git", "rev-parse", "origin/topic-a"))
+		equalLines(t, mustExecOutput("git", "log", "--pretty=%s", "topic-a", "--"), `
+			other-1
+			topic-a-0
+			main-0
+		`)
+		equalLines(t, mustExecOutput("git", "show", "topic-a:a"), "2")
+		equalLines(t, mustExecOutput("git", "branch", "--show-current"), "topic-a")
+
+		// Overwrite the other commit
+		pushOther("other-2")
+
+		withStdin(t, "2\n", func() {
+			_, _, err = testutil.CaptureOutput(func() {
+				assert.Equal(t, yascli.Run("submit"), 0)
+			})
+		})
+		assert.NilError(t, err)
+		equalLines(t, mustExecOutput("git", "log", "--pretty=%s", "origin/topic-a", "--"), `
+			other-1
+			topic-a-0
+			main-0
+		`)
+	})
+}
+
+func TestSubmitCombined(t *testing.T) {
+	testutil.WithTempWorkingDir(t, func() {
+		wd, err := os.Getwd()
+		assert.NilError(t, err)
+		stubFile := path.Join(wd, "github.json")
+		t.Setenv("YAS_GH_STUB", stubFile)
+
+		testutil.ExecOrFail(t, `
+			git init -q --bare --initial-branch=main origin.git
+			git clone -q origin.git repo
+			cd repo
+
+			touch main
+			git add main
+			git commit -m "main-0"
+			git push -q origin main
+
+			git checkout -b topic-a
+			touch a
+			git add a
+			git commit -m "topic-a-0"
+
+			git checkout -b topic-b
+			touch b
+			git add b
+			git commit -m "topic-b-0"
+
+			cat > .git/yas.yaml <<-'EOF'
+			trunkBranch: main
+			hooks:
+			  preSubmit: echo "$YAS_BRANCH" > .git/pre-submit
+			  postSubmit: echo "$YAS_BRANCH $YAS_PR_URL" > .git/post-submit
+			EOF
+		`)
+
+		assert.NilError(t, os.Chdir("repo"))
+
+		assert.Equal(t, yascli.Run("add", "--branch=topic-a", "--parent=main"), 0)
+		assert.Equal(t, yascli.Run("add", "--branch=topic-b", "--parent=topic-a"), 0)
+		assert.Equal(t, yascli.Run("submit", "--combined"), 0)
+
+		stub, err := ghstub.Load(stubFile)
+		assert.NilError(t, err)
+		assert.Equal(t, len(stub.PullRequests), 1)
+		assert.Equal(t, stub.PullRequests[0].HeadRefName, "topic-b")
+		assert.Equal(t, stub.PullRequests[0].BaseRefName, "main")
+		assert.Equal(t, stub.PullRequests[0].Title, "topic-a-0")
+		assert.Equal(t, stub.PullRequests[0].Body, "<!-- yas-stack-start -->\n### topic-a\n\n- topic-a-0\n\n### topic-b\n\n- topic-b-0\n<!-- yas-stack-end -->")
+
+		b, err := os.ReadFile(".git/pre-submit")
+		assert.NilError(t, err)
+		assert.Equal(t, string(b), "topic-b\n")
+
+		b, err = os.ReadFile(".git/post-submit")
+		assert.NilError(t, err)
+		assert.Equal(t, string(b), "topic-b "+stub.PullRequests[0].URL+"\n")
+
+		// Text the user adds around the generated section is kept when the
+		// PR is submitted again
+		stub.PullRequests[0].Body = "Please review\n\n" + stub.PullRequests[0].Body + "\n\nThanks"
+		assert.NilError(t, stub.Save())
+
+		testutil.ExecOrFail(t, `
+			touch b1
+			git add b1
+			git commit -m "topic-b-1"
+		`)
+		assert.Equal(t, yascli.Run("submit", "--combined"), 0)
+
+		stub, err = ghstub.Load(stubFile)
+		assert.NilError(t, err)
+		assert.Equal(t, stub.PullRequests[0].Body, "Please review\n\n<!-- yas-stack-start -->\n### topic-a\n\n- topic-a-0\n\n### topic-b\n\n- topic-b-0\n- topic-b-1\n<!-- yas-stack-end -->\n\nThanks")
+
+		// A failing pre hook aborts the submit
+		testutil.ExecOrFail(t, `
+			cat > .git/yas.yaml <<-'EOF'
+			trunkBranch: main
+			hooks:
+			  preSubmit: "false"
+			EOF
+		`)
+		assert.Equal(t, yascli.Run("submit", "--combined"), 1)
+	})
+}
@@ -0,0 +1,82 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/dansimau/yas/pkg/testutil"
+	"github.com/dansimau/yas/pkg/yascli"
+	"gotest.tools/v3/assert"
+)
+
+func TestPrompt(t *testing.T) {
+	testutil.WithTempWorkingDir(t, func() {
+		testutil.ExecOrFail(t, `
+			git init --initial-branch=main
+
+			# main
+			touch main
+			git add main
+			git commit -m "main-0"
+
+			# topic-a
+			git checkout -b topic-a
+			touch a
+			git add a
+			git commit -m "topic-a-0"
+
+			# topic-b
+			git checkout -b topic-b
+			touch b
+			git add b
+			git commit -m "topic-b-0"
+		`)
+
+		assert.Equal(t, yascli.Run("config", "set", "--trunk-branch=main"), 0)
+		assert.Equal(t, yascli.Run("add", "--branch=topic-a", "--parent=main"), 0)
+		assert.Equal(t, yascli.Run("add", "--branch=topic-b", "--parent=topic-a"), 0)
+
+		t.Run("UpToDate", func(t *testing.T) {
+			output, _, err := testutil.CaptureOutput(func() {
+				assert.Equal(t, yascli.Run("prompt"), 0)
+			})
+			assert.NilError(t, err)
+			assert.Equal(t, output, "2↕\n")
+		})
+
+		t.Run("NeedsRestack", func(t *testing.T) {
+			testutil.ExecOrFail(t, `
+				git checkout main
+				echo 1 > main
+				git add main
+				git commit -m "main-1"
+				git checkout topic-b
+			`)
+
+			output, _, err := testutil.CaptureOutput(func() {
+				assert.Equal(t, yascli.Run("prompt"), 3)
+			})
+			assert.NilError(t, err)
+			assert.Equal(t, output, "2↕ 1⚠\n")
+
+			output, _, err = testutil.CaptureOutput(func() {
+				assert.Equal(t, yascli.Run("prompt", "--json"), 3)
+			})
+			assert.NilError(t, err)
+			assert.Equal(t, output, `{"branch":"topic-b","stackDepth":2,"needsRestack":1}`+"\n")
+		})
+
+		t.Run("NotTracked", func(t *testing.T) {
+			testutil.ExecOrFail(t, `git checkout main`)
+
+			output, _, err := testutil.CaptureOutput(func() {
+				assert.Equal(t, yascli.Run("prompt"), 2)
+			})
+			assert.NilError(t, err)
+			assert.Equal(t, output, "")
+
+			testutil.ExecOrFail(t, `git checkout --detach topic-a`)
+
+			assert.Equal(t, yascli.Run("prompt"), 2)
+		})
+	})
+}
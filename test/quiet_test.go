@@ -0,0 +1,85 @@
+package test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/dansimau/yas/pkg/testutil"
+	"github.com/dansimau/yas/pkg/yascli"
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/assert/cmp"
+)
+
+func TestQuiet(t *testing.T) {
+	testutil.WithTempWorkingDir(t, func() {
+		withFakeGH(t, `[]`)
+
+		testutil.ExecOrFail(t, `
+			git init -q --bare --initial-branch=main origin.git
+			git clone -q origin.git repo
+			cd repo
+
+			touch main
+			git add main
+			git commit -m "main-0"
+			git push -q origin main
+
+			git checkout -b topic-a
+			touch a
+			git add a
+			git commit -m "topic-a-0"
+
+			# Someone else pushes to main
+			cd ..
+			git clone -q origin.git other
+			cd other
+			touch other
+			git add other
+			git commit -m "main-1"
+			git push -q origin main
+		`)
+
+		assert.NilError(t, os.Chdir("repo"))
+
+		assert.Equal(t, yascli.Run("config", "set", "--trunk-branch=main"), 0)
+		assert.Equal(t, yascli.Run("add", "--branch=topic-a", "--parent=main"), 0)
+
+		stdout, stderr, err := testutil.CaptureOutput(func() {
+			assert.Equal(t, yascli.Run("--quiet", "sync", "--restack"), 0)
+		})
+		assert.NilError(t, err)
+		assert.Equal(t, stdout, "")
+		assert.Equal(t, stderr, "")
+
+		equalLines(t, mustExecOutput("git", "log", "--pretty=%s", "topic-a", "--"), `
+			topic-a-0
+			main-1
+			main-0
+		`)
+
+		// Errors are still printed, including the output of failed commands
+		stdout, stderr, err = testutil.CaptureOutput(func() {
+			assert.Equal(t, yascli.Run("--quiet", "switch", "topic-b"), 1)
+		})
+		assert.NilError(t, err)
+		assert.Equal(t, stdout, "")
+		assert.Assert(t, cmp.Contains(stderr, "ERROR: "))
+
+		// JSON results are still printed
+		stdout, stderr, err = testutil.CaptureOutput(func() {
+			assert.Equal(t, yascli.Run("--quiet", "--output=json", "restack"), 0)
+		})
+		assert.NilError(t, err)
+		assert.Assert(t, cmp.Contains(stdout, `"success":true`))
+		assert.Equal(t, stderr, "")
+
+		assert.Equal(t, yascli.Run("--quiet", "--verbose", "list"), 1)
+
+		// Later invocations in the same process aren't quiet
+		stdout, _, err = testutil.CaptureOutput(func() {
+			assert.Equal(t, yascli.Run("list"), 0)
+		})
+		assert.NilError(t, err)
+		assert.Assert(t, cmp.Contains(stdout, "topic-a"))
+	})
+}
@@ -0,0 +1,77 @@
+package test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/dansimau/yas/pkg/testutil"
+	"github.com/dansimau/yas/pkg/yascli"
+	"gotest.tools/v3/assert"
+)
+
+func TestRecover(t *testing.T) {
+	withFakeGHScript(t, `
+		case "$4" in
+		topic-c)
+			echo '[{"id":"PR_3","state":"OPEN","url":"https://github.com/test/test/pull/3","headRefName":"topic-c","baseRefName":"topic-a"}]'
+			;;
+		*)
+			echo '[]'
+			;;
+		esac
+	`)
+
+	testutil.WithTempWorkingDir(t, func() {
+		testutil.ExecOrFail(t, `
+			git init --initial-branch=main
+
+			touch main
+			git add main
+			git commit -m "main-0"
+
+			git checkout -b topic-a
+			touch a
+			git add a
+			git commit -m "topic-a-0"
+
+			git checkout -b topic-b
+			touch b
+			git add b
+			git commit -m "topic-b-0"
+
+			# topic-c is based on topic-b, but its PR is against topic-a
+			git checkout -b topic-c
+			touch c
+			git add c
+			git commit -m "topic-c-0"
+
+			# No commits of its own
+			git checkout -b topic-d main
+		`)
+
+		assert.Equal(t, yascli.Run("config", "set", "--trunk-branch=main"), 0)
+		assert.NilError(t, os.WriteFile(".git/.yasstate", []byte("corrupt"), 0o644))
+
+		// Commands fail with a corrupt state file
+		assert.Equal(t, yascli.Run("list"), 1)
+
+		assert.Equal(t, yascli.Run("recover"), 0)
+
+		stdout, _, err := testutil.CaptureOutput(func() {
+			assert.Equal(t, yascli.Run("list"), 0)
+		})
+		assert.NilError(t, err)
+
+		// GitHub PR bases are preferred over ancestry
+		equalLines(t, stdout, `
+			main
+			└── topic-a
+			    ├── topic-b
+			    └── topic-c
+		`)
+
+		b, err := os.ReadFile(".git/.yasstate.bak")
+		assert.NilError(t, err)
+		assert.Equal(t, string(b), "corrupt")
+	})
+}
@@ -0,0 +1,288 @@
+package test
+
+import (
+	"os"
+	"path"
+	"testing"
+
+	"github.com/dansimau/yas/pkg/testutil"
+	"github.com/dansimau/yas/pkg/yascli"
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/assert/cmp"
+)
+
+func TestRefreshStack(t *testing.T) {
+	testutil.WithTempWorkingDir(t, func() {
+		wd, err := os.Getwd()
+		assert.NilError(t, err)
+		prDir := path.Join(wd, "prs")
+		assert.NilError(t, os.Mkdir(prDir, 0o755))
+
+		// Responds with the contents of prs/<branch>.json
+		withFakeGHScript(t, `cat `+prDir+`/"$4".json 2>/dev/null || echo '[]'`)
+
+		writePR := func(branch, json string) {
+			assert.NilError(t, os.WriteFile(path.Join(prDir, branch+".json"), []byte(json), 0o644))
+		}
+
+		testutil.ExecOrFail(t, `
+			git init --initial-branch=main repo
+			cd repo
+
+			touch main
+			git add main
+			git commit -m "main-0"
+
+			git checkout -b topic-a
+			touch a
+			git add a
+			git commit -m "topic-a-0"
+
+			git checkout -b topic-b
+			touch b
+			git add b
+			git commit -m "topic-b-0"
+
+			git checkout -b topic-c
+			touch c
+			git add c
+			git commit -m "topic-c-0"
+		`)
+
+		assert.NilError(t, os.Chdir("repo"))
+
+		assert.Equal(t, yascli.Run("config", "set", "--trunk-branch=main"), 0)
+		assert.Equal(t, yascli.Run("add", "--branch=topic-a", "--parent=main"), 0)
+		assert.Equal(t, yascli.Run("add", "--branch=topic-b", "--parent=topic-a"), 0)
+		assert.Equal(t, yascli.Run("add", "--branch=topic-c", "--parent=topic-b"), 0)
+
+		writePR("topic-a", `[{"id":"PR_1","state":"OPEN","url":"https://github.com/test/test/pull/1","baseRefName":"main"}]`)
+		writePR("topic-b", `[{"id":"PR_2","state":"OPEN","url":"https://github.com/test/test/pull/2","baseRefName":"topic-a","isDraft":true}]`)
+
+		stdout, _, err := testutil.CaptureOutput(func() {
+			assert.Equal(t, yascli.Run("refresh", "--stack"), 0)
+		})
+		assert.NilError(t, err)
+		equalLines(t, stdout, `
+			topic-a: no PR → OPEN
+			topic-b: no PR → OPEN
+			topic-c: no changes
+		`)
+
+		writePR("topic-a", `[{"id":"PR_1","state":"MERGED","url":"https://github.com/test/test/pull/1","baseRefName":"main"}]`)
+		writePR("topic-b", `[{"id":"PR_2","state":"OPEN","url":"https://github.com/test/test/pull/2","baseRefName":"main"}]`)
+
+		stdout, _, err = testutil.CaptureOutput(func() {
+			assert.Equal(t, yascli.Run("refresh", "--all"), 0)
+		})
+		assert.NilError(t, err)
+		equalLines(t, stdout, `
+			topic-a: OPEN → MERGED
+			topic-b: base topic-a → main, ready for review
+			topic-c: no changes
+		`)
+	})
+}
+
+func TestAheadBehindRemote(t *testing.T) {
+	testutil.WithTempWorkingDir(t, func() {
+		withFakeGHScript(t, `echo '[]'`)
+
+		testutil.ExecOrFail(t, `
+			git init --bare --initial-branch=main origin.git
+			git clone -q origin.git repo
+			cd repo
+
+			touch main
+			git add main
+			git commit -m "main-0"
+			git push -q origin main
+
+			git checkout -b topic-a
+			touch a
+			git add a
+			git commit -m "topic-a-0"
+			git push -q origin topic-a
+
+			# Someone else pushes to topic-a
+			cd ..
+			git clone -q --branch topic-a origin.git other
+			cd other
+			touch other
+			git add other
+			git commit -m "topic-a-other"
+			git push -q origin topic-a
+
+			# Local commit that isn't pushed
+			cd ../repo
+			echo 1 > a
+			git commit -am "topic-a-1"
+		`)
+
+		assert.NilError(t, os.Chdir("repo"))
+
+		assert.Equal(t, yascli.Run("config", "set", "--trunk-branch=main"), 0)
+		assert.Equal(t, yascli.Run("add", "--branch=topic-a", "--parent=main"), 0)
+
+		// Not fetched yet, so the other commit isn't known
+		stdout, _, err := testutil.CaptureOutput(func() {
+			assert.Equal(t, yascli.Run("list"), 0)
+		})
+		assert.NilError(t, err)
+		equalLines(t, stdout, `
+			main
+			└── topic-a ↑1
+		`)
+
+		stdout, _, err = testutil.CaptureOutput(func() {
+			assert.Equal(t, yascli.Run("refresh"), 0)
+		})
+		assert.NilError(t, err)
+		assert.Assert(t, cmp.Contains(stdout, "topic-a: ⚠️  1 commit(s) on origin not on local branch"))
+
+		stdout, _, err = testutil.CaptureOutput(func() {
+			assert.Equal(t, yascli.Run("list"), 0)
+		})
+		assert.NilError(t, err)
+		equalLines(t, stdout, `
+			main
+			└── topic-a ↑1 ↓1
+		`)
+
+		stdout, _, err = testutil.CaptureOutput(func() {
+			assert.Equal(t, yascli.Run("status"), 0)
+		})
+		assert.NilError(t, err)
+		assert.Assert(t, cmp.Contains(stdout, "Remote: origin/topic-a (↑1 ↓1)"))
+		assert.Assert(t, cmp.Contains(stdout, "Behind remote"))
+
+		stdout, _, err = testutil.CaptureOutput(func() {
+			assert.Equal(t, yascli.Run("submit"), 0)
+		})
+		assert.NilError(t, err)
+		assert.Assert(t, cmp.Contains(stdout, "topic-a is behind origin/topic-a by 1 commit(s)"))
+	})
+}
+
+func TestNeedsSubmit(t *testing.T) {
+	testutil.WithTempWorkingDir(t, func() {
+		withFakeGH(t, `[{"id":"PR_1","number":1,"state":"OPEN","url":"https://github.com/test/test/pull/1","baseRefName":"main"}]`)
+
+		testutil.ExecOrFail(t, `
+			git init --bare --initial-branch=main origin.git
+			git clone -q origin.git repo
+			cd repo
+
+			touch main
+			git add main
+			git commit -m "main-0"
+			git push -q origin main
+
+			git checkout -b topic-a
+			touch a
+			git add a
+			git commit -m "topic-a-0"
+			git push -q origin topic-a
+		`)
+
+		assert.NilError(t, os.Chdir("repo"))
+
+		assert.Equal(t, yascli.Run("config", "set", "--trunk-branch=main"), 0)
+		assert.Equal(t, yascli.Run("add", "--branch=topic-a", "--parent=main"), 0)
+		assert.Equal(t, yascli.Run("refresh"), 0)
+
+		stdout, _, err := testutil.CaptureOutput(func() {
+			assert.Equal(t, yascli.Run("list"), 0)
+		})
+		assert.NilError(t, err)
+		equalLines(t, stdout, `
+			main
+			└── topic-a
+		`)
+
+		// Amending changes the local commits without changing the PR
+		testutil.ExecOrFail(t, `
+			echo 1 > a
+			git commit -q -a --amend -m "topic-a-0 amended"
+			echo 2 > a
+			git commit -q -a -m "topic-a-1"
+		`)
+
+		stdout, _, err = testutil.CaptureOutput(func() {
+			assert.Equal(t, yascli.Run("list"), 0)
+		})
+		assert.NilError(t, err)
+		equalLines(t, stdout, `
+			main
+			└── topic-a ↑2 ↓1 needs submit (2 unpushed commits)
+		`)
+
+		stdout, _, err = testutil.CaptureOutput(func() {
+			assert.Equal(t, yascli.Run("status"), 0)
+		})
+		assert.NilError(t, err)
+		assert.Assert(t, cmp.Contains(stdout, "Needs submit: 2 unpushed commits (hint: run `yas submit`)"))
+
+		stdout, _, err = testutil.CaptureOutput(func() {
+			assert.Equal(t, yascli.Run("refresh"), 0)
+		})
+		assert.NilError(t, err)
+		assert.Assert(t, cmp.Contains(stdout, "needs submit (2 unpushed commits)"))
+	})
+}
+
+func TestRefreshContinuesPastFailures(t *testing.T) {
+	testutil.WithTempWorkingDir(t, func() {
+		// Fails for topic-b, and responds with an open PR for other branches
+		withFakeGHScript(t, `
+			if [ "$4" = "topic-b" ]; then
+				echo "HTTP 401: Bad credentials (https://api.github.com/graphql)" >&2
+				exit 1
+			fi
+			echo '[{"id":"PR_1","state":"OPEN","url":"https://github.com/test/test/pull/1","baseRefName":"main"}]'
+		`)
+
+		testutil.ExecOrFail(t, `
+			git init --initial-branch=main repo
+			cd repo
+
+			touch main
+			git add main
+			git commit -m "main-0"
+
+			git checkout -b topic-a
+			touch a
+			git add a
+			git commit -m "topic-a-0"
+
+			git checkout -b topic-b
+			touch b
+			git add b
+			git commit -m "topic-b-0"
+
+			git checkout -b topic-c
+			touch c
+			git add c
+			git commit -m "topic-c-0"
+		`)
+
+		assert.NilError(t, os.Chdir("repo"))
+
+		assert.Equal(t, yascli.Run("config", "set", "--trunk-branch=main"), 0)
+		assert.Equal(t, yascli.Run("add", "--branch=topic-a", "--parent=main"), 0)
+		assert.Equal(t, yascli.Run("add", "--branch=topic-b", "--parent=topic-a"), 0)
+		assert.Equal(t, yascli.Run("add", "--branch=topic-c", "--parent=topic-b"), 0)
+
+		stdout, stderr, err := testutil.CaptureOutput(func() {
+			assert.Equal(t, yascli.Run("refresh", "--all"), 1)
+		})
+		assert.NilError(t, err)
+		equalLines(t, stdout, `
+			topic-a: no PR → OPEN
+			topic-b: failed to fetch PR status
+			topic-c: no PR → OPEN
+		`)
+		assert.Assert(t, cmp.Contains(stderr, "failed to fetch PR status of 1 branch(es)"))
+		assert.Assert(t, cmp.Contains(stderr, "topic-b: GitHub authentication failed: HTTP 401: Bad credentials"))
+	})
+}
@@ -0,0 +1,70 @@
+package test
+
+import (
+	"os"
+	"path"
+	"testing"
+
+	"github.com/dansimau/yas/pkg/testutil"
+	"github.com/dansimau/yas/pkg/yascli"
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/assert/cmp"
+)
+
+func TestReport(t *testing.T) {
+	testutil.WithTempWorkingDir(t, func() {
+		// $4 is the PR state
+		withFakeGHScript(t, `
+			case "$4" in
+			merged)
+				echo '[{"number":1,"title":"Merged thing","url":"https://github.com/test/test/pull/1","headRefName":"topic-a","createdAt":"2026-01-01T00:00:00Z","mergedAt":"2026-01-02T00:00:00Z"}]'
+				;;
+			*)
+				echo '[{"number":2,"title":"Add b","url":"https://github.com/test/test/pull/2","headRefName":"topic-b","createdAt":"2026-01-01T00:00:00Z"},{"number":3,"title":"Not yas","url":"https://github.com/test/test/pull/3","headRefName":"other","createdAt":"2026-01-01T00:00:00Z"}]'
+				;;
+			esac
+		`)
+
+		testutil.ExecOrFail(t, `
+			git init --initial-branch=main
+
+			touch main
+			git add main
+			git commit -m "main-0"
+
+			git checkout -b topic-a
+			touch a
+			git add a
+			git commit -m "topic-a-0"
+
+			git checkout -b topic-b
+			touch b
+			git add b
+			git commit -m "topic-b-0"
+		`)
+
+		assert.Equal(t, yascli.Run("config", "set", "--trunk-branch=main"), 0)
+		assert.Equal(t, yascli.Run("add", "--branch=topic-a", "--parent=main"), 0)
+		assert.Equal(t, yascli.Run("add", "--branch=topic-b", "--parent=topic-a"), 0)
+
+		stdout, _, err := testutil.CaptureOutput(func() {
+			assert.Equal(t, yascli.Run("report"), 0)
+		})
+		assert.NilError(t, err)
+
+		assert.Assert(t, cmp.Contains(stdout, "## Branches created (2)\n"))
+		assert.Assert(t, cmp.Contains(stdout, "- `topic-a` ("))
+		assert.Assert(t, cmp.Contains(stdout, "## PRs submitted (1)\n\n- [#2 Add b](https://github.com/test/test/pull/2) (`topic-b`)\n"))
+		assert.Assert(t, cmp.Contains(stdout, "## PRs merged (1)\n\n- [#1 Merged thing](https://github.com/test/test/pull/1) (`topic-a`), merged 2026-01-02\n"))
+		assert.Assert(t, cmp.Contains(stdout, "## Open stacks (1)\n\n- `topic-a`\n  - `topic-b`\n"))
+
+		// Branches created outside the period aren't included
+		reportFile := path.Join(t.TempDir(), "report.md")
+		assert.Equal(t, yascli.Run("report", "--since=2020-01-01", "--until=2020-01-31", "--output="+reportFile), 0)
+
+		b, err := os.ReadFile(reportFile)
+		assert.NilError(t, err)
+		assert.Assert(t, cmp.Contains(string(b), "# Report: 2020-01-01 to 2020-01-31\n"))
+		assert.Assert(t, cmp.Contains(string(b), "## Branches created (0)\n"))
+	})
+}
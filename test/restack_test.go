@@ -1,11 +1,14 @@
 package test
 
 import (
+	"os"
+	"strings"
 	"testing"
 
 	"github.com/dansimau/yas/pkg/testutil"
 	"github.com/dansimau/yas/pkg/yascli"
 	"gotest.tools/v3/assert"
+	"gotest.tools/v3/assert/cmp"
 )
 
 func TestUpdateTrunk(t *testing.T) {
@@ -53,3 +56,786 @@ func TestUpdateTrunk(t *testing.T) {
 		`)
 	})
 }
+
+func TestRestackAutostash(t *testing.T) {
+	testutil.WithTempWorkingDir(t, func() {
+		testutil.ExecOrFail(t, `
+			git init --initial-branch=main
+
+			# main
+			touch main
+			git add main
+			git commit -m "main-0"
+
+			# topic-a
+			git checkout -b topic-a
+			touch a
+			git add a
+			git commit -m "topic-a-0"
+
+			# update main
+			git checkout main
+			echo 1 > main
+			git add main
+			git commit -m "main-1"
+
+			# local modification on topic-a
+			git checkout topic-a
+			echo 1 > a
+		`)
+
+		assert.Equal(t, yascli.Run("config", "set", "--trunk-branch=main"), 0)
+		assert.Equal(t, yascli.Run("add", "--branch=topic-a", "--parent=main"), 0)
+		assert.Equal(t, yascli.Run("restack", "--autostash"), 0)
+
+		equalLines(t, mustExecOutput("git", "log", "--pretty=%D : %s"), `
+			HEAD -> topic-a : topic-a-0
+			main : main-1
+			: main-0
+		`)
+
+		equalLines(t, mustExecOutput("git", "status", "--porcelain"), "M a")
+	})
+}
+
+func TestRestackCheck(t *testing.T) {
+	testutil.WithTempWorkingDir(t, func() {
+		testutil.ExecOrFail(t, `
+			git init --initial-branch=main
+
+			# main
+			touch main
+			git add main
+			git commit -m "main-0"
+
+			# topic-a
+			git checkout -b topic-a
+			echo a > main
+			git add main
+			git commit -m "topic-a-0"
+
+			# conflicting update to main
+			git checkout main
+			echo 1 > main
+			git add main
+			git commit -m "main-1"
+		`)
+
+		assert.Equal(t, yascli.Run("config", "set", "--trunk-branch=main"), 0)
+		assert.Equal(t, yascli.Run("add", "--branch=topic-a", "--parent=main"), 0)
+
+		stdout, _, err := testutil.CaptureOutput(func() {
+			assert.Equal(t, yascli.Run("restack", "--check"), 1)
+		})
+		assert.NilError(t, err)
+
+		equalLines(t, stdout, `
+			⚠️  topic-a conflicts with main:
+			main
+		`)
+
+		// Working tree is not touched
+		equalLines(t, mustExecOutput("git", "status", "--porcelain"), "")
+	})
+}
+
+func TestRestackCheckOnlyCurrentBranchAndDescendants(t *testing.T) {
+	testutil.WithTempWorkingDir(t, func() {
+		testutil.ExecOrFail(t, `
+			git init --initial-branch=main
+
+			# main
+			touch main
+			git add main
+			git commit -m "main-0"
+
+			# topic-a
+			git checkout -b topic-a
+			echo a > main
+			git add main
+			git commit -m "topic-a-0"
+
+			# topic-b
+			git checkout -b topic-b
+			touch b
+			git add b
+			git commit -m "topic-b-0"
+
+			# conflicting update to main
+			git checkout main
+			echo 1 > main
+			git add main
+			git commit -m "main-1"
+
+			git checkout topic-b
+		`)
+
+		assert.Equal(t, yascli.Run("config", "set", "--trunk-branch=main"), 0)
+		assert.Equal(t, yascli.Run("add", "--branch=topic-a", "--parent=main"), 0)
+		assert.Equal(t, yascli.Run("add", "--branch=topic-b", "--parent=topic-a"), 0)
+
+		// topic-a is an ancestor of the current branch, so it is not checked
+		stdout, _, err := testutil.CaptureOutput(func() {
+			assert.Equal(t, yascli.Run("restack", "--check"), 0)
+		})
+		assert.NilError(t, err)
+		assert.Assert(t, cmp.Contains(stdout, "No conflicts expected"))
+
+		testutil.ExecOrFail(t, "git checkout topic-a")
+
+		stdout, _, err = testutil.CaptureOutput(func() {
+			assert.Equal(t, yascli.Run("restack", "--check"), 1)
+		})
+		assert.NilError(t, err)
+		assert.Assert(t, cmp.Contains(stdout, "topic-a conflicts with main"))
+	})
+}
+
+func TestRestackRewrittenTrunk(t *testing.T) {
+	testutil.WithTempWorkingDir(t, func() {
+		testutil.ExecOrFail(t, `
+			git init --initial-branch=main
+
+			# main
+			touch main
+			git add main
+			git commit -m "main-0"
+			echo 1 > main
+			git add main
+			git commit -m "main-1"
+		`)
+
+		assert.Equal(t, yascli.Run("config", "set", "--trunk-branch=main"), 0)
+		assert.Equal(t, yascli.Run("branch", "topic-a"), 0)
+
+		testutil.ExecOrFail(t, `
+			touch a
+			git add a
+			git commit -m "topic-a-0"
+
+			# rewrite trunk history
+			git checkout main
+			git commit --amend -m "main-1-rewritten"
+			git checkout topic-a
+		`)
+
+		withStdin(t, "y\n", func() {
+			assert.Equal(t, yascli.Run("restack"), 0)
+		})
+
+		equalLines(t, mustExecOutput("git", "log", "--pretty=%D : %s"), `
+			HEAD -> topic-a : topic-a-0
+			main : main-1-rewritten
+			: main-0
+		`)
+	})
+}
+
+func TestRestackBranch(t *testing.T) {
+	testutil.WithTempWorkingDir(t, func() {
+		testutil.ExecOrFail(t, `
+			git init --initial-branch=main
+
+			# main
+			touch main
+			git add main
+			git commit -m "main-0"
+
+			# topic-a
+			git checkout -b topic-a
+			touch a
+			git add a
+			git commit -m "topic-a-0"
+
+			# topic-b
+			git checkout -b topic-b
+			touch b
+			git add b
+			git commit -m "topic-b-0"
+
+			# topic-c
+			git checkout -b topic-c
+			touch c
+			git add c
+			git commit -m "topic-c-0"
+
+			# update topic-a
+			git checkout topic-a
+			echo 1 > a
+			git add a
+			git commit -m "topic-a-1"
+
+			# local modification on main
+			git checkout main
+			echo dirty > main
+		`)
+
+		assert.Equal(t, yascli.Run("config", "set", "--trunk-branch=main"), 0)
+		assert.Equal(t, yascli.Run("add", "--branch=topic-a", "--parent=main"), 0)
+		assert.Equal(t, yascli.Run("add", "--branch=topic-b", "--parent=topic-a"), 0)
+		assert.Equal(t, yascli.Run("add", "--branch=topic-c", "--parent=topic-b"), 0)
+		assert.Equal(t, yascli.Run("restack", "topic-b"), 0)
+
+		equalLines(t, mustExecOutput("git", "log", "--pretty=%D : %s", "topic-c"), `
+			topic-c : topic-c-0
+			topic-b : topic-b-0
+			topic-a : topic-a-1
+			: topic-a-0
+			HEAD -> main : main-0
+		`)
+
+		// Current checkout is untouched
+		equalLines(t, mustExecOutput("git", "status", "--porcelain"), "M main")
+
+		// Temporary worktree is removed
+		assert.Equal(t, strings.Count(mustExecOutput("git", "worktree", "list"), "\n"), 1)
+	})
+}
+
+// setupConflictingStacks creates two stacks on main, where topic-a conflicts
+// with a new commit on main and topic-b does not.
+func setupConflictingStacks(t *testing.T) {
+	t.Helper()
+
+	testutil.ExecOrFail(t, `
+		git init --initial-branch=main
+
+		# main
+		echo 0 > main
+		git add main
+		git commit -m "main-0"
+
+		# topic-a
+		git checkout -b topic-a
+		echo a > main
+		git add main
+		git commit -m "topic-a-0"
+
+		# topic-a2
+		git checkout -b topic-a2
+		touch a2
+		git add a2
+		git commit -m "topic-a2-0"
+
+		# topic-b
+		git checkout main
+		git checkout -b topic-b
+		touch b
+		git add b
+		git commit -m "topic-b-0"
+
+		# update main
+		git checkout main
+		echo 1 > main
+		git add main
+		git commit -m "main-1"
+	`)
+
+	assert.Equal(t, yascli.Run("config", "set", "--trunk-branch=main"), 0)
+	assert.Equal(t, yascli.Run("add", "--branch=topic-a", "--parent=main"), 0)
+	assert.Equal(t, yascli.Run("add", "--branch=topic-a2", "--parent=topic-a"), 0)
+	assert.Equal(t, yascli.Run("add", "--branch=topic-b", "--parent=main"), 0)
+}
+
+func TestContinueAbortCurrent(t *testing.T) {
+	testutil.WithTempWorkingDir(t, func() {
+		setupConflictingStacks(t)
+
+		_, stderr, err := testutil.CaptureOutput(func() {
+			assert.Equal(t, yascli.Run("restack", "--all"), 1)
+		})
+		assert.NilError(t, err)
+		assert.Assert(t, cmp.Contains(stderr, "yas continue --abort-current"))
+
+		// Another restack can't start while one is in progress
+		assert.Equal(t, yascli.Run("restack", "--all"), 1)
+
+		stdout, _, err := testutil.CaptureOutput(func() {
+			assert.Equal(t, yascli.Run("continue", "--abort-current"), 0)
+		})
+		assert.NilError(t, err)
+		assert.Assert(t, cmp.Contains(stdout, "Skipped: topic-a, topic-a2"))
+
+		equalLines(t, mustExecOutput("git", "log", "--pretty=%D : %s", "topic-b"), `
+			topic-b : topic-b-0
+			HEAD -> main : main-1
+			: main-0
+		`)
+
+		// Skipped branches are untouched
+		equalLines(t, mustExecOutput("git", "log", "--pretty=%D : %s", "topic-a2"), `
+			topic-a2 : topic-a2-0
+			topic-a : topic-a-0
+			: main-0
+		`)
+
+		// The restack is finished
+		assert.Equal(t, yascli.Run("continue"), 1)
+	})
+}
+
+func TestContinue(t *testing.T) {
+	testutil.WithTempWorkingDir(t, func() {
+		setupConflictingStacks(t)
+
+		assert.Equal(t, yascli.Run("restack", "--all"), 1)
+
+		testutil.ExecOrFail(t, `
+			echo resolved > main
+			git add main
+		`)
+
+		assert.Equal(t, yascli.Run("continue"), 0)
+
+		equalLines(t, mustExecOutput("git", "log", "--pretty=%D : %s", "topic-a2"), `
+			topic-a2 : topic-a2-0
+			topic-a : topic-a-0
+			HEAD -> main : main-1
+			: main-0
+		`)
+
+		equalLines(t, mustExecOutput("git", "log", "--pretty=%D : %s", "topic-b"), `
+			topic-b : topic-b-0
+			HEAD -> main : main-1
+			: main-0
+		`)
+	})
+}
+
+func TestRestackContinueOnError(t *testing.T) {
+	testutil.WithTempWorkingDir(t, func() {
+		setupConflictingStacks(t)
+
+		stdout, stderr, err := testutil.CaptureOutput(func() {
+			assert.Equal(t, yascli.Run("restack", "--all", "--continue-on-error"), 1)
+		})
+		assert.NilError(t, err)
+		assert.Assert(t, cmp.Contains(stdout, "1 stack(s) have conflicts and need to be restacked manually:\n    topic-a (conflict in topic-a)\n"))
+		assert.Assert(t, cmp.Contains(stderr, "yas continue"))
+
+		// The unrelated stack was restacked before stopping at the conflict
+		equalLines(t, mustExecOutput("git", "log", "--pretty=%D : %s", "topic-b"), `
+			topic-b : topic-b-0
+			HEAD, main : main-1
+			: main-0
+		`)
+
+		testutil.ExecOrFail(t, `
+			echo resolved > main
+			git add main
+		`)
+
+		assert.Equal(t, yascli.Run("continue"), 0)
+
+		equalLines(t, mustExecOutput("git", "log", "--pretty=%D : %s", "topic-a2"), `
+			topic-a2 : topic-a2-0
+			topic-a : topic-a-0
+			HEAD -> main : main-1
+			: main-0
+		`)
+	})
+}
+
+func TestRestackRemoteParent(t *testing.T) {
+	testutil.WithTempWorkingDir(t, func() {
+		testutil.ExecOrFail(t, `
+			git init --bare --initial-branch=main origin.git
+			git clone -q origin.git repo
+			cd repo
+
+			touch main
+			git add main
+			git commit -m "main-0"
+
+			git checkout -b teammate/feature
+			touch teammate
+			git add teammate
+			git commit -m "teammate-0"
+
+			git push -q origin main teammate/feature
+			git checkout -b topic-a
+			git branch -D teammate/feature
+
+			touch a
+			git add a
+			git commit -m "topic-a-0"
+		`)
+
+		assert.NilError(t, os.Chdir("repo"))
+
+		assert.Equal(t, yascli.Run("config", "set", "--trunk-branch=main"), 0)
+		assert.Equal(t, yascli.Run("add", "--branch=topic-a", "--parent=origin/teammate/feature"), 0)
+
+		stdout, _, err := testutil.CaptureOutput(func() {
+			assert.Equal(t, yascli.Run("list"), 0)
+		})
+		assert.NilError(t, err)
+		equalLines(t, stdout, `
+			main
+			└── origin/teammate/feature [remote]
+			    └── topic-a
+		`)
+
+		// The teammate pushes a new commit
+		testutil.ExecOrFail(t, `
+			git clone -q ../origin.git ../teammate
+			cd ../teammate
+			git checkout -q teammate/feature
+			echo 1 > teammate
+			git commit -q -a -m "teammate-1"
+			git push -q origin teammate/feature
+		`)
+
+		// Not fetched yet, so the remote-tracking branch is unchanged
+		stdout, _, err = testutil.CaptureOutput(func() {
+			assert.Equal(t, yascli.Run("status"), 0)
+		})
+		assert.NilError(t, err)
+		assert.Assert(t, cmp.Contains(stdout, "Parent: origin/teammate/feature\n"))
+		assert.Assert(t, !strings.Contains(stdout, "Needs restack"))
+
+		assert.Equal(t, yascli.Run("restack"), 0)
+
+		equalLines(t, mustExecOutput("git", "log", "--pretty=%D : %s"), `
+			HEAD -> topic-a : topic-a-0
+			origin/teammate/feature : teammate-1
+			: teammate-0
+			origin/main, main : main-0
+		`)
+
+		testutil.ExecOrFail(t, `
+			cd ../teammate
+			echo 2 > teammate
+			git commit -q -a -m "teammate-2"
+			git push -q origin teammate/feature
+			cd ../repo
+			git fetch -q origin
+		`)
+
+		stdout, _, err = testutil.CaptureOutput(func() {
+			assert.Equal(t, yascli.Run("status"), 0)
+		})
+		assert.NilError(t, err)
+		assert.Assert(t, cmp.Contains(stdout, "Needs restack (hint: run `yas restack`)\n"))
+	})
+}
+
+func TestRestackBackfillsBranchPoints(t *testing.T) {
+	testutil.WithTempWorkingDir(t, func() {
+		testutil.ExecOrFail(t, `
+			git init --initial-branch=main
+
+			# main
+			touch main
+			git add main
+			git commit -m "main-0"
+
+			# topic-a
+			git checkout -b topic-a
+			touch a
+			git add a
+			git commit -m "topic-a-0"
+
+			# main advances
+			git checkout main
+			touch main-1
+			git add main-1
+			git commit -m "main-1"
+			git checkout topic-a
+		`)
+
+		assert.Equal(t, yascli.Run("config", "set", "--trunk-branch=main"), 0)
+		assert.Equal(t, yascli.Run("add", "--branch=topic-a", "--parent=main"), 0)
+
+		clearBranchPoints(t)
+
+		stdout, _, err := testutil.CaptureOutput(func() {
+			assert.Equal(t, yascli.Run("restack"), 0)
+		})
+		assert.NilError(t, err)
+		assert.Assert(t, cmp.Contains(stdout, "Branch point not set for topic-a, recorded the merge-base with the parent instead"))
+
+		equalLines(t, mustExecOutput("git", "log", "--pretty=%s", "topic-a", "--"), `
+			topic-a-0
+			main-1
+			main-0
+		`)
+
+		// The branch point is persisted, so the warning isn't repeated
+		stdout, _, err = testutil.CaptureOutput(func() {
+			assert.Equal(t, yascli.Run("restack"), 0)
+		})
+		assert.NilError(t, err)
+		assert.Assert(t, !strings.Contains(stdout, "Branch point not set"))
+	})
+}
+
+func TestRestackAfterManualRebase(t *testing.T) {
+	testutil.WithTempWorkingDir(t, func() {
+		testutil.ExecOrFail(t, `
+			git init --initial-branch=main
+
+			# main
+			touch main
+			git add main
+			git commit -m "main-0"
+
+			# topic-a
+			git checkout -b topic-a
+			touch a
+			git add a
+			git commit -m "topic-a-0"
+
+			# topic-b
+			git checkout -b topic-b
+			touch b
+			git add b
+			git commit -m "topic-b-0"
+		`)
+
+		assert.Equal(t, yascli.Run("config", "set", "--trunk-branch=main"), 0)
+		assert.Equal(t, yascli.Run("add", "--branch=topic-a", "--parent=main"), 0)
+		assert.Equal(t, yascli.Run("add", "--branch=topic-b", "--parent=topic-a"), 0)
+
+		// Rebase topic-b off topic-a outside of yas, so its recorded branch
+		// point (the tip of topic-a) is no longer in the branch
+		testutil.ExecOrFail(t, `git rebase -q --onto main topic-a topic-b`)
+
+		state, err := os.ReadFile(".git/.yasstate")
+		assert.NilError(t, err)
+
+		assert.Equal(t, yascli.Run("list"), 0)
+
+		stdout, _, err := testutil.CaptureOutput(func() {
+			assert.Equal(t, yascli.Run("status"), 0)
+		})
+		assert.NilError(t, err)
+		assert.Assert(t, cmp.Contains(stdout, "Needs restack"))
+
+		// The branch point is only repaired in memory by commands that read
+		// the state, since they don't hold the repository lock
+		newState, err := os.ReadFile(".git/.yasstate")
+		assert.NilError(t, err)
+		assert.Equal(t, string(newState), string(state))
+
+		assert.Equal(t, yascli.Run("restack"), 0)
+
+		equalLines(t, mustExecOutput("git", "log", "--pretty=%s", "topic-b", "--"), `
+			topic-b-0
+			topic-a-0
+			main-0
+		`)
+
+		stdout, _, err = testutil.CaptureOutput(func() {
+			assert.Equal(t, yascli.Run("status"), 0)
+		})
+		assert.NilError(t, err)
+		assert.Assert(t, !strings.Contains(stdout, "Needs restack"))
+	})
+}
+
+func TestRestackRefusesDirtyWorktree(t *testing.T) {
+	testutil.WithTempWorkingDir(t, func() {
+		testutil.ExecOrFail(t, `
+			git init --initial-branch=main
+
+			touch main
+			git add main
+			git commit -m "main-0"
+
+			git checkout -b topic-a
+			touch a
+			git add a
+			git commit -m "topic-a-0"
+
+			git checkout -b topic-b
+			touch b
+			git add b
+			git commit -m "topic-b-0"
+
+			git checkout main
+			echo 1 > main
+			git commit -a -m "main-1"
+
+			git checkout topic-a
+		`)
+
+		assert.Equal(t, yascli.Run("config", "set", "--trunk-branch=main"), 0)
+		assert.Equal(t, yascli.Run("add", "--branch=topic-a", "--parent=main"), 0)
+		assert.Equal(t, yascli.Run("add", "--branch=topic-b", "--parent=topic-a"), 0)
+
+		oldTip := mustGetShortHash("topic-a")
+		oldChildTip := mustGetShortHash("topic-b")
+
+		// Uncommitted changes in the current worktree
+		testutil.ExecOrFail(t, "echo 1 > a")
+
+		assert.Equal(t, yascli.Run("restack"), 1)
+		assert.Equal(t, yascli.Run("move", "--onto=main", "topic-b"), 1)
+		assert.Equal(t, mustGetShortHash("topic-a"), oldTip)
+		assert.Equal(t, mustGetShortHash("topic-b"), oldChildTip)
+
+		// Uncommitted changes in a linked worktree that a branch in the
+		// stack is checked out in
+		testutil.ExecOrFail(t, `
+			git checkout a
+			echo wt >> .git/info/exclude
+			git worktree add wt topic-b
+			echo 1 > wt/b
+		`)
+
+		_, stderr, err := testutil.CaptureOutput(func() {
+			assert.Equal(t, yascli.Run("restack", "--autostash"), 1)
+		})
+		assert.NilError(t, err)
+		assert.Assert(t, cmp.Contains(stderr, "'topic-b' is checked out in worktree"))
+		assert.Equal(t, mustGetShortHash("topic-a"), oldTip)
+
+		testutil.ExecOrFail(t, "git worktree remove --force wt")
+
+		assert.Equal(t, yascli.Run("restack"), 0)
+		equalLines(t, mustExecOutput("git", "log", "--pretty=%D : %s", "topic-b"), `
+			topic-b : topic-b-0
+			HEAD -> topic-a : topic-a-0
+			main : main-1
+			: main-0
+		`)
+	})
+}
+
+func TestRestackProgress(t *testing.T) {
+	testutil.WithTempWorkingDir(t, func() {
+		testutil.ExecOrFail(t, `
+			git init --initial-branch=main
+
+			touch main
+			git add main
+			git commit -m "main-0"
+
+			git checkout -b topic-a
+			touch a
+			git add a
+			git commit -m "topic-a-0"
+
+			git checkout -b topic-b
+			touch b
+			git add b
+			git commit -m "topic-b-0"
+
+			git checkout main
+			echo 1 > main
+			git commit -a -m "main-1"
+
+			git checkout topic-b
+		`)
+
+		assert.Equal(t, yascli.Run("config", "set", "--trunk-branch=main"), 0)
+		assert.Equal(t, yascli.Run("add", "--branch=topic-a", "--parent=main"), 0)
+		assert.Equal(t, yascli.Run("add", "--branch=topic-b", "--parent=topic-a"), 0)
+
+		stdout, _, err := testutil.CaptureOutput(func() {
+			assert.Equal(t, yascli.Run("restack"), 0)
+		})
+		assert.NilError(t, err)
+		assert.Assert(t, cmp.Contains(stdout, "[1/2] Rebasing topic-a onto main…"))
+		assert.Assert(t, cmp.Contains(stdout, "[2/2] Rebasing topic-b onto topic-a…"))
+		assert.Assert(t, cmp.Contains(stdout, "topic-a | rebased"))
+		assert.Assert(t, cmp.Contains(stdout, "Done in "))
+
+		// Nothing to rebase the second time
+		stdout, _, err = testutil.CaptureOutput(func() {
+			assert.Equal(t, yascli.Run("restack"), 0)
+		})
+		assert.NilError(t, err)
+		assert.Assert(t, cmp.Contains(stdout, "[1/2] topic-a is up to date with main\n"))
+		assert.Assert(t, cmp.Contains(stdout, "topic-b | up to date"))
+	})
+}
+
+func TestRestackMovesChildrenOffMergedBranch(t *testing.T) {
+	testutil.WithTempWorkingDir(t, func() {
+		withFakeGHScript(t, `
+			case "$4" in
+			topic-a) echo '[{"id":"PR_1","number":1,"state":"MERGED","url":"https://github.com/test/test/pull/1","baseRefName":"main"}]' ;;
+			topic-b) echo '[{"id":"PR_2","number":2,"state":"OPEN","url":"https://github.com/test/test/pull/2","baseRefName":"topic-a"}]' ;;
+			*) echo '[]' ;;
+			esac
+		`)
+
+		testutil.ExecOrFail(t, `
+			git init --initial-branch=main repo
+			cd repo
+
+			touch main
+			git add main
+			git commit -m "main-0"
+
+			git checkout -b topic-a
+			touch a
+			git add a
+			git commit -m "topic-a-0"
+
+			git checkout -b topic-b
+			touch b
+			git add b
+			git commit -m "topic-b-0"
+
+			# topic-a is squash-merged, but not deleted locally
+			git checkout main
+			git merge --squash topic-a
+			git commit -m "topic-a (#1)"
+
+			git checkout topic-b
+		`)
+
+		assert.NilError(t, os.Chdir("repo"))
+
+		assert.Equal(t, yascli.Run("config", "set", "--trunk-branch=main"), 0)
+		assert.Equal(t, yascli.Run("add", "--branch=topic-a", "--parent=main"), 0)
+		assert.Equal(t, yascli.Run("add", "--branch=topic-b", "--parent=topic-a"), 0)
+		assert.Equal(t, yascli.Run("refresh", "--stack"), 0)
+
+		stdout, _, err := testutil.CaptureOutput(func() {
+			assert.Equal(t, yascli.Run("list"), 0)
+		})
+		assert.NilError(t, err)
+		equalLines(t, stdout, `
+			main
+			└── topic-a (merged)
+			    └── topic-b
+		`)
+
+		stdout, _, err = testutil.CaptureOutput(func() {
+			assert.Equal(t, yascli.Run("status"), 0)
+		})
+		assert.NilError(t, err)
+		assert.Assert(t, cmp.Contains(stdout, "Needs restack"))
+
+		topicA := mustExecOutput("git", "rev-parse", "topic-a")
+
+		stdout, _, err = testutil.CaptureOutput(func() {
+			assert.Equal(t, yascli.Run("restack"), 0)
+		})
+		assert.NilError(t, err)
+		assert.Assert(t, cmp.Contains(stdout, "Set 'main' as parent of 'topic-b' (topic-a was merged)"))
+
+		equalLines(t, mustExecOutput("git", "log", "--pretty=%s", "topic-b", "--"), `
+			topic-b-0
+			topic-a (#1)
+			main-0
+		`)
+
+		// The merged branch is left alone
+		assert.Equal(t, mustExecOutput("git", "rev-parse", "topic-a"), topicA)
+
+		stdout, _, err = testutil.CaptureOutput(func() {
+			assert.Equal(t, yascli.Run("list"), 0)
+		})
+		assert.NilError(t, err)
+		equalLines(t, stdout, `
+			main
+			├── topic-a (merged)
+			└── topic-b
+		`)
+	})
+}
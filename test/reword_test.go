@@ -0,0 +1,101 @@
+package test
+
+import (
+	"os"
+	"path"
+	"testing"
+
+	"github.com/dansimau/yas/pkg/testutil"
+	"github.com/dansimau/yas/pkg/yascli"
+	"gotest.tools/v3/assert"
+)
+
+func TestReword(t *testing.T) {
+	testutil.WithTempWorkingDir(t, func() {
+		testutil.ExecOrFail(t, `
+			git init --initial-branch=main
+
+			# main
+			touch main
+			git add main
+			git commit -m "main-0"
+
+			# topic-a
+			git checkout -b topic-a
+			touch a
+			git add a
+			git commit -m "topic-a-0"
+			touch a1
+			git add a1
+			git commit -m "topic-a-1"
+
+			# topic-b
+			git checkout -b topic-b
+			touch b
+			git add b
+			git commit -m "topic-b-0"
+
+			git checkout topic-a
+
+			cat > .git/editor.sh <<-'EOF'
+			#!/bin/sh
+			sed -i 's/topic-a-0/topic-a-reworded/' "$1"
+			EOF
+			chmod +x .git/editor.sh
+		`)
+
+		wd, err := os.Getwd()
+		assert.NilError(t, err)
+		t.Setenv("GIT_EDITOR", path.Join(wd, ".git/editor.sh"))
+
+		assert.Equal(t, yascli.Run("config", "set", "--trunk-branch=main"), 0)
+		assert.Equal(t, yascli.Run("add", "--branch=topic-a", "--parent=main"), 0)
+		assert.Equal(t, yascli.Run("add", "--branch=topic-b", "--parent=topic-a"), 0)
+		assert.Equal(t, yascli.Run("reword"), 0)
+
+		equalLines(t, mustExecOutput("git", "log", "--pretty=%D : %s", "topic-b"), `
+			topic-b : topic-b-0
+			HEAD -> topic-a : topic-a-1
+			: topic-a-reworded
+			main : main-0
+		`)
+	})
+}
+
+func TestRewordEditorWithArguments(t *testing.T) {
+	testutil.WithTempWorkingDir(t, func() {
+		testutil.ExecOrFail(t, `
+			git init --initial-branch=main
+
+			touch main
+			git add main
+			git commit -m "main-0"
+
+			git checkout -b topic-a
+			touch a
+			git add a
+			git commit -m "topic-a-0"
+
+			cat > .git/editor.sh <<-'EOF'
+			#!/bin/sh
+			sed -i "s/topic-a-0/$1/" "$2"
+			EOF
+			chmod +x .git/editor.sh
+		`)
+
+		wd, err := os.Getwd()
+		assert.NilError(t, err)
+
+		// The configured editor overrides the editor from the environment
+		t.Setenv("GIT_EDITOR", "false")
+
+		assert.Equal(t, yascli.Run("config", "set", "--trunk-branch=main", "--editor="+path.Join(wd, ".git/editor.sh")+" topic-a-reworded"), 0)
+		assert.Equal(t, yascli.Run("add", "--branch=topic-a", "--parent=main"), 0)
+		assert.Equal(t, yascli.Run("reword"), 0)
+
+		equalLines(t, mustExecOutput("git", "log", "--pretty=%D : %s"), `
+			HEAD -> topic-a : topic-a-reworded
+			main : main-0
+		`)
+	})
+}
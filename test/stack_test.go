@@ -0,0 +1,305 @@
+package test
+
+import (
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/dansimau/yas/pkg/testutil"
+	"github.com/dansimau/yas/pkg/yascli"
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/assert/cmp"
+)
+
+func TestStackListAndRestack(t *testing.T) {
+	testutil.WithTempWorkingDir(t, func() {
+		testutil.ExecOrFail(t, `
+			git init --initial-branch=main
+
+			touch main
+			git add main
+			git commit -m "main-0"
+
+			git checkout -b topic-a
+			touch a
+			git add a
+			git commit -m "topic-a-0"
+
+			git checkout -b topic-b
+			touch b
+			git add b
+			git commit -m "topic-b-0"
+
+			git checkout -b other main
+			touch other
+			git add other
+			git commit -m "other-0"
+
+			git checkout main
+			echo 1 > main
+			git commit -a -m "main-1"
+
+			git checkout other
+		`)
+
+		assert.Equal(t, yascli.Run("config", "set", "--trunk-branch=main"), 0)
+		assert.Equal(t, yascli.Run("add", "--branch=topic-a", "--parent=main"), 0)
+		assert.Equal(t, yascli.Run("add", "--branch=topic-b", "--parent=topic-a"), 0)
+		assert.Equal(t, yascli.Run("add", "--branch=other", "--parent=main"), 0)
+
+		// Defaults to the current stack
+		stdout, _, err := testutil.CaptureOutput(func() {
+			assert.Equal(t, yascli.Run("stack", "list"), 0)
+		})
+		assert.NilError(t, err)
+		equalLines(t, stdout, `
+			main
+			└── other
+		`)
+
+		stdout, _, err = testutil.CaptureOutput(func() {
+			assert.Equal(t, yascli.Run("stack", "list", "topic-b"), 0)
+		})
+		assert.NilError(t, err)
+		equalLines(t, stdout, `
+			main
+			└── topic-a
+			    └── topic-b
+		`)
+
+		// Restacking another stack leaves the current branch alone
+		assert.Equal(t, yascli.Run("stack", "restack", "topic-a"), 0)
+
+		equalLines(t, mustExecOutput("git", "log", "--pretty=%D : %s", "topic-b"), `
+			topic-b : topic-b-0
+			topic-a : topic-a-0
+			main : main-1
+			: main-0
+		`)
+
+		equalLines(t, mustExecOutput("git", "log", "--pretty=%D : %s"), `
+			HEAD -> other : other-0
+			: main-0
+		`)
+	})
+}
+
+func TestStackMergeLocal(t *testing.T) {
+	t.Setenv("GIT_EDITOR", "true")
+
+	testutil.WithTempWorkingDir(t, func() {
+		testutil.ExecOrFail(t, `
+			git init --initial-branch=main
+
+			touch main
+			git add main
+			git commit -m "main-0"
+
+			git checkout -b topic-a
+			touch a
+			git add a
+			git commit -m "topic-a-0"
+
+			git checkout -b topic-b
+			touch b
+			git add b
+			git commit -m "topic-b-0"
+		`)
+
+		assert.Equal(t, yascli.Run("config", "set", "--trunk-branch=main"), 0)
+		assert.Equal(t, yascli.Run("add", "--branch=topic-a", "--parent=main"), 0)
+		assert.Equal(t, yascli.Run("add", "--branch=topic-b", "--parent=topic-a"), 0)
+		assert.Equal(t, yascli.Run("stack", "merge", "--local"), 0)
+
+		equalLines(t, mustExecOutput("git", "log", "--pretty=%D : %s"), `
+			HEAD -> main : topic-b-0
+			: topic-a-0
+			: main-0
+		`)
+
+		equalLines(t, mustExecOutput("git", "branch", "--format=%(refname:short)"), "main")
+	})
+}
+
+func TestStackName(t *testing.T) {
+	testutil.WithTempWorkingDir(t, func() {
+		testutil.ExecOrFail(t, `
+			git init --initial-branch=main
+
+			touch main
+			git add main
+			git commit -m "main-0"
+
+			git checkout -b topic-a
+			touch a
+			git add a
+			git commit -m "topic-a-0"
+
+			git checkout -b topic-b
+			touch b
+			git add b
+			git commit -m "topic-b-0"
+
+			git checkout -b other main
+			touch other
+			git add other
+			git commit -m "other-0"
+
+			git checkout main
+			echo 1 > main
+			git commit -a -m "main-1"
+
+			git checkout other
+		`)
+
+		assert.Equal(t, yascli.Run("config", "set", "--trunk-branch=main"), 0)
+		assert.Equal(t, yascli.Run("add", "--branch=topic-a", "--parent=main"), 0)
+		assert.Equal(t, yascli.Run("add", "--branch=topic-b", "--parent=topic-a"), 0)
+		assert.Equal(t, yascli.Run("add", "--branch=other", "--parent=main"), 0)
+
+		assert.Equal(t, yascli.Run("stack", "name", "--branch=topic-b", "payments-refactor"), 0)
+
+		// Names are unique
+		assert.Equal(t, yascli.Run("stack", "name", "payments-refactor"), 1)
+
+		stdout, _, err := testutil.CaptureOutput(func() {
+			assert.Equal(t, yascli.Run("stack", "name", "--branch=topic-a"), 0)
+		})
+		assert.NilError(t, err)
+		equalLines(t, stdout, "payments-refactor")
+
+		stdout, _, err = testutil.CaptureOutput(func() {
+			assert.Equal(t, yascli.Run("list"), 0)
+		})
+		assert.NilError(t, err)
+		equalLines(t, stdout, `
+			main
+			├── other
+			└── 📚 payments-refactor
+			    └── topic-a
+			        └── topic-b
+		`)
+
+		stdout, _, err = testutil.CaptureOutput(func() {
+			assert.Equal(t, yascli.Run("list", "--stack", "payments-refactor"), 0)
+		})
+		assert.NilError(t, err)
+		equalLines(t, stdout, `
+			main
+			└── 📚 payments-refactor
+			    └── topic-a
+			        └── topic-b
+		`)
+
+		assert.Equal(t, yascli.Run("restack", "--stack", "payments-refactor"), 0)
+
+		equalLines(t, mustExecOutput("git", "log", "--pretty=%s", "topic-b", "--"), `
+			topic-b-0
+			topic-a-0
+			main-1
+			main-0
+		`)
+		equalLines(t, mustExecOutput("git", "branch", "--show-current"), "other")
+
+		assert.Equal(t, yascli.Run("stack", "name", "--branch=topic-a", "--clear"), 0)
+		assert.Equal(t, yascli.Run("stack", "name", "--branch=topic-a"), 1)
+	})
+}
+
+func TestStackWIP(t *testing.T) {
+	testutil.WithTempWorkingDir(t, func() {
+		wd, err := os.Getwd()
+		assert.NilError(t, err)
+		ghLog := path.Join(wd, "gh.log")
+
+		withFakeGHScript(t, `
+			echo "$@" >> `+ghLog+`
+			case "$2" in
+			list)
+				echo '[]'
+				;;
+			esac
+		`)
+
+		testutil.ExecOrFail(t, `
+			git init -q --bare --initial-branch=main origin.git
+			git clone -q origin.git repo
+			cd repo
+
+			touch main
+			git add main
+			git commit -m "main-0"
+			git push -q origin main
+
+			git checkout -b topic-a
+			touch a
+			git add a
+			git commit -m "topic-a-0"
+
+			git checkout -b topic-b
+			touch b
+			git add b
+			git commit -m "topic-b-0"
+		`)
+
+		assert.NilError(t, os.Chdir("repo"))
+
+		assert.Equal(t, yascli.Run("config", "set", "--trunk-branch=main"), 0)
+		assert.Equal(t, yascli.Run("add", "--branch=topic-a", "--parent=main"), 0)
+		assert.Equal(t, yascli.Run("add", "--branch=topic-b", "--parent=topic-a"), 0)
+		assert.Equal(t, yascli.Run("stack", "wip", "topic-a"), 0)
+
+		stdout, _, err := testutil.CaptureOutput(func() {
+			assert.Equal(t, yascli.Run("list"), 0)
+		})
+		assert.NilError(t, err)
+		equalLines(t, stdout, `
+			main
+			└── topic-a [wip]
+			    └── topic-b [wip]
+		`)
+
+		// Branches added to the stack later are also WIP
+		assert.Equal(t, yascli.Run("branch", "topic-c"), 0)
+		testutil.ExecOrFail(t, `
+			touch c
+			git add c
+			git commit -m "topic-c-0"
+		`)
+
+		for _, args := range [][]string{
+			{"submit"},
+			{"submit", "--stack"},
+			{"stack", "submit"},
+			{"merge", "--local"},
+			{"stack", "merge", "--local"},
+		} {
+			_, stderr, err := testutil.CaptureOutput(func() {
+				assert.Equal(t, yascli.Run(args...), 1, args)
+			})
+			assert.NilError(t, err)
+			assert.Assert(t, cmp.Contains(stderr, "is marked as WIP"), args)
+		}
+
+		_, err = os.Stat(ghLog)
+		assert.Assert(t, os.IsNotExist(err), "gh should not have been called")
+		equalLines(t, mustExecOutput("git", "log", "--pretty=%s", "main", "--"), "main-0")
+
+		assert.Equal(t, yascli.Run("submit", "--force"), 0)
+
+		b, err := os.ReadFile(ghLog)
+		assert.NilError(t, err)
+		assert.Assert(t, cmp.Contains(string(b), "pr create --head topic-c --base topic-b"))
+
+		assert.Equal(t, yascli.Run("stack", "wip", "--off"), 0)
+
+		stdout, _, err = testutil.CaptureOutput(func() {
+			assert.Equal(t, yascli.Run("list", "--porcelain"), 0)
+		})
+		assert.NilError(t, err)
+		assert.Assert(t, !strings.Contains(stdout, "wip"))
+
+		assert.Equal(t, yascli.Run("submit", "--stack"), 0)
+	})
+}
@@ -0,0 +1,95 @@
+package test
+
+import (
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/dansimau/yas/pkg/ghstub"
+	"github.com/dansimau/yas/pkg/testutil"
+	"github.com/dansimau/yas/pkg/yascli"
+	"gotest.tools/v3/assert"
+)
+
+func TestStats(t *testing.T) {
+	testutil.WithTempWorkingDir(t, func() {
+		wd, err := os.Getwd()
+		assert.NilError(t, err)
+		stubFile := path.Join(wd, "github.json")
+		t.Setenv("YAS_GH_STUB", stubFile)
+
+		testutil.ExecOrFail(t, `
+			git init -q --initial-branch=main repo
+			cd repo
+
+			touch main
+			git add main
+			git commit -m "main-0"
+
+			git branch topic-a
+			git branch topic-b
+			git branch topic-c
+			git branch topic-d
+		`)
+
+		assert.NilError(t, os.Chdir("repo"))
+
+		assert.Equal(t, yascli.Run("config", "set", "--trunk-branch=main"), 0)
+		assert.Equal(t, yascli.Run("add", "--branch=topic-a", "--parent=main"), 0)
+		assert.Equal(t, yascli.Run("add", "--branch=topic-b", "--parent=main"), 0)
+		assert.Equal(t, yascli.Run("add", "--branch=topic-c", "--parent=main"), 0)
+		assert.Equal(t, yascli.Run("add", "--branch=topic-d", "--parent=topic-c"), 0)
+
+		stub, err := ghstub.Load(stubFile)
+		assert.NilError(t, err)
+
+		now := time.Now().UTC()
+		days := func(n int) time.Time { return now.Add(-time.Duration(n) * 24 * time.Hour) }
+
+		for _, merged := range []struct {
+			head                string
+			createdAt, mergedAt time.Time
+		}{
+			// Merged within the period
+			{"topic-a", days(5), days(3)},
+			// Merged before the period
+			{"topic-b", days(50), days(40)},
+			// Not managed by yas
+			{"other", days(2), days(1)},
+		} {
+			pr, err := stub.Create(merged.head, "main", merged.head, "", false)
+			assert.NilError(t, err)
+
+			pr.State = "MERGED"
+			pr.CreatedAt = merged.createdAt
+			pr.MergedAt = merged.mergedAt
+		}
+
+		assert.NilError(t, stub.Save())
+
+		stdout, _, err := testutil.CaptureOutput(func() {
+			assert.Equal(t, yascli.Run("stats", "--days=30"), 0)
+		})
+		assert.NilError(t, err)
+
+		equalLines(t, stdout, `
+			Open stacks:            3
+			Average stack depth:    1.3
+			PRs merged (30 days):   1
+			Average time to merge:  2d 0h
+		`)
+
+		stdout, _, err = testutil.CaptureOutput(func() {
+			assert.Equal(t, yascli.Run("stats", "--days=60"), 0)
+		})
+		assert.NilError(t, err)
+
+		equalLines(t, stdout, `
+			Open stacks:            3
+			Average stack depth:    1.3
+			PRs merged (60 days):   2
+			Average time to merge:  6d 0h
+		`)
+	})
+}
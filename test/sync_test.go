@@ -0,0 +1,166 @@
+package test
+
+import (
+	"os"
+	"path"
+	"testing"
+
+	"github.com/dansimau/yas/pkg/testutil"
+	"github.com/dansimau/yas/pkg/yascli"
+	"gotest.tools/v3/assert"
+)
+
+func TestSyncRestack(t *testing.T) {
+	withFakeGH(t, "[]")
+
+	testutil.WithTempWorkingDir(t, func() {
+		testutil.ExecOrFail(t, `
+			git init --bare --initial-branch=main origin.git
+			git clone -q origin.git repo
+			cd repo
+
+			# main
+			touch main
+			git add main
+			git commit -m "main-0"
+			git push -q origin main
+
+			# topic-a
+			git checkout -b topic-a
+			touch a
+			git add a
+			git commit -m "topic-a-0"
+
+			# new commit on the remote main
+			git clone -q ../origin.git ../other
+			cd ../other
+			echo 1 > main
+			git add main
+			git commit -m "main-1"
+			git push -q origin main
+		`)
+
+		assert.NilError(t, os.Chdir("repo"))
+
+		assert.Equal(t, yascli.Run("config", "set", "--trunk-branch=main"), 0)
+		assert.Equal(t, yascli.Run("add", "--branch=topic-a", "--parent=main"), 0)
+		assert.Equal(t, yascli.Run("sync", "--restack"), 0)
+
+		equalLines(t, mustExecOutput("git", "log", "--pretty=%D : %s", "topic-a"), `
+			HEAD -> topic-a : topic-a-0
+			origin/main, main : main-1
+			: main-0
+		`)
+	})
+}
+
+func TestSyncRefusesDivergedTrunk(t *testing.T) {
+	withFakeGH(t, "[]")
+
+	testutil.WithTempWorkingDir(t, func() {
+		testutil.ExecOrFail(t, `
+			git init --bare --initial-branch=main origin.git
+			git clone -q origin.git repo
+			cd repo
+
+			touch main
+			git add main
+			git commit -m "main-0"
+			git push -q origin main
+
+			# local-only commit on main
+			echo local > main
+			git commit -am "main-local"
+
+			git clone -q ../origin.git ../other
+			cd ../other
+			echo 1 > main
+			git add main
+			git commit -m "main-1"
+			git push -q origin main
+		`)
+
+		assert.NilError(t, os.Chdir("repo"))
+
+		assert.Equal(t, yascli.Run("config", "set", "--trunk-branch=main"), 0)
+		assert.Equal(t, yascli.Run("sync"), 1)
+
+		equalLines(t, mustExecOutput("git", "log", "--pretty=%s", "main", "--"), `
+			main-local
+			main-0
+		`)
+	})
+}
+
+func TestSyncRetargetsChildrenOfMergedBranch(t *testing.T) {
+	testutil.WithTempWorkingDir(t, func() {
+		wd, err := os.Getwd()
+		assert.NilError(t, err)
+		prDir := path.Join(wd, "prs")
+		assert.NilError(t, os.Mkdir(prDir, 0o755))
+		editLog := path.Join(wd, "edits")
+
+		// Responds with the contents of prs/<branch>.json, and logs edits
+		withFakeGHScript(t, `
+			case "$2" in
+			edit)
+				echo "$@" >> `+editLog+`
+				;;
+			*)
+				cat `+prDir+`/"$4".json 2>/dev/null || echo '[]'
+				;;
+			esac
+		`)
+
+		writePR := func(branch, json string) {
+			assert.NilError(t, os.WriteFile(path.Join(prDir, branch+".json"), []byte(json), 0o644))
+		}
+
+		testutil.ExecOrFail(t, `
+			git init --bare --initial-branch=main origin.git
+			git clone -q origin.git repo
+			cd repo
+
+			touch main
+			git add main
+			git commit -m "main-0"
+			git push -q origin main
+
+			git checkout -b topic-a
+			touch a
+			git add a
+			git commit -m "topic-a-0"
+
+			git checkout -b topic-b
+			touch b
+			git add b
+			git commit -m "topic-b-0"
+		`)
+
+		assert.NilError(t, os.Chdir("repo"))
+
+		assert.Equal(t, yascli.Run("config", "set", "--trunk-branch=main"), 0)
+		assert.Equal(t, yascli.Run("add", "--branch=topic-a", "--parent=main"), 0)
+		assert.Equal(t, yascli.Run("add", "--branch=topic-b", "--parent=topic-a"), 0)
+
+		writePR("topic-a", `[{"id":"PR_1","state":"OPEN","url":"https://github.com/test/test/pull/1","baseRefName":"main"}]`)
+		writePR("topic-b", `[{"id":"PR_2","state":"OPEN","url":"https://github.com/test/test/pull/2","baseRefName":"topic-a"}]`)
+		assert.Equal(t, yascli.Run("refresh", "--all"), 0)
+
+		writePR("topic-a", `[{"id":"PR_1","state":"MERGED","url":"https://github.com/test/test/pull/1","baseRefName":"main"}]`)
+		assert.Equal(t, yascli.Run("sync"), 0)
+
+		b, err := os.ReadFile(editLog)
+		assert.NilError(t, err)
+		equalLines(t, string(b), "pr edit topic-b --base main")
+
+		stdout, _, err := testutil.CaptureOutput(func() {
+			assert.Equal(t, yascli.Run("list"), 0)
+		})
+		assert.NilError(t, err)
+		equalLines(t, stdout, `
+			main
+			└── topic-b
+		`)
+	})
+}
@@ -1,6 +1,9 @@
 package test
 
 import (
+	"encoding/json"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -64,3 +67,63 @@ func stripWhiteSpaceFromLines(s string) string {
 	}
 	return strings.Join(lines, "\n")
 }
+
+// withStdin temporarily replaces os.Stdin with a pipe containing the specified
+// input while fn runs.
+func withStdin(t *testing.T, input string, fn func()) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := w.WriteString(input); err != nil {
+		t.Fatal(err)
+	}
+	w.Close()
+
+	prevStdin := os.Stdin
+	os.Stdin = r
+
+	defer func() {
+		os.Stdin = prevStdin
+		r.Close()
+	}()
+
+	fn()
+}
+
+// withFakeGH puts a stub gh executable on the PATH that prints the specified
+// output for every invocation.
+func withFakeGH(t *testing.T, output string) {
+	withFakeGHScript(t, "cat <<'EOF'\n"+output+"\nEOF")
+}
+
+// withFakeGHScript puts a stub gh executable on the PATH that runs the
+// specified shell script.
+func withFakeGHScript(t *testing.T, script string) {
+	binDir := t.TempDir()
+
+	if err := os.WriteFile(filepath.Join(binDir, "gh"), []byte("#!/bin/sh\n"+script+"\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+}
+
+// clearBranchPoints removes the branch points of all branches from the yas
+// state, as if the branches were tracked before branch points were recorded.
+func clearBranchPoints(t *testing.T) {
+	b, err := os.ReadFile(".git/.yasstate")
+	assert.NilError(t, err)
+
+	state := map[string]any{}
+	assert.NilError(t, json.Unmarshal(b, &state))
+
+	for _, branch := range state["branches"].(map[string]any) {
+		delete(branch.(map[string]any), "BranchPoint")
+	}
+
+	b, err = json.Marshal(state)
+	assert.NilError(t, err)
+	assert.NilError(t, os.WriteFile(".git/.yasstate", b, 0o644))
+}
@@ -0,0 +1,210 @@
+package test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dansimau/yas/pkg/testutil"
+	"github.com/dansimau/yas/pkg/yascli"
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/assert/cmp"
+)
+
+func TestWorktreeList(t *testing.T) {
+	testutil.WithTempWorkingDir(t, func() {
+		testutil.ExecOrFail(t, `
+			git init --initial-branch=main repo
+			cd repo
+
+			touch main
+			git add main
+			git commit -m "main-0"
+
+			git checkout -b topic-a
+			touch a
+			git add a
+			git commit -m "topic-a-0"
+
+			git checkout -b topic-b
+			touch b
+			git add b
+			git commit -m "topic-b-0"
+
+			git checkout topic-a
+			git worktree add -q ../wt-b topic-b
+		`)
+
+		assert.NilError(t, os.Chdir("repo"))
+
+		wd, err := os.Getwd()
+		assert.NilError(t, err)
+		wd, err = filepath.EvalSymlinks(wd)
+		assert.NilError(t, err)
+		worktreePath := filepath.Join(filepath.Dir(wd), "wt-b")
+
+		assert.Equal(t, yascli.Run("config", "set", "--trunk-branch=main"), 0)
+		assert.Equal(t, yascli.Run("add", "--branch=topic-a", "--parent=main"), 0)
+		assert.Equal(t, yascli.Run("add", "--branch=topic-b", "--parent=topic-a"), 0)
+		assert.Equal(t, yascli.Run("branch", "topic-c"), 0) // checks out topic-c
+
+		stdout, _, err := testutil.CaptureOutput(func() {
+			assert.Equal(t, yascli.Run("worktree", "list"), 0)
+		})
+		assert.NilError(t, err)
+		equalLines(t, stdout, `
+			topic-a  -
+			topic-b  `+worktreePath+`
+			topic-c  `+wd+`
+		`)
+
+		// Only linked worktrees are shown in the list
+		stdout, _, err = testutil.CaptureOutput(func() {
+			assert.Equal(t, yascli.Run("list", "--verbose"), 0)
+		})
+		assert.NilError(t, err)
+		assert.Assert(t, cmp.Contains(stdout, "topic-b (+0 -0, 1 files) created just now, last commit just now [worktree: "+worktreePath+"]\n"))
+		assert.Assert(t, cmp.Contains(stdout, "topic-c (+0 -0, 0 files) created just now, last commit just now\n"))
+
+		stdout, _, err = testutil.CaptureOutput(func() {
+			assert.Equal(t, yascli.Run("status"), 0)
+		})
+		assert.NilError(t, err)
+		equalLines(t, stdout, `
+			On branch topic-c
+			Parent: topic-a
+			Worktree: `+wd+`
+		`)
+	})
+}
+
+func TestWorktreeAddRemove(t *testing.T) {
+	testutil.WithTempWorkingDir(t, func() {
+		testutil.ExecOrFail(t, `
+			git init -q --bare --initial-branch=main origin.git
+			git clone -q origin.git repo
+			cd repo
+
+			touch main
+			git add main
+			git commit -m "main-0"
+			git push -q origin main
+
+			git checkout -b topic-a
+			touch a
+			git add a
+			git commit -m "topic-a-0"
+
+			git checkout -b topic-b
+			touch b
+			git add b
+			git commit -m "topic-b-0"
+			git push -q origin topic-b
+
+			git checkout topic-a
+			git branch -D topic-b
+		`)
+
+		assert.NilError(t, os.Chdir("repo"))
+
+		wd, err := os.Getwd()
+		assert.NilError(t, err)
+		wd, err = filepath.EvalSymlinks(wd)
+		assert.NilError(t, err)
+
+		assert.Equal(t, yascli.Run("config", "set", "--trunk-branch=main", "--worktree-dir=../wt"), 0)
+		assert.Equal(t, yascli.Run("add", "--branch=topic-a", "--parent=main"), 0)
+		assert.Equal(t, yascli.Run("add", "--branch=topic-b", "--parent=topic-a"), 0)
+
+		// Already checked out in the main worktree
+		assert.Equal(t, yascli.Run("worktree", "add", "topic-a"), 1)
+
+		// topic-b only exists on origin
+		stdout, _, err := testutil.CaptureOutput(func() {
+			assert.Equal(t, yascli.Run("worktree", "add", "topic-b"), 0)
+		})
+		assert.NilError(t, err)
+
+		worktreePath := filepath.Join(filepath.Dir(wd), "wt", "topic-b")
+		assert.Equal(t, stdout, "Created worktree for topic-b at "+worktreePath+"\n")
+		equalLines(t, mustExecOutput("git", "-C", worktreePath, "branch", "--show-current"), "topic-b")
+		equalLines(t, mustExecOutput("git", "rev-parse", "--abbrev-ref", "topic-b@{upstream}"), "origin/topic-b")
+
+		// The current branch is unchanged
+		equalLines(t, mustExecOutput("git", "branch", "--show-current"), "topic-a")
+
+		// Local changes are not discarded without --force
+		assert.NilError(t, os.WriteFile(filepath.Join(worktreePath, "b"), []byte("changed\n"), 0o644))
+		assert.Equal(t, yascli.Run("worktree", "remove", "topic-b"), 1)
+		assert.Equal(t, yascli.Run("worktree", "remove", "--force", "topic-b"), 0)
+
+		_, err = os.Stat(worktreePath)
+		assert.Assert(t, os.IsNotExist(err))
+
+		assert.Equal(t, yascli.Run("worktree", "remove", "topic-b"), 1)
+		assert.Equal(t, yascli.Run("worktree", "remove", "topic-a"), 1)
+	})
+}
+
+func TestCommandsInsideWorktree(t *testing.T) {
+	testutil.WithTempWorkingDir(t, func() {
+		testutil.ExecOrFail(t, `
+			git init -q --initial-branch=main repo
+			cd repo
+
+			touch main
+			git add main
+			git commit -m "main-0"
+
+			git checkout -b topic-a
+			touch a
+			git add a
+			git commit -m "topic-a-0"
+
+			git checkout -b topic-b
+			touch b
+			git add b
+			git commit -m "topic-b-0"
+
+			git checkout main
+			echo 1 > main
+			git commit -a -m "main-1"
+		`)
+
+		assert.NilError(t, os.Chdir("repo"))
+
+		assert.Equal(t, yascli.Run("config", "set", "--trunk-branch=main", "--worktree-dir=../wt"), 0)
+		assert.Equal(t, yascli.Run("add", "--branch=topic-a", "--parent=main"), 0)
+		assert.Equal(t, yascli.Run("add", "--branch=topic-b", "--parent=topic-a"), 0)
+		assert.Equal(t, yascli.Run("worktree", "add", "topic-b"), 0)
+
+		// In a linked worktree, .git is a file rather than a directory, so the
+		// config and state are in the main worktree's git directory
+		assert.NilError(t, os.Chdir("../wt/topic-b"))
+
+		stdout, _, err := testutil.CaptureOutput(func() {
+			assert.Equal(t, yascli.Run("list"), 0)
+		})
+		assert.NilError(t, err)
+		assert.Assert(t, cmp.Contains(stdout, "topic-b"))
+
+		assert.Equal(t, yascli.Run("restack"), 0)
+		assert.Equal(t, yascli.Run("branch", "topic-c"), 0)
+
+		equalLines(t, mustExecOutput("git", "log", "--pretty=%s", "topic-b", "--"), `
+			topic-b-0
+			topic-a-0
+			main-1
+			main-0
+		`)
+
+		// Changes made in the worktree are seen from the main worktree
+		assert.NilError(t, os.Chdir("../../repo"))
+
+		stdout, _, err = testutil.CaptureOutput(func() {
+			assert.Equal(t, yascli.Run("list"), 0)
+		})
+		assert.NilError(t, err)
+		assert.Assert(t, cmp.Contains(stdout, "topic-c"))
+	})
+}